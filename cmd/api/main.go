@@ -1,6 +1,13 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -8,19 +15,117 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/template/html"
 	"github.com/msoovali/pipeline-locker/internal/app"
+	"github.com/msoovali/pipeline-locker/internal/domain"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			migrate(os.Args[2:])
+			return
+		case "export":
+			export(os.Args[2:])
+			return
+		case "import":
+			importExport(os.Args[2:])
+			return
+		case "migrate-keys":
+			migrateKeys(os.Args[2:])
+			return
+		}
+	}
 	htmlEngine := html.New("./views", ".html")
-	router := fiber.New(fiber.Config{
+	router := fiber.New(app.ProxyConfig(fiber.Config{
 		ReadTimeout:  time.Second * 30,
 		WriteTimeout: time.Second * 30,
 		Views:        htmlEngine,
-	})
+	}))
 	router.Use(logger.New())
 	router.Use(favicon.New(favicon.Config{
 		File: "./views/favicon.ico",
 	}))
 	app := app.New(router)
-	app.Log.Error.Fatal(router.Listen(app.Config.Addr))
+	go func() {
+		if err := router.Listen(app.Config.Addr); err != nil {
+			app.Log.Error.Fatal(err)
+		}
+	}()
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
+	<-shutdown
+	app.Log.Info.Printf("Shutting down")
+	// Shutdown waits for requests in progress, so that nothing is recorded in background after application is closed
+	if err := router.Shutdown(); err != nil {
+		app.Log.Error.Printf("Failed to shut down server: %v", err)
+	}
+	app.Close()
+}
+
+// migrate copies state between storages: pipeline-locker migrate -from redis -to postgres [-dry-run]
+func migrate(args []string) {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	from := flags.String("from", "", "source storage: memory, redis, postgres, sqlite, bolt or etcd")
+	to := flags.String("to", "", "target storage: memory, redis, postgres, sqlite, bolt or etcd")
+	dryRun := flags.Bool("dry-run", false, "count pipelines, events, deployments and unlock requests to migrate without writing either storage")
+	flags.Parse(args)
+	if *from == "" || *to == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if _, err := app.Migrate(app.MigrationConfig{From: *from, To: *to, DryRun: *dryRun}); err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+}
+
+// export writes state of configured storage: pipeline-locker export [-format json|csv] [-o file]
+func export(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", domain.ExportFormatJSON, "export format: json or csv, csv holds pipelines without history")
+	output := flags.String("o", "", "output file, stdout when empty")
+	flags.Parse(args)
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("Export failed: %v", err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := app.Export(w, *format); err != nil {
+		log.Fatalf("Export failed: %v", err)
+	}
+}
+
+// importExport stores export in configured storage: pipeline-locker import [-format json|csv] [-strategy skip|overwrite|merge] file
+func importExport(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", domain.ExportFormatJSON, "export format: json or csv")
+	strategy := flags.String("strategy", string(domain.ImportSkip), "what to do with pipelines already stored: skip, overwrite or merge")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(flags.Output(), "Usage: import [flags] file")
+		flags.PrintDefaults()
+		os.Exit(2)
+	}
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+	defer file.Close()
+	if _, err = app.Import(file, *format, domain.ImportStrategy(*strategy)); err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+}
+
+// migrateKeys moves data of configured storage to current pipeline keys: pipeline-locker migrate-keys [-strategy newest|keep-all] [-dry-run]
+func migrateKeys(args []string) {
+	flags := flag.NewFlagSet("migrate-keys", flag.ExitOnError)
+	strategy := flags.String("strategy", string(domain.KeyConflictNewestLock), "what to do with pipelines sharing a key: newest keeps most recently locked one, keep-all leaves them under stored keys")
+	dryRun := flags.Bool("dry-run", false, "report pipelines not stored under their current key without migrating them")
+	flags.Parse(args)
+	if err := app.MigrateKeys(domain.KeyConflictStrategy(*strategy), *dryRun); err != nil {
+		log.Fatalf("Key migration failed: %v", err)
+	}
 }
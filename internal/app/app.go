@@ -1,29 +1,57 @@
 package app
 
 import (
-	redis_pkg_v8 "github.com/go-redis/redis/v8"
-	redis_pkg_v9 "github.com/go-redis/redis/v9"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/msoovali/pipeline-locker/internal/domain"
 	"github.com/msoovali/pipeline-locker/internal/handler"
 	"github.com/msoovali/pipeline-locker/internal/logger"
+	"github.com/msoovali/pipeline-locker/internal/repository/bolt"
+	"github.com/msoovali/pipeline-locker/internal/repository/etcd"
 	"github.com/msoovali/pipeline-locker/internal/repository/memory"
-	redis_v6 "github.com/msoovali/pipeline-locker/internal/repository/redis/v6"
-	redis_v7 "github.com/msoovali/pipeline-locker/internal/repository/redis/v7"
+	"github.com/msoovali/pipeline-locker/internal/repository/postgres"
+	redis_repository "github.com/msoovali/pipeline-locker/internal/repository/redis"
+	"github.com/msoovali/pipeline-locker/internal/repository/sqlite"
 	"github.com/msoovali/pipeline-locker/internal/service"
+	"go.etcd.io/bbolt"
 )
 
 type repositories struct {
-	PipelineRepository domain.PipelineRepository
+	PipelineRepository          domain.PipelineRepository
+	OverrideTokenRepository     domain.OverrideTokenRepository
+	UnlockRequestRepository     domain.UnlockRequestRepository
+	DeploymentRepository        domain.DeploymentRepository
+	PipelineEventRepository     domain.PipelineEventRepository
+	PipelineDiscoveryRepository domain.PipelineDiscoveryRepository
+	BlockedAttemptRepository    domain.BlockedAttemptRepository
+	// BackupRepository is nil when storage doesn't support backups
+	BackupRepository domain.BackupRepository
 }
 
 type services struct {
-	PipelineService domain.PipelineService
+	PipelineService      domain.PipelineService
+	OverrideService      domain.OverrideService
+	UnlockRequestService domain.UnlockRequestService
+	DeploymentService    domain.DeploymentService
+	DiscoveryService     domain.PipelineDiscoveryService
+	BlockedService       domain.BlockedAttemptService
+	BackupService        domain.BackupService
+	ExportService        domain.ExportService
 }
 
 type handlers struct {
-	HealthHandlers   handler.HealthHandlers
-	PipelineHandlers handler.PipelineHandlers
+	HealthHandlers        handler.HealthHandlers
+	PipelineHandlers      handler.PipelineHandlers
+	OverrideHandlers      handler.OverrideHandlers
+	UnlockRequestHandlers handler.UnlockRequestHandlers
+	DeploymentHandlers    handler.DeploymentHandlers
+	BackupHandlers        handler.BackupHandlers
+	ExportHandlers        handler.ExportHandlers
 }
 
 type Application struct {
@@ -40,6 +68,7 @@ func New(router *fiber.App) *Application {
 	}
 	app.parseConfig()
 	app.initRepositories()
+	app.checkAliasedPipelines()
 	app.initServices()
 	app.initHandlers()
 	app.registerRoutes(router)
@@ -47,67 +76,429 @@ func New(router *fiber.App) *Application {
 	return app
 }
 
+// Close waits until checks and blocked attempts recorded in background are stored and closes storage, so that memory
+// storage writes final snapshot. Call it after router is shut down so that no request uses them while closing.
+func (a *Application) Close() {
+	a.Services.DiscoveryService.Close()
+	a.Services.BlockedService.Close()
+	if err := closeRepositories(a.Repositories); err != nil {
+		a.Log.Error.Printf("Failed to close storage: %v", err)
+	}
+}
+
 func (a *Application) initRepositories() {
+	a.Repositories = a.initStorageRepositories(a.Config.storage)
+}
+
+// initStorageRepositories opens given storage, configuration of the storage must be parsed beforehand
+func (a *Application) initStorageRepositories(storage string) *repositories {
 	var repositories *repositories
-	redisConfig := a.Config.redisConfig
-	if redisConfig != nil {
-		if redisConfig.version == 6 {
-			repositories = initRedis6Repositories(a.Config)
-		} else if redisConfig.version == 7 {
-			repositories = initRedis7Repositories(a.Config)
-		}
+	switch storage {
+	case storageRedis:
+		repositories = a.initRedisRepositories()
+	case storagePostgres:
+		repositories = a.initPostgresRepositories()
+	case storageSQLite:
+		repositories = a.initSQLiteRepositories()
+	case storageBolt:
+		repositories = a.initBoltRepositories()
+	case storageEtcd:
+		repositories = a.initEtcdRepositories()
 	}
 	if repositories == nil {
-		repositories = initInMemoryRepositories(a.Config)
+		repositories = a.initInMemoryRepositories()
 	}
-	a.Repositories = repositories
+
+	return repositories
+}
+
+func (a *Application) initInMemoryRepositories() *repositories {
+	config := a.Config
+	var pipelineRepository domain.PipelineRepository = memory.NewPipelineRepository(config.pipelinesCaseSensitive)
+	if config.memoryConfig != nil {
+		persistentRepository, err := memory.NewPersistentPipelineRepository(config.pipelinesCaseSensitive, memory.PersistenceConfig{
+			SnapshotPath:     config.memoryConfig.snapshotPath,
+			SnapshotInterval: config.memoryConfig.snapshotInterval,
+		}, a.Log)
+		if err != nil {
+			a.Log.Error.Fatalf("Failed to restore pipelines from snapshot: %v", err)
+		}
+		a.Log.Info.Printf("Persisting pipelines to %s", config.memoryConfig.snapshotPath)
+		pipelineRepository = persistentRepository
+	}
+	return a.newInMemoryRepositories(pipelineRepository)
 }
 
-func initInMemoryRepositories(config *ApplicationConfig) *repositories {
+func (a *Application) newInMemoryRepositories(pipelineRepository domain.PipelineRepository) *repositories {
+	config := a.Config
 	return &repositories{
-		PipelineRepository: memory.NewPipelineRepository(config.pipelinesCaseSensitive),
+		PipelineRepository:          pipelineRepository,
+		OverrideTokenRepository:     memory.NewOverrideTokenRepository(config.pipelinesCaseSensitive),
+		UnlockRequestRepository:     memory.NewUnlockRequestRepository(),
+		DeploymentRepository:        memory.NewDeploymentRepository(config.pipelinesCaseSensitive),
+		PipelineEventRepository:     memory.NewPipelineEventRepository(config.pipelinesCaseSensitive),
+		PipelineDiscoveryRepository: memory.NewPipelineDiscoveryRepository(config.pipelinesCaseSensitive),
+		BlockedAttemptRepository:    memory.NewBlockedAttemptRepository(config.pipelinesCaseSensitive),
 	}
 }
 
-func initRedis6Repositories(config *ApplicationConfig) *repositories {
-	client := initRedis6Client(config.redisConfig)
+func (a *Application) initRedisRepositories() *repositories {
+	config := a.Config
+	client := a.initRedisClient(config.redisConfig)
+	keyPrefix := config.redisConfig.keyPrefix
+	pipelineRepository := redis_repository.NewPipelineRepository(client, config.pipelinesCaseSensitive, keyPrefix, a.Log)
+	if config.redisConfig.migrateKeys && !config.keyMigration.dryRun {
+		a.migrateRedisKeys(pipelineRepository)
+	} else {
+		a.checkRedisKeys(pipelineRepository)
+	}
+	a.preparePipelineKeys(pipelineRepository)
+	// REDIS_MIGRATE_KEYS rebuilds locked pipelines index without migrating pipeline keys, locks moved by either
+	// migration are missing from the index until indexed
+	if (config.redisConfig.migrateKeys || config.keyMigration.enabled) && !config.keyMigration.dryRun {
+		a.indexRedisLockedPipelines(pipelineRepository)
+	} else {
+		a.checkRedisLockIndex(pipelineRepository)
+	}
 	return &repositories{
-		PipelineRepository: redis_v6.NewPipelineRepository(client, config.pipelinesCaseSensitive),
+		PipelineRepository:          pipelineRepository,
+		OverrideTokenRepository:     redis_repository.NewOverrideTokenRepository(client, config.pipelinesCaseSensitive, keyPrefix),
+		UnlockRequestRepository:     redis_repository.NewUnlockRequestRepository(client, keyPrefix),
+		DeploymentRepository:        redis_repository.NewDeploymentRepository(client, config.pipelinesCaseSensitive, keyPrefix),
+		PipelineEventRepository:     redis_repository.NewPipelineEventRepository(client, config.pipelinesCaseSensitive, keyPrefix),
+		PipelineDiscoveryRepository: redis_repository.NewPipelineDiscoveryRepository(client, config.pipelinesCaseSensitive, keyPrefix),
+		BlockedAttemptRepository:    redis_repository.NewBlockedAttemptRepository(client, config.pipelinesCaseSensitive, keyPrefix),
+	}
+}
+
+func (a *Application) initPostgresRepositories() *repositories {
+	db, err := postgres.Open(a.Config.postgresURL)
+	if err != nil {
+		a.Log.Error.Fatalf("Failed to open postgres database: %v", err)
 	}
+	return a.newPostgresRepositories(db)
 }
 
-func initRedis7Repositories(config *ApplicationConfig) *repositories {
-	client := initRedis7Client(config.redisConfig)
+func (a *Application) newPostgresRepositories(db *sql.DB) *repositories {
+	pipelineRepository := postgres.NewPipelineRepository(db, a.Config.pipelinesCaseSensitive)
+	a.preparePipelineKeys(pipelineRepository)
 	return &repositories{
-		PipelineRepository: redis_v7.NewPipelineRepository(client, config.pipelinesCaseSensitive),
+		PipelineRepository:          pipelineRepository,
+		OverrideTokenRepository:     postgres.NewOverrideTokenRepository(db, a.Config.pipelinesCaseSensitive),
+		UnlockRequestRepository:     postgres.NewUnlockRequestRepository(db),
+		DeploymentRepository:        postgres.NewDeploymentRepository(db, a.Config.pipelinesCaseSensitive),
+		PipelineEventRepository:     postgres.NewPipelineEventRepository(db, a.Config.pipelinesCaseSensitive),
+		PipelineDiscoveryRepository: postgres.NewPipelineDiscoveryRepository(db, a.Config.pipelinesCaseSensitive),
+		BlockedAttemptRepository:    postgres.NewBlockedAttemptRepository(db, a.Config.pipelinesCaseSensitive),
 	}
 }
 
-func initRedis6Client(config *redisConfig) *redis_pkg_v8.Client {
-	return redis_pkg_v8.NewClient(&redis_pkg_v8.Options{
-		Addr:     config.addr,
-		Username: config.username,
-		Password: config.password,
-	})
+func (a *Application) initSQLiteRepositories() *repositories {
+	db, err := sqlite.Open(a.Config.sqlitePath)
+	if err != nil {
+		a.Log.Error.Fatalf("Failed to open sqlite database: %v", err)
+	}
+	return a.newSQLiteRepositories(db)
 }
 
-func initRedis7Client(config *redisConfig) *redis_pkg_v9.Client {
-	return redis_pkg_v9.NewClient(&redis_pkg_v9.Options{
-		Addr:     config.addr,
-		Username: config.username,
-		Password: config.password,
+func (a *Application) newSQLiteRepositories(db *sql.DB) *repositories {
+	pipelineRepository := sqlite.NewPipelineRepository(db, a.Config.pipelinesCaseSensitive)
+	a.preparePipelineKeys(pipelineRepository)
+	return &repositories{
+		PipelineRepository:          pipelineRepository,
+		OverrideTokenRepository:     sqlite.NewOverrideTokenRepository(db, a.Config.pipelinesCaseSensitive),
+		UnlockRequestRepository:     sqlite.NewUnlockRequestRepository(db),
+		DeploymentRepository:        sqlite.NewDeploymentRepository(db, a.Config.pipelinesCaseSensitive),
+		PipelineEventRepository:     sqlite.NewPipelineEventRepository(db, a.Config.pipelinesCaseSensitive),
+		PipelineDiscoveryRepository: sqlite.NewPipelineDiscoveryRepository(db, a.Config.pipelinesCaseSensitive),
+		BlockedAttemptRepository:    sqlite.NewBlockedAttemptRepository(db, a.Config.pipelinesCaseSensitive),
+	}
+}
+
+func (a *Application) initBoltRepositories() *repositories {
+	db, err := bolt.Open(a.Config.boltPath)
+	if err != nil {
+		a.Log.Error.Fatalf("Failed to open bolt database: %v", err)
+	}
+	return a.newBoltRepositories(db)
+}
+
+func (a *Application) newBoltRepositories(db *bbolt.DB) *repositories {
+	pipelineRepository := bolt.NewPipelineRepository(db, a.Config.pipelinesCaseSensitive)
+	a.preparePipelineKeys(pipelineRepository)
+	return &repositories{
+		PipelineRepository:          pipelineRepository,
+		OverrideTokenRepository:     bolt.NewOverrideTokenRepository(db, a.Config.pipelinesCaseSensitive),
+		UnlockRequestRepository:     bolt.NewUnlockRequestRepository(db),
+		DeploymentRepository:        bolt.NewDeploymentRepository(db, a.Config.pipelinesCaseSensitive),
+		PipelineEventRepository:     bolt.NewPipelineEventRepository(db, a.Config.pipelinesCaseSensitive),
+		PipelineDiscoveryRepository: bolt.NewPipelineDiscoveryRepository(db, a.Config.pipelinesCaseSensitive),
+		BlockedAttemptRepository:    bolt.NewBlockedAttemptRepository(db, a.Config.pipelinesCaseSensitive),
+		BackupRepository:            bolt.NewBackupRepository(db),
+	}
+}
+
+func (a *Application) initEtcdRepositories() *repositories {
+	client, err := etcd.Open(etcd.Config{
+		Endpoints: a.Config.etcdConfig.endpoints,
+		Username:  a.Config.etcdConfig.username,
+		Password:  a.Config.etcdConfig.password,
 	})
+	if err != nil {
+		a.Log.Error.Fatalf("Failed to connect to etcd: %v", err)
+	}
+	pipelineRepository := etcd.NewPipelineRepository(client, a.Config.pipelinesCaseSensitive)
+	a.preparePipelineKeys(pipelineRepository)
+	return &repositories{
+		PipelineRepository:          pipelineRepository,
+		OverrideTokenRepository:     etcd.NewOverrideTokenRepository(client, a.Config.pipelinesCaseSensitive),
+		UnlockRequestRepository:     etcd.NewUnlockRequestRepository(client),
+		DeploymentRepository:        etcd.NewDeploymentRepository(client, a.Config.pipelinesCaseSensitive),
+		PipelineEventRepository:     etcd.NewPipelineEventRepository(client, a.Config.pipelinesCaseSensitive),
+		PipelineDiscoveryRepository: etcd.NewPipelineDiscoveryRepository(client, a.Config.pipelinesCaseSensitive),
+		BlockedAttemptRepository:    etcd.NewBlockedAttemptRepository(client, a.Config.pipelinesCaseSensitive),
+	}
+}
+
+// openStorageReadOnly opens given storage without schema migrations, key migration or any other write,
+// domain.ErrStorageNotInitialized is returned for storage not initialized by the service yet
+func (a *Application) openStorageReadOnly(storage string) (*repositories, error) {
+	switch storage {
+	case storagePostgres:
+		db, err := postgres.OpenMigrated(a.Config.postgresURL)
+		if err != nil {
+			return nil, err
+		}
+		return a.newPostgresRepositories(db), nil
+	case storageSQLite:
+		db, err := sqlite.OpenMigrated(a.Config.sqlitePath)
+		if err != nil {
+			return nil, err
+		}
+		return a.newSQLiteRepositories(db), nil
+	case storageBolt:
+		db, err := bolt.OpenMigrated(a.Config.boltPath)
+		if err != nil {
+			return nil, err
+		}
+		return a.newBoltRepositories(db), nil
+	case storageMemory:
+		pipelineRepository, err := memory.NewRestoredPipelineRepository(a.Config.pipelinesCaseSensitive, a.Config.memoryConfig.snapshotPath)
+		if err != nil {
+			return nil, err
+		}
+		return a.newInMemoryRepositories(pipelineRepository), nil
+	}
+	// redis and etcd only check keys on open while key migration is a dry run
+	a.Config.keyMigration.dryRun = true
+
+	return a.initStorageRepositories(storage), nil
+}
+
+type redisKeyMigrator interface {
+	MigrateUnprefixedKeys() (int, error)
+	CountUnprefixedKeys() (int, error)
+}
+
+// migrateRedisKeys moves pipelines stored without key prefix by earlier versions into key prefix namespace
+func (a *Application) migrateRedisKeys(migrator redisKeyMigrator) {
+	migrated, err := migrator.MigrateUnprefixedKeys()
+	if err != nil {
+		a.Log.Error.Fatalf("Failed to migrate redis keys, %d keys migrated: %v", migrated, err)
+	}
+	a.Log.Info.Printf("Migrated %d redis keys into key prefix %s", migrated, a.Config.redisConfig.keyPrefix)
+}
+
+// checkRedisKeys refuses to open redis storage holding pipelines stored without key prefix by earlier versions,
+// they would not be found and their locks would hold back no deploy
+func (a *Application) checkRedisKeys(migrator redisKeyMigrator) {
+	unprefixed, err := migrator.CountUnprefixedKeys()
+	if err != nil {
+		a.Log.Error.Fatalf("Failed to check redis keys: %v", err)
+	}
+	if unprefixed == 0 {
+		return
+	}
+	if a.Config.keyMigration.dryRun {
+		a.Log.Info.Printf("Found %d pipelines stored without key prefix, they would be moved into key prefix %s", unprefixed, a.Config.redisConfig.keyPrefix)
+		return
+	}
+	a.Log.Error.Fatalf("Found %d pipelines stored without key prefix by earlier version, move them into key prefix %s with `pipeline-locker migrate-keys` or start once with %s=true", unprefixed, a.Config.redisConfig.keyPrefix, redisMigrateKeysKey)
+}
+
+type keyedPipelineRepository interface {
+	domain.PipelineRepository
+	domain.PipelineKeyRepository
+}
+
+// preparePipelineKeys migrates pipeline keys when storage is opened by migrate-keys command, otherwise only
+// checks them
+func (a *Application) preparePipelineKeys(repository keyedPipelineRepository) {
+	if a.Config.keyMigration.enabled {
+		a.migratePipelineKeys(repository)
+		return
+	}
+	a.checkPipelineKeys(repository)
+}
+
+// checkPipelineKeys refuses to open storage holding data under other than current pipeline keys, e.g. written by
+// earlier versions or before PIPELINES_CASE_SENSITIVE was changed, as such pipelines are not found. Pipelines
+// sharing a key are left under their stored keys until merged, so they are only logged.
+func (a *Application) checkPipelineKeys(repository keyedPipelineRepository) {
+	keyService := service.NewPipelineKeyService(repository, repository, a.Config.pipelinesCaseSensitive)
+	report, err := keyService.Check(context.Background())
+	if err != nil {
+		a.Log.Error.Fatalf("Failed to check pipeline keys: %v", err)
+	}
+	colliding := a.logKeyCollisions(report.Collisions)
+	migratable := 0
+	for _, identifier := range report.Orphaned {
+		if !colliding[identifier] {
+			migratable++
+		}
+	}
+	if migratable > 0 {
+		a.Log.Error.Fatalf("Found %d pipelines not stored under their current key, they are not found until moved with `pipeline-locker migrate-keys`", migratable)
+	}
+	if kept := len(report.Orphaned); kept > 0 {
+		a.Log.Error.Printf("Found %d colliding pipelines under their stored keys, they are not found until merged with `pipeline-locker migrate-keys -strategy %s`", kept, domain.KeyConflictNewestLock)
+	}
+}
+
+// migratePipelineKeys moves data stored under pipeline keys of other encoding or case sensitivity to current
+// keys. Pipelines which get the same key are left under their stored keys and logged, unless command merges them.
+func (a *Application) migratePipelineKeys(repository keyedPipelineRepository) {
+	keyService := service.NewPipelineKeyService(repository, repository, a.Config.pipelinesCaseSensitive)
+	report, err := keyService.Migrate(context.Background(), a.Config.keyMigration.strategy, a.Config.keyMigration.dryRun)
+	if err != nil {
+		migrated := 0
+		if report != nil {
+			migrated = report.Migrated
+		}
+		a.Log.Error.Fatalf("Failed to migrate pipeline keys, %d keys migrated: %v", migrated, err)
+	}
+	a.logKeyMigration(report)
+}
+
+// logKeyCollisions logs every pipeline sharing a key with other pipeline, so PIPELINES_CASE_SENSITIVE change
+// is noticed before locks go missing. Returns colliding pipelines.
+func (a *Application) logKeyCollisions(collisions [][]domain.Pipeline) map[domain.PipelineIdentifier]bool {
+	colliding := make(map[domain.PipelineIdentifier]bool)
+	for _, collision := range collisions {
+		a.Log.Error.Printf("Pipelines %s share key %s with %s=%t", formatPipelines(collision), collision[0].GetKey(a.Config.pipelinesCaseSensitive), pipelinesCaseSensitiveKey, a.Config.pipelinesCaseSensitive)
+		for _, pipeline := range collision {
+			colliding[pipeline.PipelineIdentifier] = true
+		}
+	}
+
+	return colliding
+}
+
+func (a *Application) logKeyMigration(report *domain.KeyMigrationReport) {
+	a.logKeyCollisions(report.Collisions)
+	switch {
+	case report.DryRun:
+		a.Log.Info.Printf("Found %d pipelines not stored under their current key, %d colliding pipelines would be kept and %d dropped", len(report.Orphaned), report.Kept, report.Dropped)
+		return
+	case report.Migrated > 0 || report.Dropped > 0:
+		a.Log.Info.Printf("Migrated %d pipeline keys to current key encoding, dropped %d colliding pipelines", report.Migrated, report.Dropped)
+	}
+	if report.Kept > 0 {
+		a.Log.Error.Printf("Left %d colliding pipelines under their stored keys, they are not found until merged with `pipeline-locker migrate-keys -strategy %s`", report.Kept, domain.KeyConflictNewestLock)
+	}
+}
+
+func formatPipelines(pipelines []domain.Pipeline) string {
+	formatted := make([]string, 0, len(pipelines))
+	for _, pipeline := range pipelines {
+		lock := "unlocked"
+		if pipeline.IsLocked(time.Now()) {
+			lock = "locked by " + pipeline.LockedBy
+		}
+		formatted = append(formatted, fmt.Sprintf("%s (%s)", pipeline.Name(), lock))
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+// checkAliasedPipelines logs locks stored under environment alias before the alias was configured, requests
+// of every spelling resolve to canonical environment so such lock holds back no deploy
+func (a *Application) checkAliasedPipelines() {
+	pipelines, err := a.Repositories.PipelineRepository.FindLockedPipelines(context.Background())
+	if err != nil {
+		a.Log.Error.Printf("Failed to check locked pipelines for environment aliases: %v", err)
+		return
+	}
+	for _, pipeline := range pipelines {
+		if canonical := a.Config.environmentAliases.Resolve(pipeline.PipelineIdentifier); canonical != pipeline.PipelineIdentifier {
+			a.Log.Error.Printf("Pipeline %s is locked under alias of %s, the lock holds back no deploy and can be unlocked only without the alias in %s", formatPipelines([]domain.Pipeline{pipeline}), canonical.Name(), environmentAliasesKey)
+		}
+	}
+}
+
+type redisLockIndexer interface {
+	IndexLockedPipelines() (int, error)
+	CountUnindexedLocks() (int, error)
+}
+
+// indexRedisLockedPipelines adds locks stored by earlier versions to locked pipelines index
+func (a *Application) indexRedisLockedPipelines(indexer redisLockIndexer) {
+	indexed, err := indexer.IndexLockedPipelines()
+	if err != nil {
+		a.Log.Error.Fatalf("Failed to index locked redis pipelines: %v", err)
+	}
+	a.Log.Info.Printf("Indexed %d locked redis pipelines", indexed)
+}
+
+// checkRedisLockIndex refuses to open redis storage holding locks missing from locked pipelines index, they would
+// not be listed as locked
+func (a *Application) checkRedisLockIndex(indexer redisLockIndexer) {
+	unindexed, err := indexer.CountUnindexedLocks()
+	if err != nil {
+		a.Log.Error.Fatalf("Failed to check locked redis pipelines index: %v", err)
+	}
+	if unindexed == 0 {
+		return
+	}
+	if a.Config.keyMigration.dryRun {
+		a.Log.Info.Printf("Found %d locked redis pipelines missing from locked pipelines index, they would be indexed", unindexed)
+		return
+	}
+	a.Log.Error.Fatalf("Found %d locked redis pipelines missing from locked pipelines index, they are not listed as locked until indexed with `pipeline-locker migrate-keys` or by starting once with %s=true", unindexed, redisMigrateKeysKey)
 }
 
 func (a *Application) initServices() {
+	timeouts := service.PipelineTimeouts{
+		Status: a.Config.statusTimeout,
+		Lock:   a.Config.lockTimeout,
+		List:   a.Config.listTimeout,
+	}
 	a.Services = &services{
-		PipelineService: service.NewPipelineService(a.Repositories.PipelineRepository, a.Config.allowOverlocking),
+		PipelineService: service.NewPipelineService(a.Repositories.PipelineRepository, a.Repositories.DeploymentRepository, a.Repositories.PipelineEventRepository, service.PipelineServiceConfig{
+			AllowOverlocking:      a.Config.allowOverlocking,
+			ProtectedEnvironments: a.Config.protectedEnvironments,
+			PromotionOrder:        a.Config.promotionOrder,
+			EnvironmentAliases:    a.Config.environmentAliases,
+			Timeouts:              timeouts,
+		}),
+		OverrideService:      service.NewOverrideService(a.Repositories.OverrideTokenRepository, a.Repositories.PipelineEventRepository, a.Config.overrideTokenTTL, a.Config.environmentAliases, timeouts, a.Log),
+		UnlockRequestService: service.NewUnlockRequestService(a.Repositories.UnlockRequestRepository, a.Repositories.PipelineRepository, a.Repositories.PipelineEventRepository, reviewerNames(a.Config.unlockReviewers), a.Config.unlockRequestTTL, a.Config.environmentAliases, timeouts, a.Log),
+		DeploymentService:    service.NewDeploymentService(a.Repositories.DeploymentRepository, a.Config.environmentAliases, timeouts),
+		DiscoveryService:     service.NewPipelineDiscoveryService(a.Repositories.PipelineDiscoveryRepository, a.Config.environmentAliases, timeouts, a.Log),
+		BlockedService:       service.NewBlockedAttemptService(a.Repositories.BlockedAttemptRepository, a.Repositories.PipelineRepository, a.Config.environmentAliases, timeouts, a.Log),
+		BackupService:        service.NewBackupService(a.Repositories.BackupRepository, a.Log),
+		ExportService:        service.NewExportService(a.Repositories.PipelineRepository, a.Repositories.PipelineEventRepository, a.Repositories.DeploymentRepository, a.Config.identifierRules, a.Config.environmentAliases, a.Log),
 	}
 }
 
 func (a *Application) initHandlers() {
 	a.Handlers = &handlers{
-		HealthHandlers:   handler.NewHealthHandlers(),
-		PipelineHandlers: handler.NewPipelineHandlers(a.Services.PipelineService),
+		HealthHandlers:        handler.NewHealthHandlers(),
+		PipelineHandlers:      handler.NewPipelineHandlers(a.Services.PipelineService, a.Services.OverrideService, a.Services.UnlockRequestService, a.Services.DeploymentService, a.Services.DiscoveryService, a.Services.BlockedService, a.Config.identifierRules),
+		OverrideHandlers:      handler.NewOverrideHandlers(a.Services.OverrideService, a.Config.identifierRules),
+		UnlockRequestHandlers: handler.NewUnlockRequestHandlers(a.Services.UnlockRequestService, a.Config.identifierRules),
+		DeploymentHandlers:    handler.NewDeploymentHandlers(a.Services.DeploymentService, a.Config.identifierRules),
+		BackupHandlers:        handler.NewBackupHandlers(a.Services.BackupService),
+		ExportHandlers:        handler.NewExportHandlers(a.Services.ExportService),
 	}
 }
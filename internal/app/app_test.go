@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/repository/memory"
+)
+
+func TestApplication_Close(t *testing.T) {
+	t.Setenv(storageKey, storageMemory)
+	router := fiber.New()
+	app := New(router)
+	if status, body := sendRequest(t, router, fiber.MethodPost, "/v1/pipeline/lock", "", `{"project":"api","environment":"production","locked_by":"carol"}`); status != fiber.StatusCreated {
+		t.Fatalf("Expected pipeline to be locked, got %d %s", status, body)
+	}
+	if status, body := sendRequest(t, router, fiber.MethodGet, "/v1/pipeline/status/project/api/environment/production", "", ""); status != fiber.StatusLocked {
+		t.Fatalf("Expected status %d, got %d %s", fiber.StatusLocked, status, body)
+	}
+
+	app.Close()
+
+	known, err := app.Repositories.PipelineDiscoveryRepository.FindAll(context.Background())
+	if err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+	if len(known) != 1 || known[0].Name() != "api/production" {
+		t.Errorf("Expected check of api/production to be recorded before close returns, got %v", known)
+	}
+	pipeline, _ := app.Repositories.PipelineRepository.Find(context.Background(), domain.PipelineIdentifier{Project: "api", Environment: "production"})
+	attempts, err := app.Repositories.BlockedAttemptRepository.FindSince(context.Background(), pipeline.PipelineIdentifier, pipeline.LockedAt)
+	if err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+	if len(attempts) != 1 {
+		t.Errorf("Expected blocked attempt to be added before close returns, got %v", attempts)
+	}
+}
+
+func TestApplication_Close_memorySnapshot(t *testing.T) {
+	snapshotPath := filepath.Join(t.TempDir(), "pipelines.json")
+	t.Setenv(storageKey, storageMemory)
+	t.Setenv(memorySnapshotPathKey, snapshotPath)
+	router := fiber.New()
+	app := New(router)
+	if status, body := sendRequest(t, router, fiber.MethodPost, "/v1/pipeline/lock", "", `{"project":"api","environment":"production","locked_by":"carol"}`); status != fiber.StatusCreated {
+		t.Fatalf("Expected pipeline to be locked, got %d %s", status, body)
+	}
+
+	app.Close()
+
+	restored, err := memory.NewRestoredPipelineRepository(false, snapshotPath)
+	if err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+	pipeline, _ := restored.Find(context.Background(), domain.PipelineIdentifier{Project: "api", Environment: "production"})
+	if pipeline == nil || pipeline.LockedBy != "carol" {
+		t.Errorf("Expected lock of carol in final snapshot, got %v", pipeline)
+	}
+}
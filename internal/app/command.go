@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+// newCommandApplication creates application of command line command, storage is not opened. Everything is
+// logged to stderr, so output of the command can be piped.
+func newCommandApplication() *Application {
+	a := &Application{
+		Log: logger.New(),
+	}
+	a.Log.Info.SetOutput(os.Stderr)
+	a.parseConfig()
+
+	return a
+}
+
+// openStorage opens storage configured for the service with its services
+func (a *Application) openStorage() error {
+	if a.Config.storage == storageMemory && a.Config.memoryConfig == nil {
+		return fmt.Errorf("%s must be set to use memory storage from command line", memorySnapshotPathKey)
+	}
+	a.initRepositories()
+	a.initServices()
+
+	return nil
+}
+
+// Export writes all pipelines of configured storage to w in given format
+func Export(w io.Writer, format string) error {
+	a := newCommandApplication()
+	if err := a.openStorage(); err != nil {
+		return err
+	}
+	defer closeRepositories(a.Repositories)
+	export, err := a.Services.ExportService.Export(context.Background())
+	if err != nil {
+		return err
+	}
+
+	return domain.WriteExport(w, export, format)
+}
+
+// Import stores export read from r in configured storage
+func Import(r io.Reader, format string, strategy domain.ImportStrategy) (*domain.ImportReport, error) {
+	a := newCommandApplication()
+	if err := a.openStorage(); err != nil {
+		return nil, err
+	}
+	defer closeRepositories(a.Repositories)
+	export, err := domain.ReadExport(r, format)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.Services.ExportService.Import(context.Background(), export, strategy)
+}
+
+// MigrateKeys opens configured storage with key migration of given strategy, so data stored under other than
+// current pipeline keys, e.g. after PIPELINES_CASE_SENSITIVE was changed, is found again. Redis pipelines stored
+// without key prefix are moved into key prefix and locks missing from locked pipelines index are indexed as well.
+func MigrateKeys(strategy domain.KeyConflictStrategy, dryRun bool) error {
+	if err := strategy.Validate(); err != nil {
+		return fmt.Errorf("%w, supported strategies: %s, %s", err, domain.KeyConflictNewestLock, domain.KeyConflictKeepAll)
+	}
+	a := newCommandApplication()
+	a.Config.keyMigration = keyMigrationConfig{enabled: true, strategy: strategy, dryRun: dryRun}
+	if a.Config.redisConfig != nil {
+		a.Config.redisConfig.migrateKeys = true
+	}
+	if err := a.openStorage(); err != nil {
+		return err
+	}
+	closeRepositories(a.Repositories)
+
+	return nil
+}
@@ -1,8 +1,16 @@
 package app
 
 import (
+	"fmt"
 	"os"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/msoovali/pipeline-locker/internal/domain"
 )
 
 const (
@@ -12,27 +20,160 @@ const (
 	defaultAllowOverlocking       = false
 	pipelinesCaseSensitiveKey     = "PIPELINES_CASE_SENSITIVE"
 	defaultPipelinesCaseSensitive = true
+	storageKey                    = "STORAGE"
+	storageMemory                 = "memory"
+	storageRedis                  = "redis"
+	storagePostgres               = "postgres"
+	storageSQLite                 = "sqlite"
+	storageBolt                   = "bolt"
+	storageEtcd                   = "etcd"
+	postgresURLKey                = "POSTGRES_URL"
+	defaultPostgresURL            = "postgres://localhost:5432/pipeline_locker"
+	sqlitePathKey                 = "SQLITE_PATH"
+	defaultSQLitePath             = "pipeline-locker.db"
+	boltPathKey                   = "BOLT_PATH"
+	defaultBoltPath               = "pipeline-locker.bolt"
+	etcdEndpointsKey              = "ETCD_ENDPOINTS"
+	defaultEtcdEndpoints          = "localhost:2379"
+	etcdUsernameKey               = "ETCD_USERNAME"
+	etcdPasswordKey               = "ETCD_PASSWORD"
+	memorySnapshotPathKey         = "MEMORY_SNAPSHOT_PATH"
+	memorySnapshotIntervalKey     = "MEMORY_SNAPSHOT_INTERVAL"
+	defaultMemorySnapshotInterval = time.Minute
 	redisVersionKey               = "REDIS_VERSION"
+	redisURLKey                   = "REDIS_URL"
 	redisAddr                     = "REDIS_ADDR"
 	defaultRedisAddr              = "localhost:6379"
 	redisUsername                 = "REDIS_USERNAME"
 	defaultRedisUsername          = ""
 	redisPassword                 = "REDIS_PASSWORD"
 	defaultRedisPassword          = ""
+	redisDBKey                    = "REDIS_DB"
+	redisClusterKey               = "REDIS_CLUSTER"
+	redisSentinelMasterKey        = "REDIS_SENTINEL_MASTER"
+	redisSentinelAddrsKey         = "REDIS_SENTINEL_ADDRS"
+	redisSentinelUsernameKey      = "REDIS_SENTINEL_USERNAME"
+	redisSentinelPasswordKey      = "REDIS_SENTINEL_PASSWORD"
+	redisTLSKey                   = "REDIS_TLS"
+	redisTLSCAFileKey             = "REDIS_TLS_CA_FILE"
+	redisTLSCertFileKey           = "REDIS_TLS_CERT_FILE"
+	redisTLSKeyFileKey            = "REDIS_TLS_KEY_FILE"
+	redisTLSSkipVerifyKey         = "REDIS_TLS_SKIP_VERIFY"
+	redisPoolSizeKey              = "REDIS_POOL_SIZE"
+	redisMinIdleConnsKey          = "REDIS_MIN_IDLE_CONNS"
+	redisDialTimeoutKey           = "REDIS_DIAL_TIMEOUT"
+	redisReadTimeoutKey           = "REDIS_READ_TIMEOUT"
+	redisWriteTimeoutKey          = "REDIS_WRITE_TIMEOUT"
+	redisKeyPrefixKey             = "REDIS_KEY_PREFIX"
+	defaultRedisKeyPrefix         = "{pipeline-locker}/"
+	redisMigrateKeysKey           = "REDIS_MIGRATE_KEYS"
+	adminTokenKey                 = "ADMIN_TOKEN"
+	defaultAdminToken             = ""
+	overrideTokenTTLKey           = "OVERRIDE_TOKEN_TTL"
+	defaultOverrideTokenTTL       = 15 * time.Minute
+	protectedEnvironmentsKey      = "PROTECTED_ENVIRONMENTS"
+	unlockReviewersKey            = "UNLOCK_REVIEWERS"
+	unlockRequestTTLKey           = "UNLOCK_REQUEST_TTL"
+	defaultUnlockRequestTTL       = 24 * time.Hour
+	promotionOrderKey             = "PROMOTION_ORDER"
+	environmentAliasesKey         = "ENVIRONMENT_ALIASES"
+	storageStatusTimeoutKey       = "STORAGE_STATUS_TIMEOUT"
+	defaultStorageStatusTimeout   = 2 * time.Second
+	storageLockTimeoutKey         = "STORAGE_LOCK_TIMEOUT"
+	defaultStorageLockTimeout     = 5 * time.Second
+	storageListTimeoutKey         = "STORAGE_LIST_TIMEOUT"
+	defaultStorageListTimeout     = 10 * time.Second
+	identifierTrimKey             = "IDENTIFIER_TRIM"
+	defaultIdentifierTrim         = true
+	identifierMaxLengthKey        = "IDENTIFIER_MAX_LENGTH"
+	defaultIdentifierMaxLength    = 100
+	identifierPatternKey          = "IDENTIFIER_PATTERN"
+	identifierReservedNamesKey    = "IDENTIFIER_RESERVED_NAMES"
+	proxyHeaderKey                = "PROXY_HEADER"
+	trustedProxiesKey             = "TRUSTED_PROXIES"
 )
 
+var supportedStorages = []string{storageMemory, storageRedis, storagePostgres, storageSQLite, storageBolt, storageEtcd}
+
 type ApplicationConfig struct {
 	Addr                   string
 	allowOverlocking       bool
 	pipelinesCaseSensitive bool
-	redisConfig            *redisConfig
+	adminToken             string
+	overrideTokenTTL       time.Duration
+	protectedEnvironments  []string
+	unlockReviewers        map[string]string
+	unlockRequestTTL       time.Duration
+	promotionOrder         domain.PromotionOrder
+	environmentAliases     domain.EnvironmentAliases
+	// storage timeouts limit single pipeline operation including storage round trips
+	statusTimeout time.Duration
+	lockTimeout   time.Duration
+	listTimeout   time.Duration
+	// identifierRules restrict project and environment accepted from requests
+	identifierRules domain.IdentifierRules
+	storage         string
+	redisConfig     *redisConfig
+	postgresURL     string
+	sqlitePath      string
+	boltPath        string
+	etcdConfig      *etcdConfig
+	// memoryConfig is nil when memory storage is not persisted
+	memoryConfig *memoryConfig
+	// keyMigration is applied to pipeline keys when storage is opened by migrate-keys command, otherwise keys are
+	// only checked
+	keyMigration keyMigrationConfig
+}
+
+type keyMigrationConfig struct {
+	enabled  bool
+	strategy domain.KeyConflictStrategy
+	dryRun   bool
 }
 
 type redisConfig struct {
-	version  int
-	addr     string
+	// url overrides addrs, username, password, db and TLS when set
+	url      string
+	addrs    []string
 	username string
 	password string
+	db       int
+	// cluster connects to cluster without detecting it from server
+	cluster          bool
+	sentinelMaster   string
+	sentinelAddrs    []string
+	sentinelUsername string
+	sentinelPassword string
+	tls              redisTLSConfig
+	// zero pool sizes and timeouts leave client defaults
+	poolSize     int
+	minIdleConns int
+	dialTimeout  time.Duration
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+	// keyPrefix namespaces every key written to redis
+	keyPrefix string
+	// migrateKeys moves pipelines written without key prefix by earlier versions and indexes locks on startup
+	migrateKeys bool
+}
+
+type redisTLSConfig struct {
+	enabled    bool
+	caFile     string
+	certFile   string
+	keyFile    string
+	skipVerify bool
+}
+
+type memoryConfig struct {
+	snapshotPath     string
+	snapshotInterval time.Duration
+}
+
+type etcdConfig struct {
+	endpoints []string
+	username  string
+	password  string
 }
 
 func (a *Application) parseConfig() {
@@ -40,12 +181,100 @@ func (a *Application) parseConfig() {
 		Addr:                   a.getEnv(addrEnvKey, defaultAddr),
 		allowOverlocking:       a.getEnvBool(allowOverlockingKey, defaultAllowOverlocking),
 		pipelinesCaseSensitive: a.getEnvBool(pipelinesCaseSensitiveKey, defaultPipelinesCaseSensitive),
+		adminToken:             a.getEnv(adminTokenKey, defaultAdminToken),
+		overrideTokenTTL:       a.getEnvDuration(overrideTokenTTLKey, defaultOverrideTokenTTL),
+		protectedEnvironments:  a.getEnvList(protectedEnvironmentsKey),
+		unlockRequestTTL:       a.getEnvDuration(unlockRequestTTLKey, defaultUnlockRequestTTL),
+		promotionOrder:         a.parsePromotionOrder(a.getEnv(promotionOrderKey, "")),
+		environmentAliases:     a.parseEnvironmentAliases(a.getEnv(environmentAliasesKey, "")),
+		statusTimeout:          a.getEnvDuration(storageStatusTimeoutKey, defaultStorageStatusTimeout),
+		lockTimeout:            a.getEnvDuration(storageLockTimeoutKey, defaultStorageLockTimeout),
+		listTimeout:            a.getEnvDuration(storageListTimeoutKey, defaultStorageListTimeout),
+		identifierRules:        a.parseIdentifierRules(),
 	}
 
-	redisVersion := a.getEnvInt(redisVersionKey, 0)
-	if redisVersion != 0 {
-		a.parseRedisConfig(redisVersion)
+	reviewers, err := parseUnlockReviewers(a.getEnv(unlockReviewersKey, ""))
+	if err != nil {
+		a.Log.Error.Fatalf("Failed to parse %s: %v", unlockReviewersKey, err)
+	}
+	a.Config.unlockReviewers = reviewers
+	a.resolveAliasedEnvironments()
+
+	a.parseStorageConfig()
+}
+
+// resolveAliasedEnvironments replaces aliases in protected environments and promotion order by canonical
+// environments requests are resolved to, so that aliases don't bypass them
+func (a *Application) resolveAliasedEnvironments() {
+	protectedEnvironments, err := a.Config.environmentAliases.ResolveEnvironments(a.Config.protectedEnvironments)
+	if err != nil {
+		a.Log.Error.Fatalf("Failed to resolve aliases of %s: %v", protectedEnvironmentsKey, err)
+	}
+	promotionOrder, err := a.Config.promotionOrder.ResolveAliases(a.Config.environmentAliases)
+	if err != nil {
+		a.Log.Error.Fatalf("Failed to resolve aliases of %s: %v", promotionOrderKey, err)
+	}
+	a.Config.protectedEnvironments = protectedEnvironments
+	a.Config.promotionOrder = promotionOrder
+}
+
+// parseStorageConfig selects storage backend, redis is used when STORAGE is not set but REDIS_VERSION is
+// as configured by earlier versions
+func (a *Application) parseStorageConfig() {
+	storage := strings.ToLower(a.getEnv(storageKey, ""))
+	if storage == "" {
+		storage = storageMemory
+		if a.getEnvInt(redisVersionKey, 0) != 0 {
+			storage = storageRedis
+		}
+	}
+	if !a.parseBackendConfig(storage) {
+		a.Log.Error.Printf("Storage %s is not supported, falling back to memory based repository. Supported storages: %s", storage, strings.Join(supportedStorages, ", "))
+		storage = storageMemory
+		a.parseBackendConfig(storage)
 	}
+	a.Config.storage = storage
+}
+
+// parseBackendConfig reads settings of given storage backend, returns false if storage is not supported
+func (a *Application) parseBackendConfig(storage string) bool {
+	switch storage {
+	case storageRedis:
+		a.parseRedisConfig()
+	case storagePostgres:
+		a.Config.postgresURL = a.getEnv(postgresURLKey, defaultPostgresURL)
+	case storageSQLite:
+		a.Config.sqlitePath = a.getEnv(sqlitePathKey, defaultSQLitePath)
+	case storageBolt:
+		a.Config.boltPath = a.getEnv(boltPathKey, defaultBoltPath)
+	case storageEtcd:
+		a.Config.etcdConfig = &etcdConfig{
+			endpoints: splitList(a.getEnv(etcdEndpointsKey, defaultEtcdEndpoints)),
+			username:  a.getEnv(etcdUsernameKey, ""),
+			password:  a.getEnv(etcdPasswordKey, ""),
+		}
+	case storageMemory:
+		if snapshotPath := a.getEnv(memorySnapshotPathKey, ""); snapshotPath != "" {
+			a.Config.memoryConfig = &memoryConfig{
+				snapshotPath:     snapshotPath,
+				snapshotInterval: a.getEnvDuration(memorySnapshotIntervalKey, defaultMemorySnapshotInterval),
+			}
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+// ProxyConfig sets up router to read client address from PROXY_HEADER of requests sent by TRUSTED_PROXIES, other
+// requests are identified by their remote address
+func ProxyConfig(config fiber.Config) fiber.Config {
+	config.ProxyHeader = os.Getenv(proxyHeaderKey)
+	config.EnableTrustedProxyCheck = true
+	config.TrustedProxies = splitList(os.Getenv(trustedProxiesKey))
+
+	return config
 }
 
 func (a *Application) getEnv(key, fallback string) string {
@@ -79,16 +308,175 @@ func (a *Application) getEnvBool(key string, fallback bool) bool {
 	return fallback
 }
 
-func (a *Application) parseRedisConfig(version int) {
-	if version != 6 && version != 7 {
-		a.Log.Error.Printf("Redis version %d is not supported, falling back to memory based repository. Redis versions 6 and 7 are supported!", version)
-		return
+func (a *Application) getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if valueString, ok := os.LookupEnv(key); ok {
+		value, err := time.ParseDuration(valueString)
+		if err == nil && value > 0 {
+			return value
+		}
+		a.Log.Error.Printf("Failed to convert %s env value %s to positive duration. Falling back to default %s", key, valueString, fallback)
+	}
+
+	return fallback
+}
+
+// getEnvList returns comma separated env value as list, empty items are left out
+func (a *Application) getEnvList(key string) []string {
+	return splitList(a.getEnv(key, ""))
+}
+
+func splitList(value string) []string {
+	values := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+
+	return values
+}
+
+// parseIdentifierRules reads rules of project and environment names, empty pattern allows any characters and
+// invalid one falls back to default
+func (a *Application) parseIdentifierRules() domain.IdentifierRules {
+	rules := domain.IdentifierRules{
+		Trim:          a.getEnvBool(identifierTrimKey, defaultIdentifierTrim),
+		MaxLength:     a.getEnvInt(identifierMaxLengthKey, defaultIdentifierMaxLength),
+		ReservedNames: a.getEnvList(identifierReservedNamesKey),
+	}
+	if rules.MaxLength < 0 {
+		a.Log.Error.Printf("%s env value %d is negative. Falling back to default %d", identifierMaxLengthKey, rules.MaxLength, defaultIdentifierMaxLength)
+		rules.MaxLength = defaultIdentifierMaxLength
+	}
+	pattern := a.getEnv(identifierPatternKey, domain.DefaultIdentifierPattern)
+	if pattern == "" {
+		return rules
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		a.Log.Error.Printf("Failed to compile %s env value %s: %v. Falling back to default %s", identifierPatternKey, pattern, err, domain.DefaultIdentifierPattern)
+		compiled = regexp.MustCompile(domain.DefaultIdentifierPattern)
+	}
+	rules.Pattern = compiled
+
+	return rules
+}
+
+// parseUnlockReviewers parses comma separated reviewers with their tokens, e.g. "alice=<token>,bob=<token>".
+// Reviewer without token or token shared by reviewers is an error, as reviewer is identified by token.
+func parseUnlockReviewers(value string) (map[string]string, error) {
+	reviewers := make(map[string]string)
+	reviewerByToken := make(map[string]string)
+	for _, item := range splitList(value) {
+		name, token := item, ""
+		if i := strings.Index(item, "="); i >= 0 {
+			name, token = strings.TrimSpace(item[:i]), strings.TrimSpace(item[i+1:])
+		}
+		if name == "" || token == "" {
+			return nil, fmt.Errorf("reviewer %q has no name or token, expected <name>=<token>", name)
+		}
+		if _, exists := reviewers[name]; exists {
+			return nil, fmt.Errorf("reviewer %q is listed more than once", name)
+		}
+		if other, exists := reviewerByToken[token]; exists {
+			return nil, fmt.Errorf("reviewers %q and %q share token", other, name)
+		}
+		reviewers[name] = token
+		reviewerByToken[token] = name
+	}
+
+	return reviewers, nil
+}
+
+// reviewerNames returns sorted names of reviewers
+func reviewerNames(reviewers map[string]string) []string {
+	names := make([]string, 0, len(reviewers))
+	for name := range reviewers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// parsePromotionOrder parses semicolon separated environment orders, e.g. "dev,staging,production;payments=test,production".
+// Order prefixed with project name applies only to that project, order without prefix applies to all other projects.
+func (a *Application) parsePromotionOrder(value string) domain.PromotionOrder {
+	order := domain.PromotionOrder{
+		Projects: make(map[string][]string),
+	}
+	for _, definition := range strings.Split(value, ";") {
+		if strings.TrimSpace(definition) == "" {
+			continue
+		}
+		project := ""
+		if i := strings.Index(definition, "="); i >= 0 {
+			project = strings.TrimSpace(definition[:i])
+			definition = definition[i+1:]
+		}
+		environments := splitList(definition)
+		if project == "" {
+			order.Default = environments
+		} else {
+			order.Projects[project] = environments
+		}
+	}
+
+	return order
+}
+
+// parseEnvironmentAliases parses semicolon separated aliases of canonical environments, e.g.
+// "production=prod,prd;staging=stage;payments:production=live". Canonical environment prefixed with project name
+// and colon has aliases of that project only, they are resolved before aliases of all projects.
+func (a *Application) parseEnvironmentAliases(value string) domain.EnvironmentAliases {
+	var aliases domain.EnvironmentAliases
+	for _, definition := range strings.Split(value, ";") {
+		if strings.TrimSpace(definition) == "" {
+			continue
+		}
+		i := strings.Index(definition, "=")
+		if i < 0 {
+			a.Log.Error.Printf("Environment alias definition %q has no aliases, expected <environment>=<alias>,<alias>", definition)
+			continue
+		}
+		project, canonical := "", strings.TrimSpace(definition[:i])
+		if j := strings.Index(canonical, ":"); j >= 0 {
+			project, canonical = strings.TrimSpace(canonical[:j]), strings.TrimSpace(canonical[j+1:])
+		}
+		aliases.Add(project, canonical, splitList(definition[i+1:])...)
+	}
+
+	return aliases
+}
+
+func (a *Application) parseRedisConfig() {
+	tlsConfig := redisTLSConfig{
+		caFile:     a.getEnv(redisTLSCAFileKey, ""),
+		certFile:   a.getEnv(redisTLSCertFileKey, ""),
+		keyFile:    a.getEnv(redisTLSKeyFileKey, ""),
+		skipVerify: a.getEnvBool(redisTLSSkipVerifyKey, false),
 	}
+	// providing any of TLS settings enables TLS
+	tlsConfig.enabled = a.getEnvBool(redisTLSKey, tlsConfig.caFile != "" || tlsConfig.certFile != "" || tlsConfig.skipVerify)
 
 	a.Config.redisConfig = &redisConfig{
-		version:  version,
-		addr:     a.getEnv(redisAddr, defaultRedisAddr),
-		username: a.getEnv(redisUsername, defaultRedisUsername),
-		password: a.getEnv(redisPassword, defaultRedisPassword),
+		url:              a.getEnv(redisURLKey, ""),
+		addrs:            splitList(a.getEnv(redisAddr, defaultRedisAddr)),
+		username:         a.getEnv(redisUsername, defaultRedisUsername),
+		password:         a.getEnv(redisPassword, defaultRedisPassword),
+		db:               a.getEnvInt(redisDBKey, 0),
+		cluster:          a.getEnvBool(redisClusterKey, false),
+		sentinelMaster:   a.getEnv(redisSentinelMasterKey, ""),
+		sentinelAddrs:    a.getEnvList(redisSentinelAddrsKey),
+		sentinelUsername: a.getEnv(redisSentinelUsernameKey, ""),
+		sentinelPassword: a.getEnv(redisSentinelPasswordKey, ""),
+		tls:              tlsConfig,
+		poolSize:         a.getEnvInt(redisPoolSizeKey, 0),
+		minIdleConns:     a.getEnvInt(redisMinIdleConnsKey, 0),
+		dialTimeout:      a.getEnvDuration(redisDialTimeoutKey, 0),
+		readTimeout:      a.getEnvDuration(redisReadTimeoutKey, 0),
+		writeTimeout:     a.getEnvDuration(redisWriteTimeoutKey, 0),
+		keyPrefix:        a.getEnv(redisKeyPrefixKey, defaultRedisKeyPrefix),
+		migrateKeys:      a.getEnvBool(redisMigrateKeysKey, false),
 	}
 }
@@ -2,9 +2,13 @@ package app
 
 import (
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/msoovali/pipeline-locker/internal/domain"
 )
 
 func TestConf_parseConfig(t *testing.T) {
@@ -21,15 +25,43 @@ func TestConf_parseConfig(t *testing.T) {
 		if app.Config.pipelinesCaseSensitive != defaultPipelinesCaseSensitive {
 			t.Errorf("Expected %T, got %T", defaultPipelinesCaseSensitive, app.Config.pipelinesCaseSensitive)
 		}
+		if app.Config.adminToken != defaultAdminToken {
+			t.Errorf("Expected admin token %s, got %s", defaultAdminToken, app.Config.adminToken)
+		}
+		if app.Config.overrideTokenTTL != defaultOverrideTokenTTL {
+			t.Errorf("Expected override token TTL %s, got %s", defaultOverrideTokenTTL, app.Config.overrideTokenTTL)
+		}
+		if len(app.Config.protectedEnvironments) != 0 {
+			t.Errorf("Expected no protected environments, got %v", app.Config.protectedEnvironments)
+		}
+		if len(app.Config.unlockReviewers) != 0 {
+			t.Errorf("Expected no unlock reviewers, got %v", app.Config.unlockReviewers)
+		}
+		if app.Config.unlockRequestTTL != defaultUnlockRequestTTL {
+			t.Errorf("Expected unlock request TTL %s, got %s", defaultUnlockRequestTTL, app.Config.unlockRequestTTL)
+		}
+		if app.Config.statusTimeout != defaultStorageStatusTimeout || app.Config.lockTimeout != defaultStorageLockTimeout || app.Config.listTimeout != defaultStorageListTimeout {
+			t.Errorf("Expected storage timeouts %s, %s and %s, got %s, %s and %s", defaultStorageStatusTimeout, defaultStorageLockTimeout, defaultStorageListTimeout, app.Config.statusTimeout, app.Config.lockTimeout, app.Config.listTimeout)
+		}
 	})
 
 	const (
-		addrValue = ":9000"
+		addrValue             = ":9000"
+		adminTokenValue       = "secret"
+		overrideTokenTTLValue = time.Minute * 5
 	)
 	t.Run("envValuesProvided_returnsConfigWithProvidedValues", func(t *testing.T) {
 		os.Setenv(addrEnvKey, addrValue)
 		os.Setenv(allowOverlockingKey, "true")
 		os.Setenv(pipelinesCaseSensitiveKey, "false")
+		os.Setenv(adminTokenKey, adminTokenValue)
+		os.Setenv(overrideTokenTTLKey, "5m")
+		os.Setenv(protectedEnvironmentsKey, "production, ,live")
+		os.Setenv(unlockReviewersKey, "alice=alice-token,bob=bob-token")
+		os.Setenv(unlockRequestTTLKey, "invalid")
+		os.Setenv(storageStatusTimeoutKey, "500ms")
+		os.Setenv(storageLockTimeoutKey, "0")
+		os.Setenv(storageListTimeoutKey, "1m")
 		app := New(fiber.New())
 		app.parseConfig()
 
@@ -42,6 +74,405 @@ func TestConf_parseConfig(t *testing.T) {
 		if app.Config.pipelinesCaseSensitive != false {
 			t.Errorf("Expected %T, got %T", false, app.Config.pipelinesCaseSensitive)
 		}
+		if app.Config.adminToken != adminTokenValue {
+			t.Errorf("Expected admin token %s, got %s", adminTokenValue, app.Config.adminToken)
+		}
+		if app.Config.overrideTokenTTL != overrideTokenTTLValue {
+			t.Errorf("Expected override token TTL %s, got %s", overrideTokenTTLValue, app.Config.overrideTokenTTL)
+		}
+		if len(app.Config.protectedEnvironments) != 2 || app.Config.protectedEnvironments[0] != "production" || app.Config.protectedEnvironments[1] != "live" {
+			t.Errorf("Expected protected environments [production live], got %v", app.Config.protectedEnvironments)
+		}
+		if len(app.Config.unlockReviewers) != 2 || app.Config.unlockReviewers["bob"] != "bob-token" {
+			t.Errorf("Expected 2 unlock reviewers with tokens, got %v", app.Config.unlockReviewers)
+		}
+		if app.Config.unlockRequestTTL != defaultUnlockRequestTTL {
+			t.Errorf("Expected invalid unlock request TTL to fall back to %s, got %s", defaultUnlockRequestTTL, app.Config.unlockRequestTTL)
+		}
+		if app.Config.statusTimeout != 500*time.Millisecond {
+			t.Errorf("Expected status timeout 500ms, got %s", app.Config.statusTimeout)
+		}
+		if app.Config.lockTimeout != defaultStorageLockTimeout {
+			t.Errorf("Expected zero lock timeout to fall back to %s, got %s", defaultStorageLockTimeout, app.Config.lockTimeout)
+		}
+		if app.Config.listTimeout != time.Minute {
+			t.Errorf("Expected list timeout 1m, got %s", app.Config.listTimeout)
+		}
 		os.Clearenv()
 	})
 }
+
+func TestConf_parseConfig_resolvesAliasedEnvironments(t *testing.T) {
+	os.Setenv(environmentAliasesKey, "production=prod;staging=stage")
+	os.Setenv(protectedEnvironmentsKey, "prod")
+	os.Setenv(promotionOrderKey, "stage,prod;payments=test,prod")
+	defer os.Clearenv()
+	app := New(fiber.New())
+	app.parseConfig()
+
+	if !reflect.DeepEqual(app.Config.protectedEnvironments, []string{"production"}) {
+		t.Errorf("Expected protected environments [production], got %v", app.Config.protectedEnvironments)
+	}
+	if !reflect.DeepEqual(app.Config.promotionOrder.Default, []string{"staging", "production"}) || !reflect.DeepEqual(app.Config.promotionOrder.Projects["payments"], []string{"test", "production"}) {
+		t.Errorf("Expected promotion order of canonical environments, got %v", app.Config.promotionOrder)
+	}
+}
+
+func TestConf_parseUnlockReviewers(t *testing.T) {
+	type testCases struct {
+		description       string
+		value             string
+		expectedReviewers map[string]string
+		expectedError     string
+	}
+	for _, scenario := range []testCases{
+		{
+			description:       "emptyValue_returnsNoReviewers",
+			expectedReviewers: map[string]string{},
+		},
+		{
+			description:       "reviewersWithTokens_returnsTokensByName",
+			value:             "alice = a-token, ,bob=b-token",
+			expectedReviewers: map[string]string{"alice": "a-token", "bob": "b-token"},
+		},
+		{
+			description:   "reviewerWithoutToken_returnsError",
+			value:         "alice=a-token,bob",
+			expectedError: "reviewer \"bob\" has no name or token",
+		},
+		{
+			description:   "reviewerListedTwice_returnsError",
+			value:         "alice=a-token,alice=b-token",
+			expectedError: "reviewer \"alice\" is listed more than once",
+		},
+		{
+			description:   "reviewersShareToken_returnsError",
+			value:         "alice=token,bob=token",
+			expectedError: "reviewers \"alice\" and \"bob\" share token",
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			reviewers, err := parseUnlockReviewers(scenario.value)
+
+			if scenario.expectedError != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), scenario.expectedError) {
+					t.Errorf("Expected error %s, got %v", scenario.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected error nil, got %v", err)
+			}
+			if !reflect.DeepEqual(reviewers, scenario.expectedReviewers) {
+				t.Errorf("Expected reviewers %v, got %v", scenario.expectedReviewers, reviewers)
+			}
+		})
+	}
+}
+
+func TestConf_parsePromotionOrder(t *testing.T) {
+	app := New(fiber.New())
+
+	t.Run("emptyValue_returnsEmptyOrder", func(t *testing.T) {
+		order := app.parsePromotionOrder("")
+
+		if len(order.Default) != 0 || len(order.Projects) != 0 {
+			t.Errorf("Expected empty promotion order, got %v", order)
+		}
+	})
+
+	t.Run("defaultAndProjectOrders_returnsParsedOrder", func(t *testing.T) {
+		order := app.parsePromotionOrder("dev, staging ,production; payments=test,production;")
+
+		if !reflect.DeepEqual(order.Default, []string{"dev", "staging", "production"}) {
+			t.Errorf("Expected default order [dev staging production], got %v", order.Default)
+		}
+		if !reflect.DeepEqual(order.Projects["payments"], []string{"test", "production"}) {
+			t.Errorf("Expected payments order [test production], got %v", order.Projects["payments"])
+		}
+	})
+}
+
+func TestConf_parseEnvironmentAliases(t *testing.T) {
+	app := New(fiber.New())
+
+	t.Run("emptyValue_returnsNoAliases", func(t *testing.T) {
+		aliases := app.parseEnvironmentAliases("")
+
+		if len(aliases.Default) != 0 || len(aliases.Projects) != 0 {
+			t.Errorf("Expected no aliases, got %v", aliases)
+		}
+	})
+
+	t.Run("defaultAndProjectAliases_returnsParsedAliases", func(t *testing.T) {
+		aliases := app.parseEnvironmentAliases("production=prod, PRD; staging=stage; payments : production=live;")
+
+		expected := map[string]string{"prod": "production", "prd": "production", "stage": "staging"}
+		if !reflect.DeepEqual(aliases.Default, expected) {
+			t.Errorf("Expected default aliases %v, got %v", expected, aliases.Default)
+		}
+		if expected := map[string]string{"live": "production"}; !reflect.DeepEqual(aliases.Projects["payments"], expected) {
+			t.Errorf("Expected payments aliases %v, got %v", expected, aliases.Projects["payments"])
+		}
+	})
+}
+
+func TestConf_parseStorageConfig(t *testing.T) {
+	type testCases struct {
+		description         string
+		env                 map[string]string
+		expectedStorage     string
+		expectedPostgresURL string
+		expectedSQLitePath  string
+		expectedBoltPath    string
+		expectedEtcdConfig  *etcdConfig
+		expectedMemory      *memoryConfig
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:     "envValuesNotProvided_memoryStorage",
+			expectedStorage: storageMemory,
+		},
+		{
+			description:     "onlyRedisVersionProvided_redisStorage",
+			env:             map[string]string{redisVersionKey: "7"},
+			expectedStorage: storageRedis,
+		},
+		{
+			description:     "redisStorageWithoutVersion_redisStorage",
+			env:             map[string]string{storageKey: "redis"},
+			expectedStorage: storageRedis,
+		},
+		{
+			description:         "postgresStorage_postgresStorageWithDefaultURL",
+			env:                 map[string]string{storageKey: "Postgres", redisVersionKey: "7"},
+			expectedStorage:     storagePostgres,
+			expectedPostgresURL: defaultPostgresURL,
+		},
+		{
+			description:         "postgresStorageWithURL_postgresStorageWithProvidedURL",
+			env:                 map[string]string{storageKey: "postgres", postgresURLKey: "postgres://db/locker"},
+			expectedStorage:     storagePostgres,
+			expectedPostgresURL: "postgres://db/locker",
+		},
+		{
+			description:        "sqliteStorage_sqliteStorageWithDefaultPath",
+			env:                map[string]string{storageKey: "sqlite"},
+			expectedStorage:    storageSQLite,
+			expectedSQLitePath: defaultSQLitePath,
+		},
+		{
+			description:      "boltStorageWithPath_boltStorageWithProvidedPath",
+			env:              map[string]string{storageKey: "bolt", boltPathKey: "/data/locker.bolt"},
+			expectedStorage:  storageBolt,
+			expectedBoltPath: "/data/locker.bolt",
+		},
+		{
+			description:     "etcdStorageWithEndpoints_etcdStorageWithProvidedEndpoints",
+			env:             map[string]string{storageKey: "etcd", etcdEndpointsKey: "etcd-0:2379, etcd-1:2379", etcdUsernameKey: "locker"},
+			expectedStorage: storageEtcd,
+			expectedEtcdConfig: &etcdConfig{
+				endpoints: []string{"etcd-0:2379", "etcd-1:2379"},
+				username:  "locker",
+			},
+		},
+		{
+			description:     "memoryStorageWithSnapshotPath_persistedMemoryStorage",
+			env:             map[string]string{memorySnapshotPathKey: "/data/locker.json", memorySnapshotIntervalKey: "30s"},
+			expectedStorage: storageMemory,
+			expectedMemory: &memoryConfig{
+				snapshotPath:     "/data/locker.json",
+				snapshotInterval: 30 * time.Second,
+			},
+		},
+		{
+			description:        "sqliteStorageWithSnapshotPath_snapshotPathIgnored",
+			env:                map[string]string{storageKey: "sqlite", memorySnapshotPathKey: "/data/locker.json"},
+			expectedStorage:    storageSQLite,
+			expectedSQLitePath: defaultSQLitePath,
+		},
+		{
+			description:     "unknownStorage_memoryStorage",
+			env:             map[string]string{storageKey: "mongo"},
+			expectedStorage: storageMemory,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			app := New(fiber.New())
+			for key, value := range scenario.env {
+				os.Setenv(key, value)
+			}
+			defer os.Clearenv()
+
+			app.parseStorageConfig()
+
+			if app.Config.storage != scenario.expectedStorage {
+				t.Errorf("Expected storage %s, got %s", scenario.expectedStorage, app.Config.storage)
+			}
+			if app.Config.postgresURL != scenario.expectedPostgresURL {
+				t.Errorf("Expected postgres url %s, got %s", scenario.expectedPostgresURL, app.Config.postgresURL)
+			}
+			if app.Config.sqlitePath != scenario.expectedSQLitePath {
+				t.Errorf("Expected sqlite path %s, got %s", scenario.expectedSQLitePath, app.Config.sqlitePath)
+			}
+			if app.Config.boltPath != scenario.expectedBoltPath {
+				t.Errorf("Expected bolt path %s, got %s", scenario.expectedBoltPath, app.Config.boltPath)
+			}
+			if !reflect.DeepEqual(app.Config.etcdConfig, scenario.expectedEtcdConfig) {
+				t.Errorf("Expected etcd config %v, got %v", scenario.expectedEtcdConfig, app.Config.etcdConfig)
+			}
+			if !reflect.DeepEqual(app.Config.memoryConfig, scenario.expectedMemory) {
+				t.Errorf("Expected memory config %v, got %v", scenario.expectedMemory, app.Config.memoryConfig)
+			}
+		})
+	}
+}
+
+func TestConf_parseRedisConfig(t *testing.T) {
+	t.Run("envValuesNotProvided_singleNodeWithDefaults", func(t *testing.T) {
+		app := New(fiber.New())
+
+		app.parseRedisConfig()
+
+		expected := &redisConfig{
+			addrs:         []string{defaultRedisAddr},
+			sentinelAddrs: []string{},
+			keyPrefix:     defaultRedisKeyPrefix,
+		}
+		if !reflect.DeepEqual(app.Config.redisConfig, expected) {
+			t.Errorf("Expected redis config %+v, got %+v", expected, app.Config.redisConfig)
+		}
+	})
+
+	t.Run("envValuesProvided_configWithProvidedValues", func(t *testing.T) {
+		for key, value := range map[string]string{
+			redisURLKey:              "rediss://redis:6380",
+			redisAddr:                "redis-0:6379,redis-1:6379",
+			redisDBKey:               "1",
+			redisClusterKey:          "true",
+			redisSentinelMasterKey:   "mymaster",
+			redisSentinelAddrsKey:    "sentinel-0:26379, sentinel-1:26379",
+			redisSentinelPasswordKey: "sentinel-secret",
+			redisTLSCAFileKey:        "/etc/redis/ca.pem",
+			redisPoolSizeKey:         "20",
+			redisMinIdleConnsKey:     "5",
+			redisDialTimeoutKey:      "2s",
+			redisReadTimeoutKey:      "500ms",
+			redisWriteTimeoutKey:     "1s",
+			redisKeyPrefixKey:        "locker:",
+			redisMigrateKeysKey:      "true",
+		} {
+			os.Setenv(key, value)
+		}
+		defer os.Clearenv()
+		app := New(fiber.New())
+
+		app.parseRedisConfig()
+
+		expected := &redisConfig{
+			url:              "rediss://redis:6380",
+			addrs:            []string{"redis-0:6379", "redis-1:6379"},
+			db:               1,
+			cluster:          true,
+			sentinelMaster:   "mymaster",
+			sentinelAddrs:    []string{"sentinel-0:26379", "sentinel-1:26379"},
+			sentinelPassword: "sentinel-secret",
+			tls: redisTLSConfig{
+				enabled: true,
+				caFile:  "/etc/redis/ca.pem",
+			},
+			poolSize:     20,
+			minIdleConns: 5,
+			dialTimeout:  2 * time.Second,
+			readTimeout:  500 * time.Millisecond,
+			writeTimeout: time.Second,
+			keyPrefix:    "locker:",
+			migrateKeys:  true,
+		}
+		if !reflect.DeepEqual(app.Config.redisConfig, expected) {
+			t.Errorf("Expected redis config %+v, got %+v", expected, app.Config.redisConfig)
+		}
+	})
+}
+
+func TestConf_parseIdentifierRules(t *testing.T) {
+	t.Run("envValuesNotProvided_defaultRules", func(t *testing.T) {
+		app := New(fiber.New())
+
+		rules := app.parseIdentifierRules()
+
+		if !rules.Trim || rules.MaxLength != defaultIdentifierMaxLength || len(rules.ReservedNames) != 0 {
+			t.Errorf("Expected default rules, got %+v", rules)
+		}
+		if rules.Pattern == nil || rules.Pattern.String() != domain.DefaultIdentifierPattern {
+			t.Errorf("Expected default pattern %s, got %v", domain.DefaultIdentifierPattern, rules.Pattern)
+		}
+	})
+
+	t.Run("envValuesProvided_rulesWithProvidedValues", func(t *testing.T) {
+		for key, value := range map[string]string{
+			identifierTrimKey:          "false",
+			identifierMaxLengthKey:     "0",
+			identifierPatternKey:       "",
+			identifierReservedNamesKey: "all, none",
+		} {
+			os.Setenv(key, value)
+		}
+		defer os.Clearenv()
+		app := New(fiber.New())
+
+		rules := app.parseIdentifierRules()
+
+		if rules.Trim || rules.MaxLength != 0 || rules.Pattern != nil {
+			t.Errorf("Expected rules without trimming, length limit and pattern, got %+v", rules)
+		}
+		if !reflect.DeepEqual(rules.ReservedNames, []string{"all", "none"}) {
+			t.Errorf("Expected reserved names [all none], got %v", rules.ReservedNames)
+		}
+	})
+
+	t.Run("invalidEnvValues_fallsBackToDefaults", func(t *testing.T) {
+		os.Setenv(identifierMaxLengthKey, "-1")
+		os.Setenv(identifierPatternKey, "[a-z")
+		defer os.Clearenv()
+		app := New(fiber.New())
+
+		rules := app.parseIdentifierRules()
+
+		if rules.MaxLength != defaultIdentifierMaxLength {
+			t.Errorf("Expected max length %d, got %d", defaultIdentifierMaxLength, rules.MaxLength)
+		}
+		if rules.Pattern == nil || rules.Pattern.String() != domain.DefaultIdentifierPattern {
+			t.Errorf("Expected default pattern %s, got %v", domain.DefaultIdentifierPattern, rules.Pattern)
+		}
+	})
+}
+
+func TestConf_ProxyConfig(t *testing.T) {
+	t.Run("envValuesNotProvided_trustsNoProxy", func(t *testing.T) {
+		config := ProxyConfig(fiber.Config{AppName: "locker"})
+
+		if config.ProxyHeader != "" || !config.EnableTrustedProxyCheck || len(config.TrustedProxies) != 0 {
+			t.Errorf("Expected no proxy header and no trusted proxies, got %q and %v", config.ProxyHeader, config.TrustedProxies)
+		}
+		if config.AppName != "locker" {
+			t.Errorf("Expected rest of config to be kept, got app name %q", config.AppName)
+		}
+	})
+
+	t.Run("envValuesProvided_trustsListedProxies", func(t *testing.T) {
+		os.Setenv(proxyHeaderKey, fiber.HeaderXForwardedFor)
+		os.Setenv(trustedProxiesKey, "10.0.0.1, 192.168.0.0/16")
+		defer os.Clearenv()
+
+		config := ProxyConfig(fiber.Config{})
+
+		if config.ProxyHeader != fiber.HeaderXForwardedFor {
+			t.Errorf("Expected proxy header %s, got %s", fiber.HeaderXForwardedFor, config.ProxyHeader)
+		}
+		if !config.EnableTrustedProxyCheck || !reflect.DeepEqual(config.TrustedProxies, []string{"10.0.0.1", "192.168.0.0/16"}) {
+			t.Errorf("Expected trusted proxies [10.0.0.1 192.168.0.0/16], got %v", config.TrustedProxies)
+		}
+	})
+}
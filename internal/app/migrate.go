@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/repository/memory"
+	"github.com/msoovali/pipeline-locker/internal/service"
+)
+
+// MigrationConfig selects storages of migrate command, both are configured by the same env variables as
+// the service, e.g. REDIS_URL for redis and POSTGRES_URL for postgres
+type MigrationConfig struct {
+	From   string
+	To     string
+	DryRun bool
+}
+
+// Migrate copies pipelines with their events and deployments from one storage to another
+func Migrate(config MigrationConfig) (*domain.MigrationReport, error) {
+	a := newCommandApplication()
+	from, to := strings.ToLower(config.From), strings.ToLower(config.To)
+	if from == to {
+		return nil, fmt.Errorf("source and target storage must differ, got %s", from)
+	}
+	for _, storage := range []string{from, to} {
+		if !a.parseBackendConfig(storage) {
+			return nil, fmt.Errorf("storage %s is not supported, supported storages: %s", storage, strings.Join(supportedStorages, ", "))
+		}
+	}
+	// memory storage is migrated from or to its snapshot file
+	if (from == storageMemory || to == storageMemory) && a.Config.memoryConfig == nil {
+		return nil, fmt.Errorf("%s must be set to migrate memory storage", memorySnapshotPathKey)
+	}
+	source, target, err := a.openMigrationStorages(from, to, config.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRepositories(source)
+	defer closeRepositories(target)
+
+	a.Log.Info.Printf("Migrating from %s to %s, dry run: %t", from, to, config.DryRun)
+	report, err := service.NewMigrationService(migrationRepositories(source), migrationRepositories(target), a.Log).Migrate(context.Background(), config.DryRun)
+	if report != nil {
+		verb := "Migrated"
+		if report.DryRun {
+			verb = "Would migrate"
+		}
+		a.Log.Info.Printf("%s %d pipelines, %d events, %d deployments and %d pending unlock requests, skipped %d already migrated", verb, report.Pipelines, report.Events, report.Deployments, report.UnlockRequests, report.Skipped)
+		a.Log.Info.Printf("Override tokens, discovered pipelines and blocked deploy attempts are not migrated")
+	}
+
+	return report, err
+}
+
+// openMigrationStorages opens source and target storage, on dry run neither of them is written to and target
+// not initialized yet is migrated to as empty storage
+func (a *Application) openMigrationStorages(from, to string, dryRun bool) (*repositories, *repositories, error) {
+	if !dryRun {
+		return a.initStorageRepositories(from), a.initStorageRepositories(to), nil
+	}
+	source, err := a.openStorageReadOnly(from)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open source storage %s: %w", from, err)
+	}
+	target, err := a.openStorageReadOnly(to)
+	if errors.Is(err, domain.ErrStorageNotInitialized) {
+		a.Log.Info.Printf("Target storage %s is empty: %v", to, err)
+		return source, a.newInMemoryRepositories(memory.NewPipelineRepository(a.Config.pipelinesCaseSensitive)), nil
+	}
+	if err != nil {
+		closeRepositories(source)
+		return nil, nil, fmt.Errorf("failed to open target storage %s: %w", to, err)
+	}
+
+	return source, target, nil
+}
+
+func migrationRepositories(repositories *repositories) service.MigrationRepositories {
+	return service.MigrationRepositories{
+		Pipelines:      repositories.PipelineRepository,
+		Events:         repositories.PipelineEventRepository,
+		Deployments:    repositories.DeploymentRepository,
+		UnlockRequests: repositories.UnlockRequestRepository,
+	}
+}
+
+// closeRepositories writes final snapshot of persisted memory storage
+func closeRepositories(repositories *repositories) error {
+	if closer, ok := repositories.PipelineRepository.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
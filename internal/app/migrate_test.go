@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/repository/sqlite"
+)
+
+func TestMigrate_dryRun(t *testing.T) {
+	t.Run("Migrate_targetNotInitialized_reportsEverythingWithoutCreatingTarget", func(t *testing.T) {
+		directory := t.TempDir()
+		sourcePath := filepath.Join(directory, "pipeline-locker.db")
+		targetPath := filepath.Join(directory, "pipeline-locker.bolt")
+		db, _ := sqlite.Open(sourcePath)
+		sqlite.NewPipelineRepository(db, false).Add(context.Background(), domain.Pipeline{
+			PipelineIdentifier: domain.PipelineIdentifier{Project: "project", Environment: "dev"},
+			PipelineLockedBy:   domain.PipelineLockedBy{LockedBy: "user"},
+		})
+		db.Close()
+		t.Setenv(sqlitePathKey, sourcePath)
+		t.Setenv(boltPathKey, targetPath)
+
+		report, err := Migrate(MigrationConfig{From: storageSQLite, To: storageBolt, DryRun: true})
+
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		if !report.DryRun || report.Pipelines != 1 {
+			t.Errorf("Expected dry run report of 1 pipeline, got %+v", report)
+		}
+		if _, err = os.Stat(targetPath); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected target file not to be created, got %v", err)
+		}
+	})
+
+	t.Run("Migrate_sourceNotInitialized_returnsErrStorageNotInitialized", func(t *testing.T) {
+		directory := t.TempDir()
+		sourcePath := filepath.Join(directory, "pipeline-locker.bolt")
+		t.Setenv(boltPathKey, sourcePath)
+		t.Setenv(sqlitePathKey, filepath.Join(directory, "pipeline-locker.db"))
+
+		_, err := Migrate(MigrationConfig{From: storageBolt, To: storageSQLite, DryRun: true})
+
+		if !errors.Is(err, domain.ErrStorageNotInitialized) {
+			t.Errorf("Expected %v, got %v", domain.ErrStorageNotInitialized, err)
+		}
+		if _, err = os.Stat(sourcePath); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected source file not to be created, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	redis_pkg "github.com/go-redis/redis/v9"
+	redis_repository "github.com/msoovali/pipeline-locker/internal/repository/redis"
+)
+
+// redisConnectTimeout limits detecting redis server on startup
+const redisConnectTimeout = 10 * time.Second
+
+// redisConnection holds connection settings resolved from redis config, same for every redis client version
+type redisConnection struct {
+	addrs     []string
+	username  string
+	password  string
+	db        int
+	tlsConfig *tls.Config
+}
+
+// resolveRedisConnection resolves addresses, credentials and TLS from redis config.
+// REDIS_URL takes precedence over separate settings and sentinel addresses replace REDIS_ADDR when sentinel is used.
+func resolveRedisConnection(config *redisConfig) (*redisConnection, error) {
+	tlsConfig, err := newRedisTLSConfig(config.tls)
+	if err != nil {
+		return nil, err
+	}
+	connection := &redisConnection{
+		addrs:     config.addrs,
+		username:  config.username,
+		password:  config.password,
+		db:        config.db,
+		tlsConfig: tlsConfig,
+	}
+	if config.url != "" {
+		urlOptions, err := redis_pkg.ParseURL(config.url)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis URL: %w", err)
+		}
+		connection.addrs = []string{urlOptions.Addr}
+		connection.username = urlOptions.Username
+		connection.password = urlOptions.Password
+		connection.db = urlOptions.DB
+		if connection.tlsConfig == nil {
+			connection.tlsConfig = urlOptions.TLSConfig
+		} else if urlOptions.TLSConfig != nil {
+			connection.tlsConfig.ServerName = urlOptions.TLSConfig.ServerName
+		}
+	}
+	if config.sentinelMaster != "" && len(config.sentinelAddrs) > 0 {
+		connection.addrs = config.sentinelAddrs
+	}
+
+	return connection, nil
+}
+
+// newRedisTLSConfig loads CA bundle and client certificate, returns nil when TLS is disabled
+func newRedisTLSConfig(config redisTLSConfig) (*tls.Config, error) {
+	if !config.enabled {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.skipVerify,
+	}
+	if config.caFile != "" {
+		ca, err := os.ReadFile(config.caFile)
+		if err != nil {
+			return nil, err
+		}
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in %s", config.caFile)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	if config.certFile != "" || config.keyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(config.certFile, config.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+// initRedisClient connects to Redis and detects server from INFO, startup fails when server is unreachable.
+// Server running in cluster mode is connected with cluster client even when REDIS_CLUSTER is not set.
+func (a *Application) initRedisClient(config *redisConfig) redis_repository.Client {
+	connection, err := resolveRedisConnection(config)
+	if err != nil {
+		a.Log.Error.Fatalf("Failed to configure redis connection: %v", err)
+	}
+	options := &redis_pkg.UniversalOptions{
+		Addrs:            connection.addrs,
+		Username:         connection.username,
+		Password:         connection.password,
+		DB:               connection.db,
+		TLSConfig:        connection.tlsConfig,
+		MasterName:       config.sentinelMaster,
+		SentinelUsername: config.sentinelUsername,
+		SentinelPassword: config.sentinelPassword,
+		PoolSize:         config.poolSize,
+		MinIdleConns:     config.minIdleConns,
+		DialTimeout:      config.dialTimeout,
+		ReadTimeout:      config.readTimeout,
+		WriteTimeout:     config.writeTimeout,
+	}
+	client := newRedisClient(options, config.cluster)
+	server := a.detectRedisServer(client, connection)
+	if server.Cluster && !config.cluster && config.sentinelMaster == "" {
+		client.Close()
+		client = newRedisClient(options, true)
+	}
+	if (server.Cluster || config.cluster) && !redis_repository.HasHashTag(config.keyPrefix) {
+		a.Log.Error.Fatalf("%s %q has no hash tag, in cluster mode it must have one, e.g. %s, so keys updated in one transaction share hash slot", redisKeyPrefixKey, config.keyPrefix, defaultRedisKeyPrefix)
+	}
+	if connection.username != "" && !server.SupportsACL() {
+		a.Log.Error.Fatalf("%s does not support ACL users, remove redis username", server)
+	}
+	a.Log.Info.Printf("Connected to %s", server)
+
+	return client
+}
+
+func newRedisClient(options *redis_pkg.UniversalOptions, cluster bool) redis_repository.Client {
+	if cluster {
+		return redis_repository.NewClient(redis_pkg.NewClusterClient(options.Cluster()))
+	}
+
+	return redis_repository.NewClient(redis_pkg.NewUniversalClient(options))
+}
+
+func (a *Application) detectRedisServer(client redis_repository.Client, connection *redisConnection) *redis_repository.ServerInfo {
+	ctx, cancel := context.WithTimeout(context.Background(), redisConnectTimeout)
+	defer cancel()
+	server, err := redis_repository.DetectServer(ctx, client)
+	if err != nil {
+		a.Log.Error.Fatalf("Redis at %s is unreachable: %v", strings.Join(connection.addrs, ","), err)
+	}
+
+	return server
+}
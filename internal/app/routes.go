@@ -2,18 +2,58 @@ package app
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/handler"
 )
 
 func (a *Application) registerRoutes(router *fiber.App) {
 	router.Get("/health", a.Handlers.HealthHandlers.HealthCheck)
 	router.Get("/", a.Handlers.PipelineHandlers.Index)
 	router.Post("/", a.Handlers.PipelineHandlers.LockAndRedirect)
+	router.Get("/pipelines/:project/:environment/:region?/:cluster?/:component?", a.Handlers.PipelineHandlers.Details)
 
+	reviewerAuthentication := handler.NewReviewerAuthentication(a.Config.unlockReviewers)
 	v1 := router.Group("/v1")
 	{
 		v1.Post("/pipeline/lock", a.Handlers.PipelineHandlers.Lock)
 		v1.Put("/pipeline/unlock", a.Handlers.PipelineHandlers.Unlock)
-		v1.Get("/pipeline/status/project/:project/environment/:environment", a.Handlers.PipelineHandlers.GetStatus)
+		v1.Put("/pipeline/extend", a.Handlers.PipelineHandlers.Extend)
+		for _, path := range scopePaths() {
+			v1.Get("/pipeline"+path, a.Handlers.PipelineHandlers.GetPipelineDetails)
+			v1.Get("/pipeline/status"+path, a.Handlers.PipelineHandlers.GetStatus)
+			v1.Get("/pipeline/blocked"+path, a.Handlers.PipelineHandlers.GetBlockedAttempts)
+			v1.Get("/pipeline/deployments"+path, a.Handlers.DeploymentHandlers.GetHistory)
+		}
+		v1.Get("/pipelines", a.Handlers.PipelineHandlers.GetKnownPipelines)
 		v1.Get("/pipelines/locked", a.Handlers.PipelineHandlers.GetLockedPipelines)
+		v1.Get("/pipelines/matrix", a.Handlers.PipelineHandlers.GetPipelineMatrix)
+		v1.Get("/pipelines/changes", a.Handlers.PipelineHandlers.WatchPipelines)
+		v1.Post("/pipeline/deployment/:status", a.Handlers.DeploymentHandlers.Report)
+		v1.Get("/pipelines/deployments", a.Handlers.DeploymentHandlers.GetCurrent)
+		v1.Post("/unlock-request", reviewerAuthentication, a.Handlers.UnlockRequestHandlers.Create)
+		v1.Put("/unlock-request/:id/approve", reviewerAuthentication, a.Handlers.UnlockRequestHandlers.Approve)
+		v1.Put("/unlock-request/:id/reject", reviewerAuthentication, a.Handlers.UnlockRequestHandlers.Reject)
+		v1.Get("/unlock-requests/pending", a.Handlers.UnlockRequestHandlers.GetPending)
 	}
+
+	admin := v1.Group("/admin", handler.NewAdminAuthorization(a.Config.adminToken))
+	{
+		admin.Post("/pipeline/override", a.Handlers.OverrideHandlers.Issue)
+		admin.Get("/backup", a.Handlers.BackupHandlers.Backup)
+		admin.Get("/export", a.Handlers.ExportHandlers.Export)
+		admin.Post("/import", a.Handlers.ExportHandlers.Import)
+	}
+}
+
+// scopePaths returns route paths of pipeline scope path, e.g. /project/payments/environment/production/region/eu-west.
+// Path of project and environment comes first, followed by paths extended by every scope dimension.
+func scopePaths() []string {
+	path := "/project/:project/environment/:environment"
+	paths := []string{path}
+	for _, dimension := range domain.ScopeDimensions {
+		path += "/" + dimension + "/:" + dimension
+		paths = append(paths, path)
+	}
+
+	return paths
 }
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+func TestRoutes_unlockRequest(t *testing.T) {
+	t.Setenv(storageKey, storageMemory)
+	t.Setenv(protectedEnvironmentsKey, "production")
+	t.Setenv(unlockReviewersKey, "alice=alice-token,bob=bob-token")
+	router := fiber.New()
+	New(router)
+	send := func(method, path, token, body string) (int, string) {
+		return sendRequest(t, router, method, path, token, body)
+	}
+	if status, body := send(fiber.MethodPost, "/v1/pipeline/lock", "", `{"project":"api","environment":"production","locked_by":"carol"}`); status != fiber.StatusCreated {
+		t.Fatalf("Expected pipeline to be locked, got %d %s", status, body)
+	}
+
+	t.Run("Create_withoutToken_respondUnauthorized", func(t *testing.T) {
+		status, _ := send(fiber.MethodPost, "/v1/unlock-request", "", `{"project":"api","environment":"production","requested_by":"bob"}`)
+
+		if status != fiber.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", fiber.StatusUnauthorized, status)
+		}
+	})
+
+	t.Run("Approve_requestFiledUnderAnotherName_respondSelfApprovalNotAllowed", func(t *testing.T) {
+		status, body := send(fiber.MethodPost, "/v1/unlock-request", "alice-token", `{"project":"api","environment":"production","requested_by":"bob"}`)
+		if status != fiber.StatusCreated {
+			t.Fatalf("Expected request to be created, got %d %s", status, body)
+		}
+		var request domain.UnlockRequest
+		json.Unmarshal([]byte(body), &request)
+		if request.RequestedBy != "alice" {
+			t.Errorf("Expected request of alice, got %s", request.RequestedBy)
+		}
+
+		status, body = send(fiber.MethodPut, "/v1/unlock-request/"+request.ID+"/approve", "alice-token", "")
+
+		if status != fiber.StatusConflict || body != domain.ErrUnlockSelfApprovalNotAllowed.Error() {
+			t.Errorf("Expected %d %s, got %d %s", fiber.StatusConflict, domain.ErrUnlockSelfApprovalNotAllowed, status, body)
+		}
+		if status, body = send(fiber.MethodPut, "/v1/unlock-request/"+request.ID+"/approve", "bob-token", ""); status != fiber.StatusOK {
+			t.Errorf("Expected approval of another reviewer, got %d %s", status, body)
+		}
+	})
+}
+
+func TestRoutes_identifierBreakingRules(t *testing.T) {
+	t.Setenv(storageKey, storageMemory)
+	router := fiber.New()
+	app := New(router)
+	legacy := domain.Pipeline{
+		PipelineIdentifier: domain.PipelineIdentifier{Project: "legacy!", Environment: "production"},
+		PipelineLockedBy:   domain.PipelineLockedBy{LockedBy: "carol"},
+		PipelineLockedAt:   domain.PipelineLockedAt{LockedAt: time.Now()},
+	}
+	if err := app.Repositories.PipelineRepository.Add(context.Background(), legacy); err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+
+	t.Run("Lock_respondBadRequest", func(t *testing.T) {
+		status, body := sendRequest(t, router, fiber.MethodPost, "/v1/pipeline/lock", "", `{"project":"other!","environment":"production","locked_by":"carol"}`)
+
+		if status != fiber.StatusBadRequest || body != domain.ErrProjectInvalid.Error() {
+			t.Errorf("Expected %d %s, got %d %s", fiber.StatusBadRequest, domain.ErrProjectInvalid, status, body)
+		}
+	})
+
+	t.Run("GetStatus_lockedPipelineStoredBeforeRules_respondLocked", func(t *testing.T) {
+		status, body := sendRequest(t, router, fiber.MethodGet, "/v1/pipeline/status/project/legacy!/environment/production", "", "")
+
+		if status != fiber.StatusLocked {
+			t.Errorf("Expected status %d, got %d %s", fiber.StatusLocked, status, body)
+		}
+	})
+
+	t.Run("Unlock_pipelineStoredBeforeRules_respondNoContent", func(t *testing.T) {
+		status, body := sendRequest(t, router, fiber.MethodPut, "/v1/pipeline/unlock", "", `{"project":"legacy!","environment":"production"}`)
+
+		if status != fiber.StatusNoContent {
+			t.Errorf("Expected status %d, got %d %s", fiber.StatusNoContent, status, body)
+		}
+	})
+}
+
+func sendRequest(t *testing.T, router *fiber.App, method, path, token, body string) (int, string) {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if token != "" {
+		request.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+	response, err := router.Test(request)
+	if err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+	responseBody, _ := io.ReadAll(response.Body)
+
+	return response.StatusCode, string(responseBody)
+}
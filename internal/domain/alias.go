@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EnvironmentAliases map other spellings of environment to its canonical name, e.g. prod and prd to production,
+// so locks of every spelling are held by the same pipeline
+type EnvironmentAliases struct {
+	// Default maps lower case alias to canonical environment of every project
+	Default map[string]string
+	// Projects map lower case alias to canonical environment of the project, they are looked up before Default
+	Projects map[string]map[string]string
+}
+
+// Resolve returns identifier with aliased environment replaced by its canonical name, aliases match case
+// insensitively and environment without alias is returned as is
+func (a EnvironmentAliases) Resolve(identifier PipelineIdentifier) PipelineIdentifier {
+	alias := strings.ToLower(identifier.Environment)
+	for project, aliases := range a.Projects {
+		if !strings.EqualFold(project, identifier.Project) {
+			continue
+		}
+		if canonical, exists := aliases[alias]; exists {
+			identifier.Environment = canonical
+			return identifier
+		}
+	}
+	if canonical, exists := a.Default[alias]; exists {
+		identifier.Environment = canonical
+	}
+
+	return identifier
+}
+
+// ResolveEnvironments returns environments of all projects with aliases replaced by canonical names. Alias defined
+// for single project is an error, environment of all projects can't be resolved by it.
+func (a EnvironmentAliases) ResolveEnvironments(environments []string) ([]string, error) {
+	resolved := make([]string, len(environments))
+	for i, environment := range environments {
+		alias := strings.ToLower(environment)
+		for project, aliases := range a.Projects {
+			if canonical, exists := aliases[alias]; exists {
+				return nil, fmt.Errorf("%s is alias of %s environment of project %s only, list canonical environment instead", environment, canonical, project)
+			}
+		}
+		resolved[i] = a.Resolve(PipelineIdentifier{Environment: environment}).Environment
+	}
+
+	return resolved, nil
+}
+
+// Add defines aliases of canonical environment, project limits aliases to the project when not empty
+func (a *EnvironmentAliases) Add(project string, canonical string, aliases ...string) {
+	if project == "" {
+		a.Default = addAliases(a.Default, canonical, aliases)
+		return
+	}
+	if a.Projects == nil {
+		a.Projects = make(map[string]map[string]string)
+	}
+	a.Projects[project] = addAliases(a.Projects[project], canonical, aliases)
+}
+
+func addAliases(target map[string]string, canonical string, aliases []string) map[string]string {
+	if target == nil {
+		target = make(map[string]string)
+	}
+	for _, alias := range aliases {
+		target[strings.ToLower(alias)] = canonical
+	}
+
+	return target
+}
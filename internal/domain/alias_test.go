@@ -0,0 +1,79 @@
+package domain
+
+import "testing"
+
+func TestEnvironmentAliases_Resolve(t *testing.T) {
+	var aliases EnvironmentAliases
+	aliases.Add("", environment, "prod", "PRD")
+	aliases.Add("payments", environment, "live")
+	aliases.Add("payments", "sandbox", "prod")
+
+	type testCases struct {
+		description         string
+		identifier          PipelineIdentifier
+		expectedEnvironment string
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:         "defaultAlias_returnsCanonicalEnvironment",
+			identifier:          PipelineIdentifier{Project: project, Environment: "prod"},
+			expectedEnvironment: environment,
+		},
+		{
+			description:         "aliasOfOtherCase_returnsCanonicalEnvironment",
+			identifier:          PipelineIdentifier{Project: project, Environment: "Prd"},
+			expectedEnvironment: environment,
+		},
+		{
+			description:         "projectAliasOfOtherProject_returnsEnvironmentAsIs",
+			identifier:          PipelineIdentifier{Project: project, Environment: "live"},
+			expectedEnvironment: "live",
+		},
+		{
+			description:         "projectAlias_returnsCanonicalEnvironmentOfProject",
+			identifier:          PipelineIdentifier{Project: "Payments", Environment: "live"},
+			expectedEnvironment: environment,
+		},
+		{
+			description:         "projectAliasOverridesDefaultAlias_returnsCanonicalEnvironmentOfProject",
+			identifier:          PipelineIdentifier{Project: "payments", Environment: "prod"},
+			expectedEnvironment: "sandbox",
+		},
+		{
+			description:         "noAlias_returnsEnvironmentAsIs",
+			identifier:          PipelineIdentifier{Project: project, Environment: "staging"},
+			expectedEnvironment: "staging",
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			resolved := aliases.Resolve(scenario.identifier)
+
+			if resolved.Environment != scenario.expectedEnvironment || resolved.Project != scenario.identifier.Project {
+				t.Errorf("Expected environment %s of project %s, got %v", scenario.expectedEnvironment, scenario.identifier.Project, resolved)
+			}
+		})
+	}
+}
+
+func TestEnvironmentAliases_ResolveEnvironments(t *testing.T) {
+	var aliases EnvironmentAliases
+	aliases.Add("", environment, "prod")
+	aliases.Add("payments", environment, "live")
+
+	t.Run("defaultAliases_returnsCanonicalEnvironments", func(t *testing.T) {
+		resolved, err := aliases.ResolveEnvironments([]string{"PROD", "staging"})
+
+		if err != nil || len(resolved) != 2 || resolved[0] != environment || resolved[1] != "staging" {
+			t.Errorf("Expected [%s staging] and nil error, got %v and %v", environment, resolved, err)
+		}
+	})
+
+	t.Run("aliasOfSingleProject_returnsError", func(t *testing.T) {
+		_, err := aliases.ResolveEnvironments([]string{"live"})
+
+		if err == nil {
+			t.Errorf("Expected error for alias of single project")
+		}
+	})
+}
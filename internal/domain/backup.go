@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"errors"
+	"io"
+)
+
+var ErrBackupNotSupported = errors.New("BACKUP_NOT_SUPPORTED")
+
+// BackupRepository is implemented by file based storages able to copy their whole state
+type BackupRepository interface {
+	// Backup writes consistent snapshot of the storage into w
+	Backup(w io.Writer) error
+}
+
+type BackupService interface {
+	IsSupported() bool
+	Backup(w io.Writer) error
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// BlockedAttemptHistorySize is the number of latest blocked deploy attempts kept per pipeline
+const BlockedAttemptHistorySize = 500
+
+// BlockedAttempt is deploy status check answered with 423 Locked
+type BlockedAttempt struct {
+	PipelineIdentifier
+	// Reason is CheckLocked or CheckNotPromoted
+	Reason      CheckOutcome `json:"reason"`
+	ClientIP    string       `json:"client_ip"`
+	JobURL      string       `json:"job_url,omitempty"`
+	User        string       `json:"user,omitempty"`
+	AttemptedAt time.Time    `json:"attempted_at"`
+}
+
+type BlockedAttemptRepository interface {
+	Add(ctx context.Context, attempt BlockedAttempt) error
+	// FindSince returns up to BlockedAttemptHistorySize latest attempts of the pipeline made at or after since, newest first
+	FindSince(ctx context.Context, pipeline PipelineIdentifier, since time.Time) ([]BlockedAttempt, error)
+}
+
+type BlockedAttemptService interface {
+	// Record adds attempt under identifier of the lock which blocked it in background, failing to add it is logged and
+	// doesn't fail the check
+	Record(ctx context.Context, attempt BlockedAttempt)
+	// GetForLock returns attempts blocked since current lock of the pipeline was taken, newest first, empty when pipeline
+	// isn't locked
+	GetForLock(ctx context.Context, pipeline PipelineIdentifier) ([]BlockedAttempt, error)
+	// CountForLocks counts attempts blocked since each lock was taken by pipeline identifier, name is not unique as
+	// project and environment may contain "/"
+	CountForLocks(ctx context.Context, pipelines []Pipeline) (map[PipelineIdentifier]int, error)
+	// Close stops recording attempts and waits until queued attempts are added, attempts recorded after it are dropped
+	Close()
+}
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// DeploymentHistorySize is the number of latest deployments kept per pipeline
+const DeploymentHistorySize = 100
+
+var (
+	ErrVersionEmpty             = errors.New("REQUEST_VERSION_EMPTY")
+	ErrDeploymentStatusInvalid  = errors.New("DEPLOYMENT_STATUS_INVALID")
+	ErrPromotionVersionRequired = errors.New("PROMOTION_VERSION_REQUIRED")
+	ErrVersionNotPromoted       = errors.New("VERSION_NOT_PROMOTED")
+)
+
+type DeploymentStatus string
+
+const (
+	DeploymentStarted   DeploymentStatus = "started"
+	DeploymentSucceeded DeploymentStatus = "succeeded"
+	DeploymentFailed    DeploymentStatus = "failed"
+)
+
+type Deployment struct {
+	PipelineIdentifier
+	DeploymentDetails
+	Status     DeploymentStatus `json:"status"`
+	DeployedAt time.Time        `json:"deployed_at"`
+}
+
+type DeploymentDetails struct {
+	Version string `json:"version" form:"version"`
+	Commit  string `json:"commit" form:"commit"`
+	Actor   string `json:"actor" form:"actor"`
+	JobURL  string `json:"job_url" form:"job_url"`
+}
+
+type DeploymentReport struct {
+	PipelineIdentifier
+	DeploymentDetails
+}
+
+// PromotionOrder defines the order in which environments are deployed. Version can be deployed to an environment
+// only after it has been successfully deployed to the previous environment. Project specific order takes precedence
+// over default order.
+type PromotionOrder struct {
+	Default  []string
+	Projects map[string][]string
+}
+
+func (s DeploymentStatus) Validate() error {
+	switch s {
+	case DeploymentStarted, DeploymentSucceeded, DeploymentFailed:
+		return nil
+	}
+
+	return ErrDeploymentStatusInvalid
+}
+
+func (r *DeploymentReport) Validate() error {
+	if err := r.PipelineIdentifier.Validate(); err != nil {
+		return err
+	}
+	if r.Version == "" {
+		return ErrVersionEmpty
+	}
+
+	return nil
+}
+
+// UpstreamEnvironment returns environment preceding pipeline environment in promotion order
+// or empty string if pipeline environment is first or not part of the order
+func (o *PromotionOrder) UpstreamEnvironment(pipeline PipelineIdentifier) string {
+	order := o.Default
+	for project, projectOrder := range o.Projects {
+		if strings.EqualFold(project, pipeline.Project) {
+			order = projectOrder
+			break
+		}
+	}
+	for i, environment := range order {
+		if strings.EqualFold(environment, pipeline.Environment) {
+			if i == 0 {
+				return ""
+			}
+			return order[i-1]
+		}
+	}
+
+	return ""
+}
+
+// ResolveAliases returns promotion order with aliases replaced by canonical environments, so that aliased
+// environment is promoted in its place. Order of project is resolved by aliases of the project as well.
+func (o PromotionOrder) ResolveAliases(aliases EnvironmentAliases) (PromotionOrder, error) {
+	defaultOrder, err := aliases.ResolveEnvironments(o.Default)
+	if err != nil {
+		return PromotionOrder{}, err
+	}
+	resolved := PromotionOrder{
+		Default:  defaultOrder,
+		Projects: make(map[string][]string, len(o.Projects)),
+	}
+	for project, order := range o.Projects {
+		projectOrder := make([]string, len(order))
+		for i, environment := range order {
+			projectOrder[i] = aliases.Resolve(PipelineIdentifier{Project: project, Environment: environment}).Environment
+		}
+		resolved.Projects[project] = projectOrder
+	}
+
+	return resolved, nil
+}
+
+type DeploymentRepository interface {
+	Add(ctx context.Context, deployment Deployment) error
+	// FindByVersion returns the latest successful deployment of version to the pipeline
+	FindByVersion(ctx context.Context, pipeline PipelineIdentifier, version string) (*Deployment, error)
+	// FindHistory returns up to DeploymentHistorySize latest deployments of the pipeline, newest first
+	FindHistory(ctx context.Context, pipeline PipelineIdentifier) ([]Deployment, error)
+	// FindCurrent returns the latest successful deployment of every pipeline
+	FindCurrent(ctx context.Context) ([]Deployment, error)
+}
+
+type DeploymentService interface {
+	Report(context.Context, DeploymentStatus, DeploymentReport) (*Deployment, error)
+	GetHistory(context.Context, PipelineIdentifier) ([]Deployment, error)
+	GetCurrent(context.Context) ([]Deployment, error)
+}
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDeploymentReport_Validate(t *testing.T) {
+	type testCases struct {
+		description   string
+		report        DeploymentReport
+		expectedError error
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:   "identifierValidateIsCalled_returnProjectEmptyError",
+			expectedError: ErrProjectEmpty,
+		},
+		{
+			description: "versionIsEmpty_returnVersionEmptyError",
+			report: DeploymentReport{
+				PipelineIdentifier: getValidIdentifier(),
+			},
+			expectedError: ErrVersionEmpty,
+		},
+		{
+			description: "success",
+			report: DeploymentReport{
+				PipelineIdentifier: getValidIdentifier(),
+				DeploymentDetails: DeploymentDetails{
+					Version: "1.0.0",
+				},
+			},
+			expectedError: nil,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			err := scenario.report.Validate()
+
+			if !errors.Is(err, scenario.expectedError) {
+				t.Errorf("Expected %v, received %v", scenario.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestDeploymentStatus_Validate(t *testing.T) {
+	for _, scenario := range []struct {
+		status        DeploymentStatus
+		expectedError error
+	}{
+		{status: DeploymentStarted},
+		{status: DeploymentSucceeded},
+		{status: DeploymentFailed},
+		{status: "", expectedError: ErrDeploymentStatusInvalid},
+		{status: "finished", expectedError: ErrDeploymentStatusInvalid},
+	} {
+		t.Run(string(scenario.status), func(t *testing.T) {
+			err := scenario.status.Validate()
+
+			if !errors.Is(err, scenario.expectedError) {
+				t.Errorf("Expected %v, received %v", scenario.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestPromotionOrder_UpstreamEnvironment(t *testing.T) {
+	order := PromotionOrder{
+		Default: []string{"dev", "staging", "production"},
+		Projects: map[string][]string{
+			"Payments": {"test", "production"},
+		},
+	}
+
+	for _, scenario := range []struct {
+		description string
+		pipeline    PipelineIdentifier
+		expected    string
+	}{
+		{
+			description: "firstEnvironment_returnEmpty",
+			pipeline:    PipelineIdentifier{Project: project, Environment: "dev"},
+			expected:    "",
+		},
+		{
+			description: "environmentNotInOrder_returnEmpty",
+			pipeline:    PipelineIdentifier{Project: project, Environment: "sandbox"},
+			expected:    "",
+		},
+		{
+			description: "downstreamEnvironment_returnPreviousEnvironment",
+			pipeline:    PipelineIdentifier{Project: project, Environment: "Production"},
+			expected:    "staging",
+		},
+		{
+			description: "projectHasOwnOrder_returnPreviousEnvironmentFromProjectOrder",
+			pipeline:    PipelineIdentifier{Project: "payments", Environment: "production"},
+			expected:    "test",
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			upstream := order.UpstreamEnvironment(scenario.pipeline)
+
+			if upstream != scenario.expected {
+				t.Errorf("Expected %s, received %s", scenario.expected, upstream)
+			}
+		})
+	}
+}
+
+func TestPromotionOrder_ResolveAliases(t *testing.T) {
+	var aliases EnvironmentAliases
+	aliases.Add("", "production", "prod")
+	aliases.Add("payments", "production", "live")
+
+	t.Run("aliasesInOrders_returnsOrdersOfCanonicalEnvironments", func(t *testing.T) {
+		order := PromotionOrder{
+			Default:  []string{"staging", "prod"},
+			Projects: map[string][]string{"payments": {"test", "live"}},
+		}
+
+		resolved, err := order.ResolveAliases(aliases)
+
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		if !reflect.DeepEqual(resolved.Default, []string{"staging", "production"}) || !reflect.DeepEqual(resolved.Projects["payments"], []string{"test", "production"}) {
+			t.Errorf("Expected aliases to be resolved, got %v", resolved)
+		}
+	})
+
+	t.Run("aliasOfSingleProjectInDefaultOrder_returnsError", func(t *testing.T) {
+		_, err := PromotionOrder{Default: []string{"staging", "live"}}.ResolveAliases(aliases)
+
+		if err == nil {
+			t.Errorf("Expected error for alias of single project in default order")
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// CheckOutcome is the answer deploy status check got
+type CheckOutcome string
+
+const (
+	CheckAllowed CheckOutcome = "allowed"
+	// CheckOverridden is locked pipeline deployed with override token
+	CheckOverridden CheckOutcome = "overridden"
+	CheckLocked     CheckOutcome = "locked"
+	// CheckNotPromoted is version not deployed to upstream environment yet
+	CheckNotPromoted CheckOutcome = "not_promoted"
+)
+
+// PipelineCheck is single deploy status check of pipeline
+type PipelineCheck struct {
+	PipelineIdentifier
+	Outcome   CheckOutcome
+	CheckedAt time.Time
+}
+
+// KnownPipeline is pipeline discovered from deploy status checks, whether it was ever locked or not
+type KnownPipeline struct {
+	PipelineIdentifier
+	FirstSeen   time.Time    `json:"first_seen"`
+	LastSeen    time.Time    `json:"last_seen"`
+	Checks      int          `json:"checks"`
+	LastOutcome CheckOutcome `json:"last_outcome"`
+}
+
+// Record counts check of the pipeline, zero value becomes pipeline first seen at the check
+func (p *KnownPipeline) Record(check PipelineCheck) {
+	if p.Checks == 0 {
+		p.FirstSeen = check.CheckedAt
+	}
+	p.PipelineIdentifier = check.PipelineIdentifier
+	p.LastSeen = check.CheckedAt
+	p.LastOutcome = check.Outcome
+	p.Checks++
+}
+
+// PipelineSuggestions are distinct values of every identifier field of known pipelines, used to autocomplete lock form
+type PipelineSuggestions struct {
+	Projects     []string
+	Environments []string
+	Regions      []string
+	Clusters     []string
+	Components   []string
+}
+
+func NewPipelineSuggestions(pipelines []KnownPipeline) PipelineSuggestions {
+	var projects, environments, regions, clusters, components []string
+	for _, pipeline := range pipelines {
+		projects = append(projects, pipeline.Project)
+		environments = append(environments, pipeline.Environment)
+		regions = append(regions, pipeline.Region)
+		clusters = append(clusters, pipeline.Cluster)
+		components = append(components, pipeline.Component)
+	}
+
+	return PipelineSuggestions{
+		Projects:     distinctValues(projects),
+		Environments: distinctValues(environments),
+		Regions:      distinctValues(regions),
+		Clusters:     distinctValues(clusters),
+		Components:   distinctValues(components),
+	}
+}
+
+// distinctValues returns sorted values without duplicates and empty values
+func distinctValues(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	distinct := make([]string, 0, len(values))
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		distinct = append(distinct, value)
+	}
+	sort.Strings(distinct)
+
+	return distinct
+}
+
+type PipelineDiscoveryRepository interface {
+	// Record counts check of pipeline, pipeline checked first time becomes known
+	Record(ctx context.Context, check PipelineCheck) error
+	FindAll(ctx context.Context) ([]KnownPipeline, error)
+}
+
+type PipelineDiscoveryService interface {
+	// Record makes checked pipeline known in background, failing to record it is logged and doesn't fail the check
+	Record(ctx context.Context, pipeline PipelineIdentifier, outcome CheckOutcome)
+	// GetKnown returns every pipeline ever checked ordered by scope path
+	GetKnown(ctx context.Context) ([]KnownPipeline, error)
+	// Close stops recording checks and waits until queued checks are recorded, checks recorded after it are dropped
+	Close()
+}
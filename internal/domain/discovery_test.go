@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKnownPipeline_Record(t *testing.T) {
+	firstCheckedAt := time.Now().Add(-time.Hour)
+	lastCheckedAt := time.Now()
+	var known KnownPipeline
+
+	known.Record(PipelineCheck{PipelineIdentifier: PipelineIdentifier{Project: project, Environment: environment}, Outcome: CheckAllowed, CheckedAt: firstCheckedAt})
+	known.Record(PipelineCheck{PipelineIdentifier: PipelineIdentifier{Project: project, Environment: environment}, Outcome: CheckLocked, CheckedAt: lastCheckedAt})
+
+	if known.Checks != 2 {
+		t.Errorf("Expected 2 checks, got %d", known.Checks)
+	}
+	if !known.FirstSeen.Equal(firstCheckedAt) || !known.LastSeen.Equal(lastCheckedAt) {
+		t.Errorf("Expected seen between %v and %v, got %v and %v", firstCheckedAt, lastCheckedAt, known.FirstSeen, known.LastSeen)
+	}
+	if known.LastOutcome != CheckLocked {
+		t.Errorf("Expected last outcome %s, got %s", CheckLocked, known.LastOutcome)
+	}
+}
+
+func TestNewPipelineSuggestions(t *testing.T) {
+	suggestions := NewPipelineSuggestions([]KnownPipeline{
+		{PipelineIdentifier: PipelineIdentifier{Project: "web", Environment: "staging"}},
+		{PipelineIdentifier: PipelineIdentifier{Project: "api", Environment: "staging", PipelineScope: NewPipelineScope("eu")}},
+		{PipelineIdentifier: PipelineIdentifier{Project: "api", Environment: "production", PipelineScope: NewPipelineScope("eu", "blue", "worker")}},
+	})
+
+	expected := PipelineSuggestions{
+		Projects:     []string{"api", "web"},
+		Environments: []string{"production", "staging"},
+		Regions:      []string{"eu"},
+		Clusters:     []string{"blue"},
+		Components:   []string{"worker"},
+	}
+	if !reflect.DeepEqual(suggestions, expected) {
+		t.Errorf("Expected suggestions %v, got %v", expected, suggestions)
+	}
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// PipelineHistorySize is the number of latest events kept per pipeline
+const PipelineHistorySize = 100
+
+type PipelineEventType string
+
+const (
+	PipelineLockedEvent        PipelineEventType = "locked"
+	PipelineUnlockedEvent      PipelineEventType = "unlocked"
+	PipelineLockExtendedEvent  PipelineEventType = "lock_extended"
+	OverrideTokenIssuedEvent   PipelineEventType = "override_issued"
+	OverrideTokenUsedEvent     PipelineEventType = "override_used"
+	UnlockRequestedEvent       PipelineEventType = "unlock_requested"
+	UnlockRequestApprovedEvent PipelineEventType = "unlock_approved"
+	UnlockRequestRejectedEvent PipelineEventType = "unlock_rejected"
+	deploymentEventPrefix                        = "deployment_"
+)
+
+type PipelineEvent struct {
+	PipelineIdentifier
+	Type       PipelineEventType `json:"type"`
+	Actor      string            `json:"actor"`
+	Details    string            `json:"details"`
+	URL        string            `json:"url,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
+// PipelineDetails holds current state and history of single pipeline
+type PipelineDetails struct {
+	PipelineIdentifier
+	Pipeline *Pipeline `json:"pipeline"`
+	// LockedParent is locked pipeline of shorter scope path covering the pipeline
+	LockedParent *Pipeline       `json:"locked_parent,omitempty"`
+	Deployment   *Deployment     `json:"deployment"`
+	Timeline     []PipelineEvent `json:"timeline"`
+}
+
+func (d PipelineDetails) IsLocked() bool {
+	return d.Pipeline != nil && d.Pipeline.IsLocked(time.Now())
+}
+
+// NewDeploymentEvent converts deployment into timeline event
+func NewDeploymentEvent(deployment Deployment) PipelineEvent {
+	details := deployment.Version
+	if deployment.Commit != "" {
+		details += " (" + deployment.Commit + ")"
+	}
+
+	return PipelineEvent{
+		PipelineIdentifier: deployment.PipelineIdentifier,
+		Type:               PipelineEventType(deploymentEventPrefix + string(deployment.Status)),
+		Actor:              deployment.Actor,
+		Details:            details,
+		URL:                deployment.JobURL,
+		OccurredAt:         deployment.DeployedAt,
+	}
+}
+
+// SortTimeline sorts events newest first
+func SortTimeline(events []PipelineEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].OccurredAt.After(events[j].OccurredAt)
+	})
+}
+
+type PipelineEventRepository interface {
+	Add(ctx context.Context, event PipelineEvent) error
+	// FindByPipeline returns up to PipelineHistorySize latest events of the pipeline, newest first
+	FindByPipeline(ctx context.Context, pipeline PipelineIdentifier) ([]PipelineEvent, error)
+}
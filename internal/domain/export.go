@@ -0,0 +1,272 @@
+package domain
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ExportVersion is version of export format written by this version, imports of other versions are rejected
+const ExportVersion = 1
+
+const (
+	ExportFormatJSON = "json"
+	ExportFormatCSV  = "csv"
+)
+
+var (
+	ErrExportVersionUnsupported = errors.New("EXPORT_VERSION_UNSUPPORTED")
+	ErrExportFormatUnsupported  = errors.New("EXPORT_FORMAT_UNSUPPORTED")
+	ErrExportInvalid            = errors.New("EXPORT_INVALID")
+	ErrImportStrategyInvalid    = errors.New("IMPORT_STRATEGY_INVALID")
+)
+
+// ImportStrategy decides what happens to pipeline already stored when the same pipeline is imported
+type ImportStrategy string
+
+const (
+	// ImportSkip leaves stored pipeline and its history untouched
+	ImportSkip ImportStrategy = "skip"
+	// ImportOverwrite replaces stored pipeline lock with imported one and adds missing history
+	ImportOverwrite ImportStrategy = "overwrite"
+	// ImportMerge keeps stored lock unless only imported pipeline is locked or it was locked later, and adds
+	// missing history
+	ImportMerge ImportStrategy = "merge"
+)
+
+// Export is versioned dump of all pipelines with their history
+type Export struct {
+	Version    int              `json:"version"`
+	ExportedAt time.Time        `json:"exported_at"`
+	Pipelines  []PipelineExport `json:"pipelines"`
+}
+
+// PipelineExport holds pipeline with its events and deployments, oldest first
+type PipelineExport struct {
+	Pipeline
+	Events      []PipelineEvent `json:"events"`
+	Deployments []Deployment    `json:"deployments"`
+}
+
+// ImportReport counts imported pipelines and history
+type ImportReport struct {
+	Pipelines int `json:"pipelines"`
+	// Skipped counts pipelines left untouched, stored lock kept on merge is not counted
+	Skipped     int `json:"skipped"`
+	Events      int `json:"events"`
+	Deployments int `json:"deployments"`
+}
+
+type ExportService interface {
+	Export(ctx context.Context) (*Export, error)
+	// Import normalizes identifiers of export like identifiers of API requests and validates whole export before
+	// storing any of it
+	Import(ctx context.Context, export *Export, strategy ImportStrategy) (*ImportReport, error)
+}
+
+func (s ImportStrategy) Validate() error {
+	switch s {
+	case ImportSkip, ImportOverwrite, ImportMerge:
+		return nil
+	}
+
+	return ErrImportStrategyInvalid
+}
+
+// Validate checks version and every pipeline, event and deployment of export
+func (e *Export) Validate() error {
+	if e.Version != ExportVersion {
+		return ErrExportVersionUnsupported
+	}
+	for i := range e.Pipelines {
+		if err := e.Pipelines[i].validate(); err != nil {
+			return fmt.Errorf("%w: pipeline %d: %v", ErrExportInvalid, i, err)
+		}
+	}
+
+	return nil
+}
+
+// validate checks pipeline and its history, history of another pipeline is not accepted
+func (p *PipelineExport) validate() error {
+	if err := p.PipelineIdentifier.Validate(); err != nil {
+		return err
+	}
+	for _, event := range p.Events {
+		if event.PipelineIdentifier != p.PipelineIdentifier {
+			return fmt.Errorf("event of pipeline %s", event.Name())
+		}
+	}
+	for _, deployment := range p.Deployments {
+		report := DeploymentReport{PipelineIdentifier: deployment.PipelineIdentifier, DeploymentDetails: deployment.DeploymentDetails}
+		if err := report.Validate(); err != nil {
+			return err
+		}
+		if err := deployment.Status.Validate(); err != nil {
+			return err
+		}
+		if deployment.PipelineIdentifier != p.PipelineIdentifier {
+			return fmt.Errorf("deployment of pipeline %s", deployment.Name())
+		}
+	}
+
+	return nil
+}
+
+// ApplyIdentifierRules normalizes identifiers of every pipeline and its history in place, returns ErrExportInvalid
+// for identifier not conforming to rules
+func (e *Export) ApplyIdentifierRules(rules IdentifierRules) error {
+	for i := range e.Pipelines {
+		if err := e.Pipelines[i].applyIdentifierRules(rules); err != nil {
+			return fmt.Errorf("%w: pipeline %d: %v", ErrExportInvalid, i, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *PipelineExport) applyIdentifierRules(rules IdentifierRules) error {
+	if err := rules.Apply(&p.PipelineIdentifier); err != nil {
+		return err
+	}
+	for i := range p.Events {
+		if err := rules.Apply(&p.Events[i].PipelineIdentifier); err != nil {
+			return err
+		}
+	}
+	for i := range p.Deployments {
+		if err := rules.Apply(&p.Deployments[i].PipelineIdentifier); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ResolveAliases returns copy of pipeline with its history under canonical environment
+func (p PipelineExport) ResolveAliases(aliases EnvironmentAliases) PipelineExport {
+	resolved := p
+	resolved.PipelineIdentifier = aliases.Resolve(p.PipelineIdentifier)
+	resolved.Events = make([]PipelineEvent, 0, len(p.Events))
+	for _, event := range p.Events {
+		event.PipelineIdentifier = aliases.Resolve(event.PipelineIdentifier)
+		resolved.Events = append(resolved.Events, event)
+	}
+	resolved.Deployments = make([]Deployment, 0, len(p.Deployments))
+	for _, deployment := range p.Deployments {
+		deployment.PipelineIdentifier = aliases.Resolve(deployment.PipelineIdentifier)
+		resolved.Deployments = append(resolved.Deployments, deployment)
+	}
+
+	return resolved
+}
+
+// WriteExport writes export in given format
+func WriteExport(w io.Writer, export *Export, format string) error {
+	switch format {
+	case ExportFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(export)
+	case ExportFormatCSV:
+		return export.writeCSV(w)
+	}
+
+	return ErrExportFormatUnsupported
+}
+
+// ReadExport reads export written by WriteExport in given format
+func ReadExport(r io.Reader, format string) (*Export, error) {
+	switch format {
+	case ExportFormatJSON:
+		var export Export
+		if err := json.NewDecoder(r).Decode(&export); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrExportInvalid, err)
+		}
+		return &export, nil
+	case ExportFormatCSV:
+		return readExportCSV(r)
+	}
+
+	return nil, ErrExportFormatUnsupported
+}
+
+var csvHeader = []string{"project", "environment", "region", "cluster", "component", "locked_by", "locked_at", "locked_until"}
+
+// csvHeaderWithoutScope is header of CSV written before scope dimensions were added
+var csvHeaderWithoutScope = []string{"project", "environment", "locked_by", "locked_at", "locked_until"}
+
+// writeCSV writes one row per pipeline, history is left out of CSV
+func (e *Export) writeCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, pipeline := range e.Pipelines {
+		lockedAt, lockedUntil := "", ""
+		if !pipeline.LockedAt.IsZero() {
+			lockedAt = pipeline.LockedAt.UTC().Format(time.RFC3339Nano)
+		}
+		if pipeline.LockedUntil != nil {
+			lockedUntil = pipeline.LockedUntil.UTC().Format(time.RFC3339Nano)
+		}
+		record := []string{pipeline.Project, pipeline.Environment, pipeline.Region, pipeline.Cluster, pipeline.Component, pipeline.LockedBy, lockedAt, lockedUntil}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// readExportCSV reads pipelines written by writeCSV as export of current version, CSV without scope columns is
+// read as pipelines without scope
+func readExportCSV(r io.Reader) (*Export, error) {
+	reader := csv.NewReader(r)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExportInvalid, err)
+	}
+	if len(records) > 0 && strings.Join(records[0], ",") == strings.Join(csvHeaderWithoutScope, ",") {
+		for i, record := range records {
+			records[i] = append(append(record[:2:2], make([]string, len(ScopeDimensions))...), record[2:]...)
+		}
+	} else if len(records) == 0 || strings.Join(records[0], ",") != strings.Join(csvHeader, ",") {
+		return nil, fmt.Errorf("%w: header %v expected", ErrExportInvalid, csvHeader)
+	}
+	export := &Export{
+		Version:   ExportVersion,
+		Pipelines: make([]PipelineExport, 0, len(records)-1),
+	}
+	for i, record := range records[1:] {
+		pipeline := Pipeline{
+			PipelineIdentifier: PipelineIdentifier{
+				Project:       record[0],
+				Environment:   record[1],
+				PipelineScope: NewPipelineScope(record[2:5]...),
+			},
+			PipelineLockedBy: PipelineLockedBy{LockedBy: record[5]},
+		}
+		if record[6] != "" {
+			if pipeline.LockedAt, err = time.Parse(time.RFC3339Nano, record[6]); err != nil {
+				return nil, fmt.Errorf("%w: row %d: %v", ErrExportInvalid, i+1, err)
+			}
+		}
+		if record[7] != "" {
+			lockedUntil, err := time.Parse(time.RFC3339Nano, record[7])
+			if err != nil {
+				return nil, fmt.Errorf("%w: row %d: %v", ErrExportInvalid, i+1, err)
+			}
+			pipeline.LockedUntil = &lockedUntil
+		}
+		export.Pipelines = append(export.Pipelines, PipelineExport{Pipeline: pipeline})
+	}
+
+	return export, nil
+}
@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExport_Validate(t *testing.T) {
+	type testCases struct {
+		description   string
+		export        Export
+		expectedError error
+		// expectedCause is error of validated pipeline, deployment or event included in message
+		expectedCause error
+	}
+
+	identifier := getValidIdentifier()
+	for _, scenario := range []testCases{
+		{
+			description:   "versionNotSupported_returnVersionUnsupportedError",
+			export:        Export{Version: ExportVersion + 1},
+			expectedError: ErrExportVersionUnsupported,
+		},
+		{
+			description: "pipelineWithoutProject_returnProjectEmptyError",
+			export: Export{
+				Version:   ExportVersion,
+				Pipelines: []PipelineExport{{Pipeline: Pipeline{PipelineIdentifier: PipelineIdentifier{Environment: "dev"}}}},
+			},
+			expectedError: ErrExportInvalid,
+			expectedCause: ErrProjectEmpty,
+		},
+		{
+			description: "deploymentWithInvalidStatus_returnStatusInvalidError",
+			export: Export{
+				Version: ExportVersion,
+				Pipelines: []PipelineExport{{
+					Pipeline:    Pipeline{PipelineIdentifier: identifier},
+					Deployments: []Deployment{{PipelineIdentifier: identifier, DeploymentDetails: DeploymentDetails{Version: "1.0.0"}, Status: "rolled_back"}},
+				}},
+			},
+			expectedError: ErrExportInvalid,
+			expectedCause: ErrDeploymentStatusInvalid,
+		},
+		{
+			description: "eventOfAnotherPipeline_returnExportInvalidError",
+			export: Export{
+				Version: ExportVersion,
+				Pipelines: []PipelineExport{{
+					Pipeline: Pipeline{PipelineIdentifier: identifier},
+					Events:   []PipelineEvent{{PipelineIdentifier: PipelineIdentifier{Project: "other", Environment: "dev"}, Type: PipelineLockedEvent}},
+				}},
+			},
+			expectedError: ErrExportInvalid,
+		},
+		{
+			description: "success",
+			export: Export{
+				Version: ExportVersion,
+				Pipelines: []PipelineExport{{
+					Pipeline:    Pipeline{PipelineIdentifier: identifier},
+					Events:      []PipelineEvent{{PipelineIdentifier: identifier, Type: PipelineLockedEvent}},
+					Deployments: []Deployment{{PipelineIdentifier: identifier, DeploymentDetails: DeploymentDetails{Version: "1.0.0"}, Status: DeploymentSucceeded}},
+				}},
+			},
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			err := scenario.export.Validate()
+
+			if !errors.Is(err, scenario.expectedError) {
+				t.Errorf("Expected %v, received %v", scenario.expectedError, err)
+			}
+			if scenario.expectedCause != nil && !strings.Contains(err.Error(), scenario.expectedCause.Error()) {
+				t.Errorf("Expected %v to be caused by %v", err, scenario.expectedCause)
+			}
+		})
+	}
+}
+
+func TestExport_WriteAndReadExport(t *testing.T) {
+	lockedAt := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	lockedUntil := lockedAt.Add(time.Hour)
+	export := &Export{
+		Version: ExportVersion,
+		Pipelines: []PipelineExport{
+			{
+				Pipeline: Pipeline{
+					PipelineIdentifier:  PipelineIdentifier{Project: "project, with comma", Environment: "production"},
+					PipelineLockedBy:    PipelineLockedBy{LockedBy: "bob"},
+					PipelineLockedAt:    PipelineLockedAt{LockedAt: lockedAt},
+					PipelineLockedUntil: PipelineLockedUntil{LockedUntil: &lockedUntil},
+				},
+				Events: []PipelineEvent{{Type: PipelineLockedEvent, Actor: "bob", OccurredAt: lockedAt}},
+			},
+			{
+				Pipeline: Pipeline{PipelineIdentifier: PipelineIdentifier{Project: "project", Environment: "dev"}},
+			},
+		},
+	}
+
+	for _, format := range []string{ExportFormatJSON, ExportFormatCSV} {
+		t.Run(format+"_roundTrip_pipelinesRestored", func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := WriteExport(&buffer, export, format); err != nil {
+				t.Fatalf("Expected nil error, got %v", err)
+			}
+
+			restored, err := ReadExport(&buffer, format)
+
+			if err != nil {
+				t.Fatalf("Expected nil error, got %v", err)
+			}
+			if restored.Version != ExportVersion || len(restored.Pipelines) != 2 {
+				t.Fatalf("Expected 2 pipelines of version %d, got %+v", ExportVersion, restored)
+			}
+			pipeline := restored.Pipelines[0]
+			if pipeline.Project != "project, with comma" || pipeline.LockedBy != "bob" || !pipeline.LockedAt.Equal(lockedAt) || pipeline.LockedUntil == nil || !pipeline.LockedUntil.Equal(lockedUntil) {
+				t.Errorf("Expected locked pipeline to be restored, got %+v", pipeline)
+			}
+			if restored.Pipelines[1].LockedUntil != nil || !restored.Pipelines[1].LockedAt.IsZero() {
+				t.Errorf("Expected unlocked pipeline to be restored, got %+v", restored.Pipelines[1])
+			}
+			expectedEvents := 1
+			if format == ExportFormatCSV {
+				expectedEvents = 0
+			}
+			if len(pipeline.Events) != expectedEvents {
+				t.Errorf("Expected %d events, got %d", expectedEvents, len(pipeline.Events))
+			}
+		})
+	}
+
+	t.Run("ReadExport_csvWithoutHeader_returnExportInvalidError", func(t *testing.T) {
+		_, err := ReadExport(strings.NewReader("project,production,bob,,\n"), ExportFormatCSV)
+
+		if !errors.Is(err, ErrExportInvalid) {
+			t.Errorf("Expected %v, received %v", ErrExportInvalid, err)
+		}
+	})
+
+	t.Run("ReadExport_unknownFormat_returnFormatUnsupportedError", func(t *testing.T) {
+		_, err := ReadExport(strings.NewReader(""), "xml")
+
+		if err != ErrExportFormatUnsupported {
+			t.Errorf("Expected %v, received %v", ErrExportFormatUnsupported, err)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrProjectTooLong      = errors.New("REQUEST_PROJECT_TOO_LONG")
+	ErrEnvironmentTooLong  = errors.New("REQUEST_ENVIRONMENT_TOO_LONG")
+	ErrProjectInvalid      = errors.New("REQUEST_PROJECT_INVALID")
+	ErrEnvironmentInvalid  = errors.New("REQUEST_ENVIRONMENT_INVALID")
+	ErrProjectReserved     = errors.New("REQUEST_PROJECT_RESERVED")
+	ErrEnvironmentReserved = errors.New("REQUEST_ENVIRONMENT_RESERVED")
+	ErrScopeTooLong        = errors.New("REQUEST_SCOPE_TOO_LONG")
+	ErrScopeInvalid        = errors.New("REQUEST_SCOPE_INVALID")
+	ErrScopeReserved       = errors.New("REQUEST_SCOPE_RESERVED")
+)
+
+// DefaultIdentifierPattern allows letters, digits, spaces and punctuation common in project and environment
+// names, so control characters and HTML are rejected
+const DefaultIdentifierPattern = `^[\p{L}\p{M}\p{N} ._:/@+#-]+$`
+
+// IdentifierRules restrict project, environment and scope dimensions accepted from requests, zero value accepts
+// any name
+type IdentifierRules struct {
+	// Trim removes leading and trailing white space before the name is checked
+	Trim bool
+	// MaxLength limits name length in characters, 0 means no limit
+	MaxLength int
+	// Pattern must match non-empty name when set
+	Pattern *regexp.Regexp
+	// ReservedNames are rejected case insensitively
+	ReservedNames []string
+}
+
+type identifierErrors struct {
+	tooLong  error
+	invalid  error
+	reserved error
+}
+
+// Apply normalizes identifier in place and returns error of first name not conforming to rules. Empty names
+// are left to Validate.
+func (r IdentifierRules) Apply(identifier *PipelineIdentifier) error {
+	project, err := r.apply(identifier.Project, identifierErrors{ErrProjectTooLong, ErrProjectInvalid, ErrProjectReserved})
+	if err != nil {
+		return err
+	}
+	environment, err := r.apply(identifier.Environment, identifierErrors{ErrEnvironmentTooLong, ErrEnvironmentInvalid, ErrEnvironmentReserved})
+	if err != nil {
+		return err
+	}
+	scope := identifier.PipelineScope
+	for i := range ScopeDimensions {
+		dimension := scope.dimension(i)
+		if *dimension, err = r.apply(*dimension, identifierErrors{ErrScopeTooLong, ErrScopeInvalid, ErrScopeReserved}); err != nil {
+			return err
+		}
+	}
+	identifier.Project, identifier.Environment, identifier.PipelineScope = project, environment, scope
+
+	return nil
+}
+
+// Normalize trims identifier in place without checking it, so that pipelines stored before the rules were
+// tightened can still be found, checked and unlocked. Rules are checked by Apply only when identifier is created.
+func (r IdentifierRules) Normalize(identifier *PipelineIdentifier) {
+	if !r.Trim {
+		return
+	}
+	identifier.Project = strings.TrimSpace(identifier.Project)
+	identifier.Environment = strings.TrimSpace(identifier.Environment)
+	for i := range ScopeDimensions {
+		dimension := identifier.PipelineScope.dimension(i)
+		*dimension = strings.TrimSpace(*dimension)
+	}
+}
+
+func (r IdentifierRules) apply(name string, errs identifierErrors) (string, error) {
+	if r.Trim {
+		name = strings.TrimSpace(name)
+	}
+	if name == "" {
+		return name, nil
+	}
+	if r.MaxLength > 0 && utf8.RuneCountInString(name) > r.MaxLength {
+		return name, errs.tooLong
+	}
+	if r.Pattern != nil && !r.Pattern.MatchString(name) {
+		return name, errs.invalid
+	}
+	for _, reserved := range r.ReservedNames {
+		if strings.EqualFold(name, reserved) {
+			return name, errs.reserved
+		}
+	}
+
+	return name, nil
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestIdentifierRules_Apply(t *testing.T) {
+	type testCases struct {
+		description        string
+		rules              IdentifierRules
+		identifier         PipelineIdentifier
+		expectedIdentifier PipelineIdentifier
+		expectedErr        error
+	}
+	defaultRules := IdentifierRules{
+		Trim:          true,
+		MaxLength:     10,
+		Pattern:       regexp.MustCompile(DefaultIdentifierPattern),
+		ReservedNames: []string{"all"},
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:        "zeroRules_returnsNilAndKeepsIdentifier",
+			identifier:         PipelineIdentifier{Project: " <b>project</b> ", Environment: "\tall"},
+			expectedIdentifier: PipelineIdentifier{Project: " <b>project</b> ", Environment: "\tall"},
+		},
+		{
+			description:        "surroundingWhiteSpace_returnsNilAndTrimsIdentifier",
+			rules:              defaultRules,
+			identifier:         PipelineIdentifier{Project: " project\n", Environment: "\tprod "},
+			expectedIdentifier: PipelineIdentifier{Project: "project", Environment: "prod"},
+		},
+		{
+			description:        "emptyNames_returnsNil",
+			rules:              defaultRules,
+			identifier:         PipelineIdentifier{Project: "  ", Environment: ""},
+			expectedIdentifier: PipelineIdentifier{Project: "", Environment: ""},
+		},
+		{
+			description:        "nonLatinNameOfMaxLength_returnsNil",
+			rules:              defaultRules,
+			identifier:         PipelineIdentifier{Project: "ΟΔΟΣ/caf\u00e91", Environment: "team@prod"},
+			expectedIdentifier: PipelineIdentifier{Project: "ΟΔΟΣ/caf\u00e91", Environment: "team@prod"},
+		},
+		{
+			description: "projectTooLong_returnsErrProjectTooLong",
+			rules:       defaultRules,
+			identifier:  PipelineIdentifier{Project: "project-too-long", Environment: "prod"},
+			expectedErr: ErrProjectTooLong,
+		},
+		{
+			description: "environmentTooLong_returnsErrEnvironmentTooLong",
+			rules:       defaultRules,
+			identifier:  PipelineIdentifier{Project: "project", Environment: "production-eu"},
+			expectedErr: ErrEnvironmentTooLong,
+		},
+		{
+			description: "projectWithHtml_returnsErrProjectInvalid",
+			rules:       defaultRules,
+			identifier:  PipelineIdentifier{Project: "<script>", Environment: "prod"},
+			expectedErr: ErrProjectInvalid,
+		},
+		{
+			description: "environmentWithControlCharacter_returnsErrEnvironmentInvalid",
+			rules:       defaultRules,
+			identifier:  PipelineIdentifier{Project: "project", Environment: "pr\x00od"},
+			expectedErr: ErrEnvironmentInvalid,
+		},
+		{
+			description: "reservedProjectOfOtherCase_returnsErrProjectReserved",
+			rules:       defaultRules,
+			identifier:  PipelineIdentifier{Project: " ALL ", Environment: "prod"},
+			expectedErr: ErrProjectReserved,
+		},
+		{
+			description: "reservedEnvironment_returnsErrEnvironmentReserved",
+			rules:       defaultRules,
+			identifier:  PipelineIdentifier{Project: "project", Environment: "all"},
+			expectedErr: ErrEnvironmentReserved,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			identifier := scenario.identifier
+
+			err := scenario.rules.Apply(&identifier)
+
+			if err != scenario.expectedErr {
+				t.Errorf("Expected error %v, got %v", scenario.expectedErr, err)
+			}
+			if err == nil && identifier != scenario.expectedIdentifier {
+				t.Errorf("Expected identifier %v, got %v", scenario.expectedIdentifier, identifier)
+			}
+		})
+	}
+}
+
+func TestIdentifierRules_Normalize(t *testing.T) {
+	type testCases struct {
+		description        string
+		rules              IdentifierRules
+		identifier         PipelineIdentifier
+		expectedIdentifier PipelineIdentifier
+	}
+	for _, scenario := range []testCases{
+		{
+			description:        "trimDisabled_keepsIdentifier",
+			identifier:         PipelineIdentifier{Project: " project ", Environment: "\tprod"},
+			expectedIdentifier: PipelineIdentifier{Project: " project ", Environment: "\tprod"},
+		},
+		{
+			description:        "nameBreakingRules_trimsIdentifierWithoutChecking",
+			rules:              IdentifierRules{Trim: true, MaxLength: 3, Pattern: regexp.MustCompile(DefaultIdentifierPattern), ReservedNames: []string{"all"}},
+			identifier:         PipelineIdentifier{Project: " <b>project</b> ", Environment: "all\n", PipelineScope: PipelineScope{Region: " eu-west "}},
+			expectedIdentifier: PipelineIdentifier{Project: "<b>project</b>", Environment: "all", PipelineScope: PipelineScope{Region: "eu-west"}},
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			identifier := scenario.identifier
+
+			scenario.rules.Normalize(&identifier)
+
+			if identifier != scenario.expectedIdentifier {
+				t.Errorf("Expected identifier %v, got %v", scenario.expectedIdentifier, identifier)
+			}
+		})
+	}
+}
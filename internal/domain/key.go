@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// KeySeparator separates escaped project and environment in pipeline key
+const KeySeparator = ":"
+
+// keyEscaper percent-encodes separator, "/" used by storages to nest keys under pipeline key and "%" itself,
+// so pipeline key is decodable and distinct pipelines never share a key
+var keyEscaper = strings.NewReplacer("%", "%25", KeySeparator, "%3A", "/", "%2F")
+
+// GetKey returns key of pipeline shared by all storages, parts of scope path joined by separator. Parts are NFC
+// normalized and, unless caseSensitiveKey, Unicode case folded, so "STRASSE" and "straße" are the same pipeline
+// when case insensitive. Key of pipeline without scope dimensions, "%", ":", "/" and non-ASCII characters is
+// the same as LegacyKey.
+func (p *PipelineIdentifier) GetKey(caseSensitiveKey bool) string {
+	path := p.Path()
+	for i, part := range path {
+		path[i] = escapeKeyPart(part, caseSensitiveKey)
+	}
+
+	return strings.Join(path, KeySeparator)
+}
+
+// LegacyKey returns key stored by earlier versions, which is ambiguous when project or environment contains
+// separator. It is used only to migrate stored keys to GetKey.
+func (p *PipelineIdentifier) LegacyKey(caseSensitiveKey bool) string {
+	project := p.Project
+	environment := p.Environment
+	if !caseSensitiveKey {
+		project = strings.ToLower(project)
+		environment = strings.ToLower(environment)
+	}
+	return project + KeySeparator + environment
+}
+
+func escapeKeyPart(part string, caseSensitiveKey bool) string {
+	if caseSensitiveKey {
+		part = norm.NFC.String(part)
+	} else {
+		// canonical caseless match, caser is stateful and is not shared between goroutines
+		part = norm.NFC.String(cases.Fold().String(norm.NFD.String(part)))
+	}
+
+	return keyEscaper.Replace(part)
+}
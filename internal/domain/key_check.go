@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrKeyConflictStrategyInvalid = errors.New("KEY_CONFLICT_STRATEGY_INVALID")
+
+// KeyConflictStrategy decides what happens to stored pipelines which get the same key, e.g. "Api" and "api"
+// after PIPELINES_CASE_SENSITIVE is turned off
+type KeyConflictStrategy string
+
+const (
+	// KeyConflictNewestLock stores most recently locked pipeline under the key and drops the others
+	KeyConflictNewestLock KeyConflictStrategy = "newest"
+	// KeyConflictKeepAll leaves colliding pipelines under keys they are stored under, so nothing is dropped
+	// and they are not found until merged
+	KeyConflictKeepAll KeyConflictStrategy = "keep-all"
+)
+
+func (s KeyConflictStrategy) Validate() error {
+	switch s {
+	case KeyConflictNewestLock, KeyConflictKeepAll:
+		return nil
+	}
+
+	return ErrKeyConflictStrategyInvalid
+}
+
+// KeyCheckReport lists stored pipelines which are not found by their current key
+type KeyCheckReport struct {
+	// Orphaned pipelines are stored under other key than current one, e.g. after case sensitivity change
+	Orphaned []PipelineIdentifier `json:"orphaned"`
+	// Collisions group pipelines sharing current key, pipeline stored under the key is first
+	Collisions [][]Pipeline `json:"collisions"`
+}
+
+// KeyMigrationReport counts keys moved to current pipeline keys, on dry run nothing is written
+type KeyMigrationReport struct {
+	KeyCheckReport
+	DryRun   bool `json:"dry_run"`
+	Migrated int  `json:"migrated"`
+	// Kept counts colliding pipelines left under their stored keys
+	Kept int `json:"kept"`
+	// Dropped counts colliding pipelines replaced by newest lock
+	Dropped int `json:"dropped"`
+}
+
+// PipelineKeyRepository is implemented by storages keeping data under pipeline keys, which change with key
+// encoding and case sensitivity
+type PipelineKeyRepository interface {
+	// MigrateKeys moves values stored under other than current pipeline key to current key, values of pipelines
+	// in keep are left under their stored keys. When current key already holds value or history, it is kept
+	// and moved value is dropped. Returns number of moved keys.
+	MigrateKeys(keep map[PipelineIdentifier]bool) (int, error)
+}
+
+type PipelineKeyService interface {
+	// Check finds pipelines not found by their current key without changing storage
+	Check(ctx context.Context) (*KeyCheckReport, error)
+	// Migrate moves pipelines to their current keys, pipelines sharing a key are merged by strategy
+	Migrate(ctx context.Context, strategy KeyConflictStrategy, dryRun bool) (*KeyMigrationReport, error)
+}
@@ -0,0 +1,91 @@
+package domain
+
+import "testing"
+
+func TestPipelineIdentifier_GetKey(t *testing.T) {
+	type testCases struct {
+		description      string
+		first            PipelineIdentifier
+		second           PipelineIdentifier
+		caseSensitiveKey bool
+		expectedSameKey  bool
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:     "separatorInProjectAndEnvironment_differentKeys",
+			first:           PipelineIdentifier{Project: "a:b", Environment: "c"},
+			second:          PipelineIdentifier{Project: "a", Environment: "b:c"},
+			expectedSameKey: false,
+		},
+		{
+			description:     "escapedSeparatorInProject_differentKeys",
+			first:           PipelineIdentifier{Project: "a%3Ab", Environment: "c"},
+			second:          PipelineIdentifier{Project: "a:b", Environment: "c"},
+			expectedSameKey: false,
+		},
+		{
+			description:     "slashInProjectAndEnvironment_differentKeys",
+			first:           PipelineIdentifier{Project: "a/b", Environment: "c"},
+			second:          PipelineIdentifier{Project: "a", Environment: "b/c"},
+			expectedSameKey: false,
+		},
+		{
+			description:     "separatorInEnvironmentAndRegion_differentKeys",
+			first:           PipelineIdentifier{Project: "a", Environment: "b:c"},
+			second:          PipelineIdentifier{Project: "a", Environment: "b", PipelineScope: PipelineScope{Region: "c"}},
+			expectedSameKey: false,
+		},
+		{
+			description:     "caseInsensitiveSharpS_sameKey",
+			first:           PipelineIdentifier{Project: "STRASSE", Environment: environment},
+			second:          PipelineIdentifier{Project: "straße", Environment: environment},
+			expectedSameKey: true,
+		},
+		{
+			description:     "caseInsensitiveFinalSigma_sameKey",
+			first:           PipelineIdentifier{Project: "ΟΔΟΣ", Environment: environment},
+			second:          PipelineIdentifier{Project: "οδος", Environment: environment},
+			expectedSameKey: true,
+		},
+		{
+			description:      "caseSensitiveSharpS_differentKeys",
+			first:            PipelineIdentifier{Project: "STRASSE", Environment: environment},
+			second:           PipelineIdentifier{Project: "straße", Environment: environment},
+			caseSensitiveKey: true,
+			expectedSameKey:  false,
+		},
+		{
+			description:      "composedAndDecomposedAccent_sameKey",
+			first:            PipelineIdentifier{Project: "caf\u00e9", Environment: environment},
+			second:           PipelineIdentifier{Project: "cafe\u0301", Environment: environment},
+			caseSensitiveKey: true,
+			expectedSameKey:  true,
+		},
+		{
+			description:     "caseInsensitiveDecomposedUpperAccent_sameKey",
+			first:           PipelineIdentifier{Project: "CAFE\u0301", Environment: environment},
+			second:          PipelineIdentifier{Project: "caf\u00e9", Environment: environment},
+			expectedSameKey: true,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			firstKey := scenario.first.GetKey(scenario.caseSensitiveKey)
+			secondKey := scenario.second.GetKey(scenario.caseSensitiveKey)
+
+			if (firstKey == secondKey) != scenario.expectedSameKey {
+				t.Errorf("Expected keys %q and %q to be same %t", firstKey, secondKey, scenario.expectedSameKey)
+			}
+		})
+	}
+
+	t.Run("plainIdentifier_sameAsLegacyKey", func(t *testing.T) {
+		identifier := PipelineIdentifier{Project: "Area51", Environment: "Production"}
+
+		for _, caseSensitiveKey := range []bool{true, false} {
+			if key, legacyKey := identifier.GetKey(caseSensitiveKey), identifier.LegacyKey(caseSensitiveKey); key != legacyKey {
+				t.Errorf("Expected key %q to equal legacy key %q", key, legacyKey)
+			}
+		}
+	})
+}
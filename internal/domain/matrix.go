@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// PipelineMatrix is a grid of projects (rows) by environments (columns), pipelines of narrower scope get a row
+// of their own
+type PipelineMatrix struct {
+	Environments []string            `json:"environments"`
+	Rows         []PipelineMatrixRow `json:"rows"`
+}
+
+type PipelineMatrixRow struct {
+	Project string `json:"project"`
+	PipelineScope
+	Cells []PipelineMatrixCell `json:"cells"`
+}
+
+// PipelineMatrixCell holds the state of single pipeline, Pipeline and Deployment are nil if there is nothing stored
+type PipelineMatrixCell struct {
+	PipelineIdentifier
+	Pipeline   *Pipeline   `json:"pipeline"`
+	Deployment *Deployment `json:"deployment"`
+}
+
+func (c PipelineMatrixCell) IsLocked() bool {
+	return c.Pipeline != nil && c.Pipeline.IsLocked(time.Now())
+}
+
+// NewPipelineMatrix builds matrix of every project and environment found from pipelines and deployments.
+// Environments are ordered by environmentOrder, environments not part of the order follow in alphabetical order.
+func NewPipelineMatrix(pipelines []Pipeline, deployments []Deployment, environmentOrder []string) *PipelineMatrix {
+	cells := make(map[PipelineIdentifier]*PipelineMatrixCell)
+	cell := func(identifier PipelineIdentifier) *PipelineMatrixCell {
+		if _, exists := cells[identifier]; !exists {
+			cells[identifier] = &PipelineMatrixCell{PipelineIdentifier: identifier}
+		}
+		return cells[identifier]
+	}
+	for i := range pipelines {
+		cell(pipelines[i].PipelineIdentifier).Pipeline = &pipelines[i]
+	}
+	for i := range deployments {
+		cell(deployments[i].PipelineIdentifier).Deployment = &deployments[i]
+	}
+
+	// rows are identified by pipeline identifier without environment
+	rows := make([]PipelineIdentifier, 0)
+	environments := make([]string, 0)
+	seenRows := make(map[PipelineIdentifier]bool)
+	seenEnvironments := make(map[string]bool)
+	for identifier := range cells {
+		if row := (PipelineIdentifier{Project: identifier.Project, PipelineScope: identifier.PipelineScope}); !seenRows[row] {
+			seenRows[row] = true
+			rows = append(rows, row)
+		}
+		if !seenEnvironments[identifier.Environment] {
+			seenEnvironments[identifier.Environment] = true
+			environments = append(environments, identifier.Environment)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Less(rows[j])
+	})
+	sortEnvironments(environments, environmentOrder)
+
+	matrix := &PipelineMatrix{
+		Environments: environments,
+		Rows:         make([]PipelineMatrixRow, 0, len(rows)),
+	}
+	for _, rowIdentifier := range rows {
+		row := PipelineMatrixRow{
+			Project:       rowIdentifier.Project,
+			PipelineScope: rowIdentifier.PipelineScope,
+			Cells:         make([]PipelineMatrixCell, 0, len(environments)),
+		}
+		for _, environment := range environments {
+			identifier := rowIdentifier
+			identifier.Environment = environment
+			if c, exists := cells[identifier]; exists {
+				row.Cells = append(row.Cells, *c)
+			} else {
+				row.Cells = append(row.Cells, PipelineMatrixCell{PipelineIdentifier: identifier})
+			}
+		}
+		matrix.Rows = append(matrix.Rows, row)
+	}
+
+	return matrix
+}
+
+func sortEnvironments(environments []string, order []string) {
+	position := func(environment string) int {
+		for i, ordered := range order {
+			if strings.EqualFold(ordered, environment) {
+				return i
+			}
+		}
+		return len(order)
+	}
+	sort.SliceStable(environments, func(i, j int) bool {
+		pi, pj := position(environments[i]), position(environments[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return environments[i] < environments[j]
+	})
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewPipelineMatrix(t *testing.T) {
+	pipelines := []Pipeline{
+		{
+			PipelineIdentifier: PipelineIdentifier{Project: "web", Environment: "production"},
+			PipelineLockedBy:   PipelineLockedBy{LockedBy: lockedBy},
+		},
+		{
+			PipelineIdentifier: PipelineIdentifier{Project: project, Environment: "sandbox"},
+		},
+	}
+	deployments := []Deployment{
+		{
+			PipelineIdentifier: PipelineIdentifier{Project: project, Environment: "dev"},
+			DeploymentDetails:  DeploymentDetails{Version: "1.0.0"},
+		},
+		{
+			PipelineIdentifier: PipelineIdentifier{Project: "web", Environment: "production"},
+			DeploymentDetails:  DeploymentDetails{Version: "2.0.0"},
+		},
+	}
+
+	matrix := NewPipelineMatrix(pipelines, deployments, []string{"dev", "staging", "production"})
+
+	t.Run("environmentsAreOrdered_orderedEnvironmentsFirst", func(t *testing.T) {
+		expected := []string{"dev", "production", "sandbox"}
+		if !reflect.DeepEqual(matrix.Environments, expected) {
+			t.Errorf("Expected environments %v, got %v", expected, matrix.Environments)
+		}
+	})
+
+	t.Run("projectsAreRows_sortedAlphabetically", func(t *testing.T) {
+		if len(matrix.Rows) != 2 || matrix.Rows[0].Project != project || matrix.Rows[1].Project != "web" {
+			t.Fatalf("Expected rows %s and web, got %v", project, matrix.Rows)
+		}
+		for _, row := range matrix.Rows {
+			if len(row.Cells) != len(matrix.Environments) {
+				t.Errorf("Expected %d cells in row %s, got %d", len(matrix.Environments), row.Project, len(row.Cells))
+			}
+		}
+	})
+
+	t.Run("cellsHoldPipelineAndDeployment", func(t *testing.T) {
+		webProduction := matrix.Rows[1].Cells[1]
+		if !webProduction.IsLocked() || webProduction.Deployment == nil || webProduction.Deployment.Version != "2.0.0" {
+			t.Errorf("Expected locked web/production deployed with 2.0.0, got %v", webProduction)
+		}
+		projectDev := matrix.Rows[0].Cells[0]
+		if projectDev.IsLocked() || projectDev.Pipeline != nil || projectDev.Deployment == nil {
+			t.Errorf("Expected %s/dev with deployment only, got %v", project, projectDev)
+		}
+		webSandbox := matrix.Rows[1].Cells[2]
+		if webSandbox.Pipeline != nil || webSandbox.Deployment != nil || webSandbox.Project != "web" || webSandbox.Environment != "sandbox" {
+			t.Errorf("Expected empty web/sandbox cell, got %v", webSandbox)
+		}
+	})
+
+	t.Run("scopedPipeline_rowFollowsProjectRow", func(t *testing.T) {
+		scoped := PipelineIdentifier{Project: project, Environment: "production", PipelineScope: PipelineScope{Region: "eu-west"}}
+		matrix := NewPipelineMatrix(append(pipelines, Pipeline{PipelineIdentifier: scoped}), deployments, nil)
+
+		if len(matrix.Rows) != 3 || matrix.Rows[1].Project != project || matrix.Rows[1].PipelineScope != scoped.PipelineScope {
+			t.Fatalf("Expected row of %s/eu-west second, got %v", project, matrix.Rows)
+		}
+		for _, cell := range matrix.Rows[1].Cells {
+			if cell.PipelineScope != scoped.PipelineScope {
+				t.Errorf("Expected cell of scope %v, got %v", scoped.PipelineScope, cell)
+			}
+		}
+	})
+}
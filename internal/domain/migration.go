@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrMigrationNotVerified is returned when pipeline read back from target storage differs from source
+var ErrMigrationNotVerified = errors.New("MIGRATION_NOT_VERIFIED")
+
+// ErrStorageNotInitialized is returned when storage opened without writing to it lacks its schema
+var ErrStorageNotInitialized = errors.New("STORAGE_NOT_INITIALIZED")
+
+// MigrationReport counts state copied from source to target storage, on dry run nothing is written
+type MigrationReport struct {
+	DryRun      bool `json:"dry_run"`
+	Pipelines   int  `json:"pipelines"`
+	Events      int  `json:"events"`
+	Deployments int  `json:"deployments"`
+	// UnlockRequests counts pending unlock requests
+	UnlockRequests int `json:"unlock_requests"`
+	// Skipped counts events, deployments and unlock requests already present in target storage by earlier run
+	Skipped int `json:"skipped"`
+}
+
+type MigrationService interface {
+	// Migrate copies every pipeline with its events and deployments and pending unlock requests to target storage
+	// and verifies stored pipelines
+	Migrate(ctx context.Context, dryRun bool) (*MigrationReport, error)
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrIssuedByEmpty        = errors.New("REQUEST_ISSUED_BY_EMPTY")
+	ErrOverrideTokenInvalid = errors.New("OVERRIDE_TOKEN_INVALID")
+)
+
+type OverrideToken struct {
+	PipelineIdentifier
+	Token     string    `json:"token"`
+	IssuedBy  string    `json:"issued_by"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type OverrideTokenRequest struct {
+	PipelineIdentifier
+	IssuedBy string `json:"issued_by" form:"issued_by"`
+}
+
+func (t *OverrideToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+func (r *OverrideTokenRequest) Validate() error {
+	if err := r.PipelineIdentifier.Validate(); err != nil {
+		return err
+	}
+	if r.IssuedBy == "" {
+		return ErrIssuedByEmpty
+	}
+
+	return nil
+}
+
+type OverrideTokenRepository interface {
+	Add(ctx context.Context, token OverrideToken) error
+	// Consume removes the token and returns it, if it exists and is bound to given pipeline.
+	// Removal is atomic, so the same token is never returned twice.
+	Consume(ctx context.Context, pipeline PipelineIdentifier, token string) (*OverrideToken, error)
+}
+
+type OverrideService interface {
+	Issue(context.Context, OverrideTokenRequest) (*OverrideToken, error)
+	Use(ctx context.Context, pipeline PipelineIdentifier, token string) (bool, error)
+}
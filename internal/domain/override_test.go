@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOverrideTokenRequest_Validate(t *testing.T) {
+	type testCases struct {
+		description   string
+		request       OverrideTokenRequest
+		expectedError error
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:   "identifierValidateIsCalled_returnProjectEmptyError",
+			expectedError: ErrProjectEmpty,
+		},
+		{
+			description: "issuedByIsEmpty_returnIssuedByEmptyError",
+			request: OverrideTokenRequest{
+				PipelineIdentifier: getValidIdentifier(),
+			},
+			expectedError: ErrIssuedByEmpty,
+		},
+		{
+			description: "success",
+			request: OverrideTokenRequest{
+				PipelineIdentifier: getValidIdentifier(),
+				IssuedBy:           lockedBy,
+			},
+			expectedError: nil,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			err := scenario.request.Validate()
+
+			if !errors.Is(err, scenario.expectedError) {
+				t.Errorf("Expected %v, received %v", scenario.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestOverrideToken_IsExpired(t *testing.T) {
+	now := time.Now()
+	for _, scenario := range []struct {
+		description string
+		expiresAt   time.Time
+		expected    bool
+	}{
+		{
+			description: "expiresInFuture_returnFalse",
+			expiresAt:   now.Add(time.Minute),
+			expected:    false,
+		},
+		{
+			description: "expiresNow_returnTrue",
+			expiresAt:   now,
+			expected:    true,
+		},
+		{
+			description: "expiredInPast_returnTrue",
+			expiresAt:   now.Add(-time.Minute),
+			expected:    true,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			token := OverrideToken{ExpiresAt: scenario.expiresAt}
+
+			if token.IsExpired(now) != scenario.expected {
+				t.Errorf("Expected %t, received %t", scenario.expected, token.IsExpired(now))
+			}
+		})
+	}
+}
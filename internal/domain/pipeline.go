@@ -1,8 +1,8 @@
 package domain
 
 import (
+	"context"
 	"errors"
-	"strings"
 	"time"
 )
 
@@ -11,17 +11,28 @@ var (
 	ErrEnvironmentEmpty      = errors.New("REQUEST_ENVIRONMENT_EMPTY")
 	ErrLockedByEmpty         = errors.New("REQUEST_LOCKED_BY_EMPTY")
 	ErrPipelineAlreadyLocked = errors.New("PIPELINE_ALREADY_LOCKED")
+	ErrDurationEmpty         = errors.New("REQUEST_DURATION_EMPTY")
+	ErrDurationInvalid       = errors.New("REQUEST_DURATION_INVALID")
+	ErrPipelineLockNoExpiry  = errors.New("PIPELINE_LOCK_HAS_NO_EXPIRY")
+	ErrWatchNotSupported     = errors.New("WATCH_NOT_SUPPORTED")
+	// ErrStorageTimeout is returned when storage doesn't respond within operation timeout
+	ErrStorageTimeout = errors.New("STORAGE_TIMEOUT")
+	// ErrRequestCanceled is returned when request is canceled before storage responds, e.g. on shutdown
+	ErrRequestCanceled = errors.New("REQUEST_CANCELED")
 )
 
 type Pipeline struct {
 	PipelineIdentifier
 	PipelineLockedBy
 	PipelineLockedAt
+	PipelineLockedUntil
 }
 
+// PipelineIdentifier identifies pipeline by scope path of project, environment and optional scope dimensions
 type PipelineIdentifier struct {
 	Project     string `json:"project" form:"project"`
 	Environment string `json:"environment" form:"environment"`
+	PipelineScope
 }
 
 type PipelineLockedBy struct {
@@ -32,9 +43,39 @@ type PipelineLockedAt struct {
 	LockedAt time.Time `json:"locked_at"`
 }
 
+// PipelineLockedUntil holds lock expiry time, lock without expiry time stays until unlocked
+type PipelineLockedUntil struct {
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+}
+
+type PipelineLockDuration struct {
+	Duration string `json:"duration" form:"duration"`
+}
+
 type PipelineLockRequest struct {
 	PipelineIdentifier
 	PipelineLockedBy
+	PipelineLockDuration
+}
+
+type PipelineExtendRequest struct {
+	PipelineIdentifier
+	PipelineLockDuration
+}
+
+type PipelineStatusRequest struct {
+	PipelineIdentifier
+	Version string `json:"version" query:"version"`
+}
+
+// IsLocked reports whether pipeline is locked at given time
+func (p *Pipeline) IsLocked(now time.Time) bool {
+	return p.LockedBy != "" && (p.LockedUntil == nil || now.Before(*p.LockedUntil))
+}
+
+// HasLock reports whether pipeline is locked by the same person at the same time as given lock
+func (p *Pipeline) HasLock(lock Pipeline) bool {
+	return p.LockedBy != "" && p.LockedBy == lock.LockedBy && p.LockedAt.Equal(lock.LockedAt)
 }
 
 func (p *PipelineIdentifier) Validate() error {
@@ -45,17 +86,7 @@ func (p *PipelineIdentifier) Validate() error {
 		return ErrEnvironmentEmpty
 	}
 
-	return nil
-}
-
-func (p *PipelineIdentifier) GetKey(caseSensitiveKey bool, separator string) string {
-	project := p.Project
-	environment := p.Environment
-	if !caseSensitiveKey {
-		project = strings.ToLower(project)
-		environment = strings.ToLower(environment)
-	}
-	return project + separator + environment
+	return p.PipelineScope.validate()
 }
 
 func (p *PipelineLockRequest) Validate() error {
@@ -65,19 +96,74 @@ func (p *PipelineLockRequest) Validate() error {
 	if p.LockedBy == "" {
 		return ErrLockedByEmpty
 	}
+	if p.Duration == "" {
+		return nil
+	}
+	_, err := p.Parse()
 
-	return nil
+	return err
+}
+
+func (p *PipelineExtendRequest) Validate() error {
+	if err := p.PipelineIdentifier.Validate(); err != nil {
+		return err
+	}
+	if p.Duration == "" {
+		return ErrDurationEmpty
+	}
+	_, err := p.Parse()
+
+	return err
+}
+
+// Parse returns lock duration, zero duration means that lock has no expiry
+func (p *PipelineLockDuration) Parse() (time.Duration, error) {
+	if p.Duration == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(p.Duration)
+	if err != nil || duration <= 0 {
+		return 0, ErrDurationInvalid
+	}
+
+	return duration, nil
 }
 
 type PipelineRepository interface {
-	Find(pipeline PipelineIdentifier) (*Pipeline, error)
-	Add(pipeline Pipeline) error
-	FindLockedPipelines() ([]Pipeline, error)
+	Find(ctx context.Context, pipeline PipelineIdentifier) (*Pipeline, error)
+	Add(ctx context.Context, pipeline Pipeline) error
+	FindLockedPipelines(ctx context.Context) ([]Pipeline, error)
+	FindAll(ctx context.Context) ([]Pipeline, error)
+}
+
+// PipelineLockRepository is implemented by repositories able to lock pipeline atomically,
+// so that only one of concurrent lock requests succeeds when overlocking is not allowed
+type PipelineLockRepository interface {
+	// LockIfUnlocked stores pipeline unless it is locked at pipeline.LockedAt, returns false if pipeline was locked
+	LockIfUnlocked(ctx context.Context, pipeline Pipeline) (bool, error)
+}
+
+// PipelineUnlockRepository is implemented by repositories able to unlock pipeline atomically,
+// so that lock taken after unlock was requested is not released
+type PipelineUnlockRepository interface {
+	// UnlockIfLocked unlocks pipeline only if it still has given lock, returns false if lock has changed
+	UnlockIfLocked(ctx context.Context, lock Pipeline) (bool, error)
+}
+
+// PipelineWatchRepository is implemented by repositories able to notify about pipeline changes as they happen,
+// including locks expired by the storage itself
+type PipelineWatchRepository interface {
+	// Watch sends pipeline every time it is locked or unlocked, until ctx is done
+	Watch(ctx context.Context) (<-chan Pipeline, error)
 }
 
 type PipelineService interface {
-	IsDeployAllowed(PipelineIdentifier) (bool, error)
-	Lock(PipelineLockRequest) error
-	Unlock(PipelineIdentifier) error
-	GetLockedPipelines() ([]Pipeline, error)
+	IsDeployAllowed(context.Context, PipelineStatusRequest) (bool, error)
+	Lock(context.Context, PipelineLockRequest) error
+	Unlock(context.Context, PipelineIdentifier) error
+	GetLockedPipelines(context.Context) ([]Pipeline, error)
+	Extend(context.Context, PipelineExtendRequest) error
+	GetPipelineMatrix(context.Context) (*PipelineMatrix, error)
+	GetPipelineDetails(context.Context, PipelineIdentifier) (*PipelineDetails, error)
+	WatchPipelines(context.Context) (<-chan Pipeline, error)
 }
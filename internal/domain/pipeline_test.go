@@ -3,6 +3,7 @@ package domain
 import (
 	"errors"
 	"testing"
+	"time"
 )
 
 const project = "area51"
@@ -36,6 +37,15 @@ func TestPipelineIdentifier_Validate(t *testing.T) {
 			},
 			expectedError: ErrEnvironmentEmpty,
 		},
+		{
+			description: "clusterWithoutRegion_returnScopeIncompleteError",
+			pipelineIdentifier: PipelineIdentifier{
+				Project:       project,
+				Environment:   environment,
+				PipelineScope: PipelineScope{Cluster: "k8s-1"},
+			},
+			expectedError: ErrScopeIncomplete,
+		},
 		{
 			description:        "success",
 			pipelineIdentifier: getValidIdentifier(),
@@ -71,6 +81,19 @@ func TestPipelineLockRequest_Validate(t *testing.T) {
 			},
 			expectedError: ErrLockedByEmpty,
 		},
+		{
+			description: "durationIsInvalid_returnDurationInvalidError",
+			request: PipelineLockRequest{
+				PipelineIdentifier: getValidIdentifier(),
+				PipelineLockedBy: PipelineLockedBy{
+					LockedBy: lockedBy,
+				},
+				PipelineLockDuration: PipelineLockDuration{
+					Duration: "-1h",
+				},
+			},
+			expectedError: ErrDurationInvalid,
+		},
 		{
 			description: "success",
 			request: PipelineLockRequest{
@@ -91,3 +114,152 @@ func TestPipelineLockRequest_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestPipelineExtendRequest_Validate(t *testing.T) {
+	type testCases struct {
+		description   string
+		request       PipelineExtendRequest
+		expectedError error
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:   "identifierValidateIsCalled_returnProjectEmptyError",
+			expectedError: ErrProjectEmpty,
+		},
+		{
+			description: "durationIsEmpty_returnDurationEmptyError",
+			request: PipelineExtendRequest{
+				PipelineIdentifier: getValidIdentifier(),
+			},
+			expectedError: ErrDurationEmpty,
+		},
+		{
+			description: "durationIsInvalid_returnDurationInvalidError",
+			request: PipelineExtendRequest{
+				PipelineIdentifier: getValidIdentifier(),
+				PipelineLockDuration: PipelineLockDuration{
+					Duration: "tomorrow",
+				},
+			},
+			expectedError: ErrDurationInvalid,
+		},
+		{
+			description: "success",
+			request: PipelineExtendRequest{
+				PipelineIdentifier: getValidIdentifier(),
+				PipelineLockDuration: PipelineLockDuration{
+					Duration: "30m",
+				},
+			},
+			expectedError: nil,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			err := scenario.request.Validate()
+
+			if !errors.Is(err, scenario.expectedError) {
+				t.Errorf("Expected %v, received %v", scenario.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestPipeline_IsLocked(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Minute)
+	past := now.Add(-time.Minute)
+	type testCases struct {
+		description string
+		pipeline    Pipeline
+		expected    bool
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description: "lockedByIsEmpty_returnFalse",
+			pipeline:    Pipeline{},
+			expected:    false,
+		},
+		{
+			description: "lockHasNoExpiry_returnTrue",
+			pipeline: Pipeline{
+				PipelineLockedBy: PipelineLockedBy{LockedBy: lockedBy},
+			},
+			expected: true,
+		},
+		{
+			description: "lockNotExpired_returnTrue",
+			pipeline: Pipeline{
+				PipelineLockedBy:    PipelineLockedBy{LockedBy: lockedBy},
+				PipelineLockedUntil: PipelineLockedUntil{LockedUntil: &future},
+			},
+			expected: true,
+		},
+		{
+			description: "lockExpired_returnFalse",
+			pipeline: Pipeline{
+				PipelineLockedBy:    PipelineLockedBy{LockedBy: lockedBy},
+				PipelineLockedUntil: PipelineLockedUntil{LockedUntil: &past},
+			},
+			expected: false,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			if locked := scenario.pipeline.IsLocked(now); locked != scenario.expected {
+				t.Errorf("Expected %t, received %t", scenario.expected, locked)
+			}
+		})
+	}
+}
+
+func TestPipeline_HasLock(t *testing.T) {
+	now := time.Now()
+	lock := Pipeline{
+		PipelineLockedBy: PipelineLockedBy{LockedBy: lockedBy},
+		PipelineLockedAt: PipelineLockedAt{LockedAt: now},
+	}
+	type testCases struct {
+		description string
+		pipeline    Pipeline
+		expected    bool
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description: "pipelineUnlocked_returnFalse",
+			pipeline:    Pipeline{},
+			expected:    false,
+		},
+		{
+			description: "lockedBySomeoneElse_returnFalse",
+			pipeline: Pipeline{
+				PipelineLockedBy: PipelineLockedBy{LockedBy: "someone else"},
+				PipelineLockedAt: PipelineLockedAt{LockedAt: now},
+			},
+			expected: false,
+		},
+		{
+			description: "lockedAgainLater_returnFalse",
+			pipeline: Pipeline{
+				PipelineLockedBy: PipelineLockedBy{LockedBy: lockedBy},
+				PipelineLockedAt: PipelineLockedAt{LockedAt: now.Add(time.Minute)},
+			},
+			expected: false,
+		},
+		{
+			description: "sameLock_returnTrue",
+			pipeline: Pipeline{
+				PipelineLockedBy: PipelineLockedBy{LockedBy: lockedBy},
+				PipelineLockedAt: PipelineLockedAt{LockedAt: now.UTC()},
+			},
+			expected: true,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			if hasLock := scenario.pipeline.HasLock(lock); hasLock != scenario.expected {
+				t.Errorf("Expected %t, received %t", scenario.expected, hasLock)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrScopeIncomplete = errors.New("REQUEST_SCOPE_INCOMPLETE")
+	// ErrParentPipelineLocked is returned when pipeline is covered by lock of shorter scope path
+	ErrParentPipelineLocked = errors.New("PARENT_PIPELINE_LOCKED")
+)
+
+// ScopeDimensions name dimensions of PipelineScope in order they follow environment in scope path
+var ScopeDimensions = []string{"region", "cluster", "component"}
+
+// PipelineScope narrows pipeline of project and environment by optional dimensions, e.g. payments / production /
+// eu-west. Dimension can be set only when dimensions preceding it are set.
+type PipelineScope struct {
+	Region    string `json:"region,omitempty" form:"region"`
+	Cluster   string `json:"cluster,omitempty" form:"cluster"`
+	Component string `json:"component,omitempty" form:"component"`
+}
+
+// NewPipelineScope returns scope of dimension values given in ScopeDimensions order
+func NewPipelineScope(values ...string) PipelineScope {
+	var scope PipelineScope
+	for i, value := range values {
+		*scope.dimension(i) = value
+	}
+
+	return scope
+}
+
+// Dimensions returns values of set dimensions in ScopeDimensions order
+func (s PipelineScope) Dimensions() []string {
+	dimensions := make([]string, 0, len(ScopeDimensions))
+	for i := range ScopeDimensions {
+		if value := *s.dimension(i); value != "" {
+			dimensions = append(dimensions, value)
+		}
+	}
+
+	return dimensions
+}
+
+func (s *PipelineScope) dimension(i int) *string {
+	return []*string{&s.Region, &s.Cluster, &s.Component}[i]
+}
+
+func (s PipelineScope) validate() error {
+	for i := 1; i < len(ScopeDimensions); i++ {
+		if *s.dimension(i) != "" && *s.dimension(i - 1) == "" {
+			return ErrScopeIncomplete
+		}
+	}
+
+	return nil
+}
+
+// Path returns scope path of pipeline, project and environment followed by set dimensions
+func (p PipelineIdentifier) Path() []string {
+	return append([]string{p.Project, p.Environment}, p.Dimensions()...)
+}
+
+// Name returns scope path joined by "/", e.g. payments/production/eu-west
+func (p PipelineIdentifier) Name() string {
+	return strings.Join(p.Path(), "/")
+}
+
+// URLPath returns scope path with every part path escaped and joined by "/", for building links of pipeline pages
+func (p PipelineIdentifier) URLPath() string {
+	path := p.Path()
+	for i, part := range path {
+		path[i] = url.PathEscape(part)
+	}
+
+	return strings.Join(path, "/")
+}
+
+// Parents returns pipelines of shorter scope paths down to project and environment, shortest first. Lock of
+// parent covers the pipeline.
+func (p PipelineIdentifier) Parents() []PipelineIdentifier {
+	dimensions := p.Dimensions()
+	parents := make([]PipelineIdentifier, 0, len(dimensions))
+	for i := range dimensions {
+		parents = append(parents, PipelineIdentifier{
+			Project:       p.Project,
+			Environment:   p.Environment,
+			PipelineScope: NewPipelineScope(dimensions[:i]...),
+		})
+	}
+
+	return parents
+}
+
+// Less orders pipelines by scope path part by part, so pipeline precedes pipelines its lock covers
+func (p PipelineIdentifier) Less(other PipelineIdentifier) bool {
+	path, otherPath := p.Path(), other.Path()
+	for i := 0; i < len(path) && i < len(otherPath); i++ {
+		if path[i] != otherPath[i] {
+			return path[i] < otherPath[i]
+		}
+	}
+
+	return len(path) < len(otherPath)
+}
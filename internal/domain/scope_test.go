@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPipelineIdentifier_Parents(t *testing.T) {
+	identifier := PipelineIdentifier{Project: project, Environment: environment, PipelineScope: NewPipelineScope("eu-west", "k8s-1")}
+
+	expected := []PipelineIdentifier{
+		getValidIdentifier(),
+		{Project: project, Environment: environment, PipelineScope: PipelineScope{Region: "eu-west"}},
+	}
+	if parents := identifier.Parents(); !reflect.DeepEqual(parents, expected) {
+		t.Errorf("Expected parents %v, got %v", expected, parents)
+	}
+	if parents := getValidIdentifier().Parents(); len(parents) != 0 {
+		t.Errorf("Expected pipeline without scope to have no parents, got %v", parents)
+	}
+	if name := identifier.Name(); name != "area51/production/eu-west/k8s-1" {
+		t.Errorf("Expected name area51/production/eu-west/k8s-1, got %s", name)
+	}
+}
+
+func TestPipelineIdentifier_URLPath(t *testing.T) {
+	identifier := PipelineIdentifier{Project: "team/payments", Environment: "prod #1", PipelineScope: NewPipelineScope("eu?west")}
+
+	if path := identifier.URLPath(); path != "team%2Fpayments/prod%20%231/eu%3Fwest" {
+		t.Errorf("Expected path team%%2Fpayments/prod%%20%%231/eu%%3Fwest, got %s", path)
+	}
+}
+
+func TestPipelineIdentifier_Less(t *testing.T) {
+	parent := getValidIdentifier()
+	child := PipelineIdentifier{Project: project, Environment: environment, PipelineScope: PipelineScope{Region: "eu-west"}}
+
+	if !parent.Less(child) || child.Less(parent) {
+		t.Errorf("Expected %v to precede %v", parent, child)
+	}
+}
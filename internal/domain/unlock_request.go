@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrRequestedByEmpty             = errors.New("REQUEST_REQUESTED_BY_EMPTY")
+	ErrReviewedByEmpty              = errors.New("REQUEST_REVIEWED_BY_EMPTY")
+	ErrPipelineNotLocked            = errors.New("PIPELINE_NOT_LOCKED")
+	ErrUnlockApprovalRequired       = errors.New("UNLOCK_APPROVAL_REQUIRED")
+	ErrUnlockRequestNotFound        = errors.New("UNLOCK_REQUEST_NOT_FOUND")
+	ErrUnlockRequestNotPending      = errors.New("UNLOCK_REQUEST_NOT_PENDING")
+	ErrUnlockRequestAlreadyPending  = errors.New("UNLOCK_REQUEST_ALREADY_PENDING")
+	ErrUnlockSelfApprovalNotAllowed = errors.New("UNLOCK_SELF_APPROVAL_NOT_ALLOWED")
+	ErrUnlockReviewerNotAuthorized  = errors.New("UNLOCK_REVIEWER_NOT_AUTHORIZED")
+	ErrUnlockReviewersNotConfigured = errors.New("UNLOCK_REVIEWERS_NOT_CONFIGURED")
+	// ErrUnlockRequestLockChanged is returned when pipeline was unlocked or locked again after unlock was requested
+	ErrUnlockRequestLockChanged = errors.New("UNLOCK_REQUEST_LOCK_CHANGED")
+)
+
+type UnlockRequestStatus string
+
+const (
+	UnlockRequestPending  UnlockRequestStatus = "pending"
+	UnlockRequestApproved UnlockRequestStatus = "approved"
+	UnlockRequestRejected UnlockRequestStatus = "rejected"
+	UnlockRequestExpired  UnlockRequestStatus = "expired"
+)
+
+type UnlockRequest struct {
+	ID string `json:"id"`
+	PipelineIdentifier
+	// LockedBy and LockedAt identify the lock unlock is requested for, approval doesn't release lock taken later
+	LockedBy    string              `json:"locked_by"`
+	LockedAt    time.Time           `json:"locked_at"`
+	RequestedBy string              `json:"requested_by"`
+	RequestedAt time.Time           `json:"requested_at"`
+	ExpiresAt   time.Time           `json:"expires_at"`
+	Status      UnlockRequestStatus `json:"status"`
+	ReviewedBy  string              `json:"reviewed_by"`
+	ReviewedAt  time.Time           `json:"reviewed_at"`
+}
+
+type UnlockRequestCreateRequest struct {
+	PipelineIdentifier
+	// RequestedBy is reviewer authenticated by token, requested_by of request body is ignored
+	RequestedBy string `json:"requested_by" form:"requested_by"`
+}
+
+// UnlockRequestReview holds reviewer authenticated by reviewer token
+type UnlockRequestReview struct {
+	ReviewedBy string
+}
+
+// StatusAt returns request status at given time, pending request past its expiry time is expired
+func (r *UnlockRequest) StatusAt(now time.Time) UnlockRequestStatus {
+	if r.Status == UnlockRequestPending && !now.Before(r.ExpiresAt) {
+		return UnlockRequestExpired
+	}
+
+	return r.Status
+}
+
+// Lock returns pipeline locked as it was when unlock was requested
+func (r *UnlockRequest) Lock() Pipeline {
+	return Pipeline{
+		PipelineIdentifier: r.PipelineIdentifier,
+		PipelineLockedBy:   PipelineLockedBy{LockedBy: r.LockedBy},
+		PipelineLockedAt:   PipelineLockedAt{LockedAt: r.LockedAt},
+	}
+}
+
+func (r *UnlockRequestCreateRequest) Validate() error {
+	if err := r.PipelineIdentifier.Validate(); err != nil {
+		return err
+	}
+	if r.RequestedBy == "" {
+		return ErrRequestedByEmpty
+	}
+
+	return nil
+}
+
+func (r *UnlockRequestReview) Validate() error {
+	if r.ReviewedBy == "" {
+		return ErrReviewedByEmpty
+	}
+
+	return nil
+}
+
+type UnlockRequestRepository interface {
+	Add(ctx context.Context, request UnlockRequest) error
+	Find(ctx context.Context, id string) (*UnlockRequest, error)
+	// Update stores reviewed or expired request only if stored request is still pending, returns
+	// ErrUnlockRequestNotPending otherwise, so that request is reviewed once
+	Update(ctx context.Context, request UnlockRequest) error
+	FindPending(ctx context.Context) ([]UnlockRequest, error)
+}
+
+type UnlockRequestService interface {
+	Create(context.Context, UnlockRequestCreateRequest) (*UnlockRequest, error)
+	Approve(ctx context.Context, id string, review UnlockRequestReview) (*UnlockRequest, error)
+	Reject(ctx context.Context, id string, review UnlockRequestReview) (*UnlockRequest, error)
+	GetPending(context.Context) ([]UnlockRequest, error)
+}
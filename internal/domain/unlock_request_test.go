@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUnlockRequestCreateRequest_Validate(t *testing.T) {
+	type testCases struct {
+		description   string
+		request       UnlockRequestCreateRequest
+		expectedError error
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:   "identifierValidateIsCalled_returnProjectEmptyError",
+			expectedError: ErrProjectEmpty,
+		},
+		{
+			description: "requestedByIsEmpty_returnRequestedByEmptyError",
+			request: UnlockRequestCreateRequest{
+				PipelineIdentifier: getValidIdentifier(),
+			},
+			expectedError: ErrRequestedByEmpty,
+		},
+		{
+			description: "success",
+			request: UnlockRequestCreateRequest{
+				PipelineIdentifier: getValidIdentifier(),
+				RequestedBy:        lockedBy,
+			},
+			expectedError: nil,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			err := scenario.request.Validate()
+
+			if !errors.Is(err, scenario.expectedError) {
+				t.Errorf("Expected %v, received %v", scenario.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestUnlockRequestReview_Validate(t *testing.T) {
+	t.Run("reviewedByIsEmpty_returnReviewedByEmptyError", func(t *testing.T) {
+		review := UnlockRequestReview{}
+
+		if err := review.Validate(); !errors.Is(err, ErrReviewedByEmpty) {
+			t.Errorf("Expected %v, received %v", ErrReviewedByEmpty, err)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		review := UnlockRequestReview{ReviewedBy: lockedBy}
+
+		if err := review.Validate(); err != nil {
+			t.Errorf("Expected nil, received %v", err)
+		}
+	})
+}
+
+func TestUnlockRequest_StatusAt(t *testing.T) {
+	now := time.Now()
+	for _, scenario := range []struct {
+		description    string
+		request        UnlockRequest
+		expectedStatus UnlockRequestStatus
+	}{
+		{
+			description:    "pendingAndNotExpired_returnPending",
+			request:        UnlockRequest{Status: UnlockRequestPending, ExpiresAt: now.Add(time.Minute)},
+			expectedStatus: UnlockRequestPending,
+		},
+		{
+			description:    "pendingAndExpired_returnExpired",
+			request:        UnlockRequest{Status: UnlockRequestPending, ExpiresAt: now},
+			expectedStatus: UnlockRequestExpired,
+		},
+		{
+			description:    "approvedAndExpired_returnApproved",
+			request:        UnlockRequest{Status: UnlockRequestApproved, ExpiresAt: now.Add(-time.Minute)},
+			expectedStatus: UnlockRequestApproved,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			status := scenario.request.StatusAt(now)
+
+			if status != scenario.expectedStatus {
+				t.Errorf("Expected %s, received %s", scenario.expectedStatus, status)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const bearerPrefix = "Bearer "
+
+// NewAdminAuthorization returns middleware which lets through only requests carrying configured admin token
+// in Authorization header. If admin token is not configured, admin API is disabled.
+func NewAdminAuthorization(adminToken string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if adminToken == "" {
+			return c.Status(fiber.StatusForbidden).SendString("ADMIN_API_DISABLED")
+		}
+		authorization := c.Get(fiber.HeaderAuthorization)
+		if !strings.HasPrefix(authorization, bearerPrefix) {
+			return c.Status(fiber.StatusUnauthorized).SendString("ADMIN_TOKEN_MISSING")
+		}
+		token := strings.TrimPrefix(authorization, bearerPrefix)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).SendString("ADMIN_TOKEN_INVALID")
+		}
+
+		return c.Next()
+	}
+}
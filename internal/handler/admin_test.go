@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminAuthorization(t *testing.T) {
+	const adminToken = "secret"
+	type testCases struct {
+		description         string
+		configuredToken     string
+		authorizationHeader string
+		expectedStatus      int
+	}
+	for _, scenario := range []testCases{
+		{
+			description:         "adminTokenNotConfigured_respondForbidden",
+			authorizationHeader: "Bearer ",
+			expectedStatus:      fiber.StatusForbidden,
+		},
+		{
+			description:     "authorizationHeaderMissing_respondUnauthorized",
+			configuredToken: adminToken,
+			expectedStatus:  fiber.StatusUnauthorized,
+		},
+		{
+			description:         "tokenDoesNotMatch_respondUnauthorized",
+			configuredToken:     adminToken,
+			authorizationHeader: "Bearer wrong",
+			expectedStatus:      fiber.StatusUnauthorized,
+		},
+		{
+			description:         "tokenMatches_callsNextHandler",
+			configuredToken:     adminToken,
+			authorizationHeader: "Bearer " + adminToken,
+			expectedStatus:      fiber.StatusOK,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", NewAdminAuthorization(scenario.configuredToken), func(c *fiber.Ctx) error {
+				return c.SendString("OK")
+			})
+			request := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			if scenario.authorizationHeader != "" {
+				request.Header.Set(fiber.HeaderAuthorization, scenario.authorizationHeader)
+			}
+
+			response, err := app.Test(request)
+
+			if err != nil {
+				t.Fatalf("Expected error nil, got %v", err)
+			}
+			if response.StatusCode != scenario.expectedStatus {
+				t.Errorf("Expected status %d, got %d", scenario.expectedStatus, response.StatusCode)
+			}
+		})
+	}
+}
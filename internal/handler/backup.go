@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"bufio"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+const backupFileTimeFormat = "20060102T150405Z"
+
+type backupHandlers struct {
+	service domain.BackupService
+}
+
+func NewBackupHandlers(service domain.BackupService) *backupHandlers {
+	return &backupHandlers{
+		service: service,
+	}
+}
+
+// Backup streams storage snapshot as file download, snapshot is written while response is sent
+func (h *backupHandlers) Backup(c *fiber.Ctx) error {
+	if !h.service.IsSupported() {
+		return c.Status(fiber.StatusNotImplemented).SendString(domain.ErrBackupNotSupported.Error())
+	}
+	c.Attachment(fmt.Sprintf("pipeline-locker-%s.db", time.Now().UTC().Format(backupFileTimeFormat)))
+	c.Set(fiber.HeaderContentType, fiber.MIMEOctetStream)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.service.Backup(w); err == nil {
+			w.Flush()
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type backupServiceMock struct {
+	domain.BackupService
+	supported  bool
+	fakeBackup func(w io.Writer) error
+}
+
+func (m *backupServiceMock) IsSupported() bool {
+	return m.supported
+}
+
+func (m *backupServiceMock) Backup(w io.Writer) error {
+	if m.fakeBackup != nil {
+		return m.fakeBackup(w)
+	}
+
+	return nil
+}
+
+func TestBackupHandler_Backup(t *testing.T) {
+	type testCases struct {
+		description          string
+		supported            bool
+		expectedStatus       int
+		expectedResponseBody string
+		expectedDisposition  string
+	}
+	for _, scenario := range []testCases{
+		{
+			description:          "backupNotSupported_respondNotImplemented",
+			expectedStatus:       fiber.StatusNotImplemented,
+			expectedResponseBody: domain.ErrBackupNotSupported.Error(),
+		},
+		{
+			description:          "backupSupported_respondSnapshotAsAttachment",
+			supported:            true,
+			expectedStatus:       fiber.StatusOK,
+			expectedResponseBody: "snapshot",
+			expectedDisposition:  "attachment; filename=\"pipeline-locker-",
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			handler := NewBackupHandlers(&backupServiceMock{
+				supported: scenario.supported,
+				fakeBackup: func(w io.Writer) error {
+					_, err := w.Write([]byte("snapshot"))
+					return err
+				},
+			})
+			app := fiber.New()
+			app.Get("/backup", handler.Backup)
+
+			response, _ := app.Test(httptest.NewRequest(fiber.MethodGet, "/backup", nil))
+
+			if response.StatusCode != scenario.expectedStatus {
+				t.Errorf("Expected status %d, got %d", scenario.expectedStatus, response.StatusCode)
+			}
+			body, _ := io.ReadAll(response.Body)
+			if string(body) != scenario.expectedResponseBody {
+				t.Errorf("Expected response body %s, got %s", scenario.expectedResponseBody, string(body))
+			}
+			if disposition := response.Header.Get(fiber.HeaderContentDisposition); !strings.HasPrefix(disposition, scenario.expectedDisposition) {
+				t.Errorf("Expected content disposition %s, got %s", scenario.expectedDisposition, disposition)
+			}
+		})
+	}
+}
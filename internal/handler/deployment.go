@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type deploymentHandlers struct {
+	service         domain.DeploymentService
+	identifierRules domain.IdentifierRules
+}
+
+func NewDeploymentHandlers(service domain.DeploymentService, identifierRules domain.IdentifierRules) *deploymentHandlers {
+	return &deploymentHandlers{
+		service:         service,
+		identifierRules: identifierRules,
+	}
+}
+
+func (h *deploymentHandlers) Report(c *fiber.Ctx) error {
+	r := new(domain.DeploymentReport)
+	if err := c.BodyParser(r); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	identifier := createImmutablePipelineIdentifier(r.PipelineIdentifier)
+	if err := h.identifierRules.Apply(&identifier); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	deployment, err := h.service.Report(c.Context(), domain.DeploymentStatus(utils.ImmutableString(c.Params("status"))), domain.DeploymentReport{
+		PipelineIdentifier: identifier,
+		DeploymentDetails: domain.DeploymentDetails{
+			Version: utils.ImmutableString(r.Version),
+			Commit:  utils.ImmutableString(r.Commit),
+			Actor:   utils.ImmutableString(r.Actor),
+			JobURL:  utils.ImmutableString(r.JobURL),
+		},
+	})
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(deployment)
+}
+
+func (h *deploymentHandlers) GetHistory(c *fiber.Ctx) error {
+	identifier := getPipelineIdentifierFromParams(c)
+	h.identifierRules.Normalize(&identifier)
+	deployments, err := h.service.GetHistory(c.Context(), identifier)
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+
+	return c.JSON(deployments)
+}
+
+func (h *deploymentHandlers) GetCurrent(c *fiber.Ctx) error {
+	deployments, err := h.service.GetCurrent(c.Context())
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+
+	return c.JSON(deployments)
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type deploymentServiceMock struct {
+	domain.DeploymentService
+	fakeReport     func(status domain.DeploymentStatus, report domain.DeploymentReport) (*domain.Deployment, error)
+	fakeGetHistory func(pipeline domain.PipelineIdentifier) ([]domain.Deployment, error)
+	fakeGetCurrent func() ([]domain.Deployment, error)
+}
+
+func (m *deploymentServiceMock) Report(ctx context.Context, status domain.DeploymentStatus, report domain.DeploymentReport) (*domain.Deployment, error) {
+	if m.fakeReport != nil {
+		return m.fakeReport(status, report)
+	}
+
+	return &domain.Deployment{}, nil
+}
+
+func (m *deploymentServiceMock) GetHistory(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.Deployment, error) {
+	if m.fakeGetHistory != nil {
+		return m.fakeGetHistory(pipeline)
+	}
+
+	return make([]domain.Deployment, 0), nil
+}
+
+func (m *deploymentServiceMock) GetCurrent(ctx context.Context) ([]domain.Deployment, error) {
+	if m.fakeGetCurrent != nil {
+		return m.fakeGetCurrent()
+	}
+
+	return make([]domain.Deployment, 0), nil
+}
+
+func TestDeploymentHandler_Report(t *testing.T) {
+	type testCases struct {
+		description           string
+		path                  string
+		requestBody           string
+		expectedStatus        int
+		expectedResponseBody  string
+		fakeReportReturnError error
+	}
+	for _, scenario := range []testCases{
+		{
+			description:          "brokenRequestBody_respondBadRequest",
+			path:                 "/succeeded",
+			requestBody:          "{123",
+			expectedStatus:       fiber.StatusBadRequest,
+			expectedResponseBody: "invalid character",
+		},
+		{
+			description:           "serviceReturnsError_respondConflict",
+			path:                  "/finished",
+			requestBody:           getPipelineRequestBodyMock(),
+			expectedStatus:        fiber.StatusConflict,
+			expectedResponseBody:  domain.ErrDeploymentStatusInvalid.Error(),
+			fakeReportReturnError: domain.ErrDeploymentStatusInvalid,
+		},
+		{
+			description:          "serviceReturnsDeployment_respondCreated",
+			path:                 "/started",
+			requestBody:          "{\"project\":\"proj\",\"environment\":\"env\",\"version\":\"1.0.0\",\"commit\":\"abc\",\"actor\":\"user\",\"job_url\":\"https://ci\"}",
+			expectedStatus:       fiber.StatusCreated,
+			expectedResponseBody: "{\"project\":\"proj\",\"environment\":\"env\",\"version\":\"1.0.0\",\"commit\":\"abc\",\"actor\":\"user\",\"job_url\":\"https://ci\",\"status\":\"started\",\"deployed_at\":\"0001-01-01T00:00:00Z\"}",
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			handler := NewDeploymentHandlers(&deploymentServiceMock{
+				fakeReport: func(status domain.DeploymentStatus, report domain.DeploymentReport) (*domain.Deployment, error) {
+					if scenario.fakeReportReturnError != nil {
+						return nil, scenario.fakeReportReturnError
+					}
+					return &domain.Deployment{
+						PipelineIdentifier: report.PipelineIdentifier,
+						DeploymentDetails:  report.DeploymentDetails,
+						Status:             status,
+					}, nil
+				},
+			}, domain.IdentifierRules{})
+			app := fiber.New()
+			app.Post("/:status", handler.Report)
+			request := httptest.NewRequest(fiber.MethodPost, scenario.path, strings.NewReader(scenario.requestBody))
+			request.Header.Set("content-type", "application/json")
+
+			response, err := app.Test(request)
+			if err != nil {
+				t.Fatalf("Expected error nil, got %v", err)
+			}
+			body, _ := io.ReadAll(response.Body)
+
+			if response.StatusCode != scenario.expectedStatus {
+				t.Errorf("Expected status %d, got %d", scenario.expectedStatus, response.StatusCode)
+			}
+			if !strings.Contains(string(body), scenario.expectedResponseBody) {
+				t.Errorf("Expected body %s, got %s", scenario.expectedResponseBody, string(body))
+			}
+		})
+	}
+}
+
+func TestDeploymentHandler_GetHistory(t *testing.T) {
+	t.Run("serviceReturnsHistory_respondOkWithHistory", func(t *testing.T) {
+		var requestedPipeline domain.PipelineIdentifier
+		handler := NewDeploymentHandlers(&deploymentServiceMock{
+			fakeGetHistory: func(pipeline domain.PipelineIdentifier) ([]domain.Deployment, error) {
+				requestedPipeline = pipeline
+				return []domain.Deployment{{PipelineIdentifier: pipeline, Status: domain.DeploymentFailed}}, nil
+			},
+		}, domain.IdentifierRules{})
+		app := fiber.New()
+		app.Get("/project/:project/environment/:environment", handler.GetHistory)
+
+		response, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/project/proj/environment/env", nil))
+		if err != nil {
+			t.Fatalf("Expected error nil, got %v", err)
+		}
+		body, _ := io.ReadAll(response.Body)
+
+		if response.StatusCode != fiber.StatusOK {
+			t.Errorf("Expected status %d, got %d", fiber.StatusOK, response.StatusCode)
+		}
+		if requestedPipeline.Project != "proj" || requestedPipeline.Environment != "env" {
+			t.Errorf("Expected history of proj/env, got %v", requestedPipeline)
+		}
+		if !strings.Contains(string(body), "\"status\":\"failed\"") {
+			t.Errorf("Expected body to contain failed deployment, got %s", string(body))
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+const mimeTextCSV = "text/csv"
+
+type exportHandlers struct {
+	service domain.ExportService
+}
+
+func NewExportHandlers(service domain.ExportService) *exportHandlers {
+	return &exportHandlers{
+		service: service,
+	}
+}
+
+// Export responds all pipelines as file download, ?format=csv responds pipelines without history as CSV
+func (h *exportHandlers) Export(c *fiber.Ctx) error {
+	format := strings.ToLower(c.Query("format", domain.ExportFormatJSON))
+	if format != domain.ExportFormatJSON && format != domain.ExportFormatCSV {
+		return c.Status(fiber.StatusBadRequest).SendString(domain.ErrExportFormatUnsupported.Error())
+	}
+	export, err := h.service.Export(c.Context())
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+	var body bytes.Buffer
+	if err = domain.WriteExport(&body, export, format); err != nil {
+		return err
+	}
+	c.Attachment(fmt.Sprintf("pipeline-locker-%s.%s", export.ExportedAt.UTC().Format(backupFileTimeFormat), format))
+	if format == domain.ExportFormatCSV {
+		c.Set(fiber.HeaderContentType, mimeTextCSV)
+	} else {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+
+	return c.Send(body.Bytes())
+}
+
+// Import stores export sent as request body, format is read from ?format or Content-Type and conflicting
+// pipelines are resolved by ?strategy, skip by default
+func (h *exportHandlers) Import(c *fiber.Ctx) error {
+	format := strings.ToLower(c.Query("format"))
+	if format == "" {
+		format = domain.ExportFormatJSON
+		if strings.HasPrefix(c.Get(fiber.HeaderContentType), mimeTextCSV) {
+			format = domain.ExportFormatCSV
+		}
+	}
+	export, err := domain.ReadExport(bytes.NewReader(c.Body()), format)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	report, err := h.service.Import(c.Context(), export, domain.ImportStrategy(strings.ToLower(c.Query("strategy", string(domain.ImportSkip)))))
+	if err != nil {
+		return c.Status(importErrorStatus(err)).SendString(err.Error())
+	}
+
+	return c.JSON(report)
+}
+
+// importErrorStatus maps invalid export to bad request, errors of storing it as other service errors
+func importErrorStatus(err error) int {
+	for _, requestErr := range []error{domain.ErrExportInvalid, domain.ErrExportVersionUnsupported, domain.ErrImportStrategyInvalid} {
+		if errors.Is(err, requestErr) {
+			return fiber.StatusBadRequest
+		}
+	}
+
+	return serviceErrorStatus(err)
+}
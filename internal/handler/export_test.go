@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type exportServiceMock struct {
+	domain.ExportService
+	fakeExport func() (*domain.Export, error)
+	fakeImport func(export *domain.Export, strategy domain.ImportStrategy) (*domain.ImportReport, error)
+}
+
+func (m *exportServiceMock) Export(ctx context.Context) (*domain.Export, error) {
+	return m.fakeExport()
+}
+
+func (m *exportServiceMock) Import(ctx context.Context, export *domain.Export, strategy domain.ImportStrategy) (*domain.ImportReport, error) {
+	return m.fakeImport(export, strategy)
+}
+
+func TestExportHandler_Export(t *testing.T) {
+	type testCases struct {
+		description         string
+		url                 string
+		serviceErr          error
+		expectedStatus      int
+		expectedContentType string
+		expectedBodyPrefix  string
+		expectedDisposition string
+	}
+	for _, scenario := range []testCases{
+		{
+			description:         "formatNotProvided_respondJSONAttachment",
+			url:                 "/export",
+			expectedStatus:      fiber.StatusOK,
+			expectedContentType: fiber.MIMEApplicationJSON,
+			expectedBodyPrefix:  "{",
+			expectedDisposition: "attachment; filename=\"pipeline-locker-20220501T100000Z.json\"",
+		},
+		{
+			description:         "csvFormat_respondCSVAttachment",
+			url:                 "/export?format=CSV",
+			expectedStatus:      fiber.StatusOK,
+			expectedContentType: "text/csv",
+			expectedBodyPrefix:  "project,environment,region,cluster,component,locked_by,locked_at,locked_until\nproj,env,,,,user,",
+			expectedDisposition: "attachment; filename=\"pipeline-locker-20220501T100000Z.csv\"",
+		},
+		{
+			description:        "unknownFormat_respondBadRequest",
+			url:                "/export?format=xml",
+			expectedStatus:     fiber.StatusBadRequest,
+			expectedBodyPrefix: domain.ErrExportFormatUnsupported.Error(),
+		},
+		{
+			description:        "storageTimeout_respondGatewayTimeout",
+			url:                "/export",
+			serviceErr:         domain.ErrStorageTimeout,
+			expectedStatus:     fiber.StatusGatewayTimeout,
+			expectedBodyPrefix: domain.ErrStorageTimeout.Error(),
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			handler := NewExportHandlers(&exportServiceMock{
+				fakeExport: func() (*domain.Export, error) {
+					if scenario.serviceErr != nil {
+						return nil, scenario.serviceErr
+					}
+					return &domain.Export{
+						Version:    domain.ExportVersion,
+						ExportedAt: time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC),
+						Pipelines: []domain.PipelineExport{{Pipeline: domain.Pipeline{
+							PipelineIdentifier: domain.PipelineIdentifier{Project: "proj", Environment: "env"},
+							PipelineLockedBy:   domain.PipelineLockedBy{LockedBy: "user"},
+						}}},
+					}, nil
+				},
+			})
+			app := fiber.New()
+			app.Get("/export", handler.Export)
+
+			response, _ := app.Test(httptest.NewRequest(fiber.MethodGet, scenario.url, nil))
+
+			if response.StatusCode != scenario.expectedStatus {
+				t.Errorf("Expected status %d, got %d", scenario.expectedStatus, response.StatusCode)
+			}
+			body, _ := io.ReadAll(response.Body)
+			if !strings.HasPrefix(string(body), scenario.expectedBodyPrefix) {
+				t.Errorf("Expected response body to start with %s, got %s", scenario.expectedBodyPrefix, string(body))
+			}
+			if contentType := response.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(contentType, scenario.expectedContentType) {
+				t.Errorf("Expected content type %s, got %s", scenario.expectedContentType, contentType)
+			}
+			if disposition := response.Header.Get(fiber.HeaderContentDisposition); disposition != scenario.expectedDisposition {
+				t.Errorf("Expected content disposition %s, got %s", scenario.expectedDisposition, disposition)
+			}
+		})
+	}
+}
+
+func TestExportHandler_Import(t *testing.T) {
+	const jsonExport = `{"version":1,"pipelines":[{"project":"project","environment":"environment","locked_by":"user"}]}`
+	const csvExport = "project,environment,locked_by,locked_at,locked_until\nproject,environment,user,,\n"
+
+	type testCases struct {
+		description          string
+		url                  string
+		contentType          string
+		body                 string
+		serviceErr           error
+		expectedStatus       int
+		expectedStrategy     domain.ImportStrategy
+		expectedResponseBody string
+	}
+	for _, scenario := range []testCases{
+		{
+			description:          "jsonWithoutStrategy_importedWithSkipStrategy",
+			url:                  "/import",
+			contentType:          fiber.MIMEApplicationJSON,
+			body:                 jsonExport,
+			expectedStatus:       fiber.StatusOK,
+			expectedStrategy:     domain.ImportSkip,
+			expectedResponseBody: `{"pipelines":1,"skipped":0,"events":0,"deployments":0}`,
+		},
+		{
+			description:          "csvContentTypeWithMergeStrategy_importedWithMergeStrategy",
+			url:                  "/import?strategy=merge",
+			contentType:          "text/csv; charset=utf-8",
+			body:                 csvExport,
+			expectedStatus:       fiber.StatusOK,
+			expectedStrategy:     domain.ImportMerge,
+			expectedResponseBody: `{"pipelines":1,"skipped":0,"events":0,"deployments":0}`,
+		},
+		{
+			description:          "malformedJSON_respondBadRequest",
+			url:                  "/import",
+			body:                 "{",
+			expectedStatus:       fiber.StatusBadRequest,
+			expectedResponseBody: domain.ErrExportInvalid.Error() + ": unexpected EOF",
+		},
+		{
+			description:          "invalidStrategy_respondBadRequest",
+			url:                  "/import?strategy=replace",
+			body:                 jsonExport,
+			serviceErr:           domain.ErrImportStrategyInvalid,
+			expectedStatus:       fiber.StatusBadRequest,
+			expectedStrategy:     "replace",
+			expectedResponseBody: domain.ErrImportStrategyInvalid.Error(),
+		},
+		{
+			description:          "storageTimeout_respondGatewayTimeout",
+			url:                  "/import?format=json",
+			body:                 jsonExport,
+			serviceErr:           domain.ErrStorageTimeout,
+			expectedStatus:       fiber.StatusGatewayTimeout,
+			expectedStrategy:     domain.ImportSkip,
+			expectedResponseBody: domain.ErrStorageTimeout.Error(),
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			handler := NewExportHandlers(&exportServiceMock{
+				fakeImport: func(export *domain.Export, strategy domain.ImportStrategy) (*domain.ImportReport, error) {
+					if strategy != scenario.expectedStrategy {
+						t.Errorf("Expected strategy %s, got %s", scenario.expectedStrategy, strategy)
+					}
+					if scenario.serviceErr != nil {
+						return nil, scenario.serviceErr
+					}
+					return &domain.ImportReport{Pipelines: len(export.Pipelines)}, nil
+				},
+			})
+			app := fiber.New()
+			app.Post("/import", handler.Import)
+			request := httptest.NewRequest(fiber.MethodPost, scenario.url, strings.NewReader(scenario.body))
+			request.Header.Set(fiber.HeaderContentType, scenario.contentType)
+
+			response, _ := app.Test(request)
+
+			if response.StatusCode != scenario.expectedStatus {
+				t.Errorf("Expected status %d, got %d", scenario.expectedStatus, response.StatusCode)
+			}
+			body, _ := io.ReadAll(response.Body)
+			if string(body) != scenario.expectedResponseBody {
+				t.Errorf("Expected response body %s, got %s", scenario.expectedResponseBody, string(body))
+			}
+			if scenario.expectedStatus == fiber.StatusOK {
+				var report domain.ImportReport
+				if err := json.Unmarshal(body, &report); err != nil {
+					t.Errorf("Expected import report, got %v", err)
+				}
+			}
+		})
+	}
+}
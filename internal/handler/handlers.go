@@ -5,12 +5,45 @@ import "github.com/gofiber/fiber/v2"
 type PipelineHandlers interface {
 	Lock(c *fiber.Ctx) error
 	Unlock(c *fiber.Ctx) error
+	Extend(c *fiber.Ctx) error
 	GetStatus(c *fiber.Ctx) error
 	GetLockedPipelines(c *fiber.Ctx) error
+	GetKnownPipelines(c *fiber.Ctx) error
+	GetBlockedAttempts(c *fiber.Ctx) error
+	GetPipelineMatrix(c *fiber.Ctx) error
+	GetPipelineDetails(c *fiber.Ctx) error
+	WatchPipelines(c *fiber.Ctx) error
+	Details(c *fiber.Ctx) error
 	Index(c *fiber.Ctx) error
 	LockAndRedirect(c *fiber.Ctx) error
 }
 
+type OverrideHandlers interface {
+	Issue(c *fiber.Ctx) error
+}
+
+type UnlockRequestHandlers interface {
+	Create(c *fiber.Ctx) error
+	Approve(c *fiber.Ctx) error
+	Reject(c *fiber.Ctx) error
+	GetPending(c *fiber.Ctx) error
+}
+
+type DeploymentHandlers interface {
+	Report(c *fiber.Ctx) error
+	GetHistory(c *fiber.Ctx) error
+	GetCurrent(c *fiber.Ctx) error
+}
+
+type BackupHandlers interface {
+	Backup(c *fiber.Ctx) error
+}
+
+type ExportHandlers interface {
+	Export(c *fiber.Ctx) error
+	Import(c *fiber.Ctx) error
+}
+
 type HealthHandlers interface {
 	HealthCheck(c *fiber.Ctx) error
 }
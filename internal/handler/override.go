@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type overrideHandlers struct {
+	service         domain.OverrideService
+	identifierRules domain.IdentifierRules
+}
+
+func NewOverrideHandlers(service domain.OverrideService, identifierRules domain.IdentifierRules) *overrideHandlers {
+	return &overrideHandlers{
+		service:         service,
+		identifierRules: identifierRules,
+	}
+}
+
+func (h *overrideHandlers) Issue(c *fiber.Ctx) error {
+	r := new(domain.OverrideTokenRequest)
+	if err := c.BodyParser(r); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	identifier := createImmutablePipelineIdentifier(r.PipelineIdentifier)
+	if err := h.identifierRules.Apply(&identifier); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	token, err := h.service.Issue(c.Context(), domain.OverrideTokenRequest{
+		PipelineIdentifier: identifier,
+		IssuedBy:           utils.ImmutableString(r.IssuedBy),
+	})
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(token)
+}
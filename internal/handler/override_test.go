@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/valyala/fasthttp"
+)
+
+func TestOverrideHandler_Issue(t *testing.T) {
+	type testCases struct {
+		description          string
+		requestBody          string
+		expectedStatus       int
+		expectedResponseBody string
+		fakeIssueReturnError error
+		contentTypeHeader    string
+	}
+	for _, scenario := range []testCases{
+		{
+			description:          "brokenRequestBody_respondBadRequest",
+			requestBody:          "{123",
+			expectedStatus:       fiber.StatusBadRequest,
+			expectedResponseBody: "Unprocessable Entity",
+		},
+		{
+			description:          "serviceReturnsError_respondConflict",
+			requestBody:          getPipelineRequestBodyMock(),
+			expectedStatus:       fiber.StatusConflict,
+			expectedResponseBody: domain.ErrIssuedByEmpty.Error(),
+			fakeIssueReturnError: domain.ErrIssuedByEmpty,
+			contentTypeHeader:    "application/json",
+		},
+		{
+			description:          "serviceReturnsToken_respondCreatedWithToken",
+			requestBody:          "{\"project\":\"proj\",\"environment\":\"env\",\"issued_by\":\"user\"}",
+			expectedStatus:       fiber.StatusCreated,
+			expectedResponseBody: "{\"project\":\"proj\",\"environment\":\"env\",\"token\":\"token\",\"issued_by\":\"user\",\"issued_at\":\"0001-01-01T00:00:00Z\",\"expires_at\":\"0001-01-01T00:00:00Z\"}",
+			contentTypeHeader:    "application/json",
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			handler := NewOverrideHandlers(&overrideServiceMock{
+				fakeIssue: func(request domain.OverrideTokenRequest) (*domain.OverrideToken, error) {
+					if scenario.fakeIssueReturnError != nil {
+						return nil, scenario.fakeIssueReturnError
+					}
+					return &domain.OverrideToken{
+						PipelineIdentifier: request.PipelineIdentifier,
+						Token:              "token",
+						IssuedBy:           request.IssuedBy,
+					}, nil
+				},
+			}, domain.IdentifierRules{})
+			app := fiber.New()
+			c := app.AcquireCtx(&fasthttp.RequestCtx{})
+			defer app.ReleaseCtx(c)
+			c.Request().Header.Add("content-type", scenario.contentTypeHeader)
+			c.Request().AppendBodyString(scenario.requestBody)
+
+			handler.Issue(c)
+
+			if c.Response().StatusCode() != scenario.expectedStatus {
+				t.Errorf("Expected status %d, got %d", scenario.expectedStatus, c.Response().StatusCode())
+			}
+			if string(c.Response().Body()) != scenario.expectedResponseBody {
+				t.Errorf("Expected body %s, got %s", scenario.expectedResponseBody, string(c.Response().Body()))
+			}
+		})
+	}
+}
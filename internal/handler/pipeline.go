@@ -1,18 +1,50 @@
 package handler
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/utils"
 	"github.com/msoovali/pipeline-locker/internal/domain"
 )
 
+const (
+	overrideTokenQueryKey = "override_token"
+	// job URL and user of blocked deploy are read from query or, when missing, from headers
+	jobURLQueryKey = "job_url"
+	jobURLHeader   = "X-CI-Job-URL"
+	userQueryKey   = "user"
+	userHeader     = "X-CI-User"
+	// keepAliveInterval is how often comment is sent to idle change stream to detect disconnected clients
+	keepAliveInterval = 15 * time.Second
+)
+
 type pipelineHandlers struct {
-	service domain.PipelineService
+	service              domain.PipelineService
+	overrideService      domain.OverrideService
+	unlockRequestService domain.UnlockRequestService
+	deploymentService    domain.DeploymentService
+	discoveryService     domain.PipelineDiscoveryService
+	blockedService       domain.BlockedAttemptService
+	identifierRules      domain.IdentifierRules
 }
 
-func NewPipelineHandlers(service domain.PipelineService) *pipelineHandlers {
+func NewPipelineHandlers(service domain.PipelineService, overrideService domain.OverrideService, unlockRequestService domain.UnlockRequestService, deploymentService domain.DeploymentService, discoveryService domain.PipelineDiscoveryService, blockedService domain.BlockedAttemptService, identifierRules domain.IdentifierRules) *pipelineHandlers {
 	return &pipelineHandlers{
-		service: service,
+		service:              service,
+		overrideService:      overrideService,
+		unlockRequestService: unlockRequestService,
+		deploymentService:    deploymentService,
+		discoveryService:     discoveryService,
+		blockedService:       blockedService,
+		identifierRules:      identifierRules,
 	}
 }
 
@@ -21,8 +53,12 @@ func (h *pipelineHandlers) Lock(c *fiber.Ctx) error {
 	if err := c.BodyParser(r); err != nil {
 		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
 	}
-	if err := h.service.Lock(createImmutablePipelineLockRequest(*r)); err != nil {
-		return c.Status(fiber.StatusConflict).SendString(err.Error())
+	request := createImmutablePipelineLockRequest(*r)
+	if err := h.identifierRules.Apply(&request.PipelineIdentifier); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if err := h.service.Lock(c.Context(), request); err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
 	}
 
 	return c.SendStatus(fiber.StatusCreated)
@@ -33,68 +69,310 @@ func (h *pipelineHandlers) Unlock(c *fiber.Ctx) error {
 	if err := c.BodyParser(r); err != nil {
 		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
 	}
-	if err := h.service.Unlock(createImmutablePipelineIdentifier(*r)); err != nil {
-		return c.Status(fiber.StatusConflict).SendString(err.Error())
+	identifier := createImmutablePipelineIdentifier(*r)
+	h.identifierRules.Normalize(&identifier)
+	if err := h.service.Unlock(c.Context(), identifier); err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *pipelineHandlers) Extend(c *fiber.Ctx) error {
+	r := new(domain.PipelineExtendRequest)
+	if err := c.BodyParser(r); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	identifier := createImmutablePipelineIdentifier(r.PipelineIdentifier)
+	h.identifierRules.Normalize(&identifier)
+	if err := h.service.Extend(c.Context(), domain.PipelineExtendRequest{
+		PipelineIdentifier: identifier,
+		PipelineLockDuration: domain.PipelineLockDuration{
+			Duration: utils.ImmutableString(r.Duration),
+		},
+	}); err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
 func (h *pipelineHandlers) GetStatus(c *fiber.Ctx) error {
-	allowed, err := h.service.IsDeployAllowed(domain.PipelineIdentifier{
-		Project:     c.Params("project"),
-		Environment: c.Params("environment"),
-	})
+	request := domain.PipelineStatusRequest{
+		PipelineIdentifier: getPipelineIdentifierFromParams(c),
+		Version:            c.Query("version"),
+	}
+	h.identifierRules.Normalize(&request.PipelineIdentifier)
+	outcome := domain.CheckAllowed
+	allowed, err := h.service.IsDeployAllowed(c.Context(), request)
+	if err == nil && !allowed {
+		outcome = domain.CheckOverridden
+		allowed, err = h.overrideService.Use(c.Context(), request.PipelineIdentifier, c.Query(overrideTokenQueryKey))
+	}
 	if err != nil {
-		return c.Status(fiber.StatusConflict).SendString(err.Error())
+		if errors.Is(err, domain.ErrVersionNotPromoted) || errors.Is(err, domain.ErrPromotionVersionRequired) {
+			h.recordCheck(c, request.PipelineIdentifier, domain.CheckNotPromoted)
+			h.recordBlockedAttempt(c, request.PipelineIdentifier, domain.CheckNotPromoted)
+			return c.Status(fiber.StatusLocked).SendString(err.Error())
+		}
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
 	}
 	if !allowed {
+		h.recordCheck(c, request.PipelineIdentifier, domain.CheckLocked)
+		h.recordBlockedAttempt(c, request.PipelineIdentifier, domain.CheckLocked)
 		return c.Status(fiber.StatusLocked).SendString("PIPELINE_IS_LOCKED")
 	}
+	h.recordCheck(c, request.PipelineIdentifier, outcome)
 
 	return c.SendString("OK")
 }
 
+// recordCheck makes checked pipeline known, failing to record it must not fail deploy status check
+func (h *pipelineHandlers) recordCheck(c *fiber.Ctx, pipeline domain.PipelineIdentifier, outcome domain.CheckOutcome) {
+	h.discoveryService.Record(c.Context(), createImmutablePipelineIdentifier(pipeline), outcome)
+}
+
+// recordBlockedAttempt logs deploy stopped with 423 Locked, failing to log it must not fail deploy status check
+func (h *pipelineHandlers) recordBlockedAttempt(c *fiber.Ctx, pipeline domain.PipelineIdentifier, reason domain.CheckOutcome) {
+	h.blockedService.Record(c.Context(), domain.BlockedAttempt{
+		PipelineIdentifier: createImmutablePipelineIdentifier(pipeline),
+		Reason:             reason,
+		ClientIP:           utils.ImmutableString(clientIP(c)),
+		JobURL:             utils.ImmutableString(queryOrHeader(c, jobURLQueryKey, jobURLHeader)),
+		User:               utils.ImmutableString(queryOrHeader(c, userQueryKey, userHeader)),
+	})
+}
+
+// clientIP returns remote address of request, or address in proxy header when request comes from trusted proxy.
+// Proxy header listing several addresses ends with the one trusted proxy received request from, earlier ones are
+// sent by client.
+func clientIP(c *fiber.Ctx) string {
+	ip := c.IP()
+	if separator := strings.LastIndexByte(ip, ','); separator >= 0 {
+		ip = ip[separator+1:]
+	}
+
+	return strings.TrimSpace(ip)
+}
+
+func queryOrHeader(c *fiber.Ctx, queryKey string, header string) string {
+	if value := c.Query(queryKey); value != "" {
+		return value
+	}
+
+	return c.Get(header)
+}
+
+func (h *pipelineHandlers) GetBlockedAttempts(c *fiber.Ctx) error {
+	identifier := getPipelineIdentifierFromParams(c)
+	h.identifierRules.Normalize(&identifier)
+	attempts, err := h.blockedService.GetForLock(c.Context(), identifier)
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(attempts)
+}
+
+func (h *pipelineHandlers) GetKnownPipelines(c *fiber.Ctx) error {
+	pipelines, err := h.discoveryService.GetKnown(c.Context())
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(pipelines)
+}
+
 func (h *pipelineHandlers) GetLockedPipelines(c *fiber.Ctx) error {
-	pipelines, err := h.service.GetLockedPipelines()
+	pipelines, err := h.service.GetLockedPipelines(c.Context())
 	if err != nil {
-		return c.Status(fiber.StatusConflict).SendString(err.Error())
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
 	}
 	return c.JSON(pipelines)
 }
 
+func (h *pipelineHandlers) GetPipelineMatrix(c *fiber.Ctx) error {
+	matrix, err := h.service.GetPipelineMatrix(c.Context())
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(matrix)
+}
+
+func (h *pipelineHandlers) GetPipelineDetails(c *fiber.Ctx) error {
+	identifier := getPipelineIdentifierFromParams(c)
+	h.identifierRules.Normalize(&identifier)
+	details, err := h.service.GetPipelineDetails(c.Context(), identifier)
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(details)
+}
+
+// WatchPipelines streams pipeline changes as server-sent events until client disconnects
+func (h *pipelineHandlers) WatchPipelines(c *fiber.Ctx) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	changes, err := h.service.WatchPipelines(ctx)
+	if err != nil {
+		cancel()
+		if errors.Is(err, domain.ErrWatchNotSupported) {
+			return c.Status(fiber.StatusNotImplemented).SendString(err.Error())
+		}
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		keepAlive := time.NewTicker(keepAliveInterval)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case pipeline, ok := <-changes:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(pipeline)
+				if err != nil {
+					return
+				}
+				fmt.Fprintf(w, "data: %s\n\n", data)
+			case <-keepAlive.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func (h *pipelineHandlers) Details(c *fiber.Ctx) error {
+	identifier := getPipelineIdentifierFromParams(c)
+	h.identifierRules.Normalize(&identifier)
+	details, err := h.service.GetPipelineDetails(c.Context(), identifier)
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+	return c.Render("pipeline", fiber.Map{
+		"details": details,
+	}, "layouts/main")
+}
+
 func (h *pipelineHandlers) Index(c *fiber.Ctx) error {
-	pipelines, err := h.service.GetLockedPipelines()
+	pipelines, err := h.service.GetLockedPipelines(c.Context())
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+	matrix, err := h.service.GetPipelineMatrix(c.Context())
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+	unlockRequests, err := h.unlockRequestService.GetPending(c.Context())
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+	deployments, err := h.deploymentService.GetCurrent(c.Context())
 	if err != nil {
-		return c.Status(fiber.StatusConflict).SendString(err.Error())
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+	knownPipelines, err := h.discoveryService.GetKnown(c.Context())
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+	blockedAttempts, err := h.blockedService.CountForLocks(c.Context(), pipelines)
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
 	}
 	return c.Render("index", fiber.Map{
-		"pipelines": pipelines,
+		"pipelines":       pipelines,
+		"matrix":          matrix,
+		"unlockRequests":  unlockRequests,
+		"deployments":     deployments,
+		"suggestions":     domain.NewPipelineSuggestions(knownPipelines),
+		"blockedAttempts": blockedAttempts,
 	}, "layouts/main")
 }
 
+// LockAndRedirect locks pipeline submitted by form, form is rendered again with error when locking fails
 func (h *pipelineHandlers) LockAndRedirect(c *fiber.Ctx) error {
 	r := new(domain.PipelineLockRequest)
 	err := c.BodyParser(r)
 	if err == nil {
-		err = h.service.Lock(createImmutablePipelineLockRequest(*r))
+		request := createImmutablePipelineLockRequest(*r)
+		if err = h.identifierRules.Apply(&request.PipelineIdentifier); err != nil {
+			c.Status(fiber.StatusBadRequest)
+		} else {
+			err = h.service.Lock(c.Context(), request)
+		}
 	}
 	if err == nil {
 		return c.Redirect("/", fiber.StatusSeeOther)
 	}
-	pipelines, err := h.service.GetLockedPipelines()
+	pipelines, _ := h.service.GetLockedPipelines(c.Context())
+	matrix, _ := h.service.GetPipelineMatrix(c.Context())
+	unlockRequests, _ := h.unlockRequestService.GetPending(c.Context())
+	deployments, _ := h.deploymentService.GetCurrent(c.Context())
+	knownPipelines, _ := h.discoveryService.GetKnown(c.Context())
+	blockedAttempts, _ := h.blockedService.CountForLocks(c.Context(), pipelines)
 
 	return c.Render("index", fiber.Map{
-		"err":       err,
-		"pipelines": pipelines,
-		"formInput": r,
+		"err":             err,
+		"pipelines":       pipelines,
+		"matrix":          matrix,
+		"unlockRequests":  unlockRequests,
+		"deployments":     deployments,
+		"suggestions":     domain.NewPipelineSuggestions(knownPipelines),
+		"blockedAttempts": blockedAttempts,
+		"formInput":       r,
 	}, "layouts/main")
 }
 
+// serviceErrorStatus maps service error to response status, storage not responding in time is not a conflict
+func serviceErrorStatus(err error) int {
+	if errors.Is(err, domain.ErrStorageTimeout) {
+		return fiber.StatusGatewayTimeout
+	}
+	if errors.Is(err, domain.ErrRequestCanceled) {
+		return fiber.StatusServiceUnavailable
+	}
+
+	return fiber.StatusConflict
+}
+
+// getPipelineIdentifierFromParams reads scope path of route, dimensions not part of the route are empty
+func getPipelineIdentifierFromParams(c *fiber.Ctx) domain.PipelineIdentifier {
+	dimensions := make([]string, 0, len(domain.ScopeDimensions))
+	for _, dimension := range domain.ScopeDimensions {
+		dimensions = append(dimensions, pathParam(c, dimension))
+	}
+
+	return domain.PipelineIdentifier{
+		Project:       pathParam(c, "project"),
+		Environment:   pathParam(c, "environment"),
+		PipelineScope: domain.NewPipelineScope(dimensions...),
+	}
+}
+
+// pathParam returns unescaped route parameter, so parts of scope path may contain "/" and other reserved characters
+func pathParam(c *fiber.Ctx, key string) string {
+	value := c.Params(key)
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		return unescaped
+	}
+
+	return value
+}
+
 func createImmutablePipelineIdentifier(p domain.PipelineIdentifier) domain.PipelineIdentifier {
 	return domain.PipelineIdentifier{
 		Project:     utils.ImmutableString(p.Project),
 		Environment: utils.ImmutableString(p.Environment),
+		PipelineScope: domain.PipelineScope{
+			Region:    utils.ImmutableString(p.Region),
+			Cluster:   utils.ImmutableString(p.Cluster),
+			Component: utils.ImmutableString(p.Component),
+		},
 	}
 }
 
@@ -104,5 +382,8 @@ func createImmutablePipelineLockRequest(p domain.PipelineLockRequest) domain.Pip
 		PipelineLockedBy: domain.PipelineLockedBy{
 			LockedBy: utils.ImmutableString(p.LockedBy),
 		},
+		PipelineLockDuration: domain.PipelineLockDuration{
+			Duration: utils.ImmutableString(p.Duration),
+		},
 	}
 }
@@ -1,6 +1,10 @@
 package handler
 
 import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"regexp"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,21 +14,25 @@ import (
 
 type pipelineServiceMock struct {
 	domain.PipelineService
-	fakeIsDeployAllowed    func(pipeline domain.PipelineIdentifier) (bool, error)
+	fakeIsDeployAllowed    func(request domain.PipelineStatusRequest) (bool, error)
 	fakeLock               func(pipeline domain.PipelineLockRequest) error
 	fakeUnlock             func(pipeline domain.PipelineIdentifier) error
 	fakeGetLockedPipelines func() ([]domain.Pipeline, error)
+	fakeGetPipelineMatrix  func() (*domain.PipelineMatrix, error)
+	fakeExtend             func(request domain.PipelineExtendRequest) error
+	fakeGetPipelineDetails func(pipeline domain.PipelineIdentifier) (*domain.PipelineDetails, error)
+	fakeWatchPipelines     func() (<-chan domain.Pipeline, error)
 }
 
-func (m *pipelineServiceMock) IsDeployAllowed(pipeline domain.PipelineIdentifier) (bool, error) {
+func (m *pipelineServiceMock) IsDeployAllowed(ctx context.Context, request domain.PipelineStatusRequest) (bool, error) {
 	if m.fakeIsDeployAllowed != nil {
-		return m.fakeIsDeployAllowed(pipeline)
+		return m.fakeIsDeployAllowed(request)
 	}
 
 	return true, nil
 }
 
-func (m *pipelineServiceMock) Lock(pipeline domain.PipelineLockRequest) error {
+func (m *pipelineServiceMock) Lock(ctx context.Context, pipeline domain.PipelineLockRequest) error {
 	if m.fakeLock != nil {
 		return m.fakeLock(pipeline)
 	}
@@ -32,7 +40,7 @@ func (m *pipelineServiceMock) Lock(pipeline domain.PipelineLockRequest) error {
 	return nil
 }
 
-func (m *pipelineServiceMock) Unlock(pipeline domain.PipelineIdentifier) error {
+func (m *pipelineServiceMock) Unlock(ctx context.Context, pipeline domain.PipelineIdentifier) error {
 	if m.fakeUnlock != nil {
 		return m.fakeUnlock(pipeline)
 	}
@@ -40,7 +48,7 @@ func (m *pipelineServiceMock) Unlock(pipeline domain.PipelineIdentifier) error {
 	return nil
 }
 
-func (m *pipelineServiceMock) GetLockedPipelines() ([]domain.Pipeline, error) {
+func (m *pipelineServiceMock) GetLockedPipelines(ctx context.Context) ([]domain.Pipeline, error) {
 	if m.fakeGetLockedPipelines != nil {
 		return m.fakeGetLockedPipelines()
 	}
@@ -48,6 +56,60 @@ func (m *pipelineServiceMock) GetLockedPipelines() ([]domain.Pipeline, error) {
 	return nil, nil
 }
 
+func (m *pipelineServiceMock) GetPipelineMatrix(ctx context.Context) (*domain.PipelineMatrix, error) {
+	if m.fakeGetPipelineMatrix != nil {
+		return m.fakeGetPipelineMatrix()
+	}
+
+	return &domain.PipelineMatrix{}, nil
+}
+
+func (m *pipelineServiceMock) Extend(ctx context.Context, request domain.PipelineExtendRequest) error {
+	if m.fakeExtend != nil {
+		return m.fakeExtend(request)
+	}
+
+	return nil
+}
+
+func (m *pipelineServiceMock) GetPipelineDetails(ctx context.Context, pipeline domain.PipelineIdentifier) (*domain.PipelineDetails, error) {
+	if m.fakeGetPipelineDetails != nil {
+		return m.fakeGetPipelineDetails(pipeline)
+	}
+
+	return &domain.PipelineDetails{PipelineIdentifier: pipeline}, nil
+}
+
+func (m *pipelineServiceMock) WatchPipelines(ctx context.Context) (<-chan domain.Pipeline, error) {
+	if m.fakeWatchPipelines != nil {
+		return m.fakeWatchPipelines()
+	}
+
+	return nil, domain.ErrWatchNotSupported
+}
+
+type overrideServiceMock struct {
+	domain.OverrideService
+	fakeIssue func(request domain.OverrideTokenRequest) (*domain.OverrideToken, error)
+	fakeUse   func(pipeline domain.PipelineIdentifier, token string) (bool, error)
+}
+
+func (m *overrideServiceMock) Issue(ctx context.Context, request domain.OverrideTokenRequest) (*domain.OverrideToken, error) {
+	if m.fakeIssue != nil {
+		return m.fakeIssue(request)
+	}
+
+	return &domain.OverrideToken{}, nil
+}
+
+func (m *overrideServiceMock) Use(ctx context.Context, pipeline domain.PipelineIdentifier, token string) (bool, error) {
+	if m.fakeUse != nil {
+		return m.fakeUse(pipeline, token)
+	}
+
+	return false, nil
+}
+
 func getLockRequestBodyMock() string {
 	return "{\"project\":\"proj\",\"environment\":\"env\",\"locked_by\":\"user\"}"
 }
@@ -56,6 +118,55 @@ func getPipelineRequestBodyMock() string {
 	return "{\"project\":\"proj\",\"environment\":\"env\"}"
 }
 
+type pipelineDiscoveryServiceMock struct {
+	domain.PipelineDiscoveryService
+	fakeRecord   func(pipeline domain.PipelineIdentifier, outcome domain.CheckOutcome)
+	fakeGetKnown func() ([]domain.KnownPipeline, error)
+}
+
+func (m *pipelineDiscoveryServiceMock) Record(ctx context.Context, pipeline domain.PipelineIdentifier, outcome domain.CheckOutcome) {
+	if m.fakeRecord != nil {
+		m.fakeRecord(pipeline, outcome)
+	}
+}
+
+func (m *pipelineDiscoveryServiceMock) GetKnown(ctx context.Context) ([]domain.KnownPipeline, error) {
+	if m.fakeGetKnown != nil {
+		return m.fakeGetKnown()
+	}
+
+	return make([]domain.KnownPipeline, 0), nil
+}
+
+type blockedAttemptServiceMock struct {
+	domain.BlockedAttemptService
+	fakeRecord        func(attempt domain.BlockedAttempt)
+	fakeGetForLock    func(pipeline domain.PipelineIdentifier) ([]domain.BlockedAttempt, error)
+	fakeCountForLocks func(pipelines []domain.Pipeline) (map[domain.PipelineIdentifier]int, error)
+}
+
+func (m *blockedAttemptServiceMock) Record(ctx context.Context, attempt domain.BlockedAttempt) {
+	if m.fakeRecord != nil {
+		m.fakeRecord(attempt)
+	}
+}
+
+func (m *blockedAttemptServiceMock) GetForLock(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.BlockedAttempt, error) {
+	if m.fakeGetForLock != nil {
+		return m.fakeGetForLock(pipeline)
+	}
+
+	return make([]domain.BlockedAttempt, 0), nil
+}
+
+func (m *blockedAttemptServiceMock) CountForLocks(ctx context.Context, pipelines []domain.Pipeline) (map[domain.PipelineIdentifier]int, error) {
+	if m.fakeCountForLocks != nil {
+		return m.fakeCountForLocks(pipelines)
+	}
+
+	return make(map[domain.PipelineIdentifier]int), nil
+}
+
 func TestPipelineHandler_Lock(t *testing.T) {
 	type testCases struct {
 		description          string
@@ -64,6 +175,7 @@ func TestPipelineHandler_Lock(t *testing.T) {
 		expectedResponseBody string
 		fakeLockReturnValue  error
 		contentTypeHeader    string
+		identifierRules      domain.IdentifierRules
 	}
 	for _, scenario := range []testCases{
 		{
@@ -72,6 +184,24 @@ func TestPipelineHandler_Lock(t *testing.T) {
 			expectedStatus:       fiber.StatusBadRequest,
 			expectedResponseBody: "Unprocessable Entity",
 		},
+		{
+			description:          "projectNotMatchingPattern_respondBadRequest",
+			requestBody:          "{\"project\":\"<script>\",\"environment\":\"env\",\"locked_by\":\"user\"}",
+			expectedStatus:       fiber.StatusBadRequest,
+			expectedResponseBody: domain.ErrProjectInvalid.Error(),
+			fakeLockReturnValue:  domain.ErrPipelineAlreadyLocked,
+			contentTypeHeader:    "application/json",
+			identifierRules:      domain.IdentifierRules{Pattern: regexp.MustCompile(domain.DefaultIdentifierPattern)},
+		},
+		{
+			description:          "environmentReserved_respondBadRequest",
+			requestBody:          "{\"project\":\"proj\",\"environment\":\" ALL \",\"locked_by\":\"user\"}",
+			expectedStatus:       fiber.StatusBadRequest,
+			expectedResponseBody: domain.ErrEnvironmentReserved.Error(),
+			fakeLockReturnValue:  domain.ErrPipelineAlreadyLocked,
+			contentTypeHeader:    "application/json",
+			identifierRules:      domain.IdentifierRules{Trim: true, ReservedNames: []string{"all"}},
+		},
 		{
 			description:          "serviceReturnsError_respondConflict",
 			requestBody:          getLockRequestBodyMock(),
@@ -93,7 +223,7 @@ func TestPipelineHandler_Lock(t *testing.T) {
 				fakeLock: func(pipeline domain.PipelineLockRequest) error {
 					return scenario.fakeLockReturnValue
 				},
-			})
+			}, &overrideServiceMock{}, &unlockRequestServiceMock{}, &deploymentServiceMock{}, &pipelineDiscoveryServiceMock{}, &blockedAttemptServiceMock{}, scenario.identifierRules)
 			app := fiber.New()
 			c := app.AcquireCtx(&fasthttp.RequestCtx{})
 			defer app.ReleaseCtx(c)
@@ -149,7 +279,7 @@ func TestPipelineHandler_Unlock(t *testing.T) {
 				fakeUnlock: func(pipeline domain.PipelineIdentifier) error {
 					return scenario.fakeUnlockReturnValue
 				},
-			})
+			}, &overrideServiceMock{}, &unlockRequestServiceMock{}, &deploymentServiceMock{}, &pipelineDiscoveryServiceMock{}, &blockedAttemptServiceMock{}, domain.IdentifierRules{})
 			app := fiber.New()
 			c := app.AcquireCtx(&fasthttp.RequestCtx{})
 			defer app.ReleaseCtx(c)
@@ -167,3 +297,523 @@ func TestPipelineHandler_Unlock(t *testing.T) {
 		})
 	}
 }
+
+func TestPipelineHandler_GetStatus(t *testing.T) {
+	type testCases struct {
+		description            string
+		overrideToken          string
+		version                string
+		fakeIsDeployAllowed    bool
+		fakeIsDeployAllowedErr error
+		fakeUseReturnValue     bool
+		fakeUseReturnError     error
+		expectedStatus         int
+		expectedResponseBody   string
+		expectedUseCalls       int
+		expectedPresentedToken string
+		expectedOutcome        domain.CheckOutcome
+	}
+	for _, scenario := range []testCases{
+		{
+			description:          "pipelineNotLocked_respondOk",
+			fakeIsDeployAllowed:  true,
+			expectedStatus:       fiber.StatusOK,
+			expectedResponseBody: "OK",
+			expectedOutcome:      domain.CheckAllowed,
+		},
+		{
+			description:          "pipelineLocked_respondLocked",
+			expectedStatus:       fiber.StatusLocked,
+			expectedResponseBody: "PIPELINE_IS_LOCKED",
+			expectedUseCalls:     1,
+			expectedOutcome:      domain.CheckLocked,
+		},
+		{
+			description:            "pipelineLockedAndOverrideTokenAccepted_respondOk",
+			overrideToken:          "token",
+			fakeUseReturnValue:     true,
+			expectedStatus:         fiber.StatusOK,
+			expectedResponseBody:   "OK",
+			expectedUseCalls:       1,
+			expectedPresentedToken: "token",
+			expectedOutcome:        domain.CheckOverridden,
+		},
+		{
+			description:            "pipelineLockedAndOverrideTokenRejected_respondLocked",
+			overrideToken:          "token",
+			expectedStatus:         fiber.StatusLocked,
+			expectedResponseBody:   "PIPELINE_IS_LOCKED",
+			expectedUseCalls:       1,
+			expectedPresentedToken: "token",
+			expectedOutcome:        domain.CheckLocked,
+		},
+		{
+			description:            "versionNotPromoted_respondLocked",
+			version:                "1.0.0",
+			fakeIsDeployAllowedErr: domain.ErrVersionNotPromoted,
+			expectedStatus:         fiber.StatusLocked,
+			expectedResponseBody:   domain.ErrVersionNotPromoted.Error(),
+			expectedOutcome:        domain.CheckNotPromoted,
+		},
+		{
+			description:            "versionNotPromotedAndOverrideTokenPresented_respondLockedWithoutUsingToken",
+			overrideToken:          "token",
+			version:                "1.0.0",
+			fakeIsDeployAllowedErr: domain.ErrVersionNotPromoted,
+			fakeUseReturnValue:     true,
+			expectedStatus:         fiber.StatusLocked,
+			expectedResponseBody:   domain.ErrVersionNotPromoted.Error(),
+			expectedOutcome:        domain.CheckNotPromoted,
+		},
+		{
+			description:            "promotionVersionMissing_respondLocked",
+			fakeIsDeployAllowedErr: domain.ErrPromotionVersionRequired,
+			expectedStatus:         fiber.StatusLocked,
+			expectedResponseBody:   domain.ErrPromotionVersionRequired.Error(),
+			expectedOutcome:        domain.CheckNotPromoted,
+		},
+		{
+			description:            "storageTimeout_respondGatewayTimeout",
+			fakeIsDeployAllowedErr: domain.ErrStorageTimeout,
+			expectedStatus:         fiber.StatusGatewayTimeout,
+			expectedResponseBody:   domain.ErrStorageTimeout.Error(),
+		},
+		{
+			description:            "requestCanceled_respondServiceUnavailable",
+			fakeIsDeployAllowedErr: domain.ErrRequestCanceled,
+			expectedStatus:         fiber.StatusServiceUnavailable,
+			expectedResponseBody:   domain.ErrRequestCanceled.Error(),
+		},
+		{
+			description:            "overrideServiceReturnsError_respondConflict",
+			overrideToken:          "token",
+			fakeUseReturnError:     domain.ErrProjectEmpty,
+			expectedStatus:         fiber.StatusConflict,
+			expectedResponseBody:   domain.ErrProjectEmpty.Error(),
+			expectedUseCalls:       1,
+			expectedPresentedToken: "token",
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			var useCallsCount int
+			var presentedToken string
+			var recordedOutcome domain.CheckOutcome
+			var blockedAttempts []domain.BlockedAttempt
+			handler := NewPipelineHandlers(&pipelineServiceMock{
+				fakeIsDeployAllowed: func(request domain.PipelineStatusRequest) (bool, error) {
+					if request.Version != scenario.version {
+						t.Errorf("Expected version %s, got %s", scenario.version, request.Version)
+					}
+					return scenario.fakeIsDeployAllowed, scenario.fakeIsDeployAllowedErr
+				},
+			}, &overrideServiceMock{
+				fakeUse: func(pipeline domain.PipelineIdentifier, token string) (bool, error) {
+					useCallsCount++
+					presentedToken = token
+					return scenario.fakeUseReturnValue, scenario.fakeUseReturnError
+				},
+			}, &unlockRequestServiceMock{}, &deploymentServiceMock{}, &pipelineDiscoveryServiceMock{
+				fakeRecord: func(pipeline domain.PipelineIdentifier, outcome domain.CheckOutcome) {
+					if pipeline.Project != "proj" || pipeline.Environment != "env" {
+						t.Errorf("Expected recorded pipeline proj/env, got %s", pipeline.Name())
+					}
+					recordedOutcome = outcome
+				},
+			}, &blockedAttemptServiceMock{
+				fakeRecord: func(attempt domain.BlockedAttempt) {
+					blockedAttempts = append(blockedAttempts, attempt)
+				},
+			}, domain.IdentifierRules{})
+			app := fiber.New()
+			app.Get("/project/:project/environment/:environment", handler.GetStatus)
+
+			response, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/project/proj/environment/env?version="+scenario.version+"&override_token="+scenario.overrideToken, nil))
+			if err != nil {
+				t.Fatalf("Expected error nil, got %v", err)
+			}
+			body, _ := io.ReadAll(response.Body)
+
+			if response.StatusCode != scenario.expectedStatus {
+				t.Errorf("Expected status %d, got %d", scenario.expectedStatus, response.StatusCode)
+			}
+			if string(body) != scenario.expectedResponseBody {
+				t.Errorf("Expected body %s, got %s", scenario.expectedResponseBody, string(body))
+			}
+			if useCallsCount != scenario.expectedUseCalls {
+				t.Errorf("Expected override service Use calls %d, got %d", scenario.expectedUseCalls, useCallsCount)
+			}
+			if presentedToken != scenario.expectedPresentedToken {
+				t.Errorf("Expected presented token %s, got %s", scenario.expectedPresentedToken, presentedToken)
+			}
+			if recordedOutcome != scenario.expectedOutcome {
+				t.Errorf("Expected recorded outcome %q, got %q", scenario.expectedOutcome, recordedOutcome)
+			}
+			if scenario.expectedStatus == fiber.StatusLocked {
+				if len(blockedAttempts) != 1 || blockedAttempts[0].Reason != scenario.expectedOutcome {
+					t.Errorf("Expected single blocked attempt with reason %s, got %v", scenario.expectedOutcome, blockedAttempts)
+				}
+			} else if len(blockedAttempts) != 0 {
+				t.Errorf("Expected no blocked attempts, got %v", blockedAttempts)
+			}
+		})
+	}
+}
+
+func TestPipelineHandler_GetStatusBlockedAttempt(t *testing.T) {
+	type testCases struct {
+		description      string
+		query            string
+		headers          map[string]string
+		config           fiber.Config
+		expectedClientIP string
+		expectedJobURL   string
+		expectedUser     string
+	}
+	for _, scenario := range []testCases{
+		{
+			description:      "ciDetailsInQuery_recordsQueryValues",
+			query:            "?job_url=https://ci.example/job/1&user=alice",
+			headers:          map[string]string{"X-CI-Job-URL": "https://ci.example/job/2", "X-CI-User": "bob"},
+			expectedClientIP: "0.0.0.0",
+			expectedJobURL:   "https://ci.example/job/1",
+			expectedUser:     "alice",
+		},
+		{
+			description:      "ciDetailsInHeaders_recordsHeaderValues",
+			headers:          map[string]string{"X-CI-Job-URL": "https://ci.example/job/2", "X-CI-User": "bob"},
+			expectedClientIP: "0.0.0.0",
+			expectedJobURL:   "https://ci.example/job/2",
+			expectedUser:     "bob",
+		},
+		{
+			description:      "proxyHeaderNotConfigured_recordsRemoteIP",
+			headers:          map[string]string{fiber.HeaderXForwardedFor: "10.0.0.1"},
+			expectedClientIP: "0.0.0.0",
+		},
+		{
+			description:      "forwardedByUntrustedProxy_recordsRemoteIP",
+			headers:          map[string]string{fiber.HeaderXForwardedFor: "10.0.0.1"},
+			config:           fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor, EnableTrustedProxyCheck: true, TrustedProxies: []string{"10.0.0.0/8"}},
+			expectedClientIP: "0.0.0.0",
+		},
+		{
+			description:      "forwardedByTrustedProxy_recordsAddressAddedByProxy",
+			headers:          map[string]string{fiber.HeaderXForwardedFor: "10.0.0.9, 10.0.0.1"},
+			config:           fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor, EnableTrustedProxyCheck: true, TrustedProxies: []string{"0.0.0.0"}},
+			expectedClientIP: "10.0.0.1",
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			var recorded domain.BlockedAttempt
+			handler := NewPipelineHandlers(&pipelineServiceMock{
+				fakeIsDeployAllowed: func(request domain.PipelineStatusRequest) (bool, error) {
+					return false, nil
+				},
+			}, &overrideServiceMock{}, &unlockRequestServiceMock{}, &deploymentServiceMock{}, &pipelineDiscoveryServiceMock{}, &blockedAttemptServiceMock{
+				fakeRecord: func(attempt domain.BlockedAttempt) {
+					recorded = attempt
+				},
+			}, domain.IdentifierRules{})
+			app := fiber.New(scenario.config)
+			app.Get("/project/:project/environment/:environment", handler.GetStatus)
+			request := httptest.NewRequest(fiber.MethodGet, "/project/proj/environment/env"+scenario.query, nil)
+			for header, value := range scenario.headers {
+				request.Header.Set(header, value)
+			}
+
+			response, err := app.Test(request)
+
+			if err != nil || response.StatusCode != fiber.StatusLocked {
+				t.Fatalf("Expected status %d, got %v and %v", fiber.StatusLocked, response, err)
+			}
+			if recorded.Project != "proj" || recorded.Environment != "env" || recorded.Reason != domain.CheckLocked {
+				t.Errorf("Expected locked attempt of proj/env, got %v", recorded)
+			}
+			if recorded.ClientIP != scenario.expectedClientIP {
+				t.Errorf("Expected client IP %s, got %s", scenario.expectedClientIP, recorded.ClientIP)
+			}
+			if recorded.JobURL != scenario.expectedJobURL || recorded.User != scenario.expectedUser {
+				t.Errorf("Expected job URL %s and user %s, got %s and %s", scenario.expectedJobURL, scenario.expectedUser, recorded.JobURL, recorded.User)
+			}
+		})
+	}
+}
+
+func TestPipelineHandler_GetBlockedAttempts(t *testing.T) {
+	t.Run("serviceReturnsAttempts_respondOkWithAttempts", func(t *testing.T) {
+		var requestedPipeline domain.PipelineIdentifier
+		handler := NewPipelineHandlers(&pipelineServiceMock{}, &overrideServiceMock{}, &unlockRequestServiceMock{}, &deploymentServiceMock{}, &pipelineDiscoveryServiceMock{}, &blockedAttemptServiceMock{
+			fakeGetForLock: func(pipeline domain.PipelineIdentifier) ([]domain.BlockedAttempt, error) {
+				requestedPipeline = pipeline
+				return []domain.BlockedAttempt{{PipelineIdentifier: pipeline, Reason: domain.CheckLocked, ClientIP: "10.0.0.1"}}, nil
+			},
+		}, domain.IdentifierRules{})
+		app := fiber.New()
+		app.Get("/project/:project/environment/:environment", handler.GetBlockedAttempts)
+
+		response, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/project/proj/environment/env", nil))
+		if err != nil {
+			t.Fatalf("Expected error nil, got %v", err)
+		}
+		body, _ := io.ReadAll(response.Body)
+
+		if response.StatusCode != fiber.StatusOK {
+			t.Errorf("Expected status %d, got %d", fiber.StatusOK, response.StatusCode)
+		}
+		if requestedPipeline.Project != "proj" || requestedPipeline.Environment != "env" {
+			t.Errorf("Expected attempts of proj/env to be requested, got %s", requestedPipeline.Name())
+		}
+		if !regexp.MustCompile(`"reason":"locked","client_ip":"10.0.0.1"`).Match(body) {
+			t.Errorf("Expected blocked attempt in body, got %s", string(body))
+		}
+	})
+}
+
+func TestPipelineHandler_GetPipelineMatrix(t *testing.T) {
+	t.Run("serviceReturnsMatrix_respondOkWithMatrix", func(t *testing.T) {
+		handler := NewPipelineHandlers(&pipelineServiceMock{
+			fakeGetPipelineMatrix: func() (*domain.PipelineMatrix, error) {
+				return &domain.PipelineMatrix{Environments: []string{"env"}}, nil
+			},
+		}, &overrideServiceMock{}, &unlockRequestServiceMock{}, &deploymentServiceMock{}, &pipelineDiscoveryServiceMock{}, &blockedAttemptServiceMock{}, domain.IdentifierRules{})
+		app := fiber.New()
+		c := app.AcquireCtx(&fasthttp.RequestCtx{})
+		defer app.ReleaseCtx(c)
+
+		handler.GetPipelineMatrix(c)
+
+		if c.Response().StatusCode() != fiber.StatusOK {
+			t.Errorf("Expected status %d, got %d", fiber.StatusOK, c.Response().StatusCode())
+		}
+		expectedBody := "{\"environments\":[\"env\"],\"rows\":null}"
+		if string(c.Response().Body()) != expectedBody {
+			t.Errorf("Expected body %s, got %s", expectedBody, string(c.Response().Body()))
+		}
+	})
+}
+
+func TestPipelineHandler_GetKnownPipelines(t *testing.T) {
+	t.Run("serviceReturnsPipelines_respondOkWithPipelines", func(t *testing.T) {
+		handler := NewPipelineHandlers(&pipelineServiceMock{}, &overrideServiceMock{}, &unlockRequestServiceMock{}, &deploymentServiceMock{}, &pipelineDiscoveryServiceMock{
+			fakeGetKnown: func() ([]domain.KnownPipeline, error) {
+				return []domain.KnownPipeline{{
+					PipelineIdentifier: domain.PipelineIdentifier{Project: "proj", Environment: "env"},
+					Checks:             3,
+					LastOutcome:        domain.CheckLocked,
+				}}, nil
+			},
+		}, &blockedAttemptServiceMock{}, domain.IdentifierRules{})
+		app := fiber.New()
+		c := app.AcquireCtx(&fasthttp.RequestCtx{})
+		defer app.ReleaseCtx(c)
+
+		handler.GetKnownPipelines(c)
+
+		if c.Response().StatusCode() != fiber.StatusOK {
+			t.Errorf("Expected status %d, got %d", fiber.StatusOK, c.Response().StatusCode())
+		}
+		if !regexp.MustCompile(`"project":"proj".*"checks":3,"last_outcome":"locked"`).Match(c.Response().Body()) {
+			t.Errorf("Expected known pipeline in body, got %s", string(c.Response().Body()))
+		}
+	})
+	t.Run("serviceReturnsError_respondGatewayTimeout", func(t *testing.T) {
+		handler := NewPipelineHandlers(&pipelineServiceMock{}, &overrideServiceMock{}, &unlockRequestServiceMock{}, &deploymentServiceMock{}, &pipelineDiscoveryServiceMock{
+			fakeGetKnown: func() ([]domain.KnownPipeline, error) {
+				return nil, domain.ErrStorageTimeout
+			},
+		}, &blockedAttemptServiceMock{}, domain.IdentifierRules{})
+		app := fiber.New()
+		c := app.AcquireCtx(&fasthttp.RequestCtx{})
+		defer app.ReleaseCtx(c)
+
+		handler.GetKnownPipelines(c)
+
+		if c.Response().StatusCode() != fiber.StatusGatewayTimeout {
+			t.Errorf("Expected status %d, got %d", fiber.StatusGatewayTimeout, c.Response().StatusCode())
+		}
+	})
+}
+
+func TestPipelineHandler_Extend(t *testing.T) {
+	type testCases struct {
+		description           string
+		requestBody           string
+		expectedStatus        int
+		expectedResponseBody  string
+		fakeExtendReturnValue error
+		contentTypeHeader     string
+	}
+	for _, scenario := range []testCases{
+		{
+			description:          "brokenRequestBody_respondBadRequest",
+			requestBody:          "123",
+			expectedStatus:       fiber.StatusBadRequest,
+			expectedResponseBody: "Unprocessable Entity",
+		},
+		{
+			description:           "serviceReturnsError_respondConflict",
+			requestBody:           getPipelineRequestBodyMock(),
+			expectedStatus:        fiber.StatusConflict,
+			expectedResponseBody:  domain.ErrPipelineLockNoExpiry.Error(),
+			fakeExtendReturnValue: domain.ErrPipelineLockNoExpiry,
+			contentTypeHeader:     "application/json",
+		},
+		{
+			description:          "serviceReturnsNil_respondNoContent",
+			requestBody:          "{\"project\":\"proj\",\"environment\":\"env\",\"duration\":\"1h\"}",
+			expectedStatus:       fiber.StatusNoContent,
+			expectedResponseBody: "No Content",
+			contentTypeHeader:    "application/json",
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			handler := NewPipelineHandlers(&pipelineServiceMock{
+				fakeExtend: func(request domain.PipelineExtendRequest) error {
+					return scenario.fakeExtendReturnValue
+				},
+			}, &overrideServiceMock{}, &unlockRequestServiceMock{}, &deploymentServiceMock{}, &pipelineDiscoveryServiceMock{}, &blockedAttemptServiceMock{}, domain.IdentifierRules{})
+			app := fiber.New()
+			c := app.AcquireCtx(&fasthttp.RequestCtx{})
+			defer app.ReleaseCtx(c)
+			c.Request().Header.Add("content-type", scenario.contentTypeHeader)
+			c.Request().AppendBodyString(scenario.requestBody)
+
+			handler.Extend(c)
+
+			if c.Response().StatusCode() != scenario.expectedStatus {
+				t.Errorf("Expected status %d, got %d", scenario.expectedStatus, c.Response().StatusCode())
+			}
+			if string(c.Response().Body()) != scenario.expectedResponseBody {
+				t.Errorf("Expected body %s, got %s", scenario.expectedResponseBody, string(c.Response().Body()))
+			}
+		})
+	}
+}
+
+func TestPipelineHandler_GetPipelineDetails(t *testing.T) {
+	t.Run("serviceReturnsDetails_respondOkWithDetails", func(t *testing.T) {
+		var requestedPipeline domain.PipelineIdentifier
+		handler := NewPipelineHandlers(&pipelineServiceMock{
+			fakeGetPipelineDetails: func(pipeline domain.PipelineIdentifier) (*domain.PipelineDetails, error) {
+				requestedPipeline = pipeline
+				return &domain.PipelineDetails{PipelineIdentifier: pipeline}, nil
+			},
+		}, &overrideServiceMock{}, &unlockRequestServiceMock{}, &deploymentServiceMock{}, &pipelineDiscoveryServiceMock{}, &blockedAttemptServiceMock{}, domain.IdentifierRules{})
+		app := fiber.New()
+		app.Get("/pipeline/project/:project/environment/:environment", handler.GetPipelineDetails)
+
+		response, err := app.Test(httptest.NewRequest("GET", "/pipeline/project/proj/environment/env", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if response.StatusCode != fiber.StatusOK {
+			t.Errorf("Expected status %d, got %d", fiber.StatusOK, response.StatusCode)
+		}
+		if requestedPipeline.Project != "proj" || requestedPipeline.Environment != "env" {
+			t.Errorf("Expected pipeline proj/env to be requested, got %v", requestedPipeline)
+		}
+	})
+
+	t.Run("scopePath_requestScopedPipeline", func(t *testing.T) {
+		var requestedPipeline domain.PipelineIdentifier
+		handler := NewPipelineHandlers(&pipelineServiceMock{
+			fakeGetPipelineDetails: func(pipeline domain.PipelineIdentifier) (*domain.PipelineDetails, error) {
+				requestedPipeline = pipeline
+				return &domain.PipelineDetails{PipelineIdentifier: pipeline}, nil
+			},
+		}, &overrideServiceMock{}, &unlockRequestServiceMock{}, &deploymentServiceMock{}, &pipelineDiscoveryServiceMock{}, &blockedAttemptServiceMock{}, domain.IdentifierRules{})
+		app := fiber.New()
+		app.Get("/pipeline/project/:project/environment/:environment/region/:region/cluster/:cluster", handler.GetPipelineDetails)
+
+		response, err := app.Test(httptest.NewRequest("GET", "/pipeline/project/proj/environment/env/region/eu-west/cluster/k8s-1", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if response.StatusCode != fiber.StatusOK {
+			t.Errorf("Expected status %d, got %d", fiber.StatusOK, response.StatusCode)
+		}
+		if expected := domain.NewPipelineScope("eu-west", "k8s-1"); requestedPipeline.PipelineScope != expected {
+			t.Errorf("Expected pipeline of scope %v to be requested, got %v", expected, requestedPipeline)
+		}
+	})
+
+	t.Run("escapedPathParts_requestUnescapedPipeline", func(t *testing.T) {
+		var requestedPipeline domain.PipelineIdentifier
+		handler := NewPipelineHandlers(&pipelineServiceMock{
+			fakeGetPipelineDetails: func(pipeline domain.PipelineIdentifier) (*domain.PipelineDetails, error) {
+				requestedPipeline = pipeline
+				return &domain.PipelineDetails{PipelineIdentifier: pipeline}, nil
+			},
+		}, &overrideServiceMock{}, &unlockRequestServiceMock{}, &deploymentServiceMock{}, &pipelineDiscoveryServiceMock{}, &blockedAttemptServiceMock{}, domain.IdentifierRules{})
+		app := fiber.New()
+		app.Get("/pipelines/:project/:environment/:region?/:cluster?/:component?", handler.GetPipelineDetails)
+		expected := domain.PipelineIdentifier{Project: "team/payments", Environment: "prod #1", PipelineScope: domain.NewPipelineScope("eu?west")}
+
+		response, err := app.Test(httptest.NewRequest("GET", "/pipelines/"+expected.URLPath(), nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if response.StatusCode != fiber.StatusOK {
+			t.Errorf("Expected status %d, got %d", fiber.StatusOK, response.StatusCode)
+		}
+		if requestedPipeline != expected {
+			t.Errorf("Expected pipeline %v to be requested, got %v", expected, requestedPipeline)
+		}
+	})
+}
+
+func TestPipelineHandler_WatchPipelines(t *testing.T) {
+	type testCases struct {
+		description          string
+		fakeWatchPipelines   func() (<-chan domain.Pipeline, error)
+		expectedStatus       int
+		expectedResponseBody string
+	}
+	for _, scenario := range []testCases{
+		{
+			description:          "watchNotSupported_respondNotImplemented",
+			expectedStatus:       fiber.StatusNotImplemented,
+			expectedResponseBody: domain.ErrWatchNotSupported.Error(),
+		},
+		{
+			description: "serviceSendsChange_respondEventStreamWithChange",
+			fakeWatchPipelines: func() (<-chan domain.Pipeline, error) {
+				changes := make(chan domain.Pipeline, 1)
+				changes <- domain.Pipeline{
+					PipelineIdentifier: domain.PipelineIdentifier{
+						Project:     "proj",
+						Environment: "env",
+					},
+				}
+				close(changes)
+				return changes, nil
+			},
+			expectedStatus:       fiber.StatusOK,
+			expectedResponseBody: "data: {\"project\":\"proj\",\"environment\":\"env\",\"locked_by\":\"\",\"locked_at\":\"0001-01-01T00:00:00Z\"}\n\n",
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			handler := NewPipelineHandlers(&pipelineServiceMock{
+				fakeWatchPipelines: scenario.fakeWatchPipelines,
+			}, &overrideServiceMock{}, &unlockRequestServiceMock{}, &deploymentServiceMock{}, &pipelineDiscoveryServiceMock{}, &blockedAttemptServiceMock{}, domain.IdentifierRules{})
+			app := fiber.New()
+			app.Get("/pipelines/changes", handler.WatchPipelines)
+
+			response, err := app.Test(httptest.NewRequest("GET", "/pipelines/changes", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if response.StatusCode != scenario.expectedStatus {
+				t.Errorf("Expected status %d, got %d", scenario.expectedStatus, response.StatusCode)
+			}
+			body, _ := io.ReadAll(response.Body)
+			if string(body) != scenario.expectedResponseBody {
+				t.Errorf("Expected response body %s, got %s", scenario.expectedResponseBody, string(body))
+			}
+		})
+	}
+}
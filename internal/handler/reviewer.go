@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// reviewerLocalsKey holds name of authenticated reviewer in request locals
+const reviewerLocalsKey = "reviewer"
+
+// NewReviewerAuthentication returns middleware which lets through only requests carrying token of configured reviewer
+// in Authorization header and stores name of the reviewer for handlers. Reviewer tokens are given by reviewer name.
+// If reviewers are not configured, unlock requests can't be reviewed.
+func NewReviewerAuthentication(reviewerTokens map[string]string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(reviewerTokens) == 0 {
+			return c.Status(fiber.StatusForbidden).SendString("UNLOCK_REVIEW_DISABLED")
+		}
+		authorization := c.Get(fiber.HeaderAuthorization)
+		if !strings.HasPrefix(authorization, bearerPrefix) {
+			return c.Status(fiber.StatusUnauthorized).SendString("REVIEWER_TOKEN_MISSING")
+		}
+		token := []byte(strings.TrimPrefix(authorization, bearerPrefix))
+		reviewer := ""
+		// every token is compared, so that response time doesn't tell which reviewer token matched
+		for name, reviewerToken := range reviewerTokens {
+			if subtle.ConstantTimeCompare(token, []byte(reviewerToken)) == 1 {
+				reviewer = name
+			}
+		}
+		if reviewer == "" {
+			return c.Status(fiber.StatusUnauthorized).SendString("REVIEWER_TOKEN_INVALID")
+		}
+		c.Locals(reviewerLocalsKey, reviewer)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestReviewerAuthentication(t *testing.T) {
+	reviewerTokens := map[string]string{
+		"alice": "alice-token",
+		"bob":   "bob-token",
+	}
+	type testCases struct {
+		description         string
+		configuredTokens    map[string]string
+		authorizationHeader string
+		expectedStatus      int
+		expectedReviewer    string
+	}
+	for _, scenario := range []testCases{
+		{
+			description:         "reviewersNotConfigured_respondForbidden",
+			authorizationHeader: "Bearer ",
+			expectedStatus:      fiber.StatusForbidden,
+		},
+		{
+			description:      "authorizationHeaderMissing_respondUnauthorized",
+			configuredTokens: reviewerTokens,
+			expectedStatus:   fiber.StatusUnauthorized,
+		},
+		{
+			description:         "tokenDoesNotMatch_respondUnauthorized",
+			configuredTokens:    reviewerTokens,
+			authorizationHeader: "Bearer wrong",
+			expectedStatus:      fiber.StatusUnauthorized,
+		},
+		{
+			description:         "tokenMatches_callsNextHandlerWithReviewerOfToken",
+			configuredTokens:    reviewerTokens,
+			authorizationHeader: "Bearer bob-token",
+			expectedStatus:      fiber.StatusOK,
+			expectedReviewer:    "bob",
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", NewReviewerAuthentication(scenario.configuredTokens), func(c *fiber.Ctx) error {
+				return c.SendString(c.Locals(reviewerLocalsKey).(string))
+			})
+			request := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			if scenario.authorizationHeader != "" {
+				request.Header.Set(fiber.HeaderAuthorization, scenario.authorizationHeader)
+			}
+
+			response, err := app.Test(request)
+
+			if err != nil {
+				t.Fatalf("Expected error nil, got %v", err)
+			}
+			if response.StatusCode != scenario.expectedStatus {
+				t.Errorf("Expected status %d, got %d", scenario.expectedStatus, response.StatusCode)
+			}
+			if scenario.expectedReviewer != "" {
+				body, _ := io.ReadAll(response.Body)
+				if string(body) != scenario.expectedReviewer {
+					t.Errorf("Expected reviewer %s, got %s", scenario.expectedReviewer, string(body))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type unlockRequestHandlers struct {
+	service         domain.UnlockRequestService
+	identifierRules domain.IdentifierRules
+}
+
+func NewUnlockRequestHandlers(service domain.UnlockRequestService, identifierRules domain.IdentifierRules) *unlockRequestHandlers {
+	return &unlockRequestHandlers{
+		service:         service,
+		identifierRules: identifierRules,
+	}
+}
+
+// Create files unlock request of reviewer authenticated by reviewer middleware. Requester is taken from the token,
+// so that nobody files request under name of another person and approves it.
+func (h *unlockRequestHandlers) Create(c *fiber.Ctx) error {
+	requester, _ := c.Locals(reviewerLocalsKey).(string)
+	if requester == "" {
+		return c.Status(fiber.StatusUnauthorized).SendString("REVIEWER_TOKEN_MISSING")
+	}
+	r := new(domain.UnlockRequestCreateRequest)
+	if err := c.BodyParser(r); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	identifier := createImmutablePipelineIdentifier(r.PipelineIdentifier)
+	if err := h.identifierRules.Apply(&identifier); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	request, err := h.service.Create(c.Context(), domain.UnlockRequestCreateRequest{
+		PipelineIdentifier: identifier,
+		RequestedBy:        requester,
+	})
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(request)
+}
+
+func (h *unlockRequestHandlers) Approve(c *fiber.Ctx) error {
+	return h.review(c, h.service.Approve)
+}
+
+func (h *unlockRequestHandlers) Reject(c *fiber.Ctx) error {
+	return h.review(c, h.service.Reject)
+}
+
+func (h *unlockRequestHandlers) GetPending(c *fiber.Ctx) error {
+	requests, err := h.service.GetPending(c.Context())
+	if err != nil {
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+
+	return c.JSON(requests)
+}
+
+// review passes reviewer authenticated by reviewer middleware to review func
+func (h *unlockRequestHandlers) review(c *fiber.Ctx, review func(context.Context, string, domain.UnlockRequestReview) (*domain.UnlockRequest, error)) error {
+	reviewer, _ := c.Locals(reviewerLocalsKey).(string)
+	if reviewer == "" {
+		return c.Status(fiber.StatusUnauthorized).SendString("REVIEWER_TOKEN_MISSING")
+	}
+	request, err := review(c.Context(), utils.ImmutableString(c.Params("id")), domain.UnlockRequestReview{
+		ReviewedBy: reviewer,
+	})
+	if err != nil {
+		if err == domain.ErrUnlockRequestNotFound {
+			return c.Status(fiber.StatusNotFound).SendString(err.Error())
+		}
+		return c.Status(serviceErrorStatus(err)).SendString(err.Error())
+	}
+
+	return c.JSON(request)
+}
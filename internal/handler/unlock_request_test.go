@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/valyala/fasthttp"
+)
+
+type unlockRequestServiceMock struct {
+	domain.UnlockRequestService
+	fakeCreate     func(request domain.UnlockRequestCreateRequest) (*domain.UnlockRequest, error)
+	fakeApprove    func(id string, review domain.UnlockRequestReview) (*domain.UnlockRequest, error)
+	fakeReject     func(id string, review domain.UnlockRequestReview) (*domain.UnlockRequest, error)
+	fakeGetPending func() ([]domain.UnlockRequest, error)
+}
+
+func (m *unlockRequestServiceMock) Create(ctx context.Context, request domain.UnlockRequestCreateRequest) (*domain.UnlockRequest, error) {
+	if m.fakeCreate != nil {
+		return m.fakeCreate(request)
+	}
+
+	return &domain.UnlockRequest{}, nil
+}
+
+func (m *unlockRequestServiceMock) Approve(ctx context.Context, id string, review domain.UnlockRequestReview) (*domain.UnlockRequest, error) {
+	if m.fakeApprove != nil {
+		return m.fakeApprove(id, review)
+	}
+
+	return &domain.UnlockRequest{}, nil
+}
+
+func (m *unlockRequestServiceMock) Reject(ctx context.Context, id string, review domain.UnlockRequestReview) (*domain.UnlockRequest, error) {
+	if m.fakeReject != nil {
+		return m.fakeReject(id, review)
+	}
+
+	return &domain.UnlockRequest{}, nil
+}
+
+func (m *unlockRequestServiceMock) GetPending(ctx context.Context) ([]domain.UnlockRequest, error) {
+	if m.fakeGetPending != nil {
+		return m.fakeGetPending()
+	}
+
+	return make([]domain.UnlockRequest, 0), nil
+}
+
+func TestUnlockRequestHandler_Create(t *testing.T) {
+	type testCases struct {
+		description           string
+		requestBody           string
+		expectedStatus        int
+		expectedResponseBody  string
+		fakeCreateReturnError error
+		contentTypeHeader     string
+		requester             string
+	}
+	for _, scenario := range []testCases{
+		{
+			description:          "requesterNotAuthenticated_respondUnauthorized",
+			requestBody:          getPipelineRequestBodyMock(),
+			expectedStatus:       fiber.StatusUnauthorized,
+			expectedResponseBody: "REVIEWER_TOKEN_MISSING",
+			contentTypeHeader:    "application/json",
+		},
+		{
+			description:          "brokenRequestBody_respondBadRequest",
+			requestBody:          "{123",
+			requester:            "user",
+			expectedStatus:       fiber.StatusBadRequest,
+			expectedResponseBody: "Unprocessable Entity",
+		},
+		{
+			description:           "serviceReturnsError_respondConflict",
+			requestBody:           getPipelineRequestBodyMock(),
+			expectedStatus:        fiber.StatusConflict,
+			expectedResponseBody:  domain.ErrPipelineNotLocked.Error(),
+			fakeCreateReturnError: domain.ErrPipelineNotLocked,
+			contentTypeHeader:     "application/json",
+			requester:             "user",
+		},
+		{
+			description:          "serviceReturnsRequest_respondCreated",
+			requestBody:          "{\"project\":\"proj\",\"environment\":\"env\",\"requested_by\":\"user\"}",
+			expectedStatus:       fiber.StatusCreated,
+			expectedResponseBody: "{\"id\":\"id\",\"project\":\"proj\",\"environment\":\"env\",\"locked_by\":\"\",\"locked_at\":\"0001-01-01T00:00:00Z\",\"requested_by\":\"user\",\"requested_at\":\"0001-01-01T00:00:00Z\",\"expires_at\":\"0001-01-01T00:00:00Z\",\"status\":\"pending\",\"reviewed_by\":\"\",\"reviewed_at\":\"0001-01-01T00:00:00Z\"}",
+			contentTypeHeader:    "application/json",
+			requester:            "user",
+		},
+		{
+			description:          "requesterInBody_requesterOfTokenIsUsed",
+			requestBody:          "{\"project\":\"proj\",\"environment\":\"env\",\"requested_by\":\"someone else\"}",
+			expectedStatus:       fiber.StatusCreated,
+			expectedResponseBody: "{\"id\":\"id\",\"project\":\"proj\",\"environment\":\"env\",\"locked_by\":\"\",\"locked_at\":\"0001-01-01T00:00:00Z\",\"requested_by\":\"user\",\"requested_at\":\"0001-01-01T00:00:00Z\",\"expires_at\":\"0001-01-01T00:00:00Z\",\"status\":\"pending\",\"reviewed_by\":\"\",\"reviewed_at\":\"0001-01-01T00:00:00Z\"}",
+			contentTypeHeader:    "application/json",
+			requester:            "user",
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			handler := NewUnlockRequestHandlers(&unlockRequestServiceMock{
+				fakeCreate: func(request domain.UnlockRequestCreateRequest) (*domain.UnlockRequest, error) {
+					if scenario.fakeCreateReturnError != nil {
+						return nil, scenario.fakeCreateReturnError
+					}
+					return &domain.UnlockRequest{
+						ID:                 "id",
+						PipelineIdentifier: request.PipelineIdentifier,
+						RequestedBy:        request.RequestedBy,
+						Status:             domain.UnlockRequestPending,
+					}, nil
+				},
+			}, domain.IdentifierRules{})
+			app := fiber.New()
+			c := app.AcquireCtx(&fasthttp.RequestCtx{})
+			defer app.ReleaseCtx(c)
+			c.Request().Header.Add("content-type", scenario.contentTypeHeader)
+			c.Request().AppendBodyString(scenario.requestBody)
+			if scenario.requester != "" {
+				c.Locals(reviewerLocalsKey, scenario.requester)
+			}
+
+			handler.Create(c)
+
+			if c.Response().StatusCode() != scenario.expectedStatus {
+				t.Errorf("Expected status %d, got %d", scenario.expectedStatus, c.Response().StatusCode())
+			}
+			if string(c.Response().Body()) != scenario.expectedResponseBody {
+				t.Errorf("Expected body %s, got %s", scenario.expectedResponseBody, string(c.Response().Body()))
+			}
+		})
+	}
+}
+
+func TestUnlockRequestHandler_Review(t *testing.T) {
+	const reviewerToken = "reviewer-token"
+	type testCases struct {
+		description          string
+		path                 string
+		authorizationHeader  string
+		requestBody          string
+		fakeReturnError      error
+		expectedStatus       int
+		expectedResponseBody string
+		expectedID           string
+		expectedReviewer     string
+	}
+	for _, scenario := range []testCases{
+		{
+			description:          "reviewerNotAuthenticated_respondUnauthorized",
+			path:                 "/id/approve",
+			requestBody:          "{\"reviewed_by\":\"reviewer\"}",
+			expectedStatus:       fiber.StatusUnauthorized,
+			expectedResponseBody: "REVIEWER_TOKEN_MISSING",
+		},
+		{
+			description:          "requestNotFound_respondNotFound",
+			path:                 "/id/approve",
+			authorizationHeader:  "Bearer " + reviewerToken,
+			fakeReturnError:      domain.ErrUnlockRequestNotFound,
+			expectedStatus:       fiber.StatusNotFound,
+			expectedResponseBody: domain.ErrUnlockRequestNotFound.Error(),
+			expectedID:           "id",
+			expectedReviewer:     "reviewer",
+		},
+		{
+			description:          "selfApproval_respondConflict",
+			path:                 "/id/approve",
+			authorizationHeader:  "Bearer " + reviewerToken,
+			fakeReturnError:      domain.ErrUnlockSelfApprovalNotAllowed,
+			expectedStatus:       fiber.StatusConflict,
+			expectedResponseBody: domain.ErrUnlockSelfApprovalNotAllowed.Error(),
+			expectedID:           "id",
+			expectedReviewer:     "reviewer",
+		},
+		{
+			description:          "reviewerInBody_reviewerOfTokenIsUsed",
+			path:                 "/id/approve",
+			authorizationHeader:  "Bearer " + reviewerToken,
+			requestBody:          "{\"reviewed_by\":\"someone else\"}",
+			expectedStatus:       fiber.StatusOK,
+			expectedResponseBody: "approved",
+			expectedID:           "id",
+			expectedReviewer:     "reviewer",
+		},
+		{
+			description:          "approved_respondOk",
+			path:                 "/id/approve",
+			authorizationHeader:  "Bearer " + reviewerToken,
+			expectedStatus:       fiber.StatusOK,
+			expectedResponseBody: "approved",
+			expectedID:           "id",
+			expectedReviewer:     "reviewer",
+		},
+		{
+			description:          "rejected_respondOk",
+			path:                 "/id/reject",
+			authorizationHeader:  "Bearer " + reviewerToken,
+			expectedStatus:       fiber.StatusOK,
+			expectedResponseBody: "rejected",
+			expectedID:           "id",
+			expectedReviewer:     "reviewer",
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			var receivedID, receivedReviewer string
+			review := func(status domain.UnlockRequestStatus) func(string, domain.UnlockRequestReview) (*domain.UnlockRequest, error) {
+				return func(id string, review domain.UnlockRequestReview) (*domain.UnlockRequest, error) {
+					receivedID = id
+					receivedReviewer = review.ReviewedBy
+					if scenario.fakeReturnError != nil {
+						return nil, scenario.fakeReturnError
+					}
+					return &domain.UnlockRequest{Status: status}, nil
+				}
+			}
+			handler := NewUnlockRequestHandlers(&unlockRequestServiceMock{
+				fakeApprove: review(domain.UnlockRequestApproved),
+				fakeReject:  review(domain.UnlockRequestRejected),
+			}, domain.IdentifierRules{})
+			app := fiber.New()
+			reviewers := app.Group("/", NewReviewerAuthentication(map[string]string{"reviewer": reviewerToken}))
+			reviewers.Put("/:id/approve", handler.Approve)
+			reviewers.Put("/:id/reject", handler.Reject)
+			request := httptest.NewRequest(fiber.MethodPut, scenario.path, strings.NewReader(scenario.requestBody))
+			request.Header.Set("content-type", "application/json")
+			if scenario.authorizationHeader != "" {
+				request.Header.Set(fiber.HeaderAuthorization, scenario.authorizationHeader)
+			}
+
+			response, err := app.Test(request)
+			if err != nil {
+				t.Fatalf("Expected error nil, got %v", err)
+			}
+			body, _ := io.ReadAll(response.Body)
+
+			if response.StatusCode != scenario.expectedStatus {
+				t.Errorf("Expected status %d, got %d", scenario.expectedStatus, response.StatusCode)
+			}
+			if !strings.Contains(string(body), scenario.expectedResponseBody) {
+				t.Errorf("Expected body to contain %s, got %s", scenario.expectedResponseBody, string(body))
+			}
+			if receivedID != scenario.expectedID || receivedReviewer != scenario.expectedReviewer {
+				t.Errorf("Expected review of %s by %s, got %s by %s", scenario.expectedID, scenario.expectedReviewer, receivedID, receivedReviewer)
+			}
+		})
+	}
+}
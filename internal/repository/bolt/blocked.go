@@ -0,0 +1,54 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"go.etcd.io/bbolt"
+)
+
+// errAttemptBeforeSince stops iterating attempts older than requested
+var errAttemptBeforeSince = errors.New("ATTEMPT_BEFORE_SINCE")
+
+type blockedAttemptRepository struct {
+	db               *bbolt.DB
+	caseSensitiveKey bool
+}
+
+func NewBlockedAttemptRepository(db *bbolt.DB, caseSensitiveKey bool) *blockedAttemptRepository {
+	return &blockedAttemptRepository{
+		db:               db,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *blockedAttemptRepository) Add(ctx context.Context, attempt domain.BlockedAttempt) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		return appendToHistory(tx.Bucket(blockedAttemptsBucket), attempt.PipelineIdentifier.GetKey(r.caseSensitiveKey), attempt, domain.BlockedAttemptHistorySize)
+	})
+}
+
+func (r *blockedAttemptRepository) FindSince(ctx context.Context, pipeline domain.PipelineIdentifier, since time.Time) ([]domain.BlockedAttempt, error) {
+	attempts := make([]domain.BlockedAttempt, 0)
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return forEachNewestFirst(tx.Bucket(blockedAttemptsBucket), pipeline.GetKey(r.caseSensitiveKey), func(value []byte) error {
+			var attempt domain.BlockedAttempt
+			if err := json.Unmarshal(value, &attempt); err != nil {
+				return err
+			}
+			if attempt.AttemptedAt.Before(since) {
+				return errAttemptBeforeSince
+			}
+			attempts = append(attempts, attempt)
+			return nil
+		})
+	})
+	if err != nil && !errors.Is(err, errAttemptBeforeSince) {
+		return nil, err
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,150 @@
+package bolt
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"go.etcd.io/bbolt"
+)
+
+const (
+	// openTimeout limits waiting for file lock held by another process
+	openTimeout = time.Second
+)
+
+var (
+	pipelinesBucket      = []byte("pipelines")
+	overrideTokensBucket = []byte("override-tokens")
+	unlockRequestsBucket = []byte("unlock-requests")
+	// deploymentHistoryBucket holds bucket of latest deployments per pipeline key
+	deploymentHistoryBucket = []byte("deployment-history")
+	// deploymentVersionsBucket holds bucket of latest successful deployment of each version per pipeline key
+	deploymentVersionsBucket = []byte("deployment-versions")
+	currentDeploymentsBucket = []byte("deployments-current")
+	// eventsBucket holds bucket of latest events per pipeline key
+	eventsBucket = []byte("events")
+	// knownPipelinesBucket holds pipelines discovered from deploy status checks by pipeline key
+	knownPipelinesBucket = []byte("known-pipelines")
+	// blockedAttemptsBucket holds bucket of latest blocked deploy attempts per pipeline key
+	blockedAttemptsBucket = []byte("blocked-attempts")
+	buckets               = [][]byte{pipelinesBucket, overrideTokensBucket, unlockRequestsBucket, deploymentHistoryBucket, deploymentVersionsBucket, currentDeploymentsBucket, eventsBucket, knownPipelinesBucket, blockedAttemptsBucket}
+)
+
+// Open opens database file at given path, creating it and its buckets if needed
+func Open(path string) (*bbolt.DB, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, bucket := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// OpenMigrated opens existing database file at given path read-only, returns domain.ErrStorageNotInitialized
+// when the file or any of its buckets is missing
+func OpenMigrated(path string) (*bbolt.DB, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w, file %s does not exist", domain.ErrStorageNotInitialized, path)
+	}
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: openTimeout, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	err = db.View(func(tx *bbolt.Tx) error {
+		for _, bucket := range buckets {
+			if tx.Bucket(bucket) == nil {
+				return fmt.Errorf("%w, bucket %s does not exist", domain.ErrStorageNotInitialized, bucket)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+type backupRepository struct {
+	db *bbolt.DB
+}
+
+func NewBackupRepository(db *bbolt.DB) *backupRepository {
+	return &backupRepository{
+		db: db,
+	}
+}
+
+// Backup writes consistent snapshot of whole database file, writes are not blocked meanwhile
+func (r *backupRepository) Backup(w io.Writer) error {
+	return r.db.View(func(tx *bbolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
+}
+
+// appendToHistory adds value to the end of pipeline bucket in given root bucket, keeping only size latest values
+func appendToHistory(root *bbolt.Bucket, pipelineKey string, value interface{}, size int) error {
+	bucket, err := root.CreateBucketIfNotExists([]byte(pipelineKey))
+	if err != nil {
+		return err
+	}
+	marshaledValue, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	sequence, err := bucket.NextSequence()
+	if err != nil {
+		return err
+	}
+	if err = bucket.Put(sequenceKey(sequence), marshaledValue); err != nil {
+		return err
+	}
+	if sequence > uint64(size) {
+		return bucket.Delete(sequenceKey(sequence - uint64(size)))
+	}
+
+	return nil
+}
+
+// forEachNewestFirst calls fn with values of pipeline bucket in given root bucket, newest first
+func forEachNewestFirst(root *bbolt.Bucket, pipelineKey string, fn func(value []byte) error) error {
+	bucket := root.Bucket([]byte(pipelineKey))
+	if bucket == nil {
+		return nil
+	}
+	cursor := bucket.Cursor()
+	for key, value := cursor.Last(); key != nil; key, value = cursor.Prev() {
+		if err := fn(value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sequenceKey(sequence uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, sequence)
+
+	return key
+}
@@ -0,0 +1,98 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"go.etcd.io/bbolt"
+)
+
+func openTestDatabase(t *testing.T) *bbolt.DB {
+	db, err := Open(filepath.Join(t.TempDir(), "pipeline-locker.bolt"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return db
+}
+
+func TestBackupRepository(t *testing.T) {
+	db := openTestDatabase(t)
+	pipeline := domain.Pipeline{
+		PipelineIdentifier: domain.PipelineIdentifier{
+			Project:     "Project",
+			Environment: "Environment",
+		},
+		PipelineLockedBy: domain.PipelineLockedBy{
+			LockedBy: "user",
+		},
+	}
+	NewPipelineRepository(db, true).Add(context.Background(), pipeline)
+
+	t.Run("Backup_storeHasPipeline_snapshotContainsPipeline", func(t *testing.T) {
+		var backup bytes.Buffer
+		if err := NewBackupRepository(db).Backup(&backup); err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		path := filepath.Join(t.TempDir(), "backup.bolt")
+		os.WriteFile(path, backup.Bytes(), 0600)
+		restored, err := Open(path)
+		if err != nil {
+			t.Fatalf("Expected backup to be valid database, got %v", err)
+		}
+		defer restored.Close()
+
+		restoredPipeline, _ := NewPipelineRepository(restored, true).Find(context.Background(), pipeline.PipelineIdentifier)
+		if restoredPipeline == nil || restoredPipeline.LockedBy != pipeline.LockedBy {
+			t.Errorf("Expected backup to contain pipeline locked by %s, got %v", pipeline.LockedBy, restoredPipeline)
+		}
+	})
+}
+
+func TestOpenMigrated(t *testing.T) {
+	t.Run("OpenMigrated_openedDatabase_opensReadOnly", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pipeline-locker.bolt")
+		opened, _ := Open(path)
+		opened.Close()
+
+		db, err := OpenMigrated(path)
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		defer db.Close()
+		if err = NewPipelineRepository(db, true).Add(context.Background(), domain.Pipeline{}); err == nil {
+			t.Errorf("Expected write to fail")
+		}
+	})
+
+	t.Run("OpenMigrated_missingFile_returnsErrStorageNotInitializedWithoutCreatingFile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pipeline-locker.bolt")
+
+		db, err := OpenMigrated(path)
+		if !errors.Is(err, domain.ErrStorageNotInitialized) || db != nil {
+			t.Errorf("Expected %v, got %v and %v", domain.ErrStorageNotInitialized, db, err)
+		}
+		if _, err = os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected file not to be created, got %v", err)
+		}
+	})
+
+	t.Run("OpenMigrated_missingBucket_returnsErrStorageNotInitialized", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pipeline-locker.bolt")
+		opened, _ := bbolt.Open(path, 0600, nil)
+		opened.Close()
+
+		db, err := OpenMigrated(path)
+		if !errors.Is(err, domain.ErrStorageNotInitialized) || db != nil {
+			t.Errorf("Expected %v, got %v and %v", domain.ErrStorageNotInitialized, db, err)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"go.etcd.io/bbolt"
+)
+
+type deploymentRepository struct {
+	db               *bbolt.DB
+	caseSensitiveKey bool
+}
+
+func NewDeploymentRepository(db *bbolt.DB, caseSensitiveKey bool) *deploymentRepository {
+	return &deploymentRepository{
+		db:               db,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *deploymentRepository) Add(ctx context.Context, deployment domain.Deployment) error {
+	marshaledDeployment, err := json.Marshal(deployment)
+	if err != nil {
+		return err
+	}
+	pipelineKey := deployment.PipelineIdentifier.GetKey(r.caseSensitiveKey)
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		if err := appendToHistory(tx.Bucket(deploymentHistoryBucket), pipelineKey, deployment, domain.DeploymentHistorySize); err != nil {
+			return err
+		}
+		if deployment.Status != domain.DeploymentSucceeded {
+			return nil
+		}
+		versions, err := tx.Bucket(deploymentVersionsBucket).CreateBucketIfNotExists([]byte(pipelineKey))
+		if err != nil {
+			return err
+		}
+		if err = versions.Put([]byte(deployment.Version), marshaledDeployment); err != nil {
+			return err
+		}
+		return tx.Bucket(currentDeploymentsBucket).Put([]byte(pipelineKey), marshaledDeployment)
+	})
+}
+
+func (r *deploymentRepository) FindByVersion(ctx context.Context, pipeline domain.PipelineIdentifier, version string) (*domain.Deployment, error) {
+	var deployment *domain.Deployment
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		versions := tx.Bucket(deploymentVersionsBucket).Bucket([]byte(pipeline.GetKey(r.caseSensitiveKey)))
+		if versions == nil {
+			return nil
+		}
+		value := versions.Get([]byte(version))
+		if value == nil {
+			return nil
+		}
+		deployment = new(domain.Deployment)
+		return json.Unmarshal(value, deployment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+func (r *deploymentRepository) FindHistory(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.Deployment, error) {
+	deployments := make([]domain.Deployment, 0)
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return forEachNewestFirst(tx.Bucket(deploymentHistoryBucket), pipeline.GetKey(r.caseSensitiveKey), func(value []byte) error {
+			var deployment domain.Deployment
+			if err := json.Unmarshal(value, &deployment); err != nil {
+				return err
+			}
+			deployments = append(deployments, deployment)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deployments, nil
+}
+
+func (r *deploymentRepository) FindCurrent(ctx context.Context) ([]domain.Deployment, error) {
+	deployments := make([]domain.Deployment, 0)
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(currentDeploymentsBucket).ForEach(func(key, value []byte) error {
+			var deployment domain.Deployment
+			if err := json.Unmarshal(value, &deployment); err != nil {
+				return err
+			}
+			deployments = append(deployments, deployment)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deployments, nil
+}
@@ -0,0 +1,60 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"go.etcd.io/bbolt"
+)
+
+type pipelineDiscoveryRepository struct {
+	db               *bbolt.DB
+	caseSensitiveKey bool
+}
+
+func NewPipelineDiscoveryRepository(db *bbolt.DB, caseSensitiveKey bool) *pipelineDiscoveryRepository {
+	return &pipelineDiscoveryRepository{
+		db:               db,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *pipelineDiscoveryRepository) Record(ctx context.Context, check domain.PipelineCheck) error {
+	key := []byte(check.PipelineIdentifier.GetKey(r.caseSensitiveKey))
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(knownPipelinesBucket)
+		var pipeline domain.KnownPipeline
+		if value := bucket.Get(key); value != nil {
+			if err := json.Unmarshal(value, &pipeline); err != nil {
+				return err
+			}
+		}
+		pipeline.Record(check)
+		marshaledPipeline, err := json.Marshal(pipeline)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, marshaledPipeline)
+	})
+}
+
+func (r *pipelineDiscoveryRepository) FindAll(ctx context.Context) ([]domain.KnownPipeline, error) {
+	pipelines := make([]domain.KnownPipeline, 0)
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(knownPipelinesBucket).ForEach(func(key, value []byte) error {
+			var pipeline domain.KnownPipeline
+			if err := json.Unmarshal(value, &pipeline); err != nil {
+				return err
+			}
+			pipelines = append(pipelines, pipeline)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pipelines, nil
+}
@@ -0,0 +1,46 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"go.etcd.io/bbolt"
+)
+
+type pipelineEventRepository struct {
+	db               *bbolt.DB
+	caseSensitiveKey bool
+}
+
+func NewPipelineEventRepository(db *bbolt.DB, caseSensitiveKey bool) *pipelineEventRepository {
+	return &pipelineEventRepository{
+		db:               db,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *pipelineEventRepository) Add(ctx context.Context, event domain.PipelineEvent) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		return appendToHistory(tx.Bucket(eventsBucket), event.PipelineIdentifier.GetKey(r.caseSensitiveKey), event, domain.PipelineHistorySize)
+	})
+}
+
+func (r *pipelineEventRepository) FindByPipeline(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.PipelineEvent, error) {
+	events := make([]domain.PipelineEvent, 0)
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return forEachNewestFirst(tx.Bucket(eventsBucket), pipeline.GetKey(r.caseSensitiveKey), func(value []byte) error {
+			var event domain.PipelineEvent
+			if err := json.Unmarshal(value, &event); err != nil {
+				return err
+			}
+			events = append(events, event)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
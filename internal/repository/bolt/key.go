@@ -0,0 +1,164 @@
+package bolt
+
+import (
+	"encoding/json"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"go.etcd.io/bbolt"
+)
+
+// keyedBuckets hold value of pipeline under pipeline key
+var keyedBuckets = [][]byte{pipelinesBucket, currentDeploymentsBucket}
+
+// keyedHistoryBuckets hold bucket of pipeline values under pipeline key, values are limited to size latest
+// ones or keyed by version when size is 0
+var keyedHistoryBuckets = []struct {
+	name []byte
+	size int
+}{
+	{deploymentHistoryBucket, domain.DeploymentHistorySize},
+	{deploymentVersionsBucket, 0},
+	{eventsBucket, domain.PipelineHistorySize},
+}
+
+type keyedValue struct {
+	pipelineKey string
+	key         []byte
+	value       []byte
+}
+
+// MigrateKeys moves values stored under keys of other encoding or case sensitivity to current pipeline keys,
+// values of pipelines which shared a key are split and values of pipelines in keep are left under stored key.
+// When current key already holds value or history, it is kept and moved value is dropped. Returns number of
+// moved keys.
+func (r *pipelineRepository) MigrateKeys(keep map[domain.PipelineIdentifier]bool) (int, error) {
+	migrated := 0
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range keyedBuckets {
+			moved, err := migrateValueKeys(tx.Bucket(name), keep, r.caseSensitiveKey)
+			if err != nil {
+				return err
+			}
+			migrated += moved
+		}
+		for _, history := range keyedHistoryBuckets {
+			moved, err := migrateBucketKeys(tx.Bucket(history.name), history.size, keep, r.caseSensitiveKey)
+			if err != nil {
+				return err
+			}
+			migrated += moved
+		}
+		return nil
+	})
+
+	return migrated, err
+}
+
+// migrateValueKeys removes all stale keys before storing their values, so stale key may be current key of other value
+func migrateValueKeys(root *bbolt.Bucket, keep map[domain.PipelineIdentifier]bool, caseSensitiveKey bool) (int, error) {
+	stale := make([]keyedValue, 0)
+	cursor := root.Cursor()
+	for key, value := cursor.First(); key != nil; key, value = cursor.Next() {
+		pipelineKey, err := decodePipelineKey(key, value, keep, caseSensitiveKey)
+		if err != nil {
+			return 0, err
+		}
+		if pipelineKey != string(key) {
+			stale = append(stale, keyedValue{pipelineKey: pipelineKey, key: copyBytes(key), value: copyBytes(value)})
+		}
+	}
+	for _, value := range stale {
+		if err := root.Delete(value.key); err != nil {
+			return 0, err
+		}
+	}
+	for _, value := range stale {
+		if root.Get([]byte(value.pipelineKey)) != nil {
+			continue
+		}
+		if err := root.Put([]byte(value.pipelineKey), value.value); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(stale), nil
+}
+
+// migrateBucketKeys moves values of pipeline buckets holding value of other pipeline key, oldest value first
+func migrateBucketKeys(root *bbolt.Bucket, size int, keep map[domain.PipelineIdentifier]bool, caseSensitiveKey bool) (int, error) {
+	staleBuckets := make([][]byte, 0)
+	values := make([]keyedValue, 0)
+	err := root.ForEach(func(bucketKey, _ []byte) error {
+		bucketValues := make([]keyedValue, 0)
+		stale := false
+		cursor := root.Bucket(bucketKey).Cursor()
+		for key, value := cursor.First(); key != nil; key, value = cursor.Next() {
+			pipelineKey, err := decodePipelineKey(bucketKey, value, keep, caseSensitiveKey)
+			if err != nil {
+				return err
+			}
+			stale = stale || pipelineKey != string(bucketKey)
+			bucketValues = append(bucketValues, keyedValue{pipelineKey: pipelineKey, key: copyBytes(key), value: copyBytes(value)})
+		}
+		if stale {
+			staleBuckets = append(staleBuckets, copyBytes(bucketKey))
+			values = append(values, bucketValues...)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, bucketKey := range staleBuckets {
+		if err = root.DeleteBucket(bucketKey); err != nil {
+			return 0, err
+		}
+	}
+	// history stored under current key before migration is kept as it is
+	kept := make(map[string]bool)
+	for _, value := range values {
+		if _, checked := kept[value.pipelineKey]; !checked {
+			kept[value.pipelineKey] = root.Bucket([]byte(value.pipelineKey)) != nil
+		}
+		if kept[value.pipelineKey] {
+			continue
+		}
+		if size > 0 {
+			err = appendToHistory(root, value.pipelineKey, json.RawMessage(value.value), size)
+		} else {
+			err = putToBucket(root, value.pipelineKey, value.key, value.value)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(staleBuckets), nil
+}
+
+func putToBucket(root *bbolt.Bucket, bucketKey string, key, value []byte) error {
+	bucket, err := root.CreateBucketIfNotExists([]byte(bucketKey))
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put(key, value)
+}
+
+// decodePipelineKey returns current key of pipeline of value, stored key is returned for pipeline in keep
+func decodePipelineKey(storedKey, value []byte, keep map[domain.PipelineIdentifier]bool, caseSensitiveKey bool) (string, error) {
+	var identifier domain.PipelineIdentifier
+	if err := json.Unmarshal(value, &identifier); err != nil {
+		return "", err
+	}
+	if keep[identifier] {
+		return string(storedKey), nil
+	}
+
+	return identifier.GetKey(caseSensitiveKey), nil
+}
+
+// copyBytes copies value of bucket, which is valid only until it is modified
+func copyBytes(value []byte) []byte {
+	return append([]byte(nil), value...)
+}
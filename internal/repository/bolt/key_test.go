@@ -0,0 +1,46 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"go.etcd.io/bbolt"
+)
+
+func TestPipelineRepository_MigrateKeys(t *testing.T) {
+	db := openTestDatabase(t)
+	repository := NewPipelineRepository(db, false)
+	colliding := []domain.PipelineIdentifier{{Project: "a:b", Environment: "c"}, {Project: "a", Environment: "b:c"}}
+	folded := domain.PipelineIdentifier{Project: "Straße", Environment: "prod"}
+	db.Update(func(tx *bbolt.Tx) error {
+		for _, pipeline := range []domain.PipelineIdentifier{colliding[0], folded} {
+			value, _ := json.Marshal(domain.Pipeline{PipelineIdentifier: pipeline, PipelineLockedBy: domain.PipelineLockedBy{LockedBy: "user"}})
+			tx.Bucket(pipelinesBucket).Put([]byte(pipeline.LegacyKey(false)), value)
+		}
+		// colliding pipelines shared one history bucket
+		for _, pipeline := range colliding {
+			appendToHistory(tx.Bucket(eventsBucket), pipeline.LegacyKey(false), domain.PipelineEvent{PipelineIdentifier: pipeline, Type: domain.PipelineLockedEvent}, domain.PipelineHistorySize)
+		}
+		return nil
+	})
+
+	migrated, err := repository.MigrateKeys(nil)
+
+	if err != nil || migrated != 3 {
+		t.Fatalf("Expected 3 migrated keys and nil error, got %d and %v", migrated, err)
+	}
+	if pipeline, _ := repository.Find(context.Background(), domain.PipelineIdentifier{Project: "STRASSE", Environment: "PROD"}); pipeline == nil || pipeline.Project != folded.Project {
+		t.Errorf("Expected pipeline %v to be found by case folded key, got %v", folded, pipeline)
+	}
+	events := NewPipelineEventRepository(db, false)
+	for _, pipeline := range colliding {
+		if history, _ := events.FindByPipeline(context.Background(), pipeline); len(history) != 1 || history[0].PipelineIdentifier != pipeline {
+			t.Errorf("Expected only own event of pipeline %v, got %v", pipeline, history)
+		}
+	}
+	if migrated, _ = repository.MigrateKeys(nil); migrated != 0 {
+		t.Errorf("Expected nothing to migrate again, got %d", migrated)
+	}
+}
@@ -0,0 +1,85 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"go.etcd.io/bbolt"
+)
+
+type overrideTokenRepository struct {
+	db               *bbolt.DB
+	caseSensitiveKey bool
+}
+
+func NewOverrideTokenRepository(db *bbolt.DB, caseSensitiveKey bool) *overrideTokenRepository {
+	return &overrideTokenRepository{
+		db:               db,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *overrideTokenRepository) Add(ctx context.Context, token domain.OverrideToken) error {
+	marshaledToken, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(overrideTokensBucket)
+		now := time.Now()
+		expiredKeys := make([][]byte, 0)
+		err := bucket.ForEach(func(key, value []byte) error {
+			var storedToken domain.OverrideToken
+			if err := json.Unmarshal(value, &storedToken); err != nil {
+				return err
+			}
+			if storedToken.IsExpired(now) {
+				expiredKeys = append(expiredKeys, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range expiredKeys {
+			if err = bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		return bucket.Put([]byte(token.Token), marshaledToken)
+	})
+}
+
+// Consume reads and deletes token bound to given pipeline in single read-write transaction
+func (r *overrideTokenRepository) Consume(ctx context.Context, pipeline domain.PipelineIdentifier, token string) (*domain.OverrideToken, error) {
+	var consumed *domain.OverrideToken
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(overrideTokensBucket)
+		value := bucket.Get([]byte(token))
+		if value == nil {
+			return nil
+		}
+		var storedToken domain.OverrideToken
+		if err := json.Unmarshal(value, &storedToken); err != nil {
+			return err
+		}
+		if storedToken.GetKey(r.caseSensitiveKey) != pipeline.GetKey(r.caseSensitiveKey) {
+			return nil
+		}
+		if err := bucket.Delete([]byte(token)); err != nil {
+			return err
+		}
+		if !storedToken.IsExpired(time.Now()) {
+			consumed = &storedToken
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return consumed, nil
+}
@@ -0,0 +1,132 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"go.etcd.io/bbolt"
+)
+
+type pipelineRepository struct {
+	db               *bbolt.DB
+	caseSensitiveKey bool
+}
+
+func NewPipelineRepository(db *bbolt.DB, caseSensitiveKey bool) *pipelineRepository {
+	return &pipelineRepository{
+		db:               db,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *pipelineRepository) Find(ctx context.Context, identifier domain.PipelineIdentifier) (*domain.Pipeline, error) {
+	var pipeline *domain.Pipeline
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		pipeline, err = r.find(tx, identifier)
+		return err
+	})
+
+	return pipeline, err
+}
+
+func (r *pipelineRepository) find(tx *bbolt.Tx, identifier domain.PipelineIdentifier) (*domain.Pipeline, error) {
+	value := tx.Bucket(pipelinesBucket).Get([]byte(identifier.GetKey(r.caseSensitiveKey)))
+	if value == nil {
+		return nil, nil
+	}
+	var pipeline domain.Pipeline
+	if err := json.Unmarshal(value, &pipeline); err != nil {
+		return nil, err
+	}
+
+	return &pipeline, nil
+}
+
+func (r *pipelineRepository) Add(ctx context.Context, pipeline domain.Pipeline) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		return r.put(tx, pipeline)
+	})
+}
+
+func (r *pipelineRepository) put(tx *bbolt.Tx, pipeline domain.Pipeline) error {
+	marshaledPipeline, err := json.Marshal(pipeline)
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(pipelinesBucket).Put([]byte(pipeline.PipelineIdentifier.GetKey(r.caseSensitiveKey)), marshaledPipeline)
+}
+
+// LockIfUnlocked checks and stores pipeline in single read-write transaction
+func (r *pipelineRepository) LockIfUnlocked(ctx context.Context, pipeline domain.Pipeline) (bool, error) {
+	locked := false
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		existingPipeline, err := r.find(tx, pipeline.PipelineIdentifier)
+		if err != nil {
+			return err
+		}
+		if existingPipeline != nil && existingPipeline.IsLocked(pipeline.LockedAt) {
+			return nil
+		}
+		locked = true
+		return r.put(tx, pipeline)
+	})
+
+	return locked, err
+}
+
+// UnlockIfLocked checks and unlocks pipeline in single read-write transaction
+func (r *pipelineRepository) UnlockIfLocked(ctx context.Context, lock domain.Pipeline) (bool, error) {
+	unlocked := false
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		existingPipeline, err := r.find(tx, lock.PipelineIdentifier)
+		if err != nil {
+			return err
+		}
+		if existingPipeline == nil || !existingPipeline.HasLock(lock) {
+			return nil
+		}
+		unlocked = true
+		return r.put(tx, domain.Pipeline{PipelineIdentifier: lock.PipelineIdentifier})
+	})
+
+	return unlocked, err
+}
+
+func (r *pipelineRepository) FindLockedPipelines(ctx context.Context) ([]domain.Pipeline, error) {
+	pipelines, err := r.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lockedPipelines := make([]domain.Pipeline, 0)
+	now := time.Now()
+	for _, p := range pipelines {
+		if p.IsLocked(now) {
+			lockedPipelines = append(lockedPipelines, p)
+		}
+	}
+
+	return lockedPipelines, nil
+}
+
+func (r *pipelineRepository) FindAll(ctx context.Context) ([]domain.Pipeline, error) {
+	pipelines := make([]domain.Pipeline, 0)
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(pipelinesBucket).ForEach(func(key, value []byte) error {
+			var pipeline domain.Pipeline
+			if err := json.Unmarshal(value, &pipeline); err != nil {
+				return err
+			}
+			pipelines = append(pipelines, pipeline)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pipelines, nil
+}
@@ -0,0 +1,253 @@
+package bolt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/repository/repositorytest"
+)
+
+func TestPipelineRepository(t *testing.T) {
+	const projectOne string = "Project"
+	const projectTwo string = "project"
+	const environmentOne string = "Environment"
+	const environmentTwo string = "environment"
+	const userOne = "User"
+	const userTwo = "user"
+	const pipelineKeyCaseSensitive = true
+	repository := NewPipelineRepository(openTestDatabase(t), pipelineKeyCaseSensitive)
+
+	type addTestCases struct {
+		description       string
+		pipeline          domain.Pipeline
+		expectedStoreSize int
+	}
+
+	for _, scenario := range []addTestCases{
+		{
+			description: "Add_projectOneAdded_savesToStore",
+			pipeline: domain.Pipeline{
+				PipelineIdentifier: domain.PipelineIdentifier{
+					Project:     projectOne,
+					Environment: environmentOne,
+				},
+				PipelineLockedBy: domain.PipelineLockedBy{
+					LockedBy: userOne,
+				},
+			},
+			expectedStoreSize: 1,
+		},
+		{
+			description: "Add_projectOneAddedAnotherTime_overwritesStoreKey",
+			pipeline: domain.Pipeline{
+				PipelineIdentifier: domain.PipelineIdentifier{
+					Project:     projectOne,
+					Environment: environmentOne,
+				},
+				PipelineLockedBy: domain.PipelineLockedBy{
+					LockedBy: userTwo,
+				},
+			},
+			expectedStoreSize: 1,
+		},
+		{
+			description: "Add_projectTwoAdded_savesToStore",
+			pipeline: domain.Pipeline{
+				PipelineIdentifier: domain.PipelineIdentifier{
+					Project:     projectTwo,
+					Environment: environmentTwo,
+				},
+				PipelineLockedBy: domain.PipelineLockedBy{
+					LockedBy: userTwo,
+				},
+			},
+			expectedStoreSize: 2,
+		},
+		{
+			description: "Add_projectTwoAddeAnotherTimedWithoutLocker_overwritesStoreKey",
+			pipeline: domain.Pipeline{
+				PipelineIdentifier: domain.PipelineIdentifier{
+					Project:     projectTwo,
+					Environment: environmentTwo,
+				},
+			},
+			expectedStoreSize: 2,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			if err := repository.Add(context.Background(), scenario.pipeline); err != nil {
+				t.Fatalf("Expected nil error, got %v", err)
+			}
+
+			pipelines, _ := repository.FindAll(context.Background())
+			if len(pipelines) != scenario.expectedStoreSize {
+				t.Errorf("Expected store size %d, but got %d", scenario.expectedStoreSize, len(pipelines))
+			}
+			value, _ := repository.Find(context.Background(), scenario.pipeline.PipelineIdentifier)
+			if value == nil {
+				t.Fatalf("Expected pipeline %v to be added to store, but was not found from store", scenario.pipeline.PipelineIdentifier)
+			}
+			if value.LockedBy != scenario.pipeline.LockedBy {
+				t.Errorf("Expected locker user %s, but received %s", scenario.pipeline.LockedBy, value.LockedBy)
+			}
+		})
+	}
+
+	t.Run("FindByProjectAndEnvironment_pipelineExists_returnsPipeline", func(t *testing.T) {
+		pipeline, _ := repository.Find(context.Background(), domain.PipelineIdentifier{
+			Project:     projectOne,
+			Environment: environmentOne,
+		})
+		if pipeline == nil {
+			t.Errorf("Expected store to include project, but got nil")
+		} else if pipeline.Project != projectOne {
+			t.Errorf("Expected store to return pipeline with project %s, but got project %s from store instead", projectOne, pipeline.Project)
+		} else if pipeline.Environment != environmentOne {
+			t.Errorf("Expected store to return pipeline with environment %s, but got environment %s from store instead", projectOne, pipeline.Environment)
+		} else if pipeline.LockedBy != userTwo {
+			t.Errorf("Expected store to return pipeline with user %s, but got user %s from store instead", userTwo, pipeline.LockedBy)
+		}
+	})
+
+	t.Run("FindByProjectAndEnvironment_pipelineNotExists_returnsNil", func(t *testing.T) {
+		pipeline, err := repository.Find(context.Background(), domain.PipelineIdentifier{
+			Project:     projectOne,
+			Environment: environmentTwo,
+		})
+		if pipeline != nil || err != nil {
+			t.Errorf("Expected store to not include project, but got %v and error %v", pipeline, err)
+		}
+	})
+
+	t.Run("FindLockedPipelines_storeHasOnlyOneLockedPipeline_returnsSliceOfOnePipeline", func(t *testing.T) {
+		pipelines, _ := repository.FindLockedPipelines(context.Background())
+		if pipelines == nil {
+			t.Errorf("Expected store to return slice, but got nil")
+		}
+		if len(pipelines) != 1 {
+			t.Errorf("Expected store to return one pipeline, but got %d", len(pipelines))
+		}
+	})
+
+	t.Run("FindAll_storeHasLockedAndUnlockedPipelines_returnsAllPipelines", func(t *testing.T) {
+		pipelines, _ := repository.FindAll(context.Background())
+		if len(pipelines) != 2 {
+			t.Errorf("Expected store to return two pipelines, but got %d", len(pipelines))
+		}
+	})
+
+	t.Run("FindLockedPipelines_storeHasExpiredLock_returnsEmptySlice", func(t *testing.T) {
+		lockedUntil := time.Now().Add(-time.Minute)
+		repository.Add(context.Background(), domain.Pipeline{
+			PipelineIdentifier: domain.PipelineIdentifier{
+				Project:     projectOne,
+				Environment: environmentOne,
+			},
+			PipelineLockedBy: domain.PipelineLockedBy{
+				LockedBy: userOne,
+			},
+			PipelineLockedUntil: domain.PipelineLockedUntil{
+				LockedUntil: &lockedUntil,
+			},
+		})
+
+		pipelines, _ := repository.FindLockedPipelines(context.Background())
+		if len(pipelines) != 0 {
+			t.Errorf("Expected store not to return expired lock, but got %d pipelines", len(pipelines))
+		}
+		pipeline, _ := repository.Find(context.Background(), domain.PipelineIdentifier{
+			Project:     projectOne,
+			Environment: environmentOne,
+		})
+		if pipeline == nil || pipeline.LockedUntil == nil || pipeline.LockedUntil.UnixMicro() != lockedUntil.UnixMicro() {
+			t.Errorf("Expected stored lock expiry %s, got %v", lockedUntil, pipeline)
+		}
+	})
+
+	repository = NewPipelineRepository(openTestDatabase(t), pipelineKeyCaseSensitive)
+
+	t.Run("FindLockedPipelines_storeHasNoLockedPipelines_returnsEmptySlice", func(t *testing.T) {
+		pipelines, _ := repository.FindLockedPipelines(context.Background())
+		if pipelines == nil {
+			t.Errorf("Expected store to return slice, but got nil")
+		}
+		if len(pipelines) != 0 {
+			t.Errorf("Expected store to return empty slice, but got %d", len(pipelines))
+		}
+	})
+
+	t.Run("LockIfUnlocked_pipelineNotLocked_locksPipeline", func(t *testing.T) {
+		locked, err := repository.LockIfUnlocked(context.Background(), domain.Pipeline{
+			PipelineIdentifier: domain.PipelineIdentifier{
+				Project:     projectOne,
+				Environment: environmentOne,
+			},
+			PipelineLockedBy: domain.PipelineLockedBy{
+				LockedBy: userOne,
+			},
+			PipelineLockedAt: domain.PipelineLockedAt{
+				LockedAt: time.Now(),
+			},
+		})
+
+		if !locked || err != nil {
+			t.Errorf("Expected pipeline to be locked, got %t and error %v", locked, err)
+		}
+	})
+
+	t.Run("LockIfUnlocked_pipelineLocked_returnsFalseAndKeepsLock", func(t *testing.T) {
+		locked, _ := repository.LockIfUnlocked(context.Background(), domain.Pipeline{
+			PipelineIdentifier: domain.PipelineIdentifier{
+				Project:     projectOne,
+				Environment: environmentOne,
+			},
+			PipelineLockedBy: domain.PipelineLockedBy{
+				LockedBy: userTwo,
+			},
+			PipelineLockedAt: domain.PipelineLockedAt{
+				LockedAt: time.Now(),
+			},
+		})
+
+		if locked {
+			t.Errorf("Expected locked pipeline not to be locked again")
+		}
+		pipeline, _ := repository.Find(context.Background(), domain.PipelineIdentifier{
+			Project:     projectOne,
+			Environment: environmentOne,
+		})
+		if pipeline == nil || pipeline.LockedBy != userOne {
+			t.Errorf("Expected lock of %s to be kept, got %v", userOne, pipeline)
+		}
+	})
+
+	repository = NewPipelineRepository(openTestDatabase(t), false)
+	t.Run("Add_pipelineKeyCaseInSensitive_caseInsensitiveKeyIsAdded", func(t *testing.T) {
+		repository.Add(context.Background(), domain.Pipeline{
+			PipelineIdentifier: domain.PipelineIdentifier{
+				Project:     projectOne,
+				Environment: environmentOne,
+			},
+			PipelineLockedBy: domain.PipelineLockedBy{
+				LockedBy: userOne,
+			},
+		})
+
+		returnedPipeline, _ := repository.Find(context.Background(), domain.PipelineIdentifier{
+			Project:     projectTwo,
+			Environment: environmentTwo,
+		})
+
+		if returnedPipeline == nil {
+			t.Errorf("Expected repository to have caseInsensitive keys!")
+		}
+	})
+}
+
+func TestPipelineRepository_UnlockIfLocked(t *testing.T) {
+	repositorytest.TestPipelineRepositoryUnlockIfLocked(t, func(caseSensitiveKey bool) repositorytest.UnlockPipelineRepository {
+		return NewPipelineRepository(openTestDatabase(t), caseSensitiveKey)
+	})
+}
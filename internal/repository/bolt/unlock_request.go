@@ -0,0 +1,89 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"go.etcd.io/bbolt"
+)
+
+type unlockRequestRepository struct {
+	db *bbolt.DB
+}
+
+func NewUnlockRequestRepository(db *bbolt.DB) *unlockRequestRepository {
+	return &unlockRequestRepository{
+		db: db,
+	}
+}
+
+func (r *unlockRequestRepository) Add(ctx context.Context, request domain.UnlockRequest) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		return putUnlockRequest(tx, request)
+	})
+}
+
+func (r *unlockRequestRepository) Find(ctx context.Context, id string) (*domain.UnlockRequest, error) {
+	var request *domain.UnlockRequest
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		value := tx.Bucket(unlockRequestsBucket).Get([]byte(id))
+		if value == nil {
+			return nil
+		}
+		request = new(domain.UnlockRequest)
+		return json.Unmarshal(value, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+func (r *unlockRequestRepository) Update(ctx context.Context, request domain.UnlockRequest) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		value := tx.Bucket(unlockRequestsBucket).Get([]byte(request.ID))
+		if value == nil {
+			return domain.ErrUnlockRequestNotFound
+		}
+		var storedRequest domain.UnlockRequest
+		if err := json.Unmarshal(value, &storedRequest); err != nil {
+			return err
+		}
+		if storedRequest.Status != domain.UnlockRequestPending {
+			return domain.ErrUnlockRequestNotPending
+		}
+		return putUnlockRequest(tx, request)
+	})
+}
+
+func (r *unlockRequestRepository) FindPending(ctx context.Context) ([]domain.UnlockRequest, error) {
+	requests := make([]domain.UnlockRequest, 0)
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(unlockRequestsBucket).ForEach(func(key, value []byte) error {
+			var request domain.UnlockRequest
+			if err := json.Unmarshal(value, &request); err != nil {
+				return err
+			}
+			if request.Status == domain.UnlockRequestPending {
+				requests = append(requests, request)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+func putUnlockRequest(tx *bbolt.Tx, request domain.UnlockRequest) error {
+	marshaledRequest, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(unlockRequestsBucket).Put([]byte(request.ID), marshaledRequest)
+}
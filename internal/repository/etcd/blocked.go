@@ -0,0 +1,54 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// blockedAttemptsKeyPrefix prefixes per pipeline list of latest blocked deploy attempts, newest first
+const blockedAttemptsKeyPrefix = keyPrefix + "blocked-attempts/"
+
+type blockedAttemptRepository struct {
+	client           *clientv3.Client
+	caseSensitiveKey bool
+}
+
+func NewBlockedAttemptRepository(client *clientv3.Client, caseSensitiveKey bool) *blockedAttemptRepository {
+	return &blockedAttemptRepository{
+		client:           client,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *blockedAttemptRepository) Add(ctx context.Context, attempt domain.BlockedAttempt) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return prependToHistory(ctx, r.client, blockedAttemptsKeyPrefix+attempt.PipelineIdentifier.GetKey(r.caseSensitiveKey), attempt, domain.BlockedAttemptHistorySize)
+}
+
+func (r *blockedAttemptRepository) FindSince(ctx context.Context, pipeline domain.PipelineIdentifier, since time.Time) ([]domain.BlockedAttempt, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	history, err := findHistory(ctx, r.client, blockedAttemptsKeyPrefix+pipeline.GetKey(r.caseSensitiveKey))
+	if err != nil {
+		return nil, err
+	}
+	attempts := make([]domain.BlockedAttempt, 0)
+	for _, value := range history {
+		var attempt domain.BlockedAttempt
+		if err = json.Unmarshal(value, &attempt); err != nil {
+			return nil, err
+		}
+		if attempt.AttemptedAt.Before(since) {
+			break
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,102 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	// deploymentsKeyPrefix prefixes latest successful deployment of each version per pipeline
+	deploymentsKeyPrefix = keyPrefix + "deployments/"
+	// deploymentHistoryKeyPrefix prefixes per pipeline list of latest deployments, newest first
+	deploymentHistoryKeyPrefix = keyPrefix + "deployment-history/"
+	// currentDeploymentsKeyPrefix prefixes latest successful deployment of every pipeline
+	currentDeploymentsKeyPrefix = keyPrefix + "deployments-current/"
+)
+
+type deploymentRepository struct {
+	client           *clientv3.Client
+	caseSensitiveKey bool
+}
+
+func NewDeploymentRepository(client *clientv3.Client, caseSensitiveKey bool) *deploymentRepository {
+	return &deploymentRepository{
+		client:           client,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *deploymentRepository) Add(ctx context.Context, deployment domain.Deployment) error {
+	marshaledDeployment, err := json.Marshal(deployment)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	pipelineKey := deployment.PipelineIdentifier.GetKey(r.caseSensitiveKey)
+	var ops []clientv3.Op
+	if deployment.Status == domain.DeploymentSucceeded {
+		ops = append(ops,
+			clientv3.OpPut(deploymentsKeyPrefix+pipelineKey+"/"+deployment.Version, string(marshaledDeployment)),
+			clientv3.OpPut(currentDeploymentsKeyPrefix+pipelineKey, string(marshaledDeployment)),
+		)
+	}
+
+	return prependToHistory(ctx, r.client, deploymentHistoryKeyPrefix+pipelineKey, deployment, domain.DeploymentHistorySize, ops...)
+}
+
+func (r *deploymentRepository) FindByVersion(ctx context.Context, pipeline domain.PipelineIdentifier, version string) (*domain.Deployment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	response, err := r.client.Get(ctx, deploymentsKeyPrefix+pipeline.GetKey(r.caseSensitiveKey)+"/"+version)
+	if err != nil || len(response.Kvs) == 0 {
+		return nil, err
+	}
+	var deployment domain.Deployment
+	if err = json.Unmarshal(response.Kvs[0].Value, &deployment); err != nil {
+		return nil, err
+	}
+
+	return &deployment, nil
+}
+
+func (r *deploymentRepository) FindHistory(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.Deployment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	history, err := findHistory(ctx, r.client, deploymentHistoryKeyPrefix+pipeline.GetKey(r.caseSensitiveKey))
+	if err != nil {
+		return nil, err
+	}
+	deployments := make([]domain.Deployment, 0, len(history))
+	for _, value := range history {
+		var deployment domain.Deployment
+		if err = json.Unmarshal(value, &deployment); err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, deployment)
+	}
+
+	return deployments, nil
+}
+
+func (r *deploymentRepository) FindCurrent(ctx context.Context) ([]domain.Deployment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	response, err := r.client.Get(ctx, currentDeploymentsKeyPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	deployments := make([]domain.Deployment, 0, len(response.Kvs))
+	for _, kv := range response.Kvs {
+		var deployment domain.Deployment
+		if err = json.Unmarshal(kv.Value, &deployment); err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, deployment)
+	}
+
+	return deployments, nil
+}
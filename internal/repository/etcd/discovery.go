@@ -0,0 +1,79 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// knownPipelinesKeyPrefix prefixes pipelines discovered from deploy status checks
+const knownPipelinesKeyPrefix = keyPrefix + "known-pipelines/"
+
+type pipelineDiscoveryRepository struct {
+	client           *clientv3.Client
+	caseSensitiveKey bool
+}
+
+func NewPipelineDiscoveryRepository(client *clientv3.Client, caseSensitiveKey bool) *pipelineDiscoveryRepository {
+	return &pipelineDiscoveryRepository{
+		client:           client,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+// Record replaces known pipeline only if nobody modified it meanwhile, so concurrent checks are all counted
+func (r *pipelineDiscoveryRepository) Record(ctx context.Context, check domain.PipelineCheck) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	key := knownPipelinesKeyPrefix + check.PipelineIdentifier.GetKey(r.caseSensitiveKey)
+	for {
+		response, err := r.client.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		var pipeline domain.KnownPipeline
+		modRevision := int64(0)
+		if len(response.Kvs) > 0 {
+			if err = json.Unmarshal(response.Kvs[0].Value, &pipeline); err != nil {
+				return err
+			}
+			modRevision = response.Kvs[0].ModRevision
+		}
+		pipeline.Record(check)
+		marshaledPipeline, err := json.Marshal(pipeline)
+		if err != nil {
+			return err
+		}
+		txnResponse, err := r.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(key), "=", modRevision)).
+			Then(clientv3.OpPut(key, string(marshaledPipeline))).
+			Commit()
+		if err != nil {
+			return err
+		}
+		if txnResponse.Succeeded {
+			return nil
+		}
+	}
+}
+
+func (r *pipelineDiscoveryRepository) FindAll(ctx context.Context) ([]domain.KnownPipeline, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	response, err := r.client.Get(ctx, knownPipelinesKeyPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	pipelines := make([]domain.KnownPipeline, 0, len(response.Kvs))
+	for _, kv := range response.Kvs {
+		var pipeline domain.KnownPipeline
+		if err = json.Unmarshal(kv.Value, &pipeline); err != nil {
+			return nil, err
+		}
+		pipelines = append(pipelines, pipeline)
+	}
+
+	return pipelines, nil
+}
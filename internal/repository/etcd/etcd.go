@@ -0,0 +1,104 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	keyPrefix = "pipeline-locker/"
+	// requestTimeout limits every etcd operation, so unavailable cluster doesn't block requests forever
+	requestTimeout = 5 * time.Second
+	dialTimeout    = 5 * time.Second
+)
+
+type Config struct {
+	Endpoints []string
+	Username  string
+	Password  string
+}
+
+func Open(config Config) (*clientv3.Client, error) {
+	return clientv3.New(clientv3.Config{
+		Endpoints:   config.Endpoints,
+		Username:    config.Username,
+		Password:    config.Password,
+		DialTimeout: dialTimeout,
+	})
+}
+
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, requestTimeout)
+}
+
+// grantLease creates lease expiring at given time, lease TTL is rounded up to whole seconds
+func grantLease(ctx context.Context, client *clientv3.Client, expiresAt time.Time) (clientv3.LeaseID, error) {
+	ttl := int64(time.Until(expiresAt)/time.Second) + 1
+	lease, err := client.Grant(ctx, ttl)
+	if err != nil {
+		return clientv3.NoLease, err
+	}
+
+	return lease.ID, nil
+}
+
+// prependToHistory adds value to the beginning of JSON list stored at key, keeping only size latest values.
+// List is replaced only if nobody modified it meanwhile, extra ops are committed in the same transaction.
+func prependToHistory(ctx context.Context, client *clientv3.Client, key string, value interface{}, size int, ops ...clientv3.Op) error {
+	marshaledValue, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	for {
+		response, err := client.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		history := []json.RawMessage{marshaledValue}
+		modRevision := int64(0)
+		if len(response.Kvs) > 0 {
+			var storedHistory []json.RawMessage
+			if err = json.Unmarshal(response.Kvs[0].Value, &storedHistory); err != nil {
+				return err
+			}
+			history = append(history, storedHistory...)
+			modRevision = response.Kvs[0].ModRevision
+		}
+		if len(history) > size {
+			history = history[:size]
+		}
+		marshaledHistory, err := json.Marshal(history)
+		if err != nil {
+			return err
+		}
+		txnResponse, err := client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(key), "=", modRevision)).
+			Then(append(ops, clientv3.OpPut(key, string(marshaledHistory)))...).
+			Commit()
+		if err != nil {
+			return err
+		}
+		if txnResponse.Succeeded {
+			return nil
+		}
+	}
+}
+
+// findHistory returns JSON list stored at key
+func findHistory(ctx context.Context, client *clientv3.Client, key string) ([]json.RawMessage, error) {
+	response, err := client.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]json.RawMessage, 0)
+	if len(response.Kvs) > 0 {
+		if err = json.Unmarshal(response.Kvs[0].Value, &history); err != nil {
+			return nil, err
+		}
+	}
+
+	return history, nil
+}
@@ -0,0 +1,56 @@
+package etcd
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+// startTestServer starts embedded single node etcd and returns client connected to it
+func startTestServer(t *testing.T) *clientv3.Client {
+	config := embed.NewConfig()
+	config.Dir = t.TempDir()
+	// etcd warns about data directory accessible by others
+	os.Chmod(config.Dir, 0700)
+	config.LogLevel = "error"
+	clientURL := url.URL{Scheme: "http", Host: freeAddr(t)}
+	peerURL := url.URL{Scheme: "http", Host: freeAddr(t)}
+	config.LCUrls, config.ACUrls = []url.URL{clientURL}, []url.URL{clientURL}
+	config.LPUrls, config.APUrls = []url.URL{peerURL}, []url.URL{peerURL}
+	config.InitialCluster = fmt.Sprintf("%s=%s", config.Name, peerURL.String())
+	server, err := embed.StartEtcd(config)
+	if err != nil {
+		t.Fatalf("Failed to start etcd: %v", err)
+	}
+	t.Cleanup(server.Close)
+	select {
+	case <-server.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Etcd didn't become ready")
+	}
+	client, err := Open(Config{Endpoints: []string{clientURL.Host}})
+	if err != nil {
+		t.Fatalf("Failed to connect to etcd: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+	})
+
+	return client
+}
+
+func freeAddr(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find free port: %v", err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().String()
+}
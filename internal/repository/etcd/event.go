@@ -0,0 +1,50 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// eventsKeyPrefix prefixes per pipeline list of latest events, newest first
+const eventsKeyPrefix = keyPrefix + "events/"
+
+type pipelineEventRepository struct {
+	client           *clientv3.Client
+	caseSensitiveKey bool
+}
+
+func NewPipelineEventRepository(client *clientv3.Client, caseSensitiveKey bool) *pipelineEventRepository {
+	return &pipelineEventRepository{
+		client:           client,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *pipelineEventRepository) Add(ctx context.Context, event domain.PipelineEvent) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return prependToHistory(ctx, r.client, eventsKeyPrefix+event.PipelineIdentifier.GetKey(r.caseSensitiveKey), event, domain.PipelineHistorySize)
+}
+
+func (r *pipelineEventRepository) FindByPipeline(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.PipelineEvent, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	history, err := findHistory(ctx, r.client, eventsKeyPrefix+pipeline.GetKey(r.caseSensitiveKey))
+	if err != nil {
+		return nil, err
+	}
+	events := make([]domain.PipelineEvent, 0, len(history))
+	for _, value := range history {
+		var event domain.PipelineEvent
+		if err = json.Unmarshal(value, &event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,134 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// MigrateKeys moves values stored under keys of other encoding or case sensitivity to current pipeline keys,
+// histories of pipelines which shared a key are split, values of pipelines in keep are left under stored key
+// and lock keeps its lease. When current key already holds value or history, it is kept and moved value is
+// dropped. Returns number of moved keys.
+func (r *pipelineRepository) MigrateKeys(keep map[domain.PipelineIdentifier]bool) (int, error) {
+	migrated := 0
+	for _, prefix := range []string{pipelinesKeyPrefix, locksKeyPrefix, currentDeploymentsKeyPrefix, deploymentsKeyPrefix} {
+		moved, err := r.migrateValueKeys(prefix, keep)
+		migrated += moved
+		if err != nil {
+			return migrated, err
+		}
+	}
+	for _, prefix := range []string{deploymentHistoryKeyPrefix, eventsKeyPrefix} {
+		moved, err := r.migrateHistoryKeys(prefix, keep)
+		migrated += moved
+		if err != nil {
+			return migrated, err
+		}
+	}
+
+	return migrated, nil
+}
+
+// migrateValueKeys decodes pipeline of value as deployment, so version of deployment key is known
+func (r *pipelineRepository) migrateValueKeys(prefix string, keep map[domain.PipelineIdentifier]bool) (int, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	response, err := r.client.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for _, kv := range response.Kvs {
+		var deployment domain.Deployment
+		if err = json.Unmarshal(kv.Value, &deployment); err != nil {
+			return migrated, err
+		}
+		if keep[deployment.PipelineIdentifier] {
+			continue
+		}
+		key := prefix + deployment.PipelineIdentifier.GetKey(r.caseSensitiveKey)
+		if prefix == deploymentsKeyPrefix {
+			key += "/" + deployment.Version
+		}
+		if key == string(kv.Key) {
+			continue
+		}
+		var options []clientv3.OpOption
+		if kv.Lease != 0 {
+			options = append(options, clientv3.WithLease(clientv3.LeaseID(kv.Lease)))
+		}
+		_, err = r.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+			Then(clientv3.OpPut(key, string(kv.Value), options...), clientv3.OpDelete(string(kv.Key))).
+			Else(clientv3.OpDelete(string(kv.Key))).
+			Commit()
+		if err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	return migrated, nil
+}
+
+// migrateHistoryKeys splits lists holding value of other pipeline key by current key of value, order is kept.
+// Values of pipelines in keep stay in stored list.
+func (r *pipelineRepository) migrateHistoryKeys(prefix string, keep map[domain.PipelineIdentifier]bool) (int, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	response, err := r.client.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for _, kv := range response.Kvs {
+		var history []json.RawMessage
+		if err = json.Unmarshal(kv.Value, &history); err != nil {
+			return migrated, err
+		}
+		grouped := make(map[string][]json.RawMessage)
+		for _, value := range history {
+			var identifier domain.PipelineIdentifier
+			if err = json.Unmarshal(value, &identifier); err != nil {
+				return migrated, err
+			}
+			key := prefix + identifier.GetKey(r.caseSensitiveKey)
+			if keep[identifier] {
+				key = string(kv.Key)
+			}
+			grouped[key] = append(grouped[key], value)
+		}
+		if _, current := grouped[string(kv.Key)]; current && len(grouped) == 1 {
+			continue
+		}
+		var ops []clientv3.Op
+		if _, current := grouped[string(kv.Key)]; !current {
+			ops = append(ops, clientv3.OpDelete(string(kv.Key)))
+		}
+		for key, values := range grouped {
+			if key != string(kv.Key) {
+				existing, err := r.client.Get(ctx, key, clientv3.WithCountOnly())
+				if err != nil {
+					return migrated, err
+				}
+				if existing.Count > 0 {
+					continue
+				}
+			}
+			marshaledValues, err := json.Marshal(values)
+			if err != nil {
+				return migrated, err
+			}
+			ops = append(ops, clientv3.OpPut(key, string(marshaledValues)))
+		}
+		if _, err = r.client.Txn(ctx).Then(ops...).Commit(); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	return migrated, nil
+}
@@ -0,0 +1,44 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+func TestPipelineRepository_MigrateKeys(t *testing.T) {
+	client := startTestServer(t)
+	repository := NewPipelineRepository(client, false)
+	colliding := []domain.PipelineIdentifier{{Project: "a:b", Environment: "c"}, {Project: "a", Environment: "b:c"}}
+	folded := domain.PipelineIdentifier{Project: "Straße", Environment: "prod"}
+	for _, pipeline := range []domain.PipelineIdentifier{colliding[0], folded} {
+		value, _ := json.Marshal(domain.Pipeline{PipelineIdentifier: pipeline})
+		client.Put(context.Background(), pipelinesKeyPrefix+pipeline.LegacyKey(false), string(value))
+	}
+	// colliding pipelines shared one history
+	history, _ := json.Marshal([]domain.PipelineEvent{
+		{PipelineIdentifier: colliding[1], Type: domain.PipelineUnlockedEvent},
+		{PipelineIdentifier: colliding[0], Type: domain.PipelineLockedEvent},
+	})
+	client.Put(context.Background(), eventsKeyPrefix+colliding[0].LegacyKey(false), string(history))
+
+	migrated, err := repository.MigrateKeys(nil)
+
+	if err != nil || migrated != 3 {
+		t.Fatalf("Expected 3 migrated keys and nil error, got %d and %v", migrated, err)
+	}
+	if pipeline, _ := repository.Find(context.Background(), domain.PipelineIdentifier{Project: "STRASSE", Environment: "PROD"}); pipeline == nil || pipeline.Project != folded.Project {
+		t.Errorf("Expected pipeline %v to be found by case folded key, got %v", folded, pipeline)
+	}
+	events := NewPipelineEventRepository(client, false)
+	for _, pipeline := range colliding {
+		if history, _ := events.FindByPipeline(context.Background(), pipeline); len(history) != 1 || history[0].PipelineIdentifier != pipeline {
+			t.Errorf("Expected only own event of pipeline %v, got %v", pipeline, history)
+		}
+	}
+	if migrated, _ = repository.MigrateKeys(nil); migrated != 0 {
+		t.Errorf("Expected nothing to migrate again, got %d", migrated)
+	}
+}
@@ -0,0 +1,74 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const overrideTokenKeyPrefix = keyPrefix + "override/"
+
+type overrideTokenRepository struct {
+	client           *clientv3.Client
+	caseSensitiveKey bool
+}
+
+func NewOverrideTokenRepository(client *clientv3.Client, caseSensitiveKey bool) *overrideTokenRepository {
+	return &overrideTokenRepository{
+		client:           client,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+// Add stores token attached to lease, so etcd removes it when it expires
+func (r *overrideTokenRepository) Add(ctx context.Context, token domain.OverrideToken) error {
+	if token.IsExpired(time.Now()) {
+		return nil
+	}
+	marshaledToken, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	leaseID, err := grantLease(ctx, r.client, token.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.Put(ctx, overrideTokenKeyPrefix+token.Token, string(marshaledToken), clientv3.WithLease(leaseID))
+
+	return err
+}
+
+func (r *overrideTokenRepository) Consume(ctx context.Context, pipeline domain.PipelineIdentifier, token string) (*domain.OverrideToken, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	key := overrideTokenKeyPrefix + token
+	response, err := r.client.Get(ctx, key)
+	if err != nil || len(response.Kvs) == 0 {
+		return nil, err
+	}
+	var storedToken domain.OverrideToken
+	if err = json.Unmarshal(response.Kvs[0].Value, &storedToken); err != nil {
+		return nil, err
+	}
+	if storedToken.GetKey(r.caseSensitiveKey) != pipeline.GetKey(r.caseSensitiveKey) {
+		return nil, nil
+	}
+	// only the caller that actually deletes the key may use the token
+	txnResponse, err := r.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", response.Kvs[0].ModRevision)).
+		Then(clientv3.OpDelete(key)).
+		Commit()
+	if err != nil {
+		return nil, err
+	}
+	if !txnResponse.Succeeded || storedToken.IsExpired(time.Now()) {
+		return nil, nil
+	}
+
+	return &storedToken, nil
+}
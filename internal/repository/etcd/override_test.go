@@ -0,0 +1,107 @@
+package etcd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+func TestOverrideTokenRepository(t *testing.T) {
+	const token = "token"
+	pipeline := domain.PipelineIdentifier{
+		Project:     "Project",
+		Environment: "Environment",
+	}
+	otherPipeline := domain.PipelineIdentifier{
+		Project:     "Project",
+		Environment: "dev",
+	}
+	newToken := func(value string, expiresAt time.Time) domain.OverrideToken {
+		return domain.OverrideToken{
+			PipelineIdentifier: pipeline,
+			Token:              value,
+			IssuedBy:           "user",
+			IssuedAt:           time.Now(),
+			ExpiresAt:          expiresAt,
+		}
+	}
+
+	t.Run("Consume_tokenNotExists_returnsNil", func(t *testing.T) {
+		repository := NewOverrideTokenRepository(startTestServer(t), true)
+
+		consumed, err := repository.Consume(context.Background(), pipeline, token)
+
+		if err != nil || consumed != nil {
+			t.Errorf("Expected nil token and error, got %v and %v", consumed, err)
+		}
+	})
+
+	t.Run("Consume_tokenBoundToAnotherPipeline_returnsNilAndKeepsToken", func(t *testing.T) {
+		repository := NewOverrideTokenRepository(startTestServer(t), true)
+		repository.Add(context.Background(), newToken(token, time.Now().Add(time.Minute)))
+
+		consumed, _ := repository.Consume(context.Background(), otherPipeline, token)
+
+		if consumed != nil {
+			t.Errorf("Expected nil token, got %v", consumed)
+		}
+		if consumed, _ = repository.Consume(context.Background(), pipeline, token); consumed == nil {
+			t.Errorf("Expected token to remain in store")
+		}
+	})
+
+	t.Run("Consume_tokenExists_returnsTokenOnlyOnce", func(t *testing.T) {
+		repository := NewOverrideTokenRepository(startTestServer(t), true)
+		if err := repository.Add(context.Background(), newToken(token, time.Now().Add(time.Minute))); err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+
+		consumed, _ := repository.Consume(context.Background(), pipeline, token)
+		if consumed == nil || consumed.Token != token || consumed.IssuedBy != "user" {
+			t.Errorf("Expected token %s to be consumed, got %v", token, consumed)
+		}
+		consumed, _ = repository.Consume(context.Background(), pipeline, token)
+		if consumed != nil {
+			t.Errorf("Expected token to be consumed only once, got %v", consumed)
+		}
+	})
+
+	t.Run("Consume_tokenExpired_returnsNil", func(t *testing.T) {
+		repository := NewOverrideTokenRepository(startTestServer(t), true)
+		repository.Add(context.Background(), newToken(token, time.Now().Add(-time.Minute)))
+
+		consumed, _ := repository.Consume(context.Background(), pipeline, token)
+
+		if consumed != nil {
+			t.Errorf("Expected expired token not to be returned, got %v", consumed)
+		}
+	})
+
+	t.Run("Consume_pipelineKeyCaseInsensitive_returnsToken", func(t *testing.T) {
+		repository := NewOverrideTokenRepository(startTestServer(t), false)
+		repository.Add(context.Background(), newToken(token, time.Now().Add(time.Minute)))
+
+		consumed, _ := repository.Consume(context.Background(), domain.PipelineIdentifier{
+			Project:     "project",
+			Environment: "environment",
+		}, token)
+
+		if consumed == nil {
+			t.Errorf("Expected repository to match pipeline case insensitively!")
+		}
+	})
+
+	t.Run("Add_tokenNotExpired_tokenIsAttachedToLease", func(t *testing.T) {
+		client := startTestServer(t)
+		repository := NewOverrideTokenRepository(client, true)
+
+		repository.Add(context.Background(), newToken(token, time.Now().Add(time.Minute)))
+
+		response, _ := client.Get(context.Background(), overrideTokenKeyPrefix+token)
+		if len(response.Kvs) != 1 || response.Kvs[0].Lease == 0 {
+			t.Errorf("Expected token to be stored with lease, got %v", response.Kvs)
+		}
+	})
+}
@@ -0,0 +1,269 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	// pipelinesKeyPrefix prefixes every pipeline ever stored, value is unlocked pipeline
+	pipelinesKeyPrefix = keyPrefix + "pipelines/"
+	// locksKeyPrefix prefixes locked pipelines, expiring locks are attached to lease and removed by etcd
+	locksKeyPrefix = keyPrefix + "locks/"
+)
+
+type pipelineRepository struct {
+	client           *clientv3.Client
+	caseSensitiveKey bool
+}
+
+func NewPipelineRepository(client *clientv3.Client, caseSensitiveKey bool) *pipelineRepository {
+	return &pipelineRepository{
+		client:           client,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *pipelineRepository) Find(ctx context.Context, identifier domain.PipelineIdentifier) (*domain.Pipeline, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	pipelineKey := identifier.GetKey(r.caseSensitiveKey)
+	response, err := r.client.Txn(ctx).
+		Then(clientv3.OpGet(locksKeyPrefix+pipelineKey), clientv3.OpGet(pipelinesKeyPrefix+pipelineKey)).
+		Commit()
+	if err != nil {
+		return nil, err
+	}
+	for _, getResponse := range response.Responses {
+		if kvs := getResponse.GetResponseRange().Kvs; len(kvs) > 0 {
+			var pipeline domain.Pipeline
+			if err = json.Unmarshal(kvs[0].Value, &pipeline); err != nil {
+				return nil, err
+			}
+			return &pipeline, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Add stores pipeline, lock with expiry is removed by etcd when its lease expires
+func (r *pipelineRepository) Add(ctx context.Context, pipeline domain.Pipeline) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	ops, err := r.putOps(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.Txn(ctx).Then(ops...).Commit()
+
+	return err
+}
+
+// LockIfUnlocked stores lock only if lock key doesn't exist or holds expired lock not yet removed by etcd
+func (r *pipelineRepository) LockIfUnlocked(ctx context.Context, pipeline domain.Pipeline) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	ops, err := r.putOps(ctx, pipeline)
+	if err != nil {
+		return false, err
+	}
+	lockKey := locksKeyPrefix + pipeline.PipelineIdentifier.GetKey(r.caseSensitiveKey)
+	condition := clientv3.Compare(clientv3.CreateRevision(lockKey), "=", 0)
+	for {
+		response, err := r.client.Txn(ctx).
+			If(condition).
+			Then(ops...).
+			Else(clientv3.OpGet(lockKey)).
+			Commit()
+		if err != nil {
+			return false, err
+		}
+		if response.Succeeded {
+			return true, nil
+		}
+		kvs := response.Responses[0].GetResponseRange().Kvs
+		if len(kvs) == 0 {
+			condition = clientv3.Compare(clientv3.CreateRevision(lockKey), "=", 0)
+			continue
+		}
+		var existingPipeline domain.Pipeline
+		if err = json.Unmarshal(kvs[0].Value, &existingPipeline); err != nil {
+			return false, err
+		}
+		if existingPipeline.IsLocked(pipeline.LockedAt) {
+			return false, nil
+		}
+		condition = clientv3.Compare(clientv3.ModRevision(lockKey), "=", kvs[0].ModRevision)
+	}
+}
+
+// UnlockIfLocked deletes lock key only if it is not changed after it was compared to given lock
+func (r *pipelineRepository) UnlockIfLocked(ctx context.Context, lock domain.Pipeline) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	ops, err := r.putOps(ctx, domain.Pipeline{PipelineIdentifier: lock.PipelineIdentifier})
+	if err != nil {
+		return false, err
+	}
+	lockKey := locksKeyPrefix + lock.PipelineIdentifier.GetKey(r.caseSensitiveKey)
+	for {
+		response, err := r.client.Get(ctx, lockKey)
+		if err != nil {
+			return false, err
+		}
+		if len(response.Kvs) == 0 {
+			return false, nil
+		}
+		var existingPipeline domain.Pipeline
+		if err = json.Unmarshal(response.Kvs[0].Value, &existingPipeline); err != nil {
+			return false, err
+		}
+		if !existingPipeline.HasLock(lock) {
+			return false, nil
+		}
+		txnResponse, err := r.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(lockKey), "=", response.Kvs[0].ModRevision)).
+			Then(ops...).
+			Commit()
+		if err != nil {
+			return false, err
+		}
+		if txnResponse.Succeeded {
+			return true, nil
+		}
+	}
+}
+
+// putOps returns ops storing pipeline, lock key is deleted when pipeline is unlocked
+func (r *pipelineRepository) putOps(ctx context.Context, pipeline domain.Pipeline) ([]clientv3.Op, error) {
+	pipelineKey := pipeline.PipelineIdentifier.GetKey(r.caseSensitiveKey)
+	marshaledIdentifier, err := json.Marshal(domain.Pipeline{PipelineIdentifier: pipeline.PipelineIdentifier})
+	if err != nil {
+		return nil, err
+	}
+	ops := []clientv3.Op{clientv3.OpPut(pipelinesKeyPrefix+pipelineKey, string(marshaledIdentifier))}
+	if !pipeline.IsLocked(time.Now()) {
+		return append(ops, clientv3.OpDelete(locksKeyPrefix+pipelineKey)), nil
+	}
+	marshaledPipeline, err := json.Marshal(pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var options []clientv3.OpOption
+	if pipeline.LockedUntil != nil {
+		leaseID, err := grantLease(ctx, r.client, *pipeline.LockedUntil)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, clientv3.WithLease(leaseID))
+	}
+
+	return append(ops, clientv3.OpPut(locksKeyPrefix+pipelineKey, string(marshaledPipeline), options...)), nil
+}
+
+func (r *pipelineRepository) FindLockedPipelines(ctx context.Context) ([]domain.Pipeline, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	response, err := r.client.Get(ctx, locksKeyPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	pipelines := make([]domain.Pipeline, 0)
+	now := time.Now()
+	for _, kv := range response.Kvs {
+		var pipeline domain.Pipeline
+		if err = json.Unmarshal(kv.Value, &pipeline); err != nil {
+			return nil, err
+		}
+		if pipeline.IsLocked(now) {
+			pipelines = append(pipelines, pipeline)
+		}
+	}
+
+	return pipelines, nil
+}
+
+func (r *pipelineRepository) FindAll(ctx context.Context) ([]domain.Pipeline, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	response, err := r.client.Txn(ctx).
+		Then(clientv3.OpGet(pipelinesKeyPrefix, clientv3.WithPrefix()), clientv3.OpGet(locksKeyPrefix, clientv3.WithPrefix())).
+		Commit()
+	if err != nil {
+		return nil, err
+	}
+	// lock replaces pipeline of the same identifier, pipelines sharing a key are all listed
+	pipelines := make(map[domain.PipelineIdentifier]domain.Pipeline)
+	identifiers := make([]domain.PipelineIdentifier, 0)
+	for _, getResponse := range response.Responses {
+		for _, kv := range getResponse.GetResponseRange().Kvs {
+			var pipeline domain.Pipeline
+			if err = json.Unmarshal(kv.Value, &pipeline); err != nil {
+				return nil, err
+			}
+			if _, exists := pipelines[pipeline.PipelineIdentifier]; !exists {
+				identifiers = append(identifiers, pipeline.PipelineIdentifier)
+			}
+			pipelines[pipeline.PipelineIdentifier] = pipeline
+		}
+	}
+	allPipelines := make([]domain.Pipeline, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		allPipelines = append(allPipelines, pipelines[identifier])
+	}
+
+	return allPipelines, nil
+}
+
+// Watch sends pipeline every time it is locked or unlocked after Watch returns, expired lock is sent as unlocked pipeline.
+// Channel is closed when ctx is done.
+func (r *pipelineRepository) Watch(ctx context.Context) (<-chan domain.Pipeline, error) {
+	requestCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	// watch from known revision, so changes made right after Watch returns are not missed
+	response, err := r.client.Get(requestCtx, locksKeyPrefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		return nil, err
+	}
+	watchChan := r.client.Watch(clientv3.WithRequireLeader(ctx), locksKeyPrefix, clientv3.WithPrefix(), clientv3.WithPrevKV(), clientv3.WithRev(response.Header.Revision+1))
+	changes := make(chan domain.Pipeline)
+	go func() {
+		defer close(changes)
+		for watchResponse := range watchChan {
+			for _, event := range watchResponse.Events {
+				pipeline, ok := changedPipeline(event)
+				if !ok {
+					continue
+				}
+				select {
+				case changes <- pipeline:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return changes, nil
+}
+
+func changedPipeline(event *clientv3.Event) (domain.Pipeline, bool) {
+	var pipeline domain.Pipeline
+	if event.Type == mvccpb.DELETE {
+		if event.PrevKv == nil || json.Unmarshal(event.PrevKv.Value, &pipeline) != nil {
+			return pipeline, false
+		}
+		return domain.Pipeline{PipelineIdentifier: pipeline.PipelineIdentifier}, true
+	}
+	if json.Unmarshal(event.Kv.Value, &pipeline) != nil {
+		return pipeline, false
+	}
+
+	return pipeline, true
+}
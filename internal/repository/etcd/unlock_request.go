@@ -0,0 +1,107 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const unlockRequestKeyPrefix = keyPrefix + "unlock-request/"
+
+type unlockRequestRepository struct {
+	client *clientv3.Client
+}
+
+func NewUnlockRequestRepository(client *clientv3.Client) *unlockRequestRepository {
+	return &unlockRequestRepository{
+		client: client,
+	}
+}
+
+func (r *unlockRequestRepository) Add(ctx context.Context, request domain.UnlockRequest) error {
+	marshaledRequest, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	_, err = r.client.Put(ctx, unlockRequestKeyPrefix+request.ID, string(marshaledRequest))
+
+	return err
+}
+
+func (r *unlockRequestRepository) Find(ctx context.Context, id string) (*domain.UnlockRequest, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	response, err := r.client.Get(ctx, unlockRequestKeyPrefix+id)
+	if err != nil || len(response.Kvs) == 0 {
+		return nil, err
+	}
+	var request domain.UnlockRequest
+	if err = json.Unmarshal(response.Kvs[0].Value, &request); err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+// Update compares revision of request read beforehand, so that request changed meanwhile is not overwritten
+func (r *unlockRequestRepository) Update(ctx context.Context, request domain.UnlockRequest) error {
+	marshaledRequest, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	key := unlockRequestKeyPrefix + request.ID
+	response, err := r.client.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if len(response.Kvs) == 0 {
+		return domain.ErrUnlockRequestNotFound
+	}
+	var storedRequest domain.UnlockRequest
+	if err = json.Unmarshal(response.Kvs[0].Value, &storedRequest); err != nil {
+		return err
+	}
+	if storedRequest.Status != domain.UnlockRequestPending {
+		return domain.ErrUnlockRequestNotPending
+	}
+	txnResponse, err := r.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", response.Kvs[0].ModRevision)).
+		Then(clientv3.OpPut(key, string(marshaledRequest))).
+		Commit()
+	if err != nil {
+		return err
+	}
+	// pending request is changed only by its review or expiry
+	if !txnResponse.Succeeded {
+		return domain.ErrUnlockRequestNotPending
+	}
+
+	return nil
+}
+
+func (r *unlockRequestRepository) FindPending(ctx context.Context) ([]domain.UnlockRequest, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	response, err := r.client.Get(ctx, unlockRequestKeyPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	requests := make([]domain.UnlockRequest, 0)
+	for _, kv := range response.Kvs {
+		var request domain.UnlockRequest
+		if err = json.Unmarshal(kv.Value, &request); err != nil {
+			return nil, err
+		}
+		if request.Status == domain.UnlockRequestPending {
+			requests = append(requests, request)
+		}
+	}
+
+	return requests, nil
+}
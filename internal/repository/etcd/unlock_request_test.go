@@ -0,0 +1,14 @@
+package etcd
+
+import (
+	"testing"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/repository/repositorytest"
+)
+
+func TestUnlockRequestRepository(t *testing.T) {
+	repositorytest.TestUnlockRequestRepository(t, func() domain.UnlockRequestRepository {
+		return NewUnlockRequestRepository(startTestServer(t))
+	})
+}
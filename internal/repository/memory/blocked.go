@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type blockedAttemptRepository struct {
+	mu               sync.RWMutex
+	store            map[string][]domain.BlockedAttempt
+	caseSensitiveKey bool
+}
+
+func NewBlockedAttemptRepository(caseSensitiveKey bool) *blockedAttemptRepository {
+	return &blockedAttemptRepository{
+		store:            make(map[string][]domain.BlockedAttempt),
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *blockedAttemptRepository) Add(ctx context.Context, attempt domain.BlockedAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := attempt.PipelineIdentifier.GetKey(r.caseSensitiveKey)
+	attempts := append(r.store[key], attempt)
+	if len(attempts) > domain.BlockedAttemptHistorySize {
+		attempts = attempts[len(attempts)-domain.BlockedAttemptHistorySize:]
+	}
+	r.store[key] = attempts
+
+	return nil
+}
+
+func (r *blockedAttemptRepository) FindSince(ctx context.Context, pipeline domain.PipelineIdentifier, since time.Time) ([]domain.BlockedAttempt, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	attempts := r.store[pipeline.GetKey(r.caseSensitiveKey)]
+	found := make([]domain.BlockedAttempt, 0)
+	for i := len(attempts) - 1; i >= 0 && !attempts[i].AttemptedAt.Before(since); i-- {
+		found = append(found, attempts[i])
+	}
+
+	return found, nil
+}
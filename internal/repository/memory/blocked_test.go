@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/repository/repositorytest"
+)
+
+func TestBlockedAttemptRepository(t *testing.T) {
+	repositorytest.TestBlockedAttemptRepository(t, func(caseSensitiveKey bool) domain.BlockedAttemptRepository {
+		return NewBlockedAttemptRepository(caseSensitiveKey)
+	})
+}
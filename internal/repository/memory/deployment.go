@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type deploymentRepository struct {
+	mu               sync.RWMutex
+	history          map[string][]domain.Deployment
+	current          map[string]domain.Deployment
+	caseSensitiveKey bool
+}
+
+func NewDeploymentRepository(caseSensitiveKey bool) *deploymentRepository {
+	return &deploymentRepository{
+		history:          make(map[string][]domain.Deployment),
+		current:          make(map[string]domain.Deployment),
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *deploymentRepository) Add(ctx context.Context, deployment domain.Deployment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := deployment.PipelineIdentifier.GetKey(r.caseSensitiveKey)
+	history := append(r.history[key], deployment)
+	if len(history) > domain.DeploymentHistorySize {
+		history = history[len(history)-domain.DeploymentHistorySize:]
+	}
+	r.history[key] = history
+	if deployment.Status == domain.DeploymentSucceeded {
+		r.current[key] = deployment
+	}
+
+	return nil
+}
+
+func (r *deploymentRepository) FindByVersion(ctx context.Context, pipeline domain.PipelineIdentifier, version string) (*domain.Deployment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	deployments := r.history[pipeline.GetKey(r.caseSensitiveKey)]
+	for i := len(deployments) - 1; i >= 0; i-- {
+		if deployments[i].Status == domain.DeploymentSucceeded && deployments[i].Version == version {
+			deployment := deployments[i]
+			return &deployment, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *deploymentRepository) FindHistory(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.Deployment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	deployments := r.history[pipeline.GetKey(r.caseSensitiveKey)]
+	history := make([]domain.Deployment, 0, len(deployments))
+	for i := len(deployments) - 1; i >= 0; i-- {
+		history = append(history, deployments[i])
+	}
+
+	return history, nil
+}
+
+func (r *deploymentRepository) FindCurrent(ctx context.Context) ([]domain.Deployment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	deployments := make([]domain.Deployment, 0, len(r.current))
+	for _, deployment := range r.current {
+		deployments = append(deployments, deployment)
+	}
+
+	return deployments, nil
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type pipelineDiscoveryRepository struct {
+	mu               sync.RWMutex
+	pipelines        map[string]domain.KnownPipeline
+	caseSensitiveKey bool
+}
+
+func NewPipelineDiscoveryRepository(caseSensitiveKey bool) *pipelineDiscoveryRepository {
+	return &pipelineDiscoveryRepository{
+		pipelines:        make(map[string]domain.KnownPipeline),
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *pipelineDiscoveryRepository) Record(ctx context.Context, check domain.PipelineCheck) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := check.PipelineIdentifier.GetKey(r.caseSensitiveKey)
+	pipeline := r.pipelines[key]
+	pipeline.Record(check)
+	r.pipelines[key] = pipeline
+
+	return nil
+}
+
+func (r *pipelineDiscoveryRepository) FindAll(ctx context.Context) ([]domain.KnownPipeline, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	pipelines := make([]domain.KnownPipeline, 0, len(r.pipelines))
+	for _, pipeline := range r.pipelines {
+		pipelines = append(pipelines, pipeline)
+	}
+
+	return pipelines, nil
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type pipelineEventRepository struct {
+	mu               sync.RWMutex
+	store            map[string][]domain.PipelineEvent
+	caseSensitiveKey bool
+}
+
+func NewPipelineEventRepository(caseSensitiveKey bool) *pipelineEventRepository {
+	return &pipelineEventRepository{
+		store:            make(map[string][]domain.PipelineEvent),
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *pipelineEventRepository) Add(ctx context.Context, event domain.PipelineEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := event.PipelineIdentifier.GetKey(r.caseSensitiveKey)
+	events := append(r.store[key], event)
+	if len(events) > domain.PipelineHistorySize {
+		events = events[len(events)-domain.PipelineHistorySize:]
+	}
+	r.store[key] = events
+
+	return nil
+}
+
+func (r *pipelineEventRepository) FindByPipeline(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.PipelineEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	events := r.store[pipeline.GetKey(r.caseSensitiveKey)]
+	history := make([]domain.PipelineEvent, 0, len(events))
+	for i := len(events) - 1; i >= 0; i-- {
+		history = append(history, events[i])
+	}
+
+	return history, nil
+}
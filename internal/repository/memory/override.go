@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type overrideTokenRepository struct {
+	mu               sync.Mutex
+	store            map[string]domain.OverrideToken
+	caseSensitiveKey bool
+}
+
+func NewOverrideTokenRepository(caseSensitiveKey bool) *overrideTokenRepository {
+	return &overrideTokenRepository{
+		store:            make(map[string]domain.OverrideToken),
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *overrideTokenRepository) Add(ctx context.Context, token domain.OverrideToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.removeExpired(time.Now())
+	r.store[token.Token] = token
+
+	return nil
+}
+
+func (r *overrideTokenRepository) Consume(ctx context.Context, pipeline domain.PipelineIdentifier, token string) (*domain.OverrideToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	storedToken, exists := r.store[token]
+	if !exists {
+		return nil, nil
+	}
+	if storedToken.GetKey(r.caseSensitiveKey) != pipeline.GetKey(r.caseSensitiveKey) {
+		return nil, nil
+	}
+	delete(r.store, token)
+	if storedToken.IsExpired(time.Now()) {
+		return nil, nil
+	}
+
+	return &storedToken, nil
+}
+
+func (r *overrideTokenRepository) removeExpired(now time.Time) {
+	for key, token := range r.store {
+		if token.IsExpired(now) {
+			delete(r.store, key)
+		}
+	}
+}
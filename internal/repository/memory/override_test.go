@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+func TestOverrideTokenRepository(t *testing.T) {
+	const token = "token"
+	pipeline := domain.PipelineIdentifier{
+		Project:     "Project",
+		Environment: "Environment",
+	}
+	otherPipeline := domain.PipelineIdentifier{
+		Project:     "Project",
+		Environment: "dev",
+	}
+	newToken := func(expiresAt time.Time) domain.OverrideToken {
+		return domain.OverrideToken{
+			PipelineIdentifier: pipeline,
+			Token:              token,
+			IssuedBy:           "user",
+			ExpiresAt:          expiresAt,
+		}
+	}
+
+	t.Run("Consume_tokenNotExists_returnsNil", func(t *testing.T) {
+		repository := NewOverrideTokenRepository(true)
+
+		consumed, err := repository.Consume(context.Background(), pipeline, token)
+
+		if err != nil || consumed != nil {
+			t.Errorf("Expected nil token and error, got %v and %v", consumed, err)
+		}
+	})
+
+	t.Run("Consume_tokenBoundToAnotherPipeline_returnsNilAndKeepsToken", func(t *testing.T) {
+		repository := NewOverrideTokenRepository(true)
+		repository.Add(context.Background(), newToken(time.Now().Add(time.Minute)))
+
+		consumed, _ := repository.Consume(context.Background(), otherPipeline, token)
+
+		if consumed != nil {
+			t.Errorf("Expected nil token, got %v", consumed)
+		}
+		if len(repository.store) != 1 {
+			t.Errorf("Expected token to remain in store, but store size is %d", len(repository.store))
+		}
+	})
+
+	t.Run("Consume_tokenExists_returnsTokenOnlyOnce", func(t *testing.T) {
+		repository := NewOverrideTokenRepository(true)
+		repository.Add(context.Background(), newToken(time.Now().Add(time.Minute)))
+
+		consumed, _ := repository.Consume(context.Background(), pipeline, token)
+		if consumed == nil || consumed.Token != token {
+			t.Errorf("Expected token %s to be consumed, got %v", token, consumed)
+		}
+		consumed, _ = repository.Consume(context.Background(), pipeline, token)
+		if consumed != nil {
+			t.Errorf("Expected token to be consumed only once, got %v", consumed)
+		}
+	})
+
+	t.Run("Consume_tokenExpired_returnsNil", func(t *testing.T) {
+		repository := NewOverrideTokenRepository(true)
+		repository.store[token] = newToken(time.Now().Add(-time.Minute))
+
+		consumed, _ := repository.Consume(context.Background(), pipeline, token)
+
+		if consumed != nil {
+			t.Errorf("Expected expired token not to be returned, got %v", consumed)
+		}
+	})
+
+	t.Run("Consume_pipelineKeyCaseInsensitive_returnsToken", func(t *testing.T) {
+		repository := NewOverrideTokenRepository(false)
+		repository.Add(context.Background(), newToken(time.Now().Add(time.Minute)))
+
+		consumed, _ := repository.Consume(context.Background(), domain.PipelineIdentifier{
+			Project:     "project",
+			Environment: "environment",
+		}, token)
+
+		if consumed == nil {
+			t.Errorf("Expected repository to match pipeline case insensitively!")
+		}
+	})
+
+	t.Run("Add_expiredTokensInStore_removesExpiredTokens", func(t *testing.T) {
+		repository := NewOverrideTokenRepository(true)
+		repository.store["expired"] = newToken(time.Now().Add(-time.Minute))
+
+		repository.Add(context.Background(), newToken(time.Now().Add(time.Minute)))
+
+		if len(repository.store) != 1 {
+			t.Errorf("Expected store size 1, got %d", len(repository.store))
+		}
+	})
+}
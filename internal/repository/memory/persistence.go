@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+const (
+	logSuffix  = ".log"
+	tempSuffix = ".tmp"
+)
+
+// PersistenceConfig enables saving in-memory pipelines to local files, so they survive restarts
+type PersistenceConfig struct {
+	// SnapshotPath is file holding all pipelines, pipelines stored after the snapshot are appended to
+	// SnapshotPath.log
+	SnapshotPath string
+	// SnapshotInterval is how often log is compacted into snapshot, zero compacts only on startup and Close
+	SnapshotInterval time.Duration
+}
+
+// persistence appends every stored pipeline to log file and periodically replaces snapshot file with all
+// pipelines. On startup snapshot is read first and log written after it is replayed on top of it.
+type persistence struct {
+	snapshotPath string
+	interval     time.Duration
+	logFile      *os.File
+	log          *logger.Logger
+	done         chan struct{}
+	stopped      chan struct{}
+}
+
+func openPersistence(config PersistenceConfig, restore func(domain.Pipeline), log *logger.Logger) (*persistence, error) {
+	if err := readSnapshot(config.SnapshotPath, restore); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", config.SnapshotPath, err)
+	}
+	logPath := config.SnapshotPath + logSuffix
+	if err := replayLog(logPath, restore); err != nil {
+		return nil, fmt.Errorf("failed to replay log %s: %w", logPath, err)
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &persistence{
+		snapshotPath: config.SnapshotPath,
+		interval:     config.SnapshotInterval,
+		logFile:      logFile,
+		log:          log,
+	}, nil
+}
+
+func readSnapshot(path string, restore func(domain.Pipeline)) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var pipelines []domain.Pipeline
+	if err = json.Unmarshal(data, &pipelines); err != nil {
+		return err
+	}
+	for _, pipeline := range pipelines {
+		restore(pipeline)
+	}
+
+	return nil
+}
+
+// replayLog restores pipelines in order they were stored. Entry without trailing newline was cut short
+// by crash while writing it and is skipped.
+func replayLog(path string, restore func(domain.Pipeline)) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var pipeline domain.Pipeline
+		if err = json.Unmarshal(line, &pipeline); err != nil {
+			return err
+		}
+		restore(pipeline)
+	}
+}
+
+// start compacts log into snapshot every interval until stop is called
+func (p *persistence) start(snapshot func() error) {
+	if p.interval <= 0 {
+		return
+	}
+	p.done = make(chan struct{})
+	p.stopped = make(chan struct{})
+	go func() {
+		defer close(p.stopped)
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := snapshot(); err != nil {
+					p.log.Error.Printf("Failed to write snapshot %s: %v", p.snapshotPath, err)
+				}
+			case <-p.done:
+				return
+			}
+		}
+	}()
+}
+
+func (p *persistence) stop() {
+	if p.done == nil {
+		return
+	}
+	close(p.done)
+	<-p.stopped
+	p.done = nil
+}
+
+// append writes pipeline as single line, so partially written entry lacks newline
+func (p *persistence) append(pipeline domain.Pipeline) error {
+	data, err := json.Marshal(pipeline)
+	if err != nil {
+		return err
+	}
+	if _, err = p.logFile.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	return p.logFile.Sync()
+}
+
+// snapshot writes pipelines to temporary file renamed over snapshot and empties log. Log left behind by
+// crash before emptying holds only entries already included in the snapshot, so replaying it is harmless.
+func (p *persistence) snapshot(pipelines []domain.Pipeline) error {
+	data, err := json.Marshal(pipelines)
+	if err != nil {
+		return err
+	}
+	tempPath := p.snapshotPath + tempSuffix
+	file, err := os.OpenFile(tempPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tempPath, p.snapshotPath); err != nil {
+		return err
+	}
+
+	return p.logFile.Truncate(0)
+}
+
+func (p *persistence) close() error {
+	return p.logFile.Close()
+}
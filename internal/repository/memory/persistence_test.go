@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+func newPersistedPipeline(project, lockedBy string) domain.Pipeline {
+	return domain.Pipeline{
+		PipelineIdentifier: domain.PipelineIdentifier{
+			Project:     project,
+			Environment: "production",
+		},
+		PipelineLockedBy: domain.PipelineLockedBy{
+			LockedBy: lockedBy,
+		},
+	}
+}
+
+func TestPersistentPipelineRepository(t *testing.T) {
+	t.Run("NewPersistentPipelineRepository_reopenedAfterClose_restoresPipelines", func(t *testing.T) {
+		config := PersistenceConfig{SnapshotPath: filepath.Join(t.TempDir(), "pipelines.json")}
+		repository, err := NewPersistentPipelineRepository(true, config, logger.New())
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		repository.Add(context.Background(), newPersistedPipeline("one", "bob"))
+		repository.Add(context.Background(), newPersistedPipeline("two", "alice"))
+		repository.Add(context.Background(), newPersistedPipeline("two", ""))
+		if err = repository.Close(); err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+
+		repository, err = NewPersistentPipelineRepository(true, config, logger.New())
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		defer repository.Close()
+		pipelines, _ := repository.FindAll(context.Background())
+		if len(pipelines) != 2 {
+			t.Errorf("Expected 2 restored pipelines, got %d", len(pipelines))
+		}
+		lockedPipelines, _ := repository.FindLockedPipelines(context.Background())
+		if len(lockedPipelines) != 1 || lockedPipelines[0].LockedBy != "bob" {
+			t.Errorf("Expected pipeline locked by bob, got %v", lockedPipelines)
+		}
+	})
+
+	t.Run("NewPersistentPipelineRepository_notClosed_replaysLog", func(t *testing.T) {
+		config := PersistenceConfig{SnapshotPath: filepath.Join(t.TempDir(), "pipelines.json")}
+		repository, _ := NewPersistentPipelineRepository(true, config, logger.New())
+		repository.Add(context.Background(), newPersistedPipeline("one", "bob"))
+		// process crashed without final snapshot
+		repository.persistence.close()
+
+		repository, err := NewPersistentPipelineRepository(true, config, logger.New())
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		defer repository.Close()
+		pipeline, _ := repository.Find(context.Background(), newPersistedPipeline("one", "").PipelineIdentifier)
+		if pipeline == nil || pipeline.LockedBy != "bob" {
+			t.Errorf("Expected pipeline locked by bob to be replayed from log, got %v", pipeline)
+		}
+	})
+
+	t.Run("NewPersistentPipelineRepository_logEndsWithPartialEntry_partialEntrySkipped", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pipelines.json")
+		os.WriteFile(path+logSuffix, []byte(`{"project":"one","environment":"production","locked_by":"bob"}`+"\n"+`{"project":"two","envir`), 0600)
+
+		repository, err := NewPersistentPipelineRepository(true, PersistenceConfig{SnapshotPath: path}, logger.New())
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		defer repository.Close()
+		pipelines, _ := repository.FindAll(context.Background())
+		if len(pipelines) != 1 {
+			t.Errorf("Expected 1 pipeline, got %d", len(pipelines))
+		}
+		if log, _ := os.ReadFile(path + logSuffix); len(log) != 0 {
+			t.Errorf("Expected log to be compacted on startup, got %s", log)
+		}
+	})
+
+	t.Run("NewPersistentPipelineRepository_corruptSnapshot_returnsError", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pipelines.json")
+		os.WriteFile(path, []byte("not json"), 0600)
+
+		repository, err := NewPersistentPipelineRepository(true, PersistenceConfig{SnapshotPath: path}, logger.New())
+		if err == nil || repository != nil {
+			t.Errorf("Expected error, got %v and %v", repository, err)
+		}
+	})
+
+	t.Run("NewRestoredPipelineRepository_notClosed_restoresWithoutWriting", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pipelines.json")
+		persistentRepository, _ := NewPersistentPipelineRepository(true, PersistenceConfig{SnapshotPath: path}, logger.New())
+		persistentRepository.Add(context.Background(), newPersistedPipeline("one", "bob"))
+		persistentRepository.persistence.close()
+		snapshot, _ := os.ReadFile(path)
+		log, _ := os.ReadFile(path + logSuffix)
+
+		repository, err := NewRestoredPipelineRepository(true, path)
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		repository.Add(context.Background(), newPersistedPipeline("two", "alice"))
+		if err = repository.Close(); err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		pipeline, _ := repository.Find(context.Background(), newPersistedPipeline("one", "").PipelineIdentifier)
+		if pipeline == nil || pipeline.LockedBy != "bob" {
+			t.Errorf("Expected pipeline locked by bob to be replayed from log, got %v", pipeline)
+		}
+		if restoredSnapshot, _ := os.ReadFile(path); string(restoredSnapshot) != string(snapshot) {
+			t.Errorf("Expected snapshot %s to be left unchanged, got %s", snapshot, restoredSnapshot)
+		}
+		if restoredLog, _ := os.ReadFile(path + logSuffix); string(restoredLog) != string(log) {
+			t.Errorf("Expected log %s to be left unchanged, got %s", log, restoredLog)
+		}
+	})
+
+	t.Run("NewRestoredPipelineRepository_missingSnapshot_createsNoFiles", func(t *testing.T) {
+		directory := t.TempDir()
+
+		repository, err := NewRestoredPipelineRepository(true, filepath.Join(directory, "pipelines.json"))
+		if err != nil || repository == nil {
+			t.Fatalf("Expected empty repository, got %v and %v", repository, err)
+		}
+		if entries, _ := os.ReadDir(directory); len(entries) != 0 {
+			t.Errorf("Expected no files, got %d", len(entries))
+		}
+	})
+
+	t.Run("Snapshot_snapshotInterval_logIsCompacted", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pipelines.json")
+		repository, _ := NewPersistentPipelineRepository(true, PersistenceConfig{SnapshotPath: path, SnapshotInterval: 10 * time.Millisecond}, logger.New())
+		defer repository.Close()
+		repository.Add(context.Background(), newPersistedPipeline("one", "bob"))
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			log, _ := os.ReadFile(path + logSuffix)
+			snapshot, _ := os.ReadFile(path)
+			if len(log) == 0 && len(snapshot) > 2 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected log to be compacted into snapshot, got log %s and snapshot %s", log, snapshot)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+
+	t.Run("parallelAddAndSnapshot_allPipelinesRestored", func(t *testing.T) {
+		config := PersistenceConfig{SnapshotPath: filepath.Join(t.TempDir(), "pipelines.json"), SnapshotInterval: time.Millisecond}
+		repository, _ := NewPersistentPipelineRepository(true, config, logger.New())
+		var wg sync.WaitGroup
+		for worker := 0; worker < 10; worker++ {
+			wg.Add(1)
+			go func(worker int) {
+				defer wg.Done()
+				for i := 0; i < 10; i++ {
+					repository.Add(context.Background(), newPersistedPipeline(fmt.Sprintf("project-%d-%d", worker, i), "bob"))
+					repository.FindLockedPipelines(context.Background())
+				}
+			}(worker)
+		}
+		wg.Wait()
+		// simulate crash, pipelines are restored from last snapshot and log written after it
+		repository.persistence.stop()
+		repository.persistence.close()
+
+		repository, err := NewPersistentPipelineRepository(true, config, logger.New())
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		defer repository.Close()
+		pipelines, _ := repository.FindLockedPipelines(context.Background())
+		if len(pipelines) != 100 {
+			t.Errorf("Expected 100 restored pipelines, got %d", len(pipelines))
+		}
+	})
+}
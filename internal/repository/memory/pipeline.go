@@ -1,14 +1,21 @@
 package memory
 
 import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
 	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
 )
 
-const separator = ":"
-
 type pipelineRepository struct {
+	mu               sync.RWMutex
 	store            map[string]domain.Pipeline
 	caseSensitiveKey bool
+	// persistence is nil when pipelines are kept in memory only
+	persistence *persistence
 }
 
 func NewPipelineRepository(caseSensitiveKey bool) *pipelineRepository {
@@ -18,8 +25,44 @@ func NewPipelineRepository(caseSensitiveKey bool) *pipelineRepository {
 	}
 }
 
-func (r *pipelineRepository) Find(identifier domain.PipelineIdentifier) (*domain.Pipeline, error) {
-	key := identifier.GetKey(r.caseSensitiveKey, separator)
+// NewPersistentPipelineRepository restores pipelines from snapshot and log files of config and keeps
+// writing every change to them, Close must be called to stop snapshotting
+func NewPersistentPipelineRepository(caseSensitiveKey bool, config PersistenceConfig, log *logger.Logger) (*pipelineRepository, error) {
+	repository := NewPipelineRepository(caseSensitiveKey)
+	persistence, err := openPersistence(config, repository.restore, log)
+	if err != nil {
+		return nil, err
+	}
+	repository.persistence = persistence
+	// log replayed on startup is compacted right away, it may end with partially written entry
+	if err = repository.Snapshot(); err != nil {
+		persistence.close()
+		return nil, err
+	}
+	persistence.start(repository.Snapshot)
+
+	return repository, nil
+}
+
+// NewRestoredPipelineRepository restores pipelines from snapshot and log files at snapshotPath without writing
+// to them, changes are kept in memory only
+func NewRestoredPipelineRepository(caseSensitiveKey bool, snapshotPath string) (*pipelineRepository, error) {
+	repository := NewPipelineRepository(caseSensitiveKey)
+	if err := readSnapshot(snapshotPath, repository.restore); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", snapshotPath, err)
+	}
+	logPath := snapshotPath + logSuffix
+	if err := replayLog(logPath, repository.restore); err != nil {
+		return nil, fmt.Errorf("failed to replay log %s: %w", logPath, err)
+	}
+
+	return repository, nil
+}
+
+func (r *pipelineRepository) Find(ctx context.Context, identifier domain.PipelineIdentifier) (*domain.Pipeline, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key := identifier.GetKey(r.caseSensitiveKey)
 	pipeline, exists := r.store[key]
 	if !exists {
 		return nil, nil
@@ -28,20 +71,105 @@ func (r *pipelineRepository) Find(identifier domain.PipelineIdentifier) (*domain
 	return &pipeline, nil
 }
 
-func (r *pipelineRepository) Add(pipeline domain.Pipeline) error {
-	key := pipeline.PipelineIdentifier.GetKey(r.caseSensitiveKey, separator)
-	r.store[key] = pipeline
+func (r *pipelineRepository) Add(ctx context.Context, pipeline domain.Pipeline) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.put(pipeline)
+}
+
+// LockIfUnlocked checks and stores pipeline while holding write lock
+func (r *pipelineRepository) LockIfUnlocked(ctx context.Context, pipeline domain.Pipeline) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existingPipeline, exists := r.store[pipeline.PipelineIdentifier.GetKey(r.caseSensitiveKey)]
+	if exists && existingPipeline.IsLocked(pipeline.LockedAt) {
+		return false, nil
+	}
+
+	return true, r.put(pipeline)
+}
+
+// UnlockIfLocked checks and unlocks pipeline while holding write lock
+func (r *pipelineRepository) UnlockIfLocked(ctx context.Context, lock domain.Pipeline) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existingPipeline, exists := r.store[lock.PipelineIdentifier.GetKey(r.caseSensitiveKey)]
+	if !exists || !existingPipeline.HasLock(lock) {
+		return false, nil
+	}
+
+	return true, r.put(domain.Pipeline{PipelineIdentifier: lock.PipelineIdentifier})
+}
+
+// put writes pipeline to log before storing it, so pipeline is not stored if it can't be persisted
+func (r *pipelineRepository) put(pipeline domain.Pipeline) error {
+	if r.persistence != nil {
+		if err := r.persistence.append(pipeline); err != nil {
+			return err
+		}
+	}
+	r.store[pipeline.PipelineIdentifier.GetKey(r.caseSensitiveKey)] = pipeline
 
 	return nil
 }
 
-func (r *pipelineRepository) FindLockedPipelines() ([]domain.Pipeline, error) {
+func (r *pipelineRepository) FindLockedPipelines(ctx context.Context) ([]domain.Pipeline, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	lockedPipelines := make([]domain.Pipeline, 0)
+	now := time.Now()
 	for _, p := range r.store {
-		if p.LockedBy != "" {
+		if p.IsLocked(now) {
 			lockedPipelines = append(lockedPipelines, p)
 		}
 	}
 
 	return lockedPipelines, nil
 }
+
+func (r *pipelineRepository) FindAll(ctx context.Context) ([]domain.Pipeline, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.all(), nil
+}
+
+func (r *pipelineRepository) all() []domain.Pipeline {
+	pipelines := make([]domain.Pipeline, 0, len(r.store))
+	for _, p := range r.store {
+		pipelines = append(pipelines, p)
+	}
+
+	return pipelines
+}
+
+// Snapshot replaces snapshot file with all pipelines and empties log, it does nothing without persistence
+func (r *pipelineRepository) Snapshot() error {
+	if r.persistence == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.persistence.snapshot(r.all())
+}
+
+// Close writes final snapshot and closes log file
+func (r *pipelineRepository) Close() error {
+	if r.persistence == nil {
+		return nil
+	}
+	r.persistence.stop()
+	err := r.Snapshot()
+	if closeErr := r.persistence.close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// restore stores pipeline read from snapshot or log on startup
+func (r *pipelineRepository) restore(pipeline domain.Pipeline) {
+	r.store[pipeline.PipelineIdentifier.GetKey(r.caseSensitiveKey)] = pipeline
+}
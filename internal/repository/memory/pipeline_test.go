@@ -1,9 +1,15 @@
 package memory
 
 import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/repository/repositorytest"
 )
 
 func TestPipelineRepository(t *testing.T) {
@@ -84,12 +90,12 @@ func TestPipelineRepository(t *testing.T) {
 		},
 	} {
 		t.Run(scenario.description, func(t *testing.T) {
-			repository.Add(scenario.pipeline)
+			repository.Add(context.Background(), scenario.pipeline)
 
 			if len(repository.store) != scenario.expectedStoreSize {
 				t.Errorf("Expected store size %d, but got %d", scenario.expectedStoreSize, len(repository.store))
 			}
-			key := scenario.pipeline.Project + separator + scenario.pipeline.Environment
+			key := scenario.pipeline.GetKey(true)
 			value, exists := repository.store[key]
 			if !exists {
 				t.Errorf("Expected key %s to be added to store, but was not found from store", key)
@@ -101,7 +107,7 @@ func TestPipelineRepository(t *testing.T) {
 	}
 
 	t.Run("FindByProjectAndEnvironment_pipelineExists_returnsPipeline", func(t *testing.T) {
-		pipeline, _ := repository.Find(domain.PipelineIdentifier{
+		pipeline, _ := repository.Find(context.Background(), domain.PipelineIdentifier{
 			Project:     projectOne,
 			Environment: environmentOne,
 		})
@@ -117,7 +123,7 @@ func TestPipelineRepository(t *testing.T) {
 	})
 
 	t.Run("FindByProjectAndEnvironment_pipelineNotExists_returnsNil", func(t *testing.T) {
-		pipeline, _ := repository.Find(domain.PipelineIdentifier{
+		pipeline, _ := repository.Find(context.Background(), domain.PipelineIdentifier{
 			Project:     projectOne,
 			Environment: environmentTwo,
 		})
@@ -127,7 +133,7 @@ func TestPipelineRepository(t *testing.T) {
 	})
 
 	t.Run("FindByProjectAndEnvironment_pipelineNotExists_returnsNil", func(t *testing.T) {
-		pipeline, _ := repository.Find(domain.PipelineIdentifier{
+		pipeline, _ := repository.Find(context.Background(), domain.PipelineIdentifier{
 			Project:     projectOne,
 			Environment: environmentTwo,
 		})
@@ -137,7 +143,7 @@ func TestPipelineRepository(t *testing.T) {
 	})
 
 	t.Run("FindLockedPipelines_storeHasOnlyOneLockedPipeline_returnsSliceOfOnePipeline", func(t *testing.T) {
-		pipelines, _ := repository.FindLockedPipelines()
+		pipelines, _ := repository.FindLockedPipelines(context.Background())
 		if pipelines == nil {
 			t.Errorf("Expected store to return slice, but got nil")
 		}
@@ -146,10 +152,17 @@ func TestPipelineRepository(t *testing.T) {
 		}
 	})
 
+	t.Run("FindAll_storeHasLockedAndUnlockedPipelines_returnsAllPipelines", func(t *testing.T) {
+		pipelines, _ := repository.FindAll(context.Background())
+		if len(pipelines) != 2 {
+			t.Errorf("Expected store to return two pipelines, but got %d", len(pipelines))
+		}
+	})
+
 	repository = NewPipelineRepository(pipelineKeyCaseSensitive)
 
 	t.Run("FindLockedPipelines_storeHasNoLockedPipelines_returnsEmptySlice", func(t *testing.T) {
-		pipelines, _ := repository.FindLockedPipelines()
+		pipelines, _ := repository.FindLockedPipelines(context.Background())
 		if pipelines == nil {
 			t.Errorf("Expected store to return slice, but got nil")
 		}
@@ -158,9 +171,30 @@ func TestPipelineRepository(t *testing.T) {
 		}
 	})
 
+	t.Run("FindLockedPipelines_storeHasExpiredLock_returnsEmptySlice", func(t *testing.T) {
+		lockedUntil := time.Now().Add(-time.Minute)
+		repository.Add(context.Background(), domain.Pipeline{
+			PipelineIdentifier: domain.PipelineIdentifier{
+				Project:     projectOne,
+				Environment: environmentOne,
+			},
+			PipelineLockedBy: domain.PipelineLockedBy{
+				LockedBy: userOne,
+			},
+			PipelineLockedUntil: domain.PipelineLockedUntil{
+				LockedUntil: &lockedUntil,
+			},
+		})
+
+		pipelines, _ := repository.FindLockedPipelines(context.Background())
+		if len(pipelines) != 0 {
+			t.Errorf("Expected store not to return expired lock, but got %d pipelines", len(pipelines))
+		}
+	})
+
 	repository = NewPipelineRepository(false)
 	t.Run("Add_pipelineKeyCaseInSensitive_caseInsensitiveKeyIsAdded", func(t *testing.T) {
-		repository.Add(domain.Pipeline{
+		repository.Add(context.Background(), domain.Pipeline{
 			PipelineIdentifier: domain.PipelineIdentifier{
 				Project:     projectOne,
 				Environment: environmentOne,
@@ -170,7 +204,7 @@ func TestPipelineRepository(t *testing.T) {
 			},
 		})
 
-		returnedPipeline, _ := repository.Find(domain.PipelineIdentifier{
+		returnedPipeline, _ := repository.Find(context.Background(), domain.PipelineIdentifier{
 			Project:     projectTwo,
 			Environment: environmentTwo,
 		})
@@ -180,3 +214,129 @@ func TestPipelineRepository(t *testing.T) {
 		}
 	})
 }
+
+func TestPipelineRepository_LockIfUnlocked(t *testing.T) {
+	repository := NewPipelineRepository(true)
+	now := time.Now()
+	pipeline := domain.Pipeline{
+		PipelineIdentifier: domain.PipelineIdentifier{
+			Project:     "project",
+			Environment: "environment",
+		},
+		PipelineLockedBy: domain.PipelineLockedBy{
+			LockedBy: "user",
+		},
+		PipelineLockedAt: domain.PipelineLockedAt{LockedAt: now},
+	}
+
+	t.Run("LockIfUnlocked_pipelineNotExists_locksPipeline", func(t *testing.T) {
+		locked, err := repository.LockIfUnlocked(context.Background(), pipeline)
+		if !locked || err != nil {
+			t.Errorf("Expected pipeline to be locked, got %t and %v", locked, err)
+		}
+	})
+
+	t.Run("LockIfUnlocked_pipelineLocked_returnsFalse", func(t *testing.T) {
+		anotherPipeline := pipeline
+		anotherPipeline.LockedBy = "another"
+		locked, err := repository.LockIfUnlocked(context.Background(), anotherPipeline)
+		if locked || err != nil {
+			t.Errorf("Expected pipeline not to be locked, got %t and %v", locked, err)
+		}
+		stored, _ := repository.Find(context.Background(), pipeline.PipelineIdentifier)
+		if stored.LockedBy != pipeline.LockedBy {
+			t.Errorf("Expected lock of %s to be kept, got %s", pipeline.LockedBy, stored.LockedBy)
+		}
+	})
+
+	t.Run("LockIfUnlocked_lockExpired_locksPipeline", func(t *testing.T) {
+		anotherPipeline := pipeline
+		anotherPipeline.LockedBy = "another"
+		anotherPipeline.LockedAt = now.Add(time.Hour)
+		lockedUntil := now.Add(time.Minute)
+		repository.Add(context.Background(), domain.Pipeline{
+			PipelineIdentifier:  pipeline.PipelineIdentifier,
+			PipelineLockedBy:    pipeline.PipelineLockedBy,
+			PipelineLockedUntil: domain.PipelineLockedUntil{LockedUntil: &lockedUntil},
+		})
+		locked, err := repository.LockIfUnlocked(context.Background(), anotherPipeline)
+		if !locked || err != nil {
+			t.Errorf("Expected pipeline to be locked, got %t and %v", locked, err)
+		}
+	})
+}
+
+func TestPipelineRepository_UnlockIfLocked(t *testing.T) {
+	repositorytest.TestPipelineRepositoryUnlockIfLocked(t, func(caseSensitiveKey bool) repositorytest.UnlockPipelineRepository {
+		return NewPipelineRepository(caseSensitiveKey)
+	})
+}
+
+// TestPipelineRepository_Concurrency is meant to be run with -race
+func TestPipelineRepository_Concurrency(t *testing.T) {
+	const workers = 20
+	const iterations = 50
+
+	t.Run("parallelLockUnlockAndList_noDataRace", func(t *testing.T) {
+		repository := NewPipelineRepository(false)
+		var wg sync.WaitGroup
+		for worker := 0; worker < workers; worker++ {
+			wg.Add(1)
+			go func(worker int) {
+				defer wg.Done()
+				identifier := domain.PipelineIdentifier{
+					Project:     fmt.Sprintf("project-%d", worker%5),
+					Environment: "production",
+				}
+				for i := 0; i < iterations; i++ {
+					repository.Add(context.Background(), domain.Pipeline{
+						PipelineIdentifier: identifier,
+						PipelineLockedBy:   domain.PipelineLockedBy{LockedBy: fmt.Sprintf("user-%d", worker)},
+					})
+					repository.Find(context.Background(), identifier)
+					repository.FindLockedPipelines(context.Background())
+					repository.Add(context.Background(), domain.Pipeline{PipelineIdentifier: identifier})
+					repository.FindAll(context.Background())
+				}
+			}(worker)
+		}
+		wg.Wait()
+
+		pipelines, _ := repository.FindAll(context.Background())
+		if len(pipelines) != 5 {
+			t.Errorf("Expected 5 pipelines, got %d", len(pipelines))
+		}
+	})
+
+	t.Run("parallelLockIfUnlocked_onlyOneLockSucceeds", func(t *testing.T) {
+		repository := NewPipelineRepository(true)
+		now := time.Now()
+		var wg sync.WaitGroup
+		var lockedCount int32
+		for worker := 0; worker < workers; worker++ {
+			wg.Add(1)
+			go func(worker int) {
+				defer wg.Done()
+				locked, err := repository.LockIfUnlocked(context.Background(), domain.Pipeline{
+					PipelineIdentifier: domain.PipelineIdentifier{
+						Project:     "project",
+						Environment: "production",
+					},
+					PipelineLockedBy: domain.PipelineLockedBy{LockedBy: fmt.Sprintf("user-%d", worker)},
+					PipelineLockedAt: domain.PipelineLockedAt{LockedAt: now},
+				})
+				if err != nil {
+					t.Errorf("Expected nil error, got %v", err)
+				}
+				if locked {
+					atomic.AddInt32(&lockedCount, 1)
+				}
+			}(worker)
+		}
+		wg.Wait()
+
+		if lockedCount != 1 {
+			t.Errorf("Expected exactly one lock to succeed, got %d", lockedCount)
+		}
+	})
+}
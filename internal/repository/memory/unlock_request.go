@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type unlockRequestRepository struct {
+	mu    sync.RWMutex
+	store map[string]domain.UnlockRequest
+}
+
+func NewUnlockRequestRepository() *unlockRequestRepository {
+	return &unlockRequestRepository{
+		store: make(map[string]domain.UnlockRequest),
+	}
+}
+
+func (r *unlockRequestRepository) Add(ctx context.Context, request domain.UnlockRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.store[request.ID] = request
+
+	return nil
+}
+
+func (r *unlockRequestRepository) Find(ctx context.Context, id string) (*domain.UnlockRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	request, exists := r.store[id]
+	if !exists {
+		return nil, nil
+	}
+
+	return &request, nil
+}
+
+func (r *unlockRequestRepository) Update(ctx context.Context, request domain.UnlockRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	storedRequest, exists := r.store[request.ID]
+	if !exists {
+		return domain.ErrUnlockRequestNotFound
+	}
+	if storedRequest.Status != domain.UnlockRequestPending {
+		return domain.ErrUnlockRequestNotPending
+	}
+	r.store[request.ID] = request
+
+	return nil
+}
+
+func (r *unlockRequestRepository) FindPending(ctx context.Context) ([]domain.UnlockRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	pendingRequests := make([]domain.UnlockRequest, 0)
+	for _, request := range r.store {
+		if request.Status == domain.UnlockRequestPending {
+			pendingRequests = append(pendingRequests, request)
+		}
+	}
+
+	return pendingRequests, nil
+}
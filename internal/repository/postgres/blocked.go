@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type blockedAttemptRepository struct {
+	db               *sql.DB
+	caseSensitiveKey bool
+}
+
+func NewBlockedAttemptRepository(db *sql.DB, caseSensitiveKey bool) *blockedAttemptRepository {
+	return &blockedAttemptRepository{
+		db:               db,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *blockedAttemptRepository) Add(ctx context.Context, attempt domain.BlockedAttempt) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO blocked_attempts (pipeline_key, project, environment, region, cluster, component, reason, client_ip, job_url, user_name, attempted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		attempt.PipelineIdentifier.GetKey(r.caseSensitiveKey), attempt.Project, attempt.Environment, attempt.Region, attempt.Cluster, attempt.Component, string(attempt.Reason), attempt.ClientIP, attempt.JobURL, attempt.User, attempt.AttemptedAt)
+
+	return err
+}
+
+func (r *blockedAttemptRepository) FindSince(ctx context.Context, pipeline domain.PipelineIdentifier, since time.Time) ([]domain.BlockedAttempt, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT project, environment, region, cluster, component, reason, client_ip, job_url, user_name, attempted_at
+		FROM blocked_attempts WHERE pipeline_key = $1 AND attempted_at >= $2 ORDER BY id DESC LIMIT $3`,
+		pipeline.GetKey(r.caseSensitiveKey), since, domain.BlockedAttemptHistorySize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	attempts := make([]domain.BlockedAttempt, 0)
+	for rows.Next() {
+		var attempt domain.BlockedAttempt
+		if err = rows.Scan(&attempt.Project, &attempt.Environment, &attempt.Region, &attempt.Cluster, &attempt.Component, &attempt.Reason, &attempt.ClientIP, &attempt.JobURL, &attempt.User, &attempt.AttemptedAt); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, rows.Err()
+}
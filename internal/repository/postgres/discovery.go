@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+const knownPipelineColumns = "project, environment, region, cluster, component, first_seen, last_seen, checks, last_outcome"
+
+type pipelineDiscoveryRepository struct {
+	db               *sql.DB
+	caseSensitiveKey bool
+}
+
+func NewPipelineDiscoveryRepository(db *sql.DB, caseSensitiveKey bool) *pipelineDiscoveryRepository {
+	return &pipelineDiscoveryRepository{
+		db:               db,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+// Record inserts pipeline checked first time and counts checks of known pipeline in the same statement
+func (r *pipelineDiscoveryRepository) Record(ctx context.Context, check domain.PipelineCheck) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO known_pipelines (pipeline_key, `+knownPipelineColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 1, $9)
+		ON CONFLICT (pipeline_key) DO UPDATE SET
+			project = EXCLUDED.project, environment = EXCLUDED.environment,
+			region = EXCLUDED.region, cluster = EXCLUDED.cluster, component = EXCLUDED.component,
+			last_seen = EXCLUDED.last_seen, checks = known_pipelines.checks + 1, last_outcome = EXCLUDED.last_outcome`,
+		check.PipelineIdentifier.GetKey(r.caseSensitiveKey), check.Project, check.Environment, check.Region, check.Cluster, check.Component,
+		check.CheckedAt, check.CheckedAt, check.Outcome)
+
+	return err
+}
+
+func (r *pipelineDiscoveryRepository) FindAll(ctx context.Context) ([]domain.KnownPipeline, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+knownPipelineColumns+" FROM known_pipelines")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	pipelines := make([]domain.KnownPipeline, 0)
+	for rows.Next() {
+		var pipeline domain.KnownPipeline
+		if err = rows.Scan(&pipeline.Project, &pipeline.Environment, &pipeline.Region, &pipeline.Cluster, &pipeline.Component, &pipeline.FirstSeen, &pipeline.LastSeen, &pipeline.Checks, &pipeline.LastOutcome); err != nil {
+			return nil, err
+		}
+		pipelines = append(pipelines, pipeline)
+	}
+
+	return pipelines, rows.Err()
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type pipelineEventRepository struct {
+	db               *sql.DB
+	caseSensitiveKey bool
+}
+
+func NewPipelineEventRepository(db *sql.DB, caseSensitiveKey bool) *pipelineEventRepository {
+	return &pipelineEventRepository{
+		db:               db,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *pipelineEventRepository) Add(ctx context.Context, event domain.PipelineEvent) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO pipeline_events (pipeline_key, project, environment, region, cluster, component, type, actor, details, url, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		event.PipelineIdentifier.GetKey(r.caseSensitiveKey), event.Project, event.Environment, event.Region, event.Cluster, event.Component, event.Type, event.Actor, event.Details, event.URL, event.OccurredAt)
+
+	return err
+}
+
+func (r *pipelineEventRepository) FindByPipeline(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.PipelineEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT project, environment, region, cluster, component, type, actor, details, url, occurred_at
+		FROM pipeline_events WHERE pipeline_key = $1 ORDER BY id DESC LIMIT $2`,
+		pipeline.GetKey(r.caseSensitiveKey), domain.PipelineHistorySize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	events := make([]domain.PipelineEvent, 0)
+	for rows.Next() {
+		var event domain.PipelineEvent
+		if err = rows.Scan(&event.Project, &event.Environment, &event.Region, &event.Cluster, &event.Component, &event.Type, &event.Actor, &event.Details, &event.URL, &event.OccurredAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,63 @@
+CREATE TABLE pipelines (
+    pipeline_key TEXT PRIMARY KEY,
+    project      TEXT NOT NULL,
+    environment  TEXT NOT NULL,
+    locked_by    TEXT NOT NULL DEFAULT '',
+    locked_at    TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ
+);
+
+CREATE TABLE override_tokens (
+    token        TEXT PRIMARY KEY,
+    pipeline_key TEXT NOT NULL,
+    project      TEXT NOT NULL,
+    environment  TEXT NOT NULL,
+    issued_by    TEXT NOT NULL,
+    issued_at    TIMESTAMPTZ NOT NULL,
+    expires_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX override_tokens_expires_at_idx ON override_tokens (expires_at);
+
+CREATE TABLE unlock_requests (
+    id           TEXT PRIMARY KEY,
+    project      TEXT NOT NULL,
+    environment  TEXT NOT NULL,
+    requested_by TEXT NOT NULL,
+    requested_at TIMESTAMPTZ NOT NULL,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    status       TEXT NOT NULL,
+    reviewed_by  TEXT NOT NULL DEFAULT '',
+    reviewed_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX unlock_requests_status_idx ON unlock_requests (status);
+
+CREATE TABLE deployments (
+    id           BIGSERIAL PRIMARY KEY,
+    pipeline_key TEXT NOT NULL,
+    project      TEXT NOT NULL,
+    environment  TEXT NOT NULL,
+    version      TEXT NOT NULL,
+    commit_sha   TEXT NOT NULL DEFAULT '',
+    actor        TEXT NOT NULL DEFAULT '',
+    job_url      TEXT NOT NULL DEFAULT '',
+    status       TEXT NOT NULL,
+    deployed_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX deployments_pipeline_key_idx ON deployments (pipeline_key, id);
+
+CREATE TABLE pipeline_events (
+    id           BIGSERIAL PRIMARY KEY,
+    pipeline_key TEXT NOT NULL,
+    project      TEXT NOT NULL,
+    environment  TEXT NOT NULL,
+    type         TEXT NOT NULL,
+    actor        TEXT NOT NULL DEFAULT '',
+    details      TEXT NOT NULL DEFAULT '',
+    url          TEXT NOT NULL DEFAULT '',
+    occurred_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX pipeline_events_pipeline_key_idx ON pipeline_events (pipeline_key, id);
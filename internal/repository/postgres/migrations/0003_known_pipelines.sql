@@ -0,0 +1,13 @@
+-- pipelines discovered from deploy status checks
+CREATE TABLE known_pipelines (
+    pipeline_key TEXT PRIMARY KEY,
+    project      TEXT NOT NULL,
+    environment  TEXT NOT NULL,
+    region       TEXT NOT NULL DEFAULT '',
+    cluster      TEXT NOT NULL DEFAULT '',
+    component    TEXT NOT NULL DEFAULT '',
+    first_seen   TIMESTAMPTZ NOT NULL,
+    last_seen    TIMESTAMPTZ NOT NULL,
+    checks       INTEGER NOT NULL,
+    last_outcome TEXT NOT NULL
+);
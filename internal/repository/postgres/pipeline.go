@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+const pipelineColumns = "project, environment, region, cluster, component, locked_by, locked_at, locked_until"
+
+type pipelineRepository struct {
+	db               *sql.DB
+	caseSensitiveKey bool
+}
+
+func NewPipelineRepository(db *sql.DB, caseSensitiveKey bool) *pipelineRepository {
+	return &pipelineRepository{
+		db:               db,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *pipelineRepository) Find(ctx context.Context, identifier domain.PipelineIdentifier) (*domain.Pipeline, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+pipelineColumns+" FROM pipelines WHERE pipeline_key = $1", identifier.GetKey(r.caseSensitiveKey))
+	pipeline, err := scanPipeline(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return pipeline, err
+}
+
+func (r *pipelineRepository) Add(ctx context.Context, pipeline domain.Pipeline) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO pipelines (pipeline_key, `+pipelineColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (pipeline_key) DO UPDATE SET project = EXCLUDED.project, environment = EXCLUDED.environment,
+			region = EXCLUDED.region, cluster = EXCLUDED.cluster, component = EXCLUDED.component,
+			locked_by = EXCLUDED.locked_by, locked_at = EXCLUDED.locked_at, locked_until = EXCLUDED.locked_until`,
+		pipelineArgs(pipeline, r.caseSensitiveKey)...)
+
+	return err
+}
+
+// LockIfUnlocked inserts or updates pipeline in single statement, existing row is updated only if it is not locked
+func (r *pipelineRepository) LockIfUnlocked(ctx context.Context, pipeline domain.Pipeline) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `INSERT INTO pipelines (pipeline_key, `+pipelineColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (pipeline_key) DO UPDATE SET project = EXCLUDED.project, environment = EXCLUDED.environment,
+			region = EXCLUDED.region, cluster = EXCLUDED.cluster, component = EXCLUDED.component,
+			locked_by = EXCLUDED.locked_by, locked_at = EXCLUDED.locked_at, locked_until = EXCLUDED.locked_until
+		WHERE pipelines.locked_by = '' OR pipelines.locked_until <= EXCLUDED.locked_at`,
+		pipelineArgs(pipeline, r.caseSensitiveKey)...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// UnlockIfLocked unlocks pipeline in single statement, row is updated only if it still has given lock
+func (r *pipelineRepository) UnlockIfLocked(ctx context.Context, lock domain.Pipeline) (bool, error) {
+	if lock.LockedBy == "" {
+		return false, nil
+	}
+	result, err := r.db.ExecContext(ctx, `UPDATE pipelines SET locked_by = '', locked_at = $2, locked_until = NULL
+		WHERE pipeline_key = $1 AND locked_by = $3 AND locked_at = $4`,
+		lock.PipelineIdentifier.GetKey(r.caseSensitiveKey), time.Time{}, lock.LockedBy, lock.LockedAt)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *pipelineRepository) FindLockedPipelines(ctx context.Context) ([]domain.Pipeline, error) {
+	return r.query(ctx, "SELECT "+pipelineColumns+" FROM pipelines WHERE locked_by <> '' AND (locked_until IS NULL OR locked_until > $1)", time.Now())
+}
+
+func (r *pipelineRepository) FindAll(ctx context.Context) ([]domain.Pipeline, error) {
+	return r.query(ctx, "SELECT "+pipelineColumns+" FROM pipelines")
+}
+
+func (r *pipelineRepository) query(ctx context.Context, query string, args ...interface{}) ([]domain.Pipeline, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	pipelines := make([]domain.Pipeline, 0)
+	for rows.Next() {
+		pipeline, err := scanPipeline(rows)
+		if err != nil {
+			return nil, err
+		}
+		pipelines = append(pipelines, *pipeline)
+	}
+
+	return pipelines, rows.Err()
+}
+
+func pipelineArgs(pipeline domain.Pipeline, caseSensitiveKey bool) []interface{} {
+	return []interface{}{
+		pipeline.PipelineIdentifier.GetKey(caseSensitiveKey),
+		pipeline.Project,
+		pipeline.Environment,
+		pipeline.Region,
+		pipeline.Cluster,
+		pipeline.Component,
+		pipeline.LockedBy,
+		pipeline.LockedAt,
+		pipeline.LockedUntil,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPipeline(row scanner) (*domain.Pipeline, error) {
+	var pipeline domain.Pipeline
+	var lockedUntil sql.NullTime
+	if err := row.Scan(&pipeline.Project, &pipeline.Environment, &pipeline.Region, &pipeline.Cluster, &pipeline.Component, &pipeline.LockedBy, &pipeline.LockedAt, &lockedUntil); err != nil {
+		return nil, err
+	}
+	if lockedUntil.Valid {
+		pipeline.LockedUntil = &lockedUntil.Time
+	}
+
+	return &pipeline, nil
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+const (
+	// migrationLockID is advisory lock key held while migrating, so that replicas starting together don't race
+	migrationLockID = 7253021
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Open connects to database at given url and applies schema migrations
+func Open(url string) (*sql.DB, error) {
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err = Migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// OpenMigrated connects to database at given url without writing to it, returns domain.ErrStorageNotInitialized
+// while schema migrations are pending
+func OpenMigrated(url string) (*sql.DB, error) {
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	pending, err := PendingMigrations(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if len(pending) > 0 {
+		db.Close()
+		return nil, fmt.Errorf("%w, pending migrations: %s", domain.ErrStorageNotInitialized, strings.Join(pending, ", "))
+	}
+
+	return db, nil
+}
+
+// PendingMigrations returns embedded migrations not applied yet in file name order
+func PendingMigrations(db *sql.DB) ([]string, error) {
+	ctx := context.Background()
+	var initialized bool
+	if err := db.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&initialized); err != nil {
+		return nil, err
+	}
+	entries, err := fs.ReadDir(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]string, 0)
+	for _, entry := range entries {
+		applied := false
+		if initialized {
+			if err = db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", entry.Name()).Scan(&applied); err != nil {
+				return nil, err
+			}
+		}
+		if !applied {
+			pending = append(pending, entry.Name())
+		}
+	}
+
+	return pending, nil
+}
+
+// Migrate applies embedded migrations that are not applied yet in file name order
+func Migrate(db *sql.DB) error {
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err = tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", migrationLockID); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`); err != nil {
+		return err
+	}
+	entries, err := fs.ReadDir(migrations, "migrations")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		var applied bool
+		if err = tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", entry.Name()).Scan(&applied); err != nil {
+			return err
+		}
+		if applied {
+			continue
+		}
+		migration, err := migrations.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, string(migration)); err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", entry.Name()); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
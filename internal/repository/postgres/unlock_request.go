@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+const unlockRequestColumns = "id, project, environment, region, cluster, component, locked_by, locked_at, requested_by, requested_at, expires_at, status, reviewed_by, reviewed_at"
+
+type unlockRequestRepository struct {
+	db *sql.DB
+}
+
+func NewUnlockRequestRepository(db *sql.DB) *unlockRequestRepository {
+	return &unlockRequestRepository{
+		db: db,
+	}
+}
+
+func (r *unlockRequestRepository) Add(ctx context.Context, request domain.UnlockRequest) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO unlock_requests ("+unlockRequestColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)",
+		request.ID, request.Project, request.Environment, request.Region, request.Cluster, request.Component, request.LockedBy, request.LockedAt, request.RequestedBy, request.RequestedAt, request.ExpiresAt, request.Status, request.ReviewedBy, request.ReviewedAt)
+
+	return err
+}
+
+func (r *unlockRequestRepository) Find(ctx context.Context, id string) (*domain.UnlockRequest, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+unlockRequestColumns+" FROM unlock_requests WHERE id = $1", id)
+	request, err := scanUnlockRequest(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return request, err
+}
+
+// Update changes only pending request, so that request is reviewed once
+func (r *unlockRequestRepository) Update(ctx context.Context, request domain.UnlockRequest) error {
+	result, err := r.db.ExecContext(ctx, "UPDATE unlock_requests SET status = $2, reviewed_by = $3, reviewed_at = $4 WHERE id = $1 AND status = $5",
+		request.ID, request.Status, request.ReviewedBy, request.ReviewedAt, domain.UnlockRequestPending)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 1 {
+		return nil
+	}
+	var exists bool
+	if err = r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM unlock_requests WHERE id = $1)", request.ID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrUnlockRequestNotFound
+	}
+
+	return domain.ErrUnlockRequestNotPending
+}
+
+func (r *unlockRequestRepository) FindPending(ctx context.Context) ([]domain.UnlockRequest, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+unlockRequestColumns+" FROM unlock_requests WHERE status = $1 ORDER BY requested_at", domain.UnlockRequestPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	requests := make([]domain.UnlockRequest, 0)
+	for rows.Next() {
+		request, err := scanUnlockRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+
+	return requests, rows.Err()
+}
+
+func scanUnlockRequest(row scanner) (*domain.UnlockRequest, error) {
+	var request domain.UnlockRequest
+	if err := row.Scan(&request.ID, &request.Project, &request.Environment, &request.Region, &request.Cluster, &request.Component, &request.LockedBy, &request.LockedAt, &request.RequestedBy, &request.RequestedAt, &request.ExpiresAt, &request.Status, &request.ReviewedBy, &request.ReviewedAt); err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
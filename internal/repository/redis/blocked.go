@@ -0,0 +1,60 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+// blockedAttemptsKeyPrefix prefixes per pipeline list of latest blocked deploy attempts, newest first
+const blockedAttemptsKeyPrefix = "blocked-attempts/"
+
+type blockedAttemptRepository struct {
+	redisClient      Client
+	caseSensitiveKey bool
+	keyPrefix        string
+}
+
+func NewBlockedAttemptRepository(redisClient Client, caseSensitiveKey bool, keyPrefix string) *blockedAttemptRepository {
+	return &blockedAttemptRepository{
+		redisClient:      redisClient,
+		caseSensitiveKey: caseSensitiveKey,
+		keyPrefix:        keyPrefix,
+	}
+}
+
+func (r *blockedAttemptRepository) Add(ctx context.Context, attempt domain.BlockedAttempt) error {
+	marshaledAttempt, err := json.Marshal(attempt)
+	if err != nil {
+		return err
+	}
+	key := r.keyPrefix + blockedAttemptsKeyPrefix + attempt.PipelineIdentifier.GetKey(r.caseSensitiveKey)
+
+	return r.redisClient.Tx(ctx, func(pipe Pipe) {
+		pipe.LPush(key, string(marshaledAttempt))
+		pipe.LTrim(key, 0, domain.BlockedAttemptHistorySize-1)
+	})
+}
+
+func (r *blockedAttemptRepository) FindSince(ctx context.Context, pipeline domain.PipelineIdentifier, since time.Time) ([]domain.BlockedAttempt, error) {
+	key := r.keyPrefix + blockedAttemptsKeyPrefix + pipeline.GetKey(r.caseSensitiveKey)
+	values, err := r.redisClient.LRange(ctx, key, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	attempts := make([]domain.BlockedAttempt, 0)
+	for _, value := range values {
+		var attempt domain.BlockedAttempt
+		if err = json.Unmarshal([]byte(value), &attempt); err != nil {
+			return nil, err
+		}
+		if attempt.AttemptedAt.Before(since) {
+			break
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,312 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/go-redis/redis/v9"
+)
+
+// errNil is returned by Client when key or field does not exist
+var errNil = errors.New("redis: nil")
+
+// errTxConflict is returned by Client when watched key is changed before transaction is executed
+var errTxConflict = errors.New("redis: transaction conflict")
+
+// HasHashTag reports whether key prefix holds non-empty hash tag, e.g. "{pipeline-locker}/". Redis Cluster hashes
+// only the tag of such keys, so every key of the prefix is in one hash slot and a transaction may span them.
+func HasHashTag(keyPrefix string) bool {
+	start := strings.IndexByte(keyPrefix, '{')
+	if start < 0 {
+		return false
+	}
+
+	return strings.IndexByte(keyPrefix[start+1:], '}') > 0
+}
+
+// Client is the subset of Redis commands used by repositories
+type Client interface {
+	Get(ctx context.Context, key string) (string, error)
+	// GetMany fetches keys in one round trip, result of every key is returned separately
+	GetMany(ctx context.Context, keys []string) []Result
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	Del(ctx context.Context, key string) (int64, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	HGet(ctx context.Context, key, field string) (string, error)
+	HVals(ctx context.Context, key string) ([]string, error)
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	LRange(ctx context.Context, key string, start, stop int64) ([]string, error)
+	SMembers(ctx context.Context, key string) ([]string, error)
+	ZRangeByScore(ctx context.Context, key, min, max string) ([]string, error)
+	ZRemRangeByScore(ctx context.Context, key, min, max string) error
+	// Scan returns keys matching pattern, in cluster mode keys of every master node are scanned
+	Scan(ctx context.Context, match string) ([]string, error)
+	// Tx queues commands of fn and executes them in MULTI/EXEC transaction, in cluster mode keys of the transaction
+	// must share hash slot
+	Tx(ctx context.Context, fn func(Pipe)) error
+	// WatchTx reads key under WATCH and passes its value, or errNil when key does not exist, to fn. Commands queued
+	// by fn are executed in MULTI/EXEC transaction, which fails with errTxConflict if key is changed meanwhile.
+	// Error returned by fn aborts transaction.
+	WatchTx(ctx context.Context, key string, fn func(value string, err error, pipe Pipe) error) error
+	// Pipelined queues commands of fn and executes them in one round trip without transaction
+	Pipelined(ctx context.Context, fn func(Pipe)) error
+	Info(ctx context.Context, section string) (string, error)
+	Close() error
+}
+
+// Pipe queues write commands of transaction or pipeline
+type Pipe interface {
+	Set(key, value string, ttl time.Duration)
+	SetNX(key, value string)
+	Del(key string)
+	HSet(key, field, value string)
+	HSetNX(key, field, value string)
+	HIncrBy(key, field string, increment int64)
+	HDel(key, field string)
+	LPush(key, value string)
+	LTrim(key string, start, stop int64)
+	SAdd(key, member string)
+	SRem(key, member string)
+	ZAdd(key string, score float64, member string)
+	ZRem(key, member string)
+}
+
+// Result is reply of single command of pipelined request
+type Result struct {
+	Value string
+	Err   error
+}
+
+type goRedisClient struct {
+	client goredis.UniversalClient
+}
+
+// NewClient adapts go-redis client, single node, sentinel and cluster clients are supported
+func NewClient(client goredis.UniversalClient) Client {
+	return &goRedisClient{
+		client: client,
+	}
+}
+
+func (c *goRedisClient) Get(ctx context.Context, key string) (string, error) {
+	value, err := c.client.Get(ctx, key).Result()
+
+	return value, translateError(err)
+}
+
+func (c *goRedisClient) GetMany(ctx context.Context, keys []string) []Result {
+	results := make([]Result, len(keys))
+	if len(keys) == 0 {
+		return results
+	}
+	// MGET would fail with CROSSSLOT in cluster mode, pipeline is split by node instead
+	pipe := c.client.Pipeline()
+	commands := make([]*goredis.StringCmd, len(keys))
+	for i, key := range keys {
+		commands[i] = pipe.Get(ctx, key)
+	}
+	// errors are checked per command, missing key fails Exec as well
+	pipe.Exec(ctx)
+	for i, command := range commands {
+		value, err := command.Result()
+		results[i] = Result{Value: value, Err: translateError(err)}
+	}
+
+	return results
+}
+
+func (c *goRedisClient) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (c *goRedisClient) Del(ctx context.Context, key string) (int64, error) {
+	return c.client.Del(ctx, key).Result()
+}
+
+func (c *goRedisClient) Exists(ctx context.Context, key string) (bool, error) {
+	exists, err := c.client.Exists(ctx, key).Result()
+
+	return exists > 0, err
+}
+
+func (c *goRedisClient) HGet(ctx context.Context, key, field string) (string, error) {
+	value, err := c.client.HGet(ctx, key, field).Result()
+
+	return value, translateError(err)
+}
+
+func (c *goRedisClient) HVals(ctx context.Context, key string) ([]string, error) {
+	return c.client.HVals(ctx, key).Result()
+}
+
+func (c *goRedisClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return c.client.HGetAll(ctx, key).Result()
+}
+
+func (c *goRedisClient) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return c.client.LRange(ctx, key, start, stop).Result()
+}
+
+func (c *goRedisClient) SMembers(ctx context.Context, key string) ([]string, error) {
+	return c.client.SMembers(ctx, key).Result()
+}
+
+func (c *goRedisClient) ZRangeByScore(ctx context.Context, key, min, max string) ([]string, error) {
+	return c.client.ZRangeByScore(ctx, key, &goredis.ZRangeBy{
+		Min: min,
+		Max: max,
+	}).Result()
+}
+
+func (c *goRedisClient) ZRemRangeByScore(ctx context.Context, key, min, max string) error {
+	return c.client.ZRemRangeByScore(ctx, key, min, max).Err()
+}
+
+func (c *goRedisClient) Scan(ctx context.Context, match string) ([]string, error) {
+	clusterClient, ok := c.client.(*goredis.ClusterClient)
+	if !ok {
+		return scanNode(ctx, c.client, match)
+	}
+	var mutex sync.Mutex
+	keys := make([]string, 0)
+	err := clusterClient.ForEachMaster(ctx, func(ctx context.Context, node *goredis.Client) error {
+		nodeKeys, err := scanNode(ctx, node, match)
+		if err != nil {
+			return err
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		keys = append(keys, nodeKeys...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func scanNode(ctx context.Context, client goredis.Cmdable, match string) ([]string, error) {
+	keys := make([]string, 0)
+	iter := client.Scan(ctx, 0, match, 0).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Tx executes commands in transaction. In cluster mode go-redis splits transaction by hash slot, which key prefix
+// with hash tag prevents.
+func (c *goRedisClient) Tx(ctx context.Context, fn func(Pipe)) error {
+	return c.exec(ctx, c.client.TxPipeline(), fn)
+}
+
+func (c *goRedisClient) WatchTx(ctx context.Context, key string, fn func(string, error, Pipe) error) error {
+	err := c.client.Watch(ctx, func(tx *goredis.Tx) error {
+		value, getErr := tx.Get(ctx, key).Result()
+		_, err := tx.TxPipelined(ctx, func(pipeliner goredis.Pipeliner) error {
+			return fn(value, translateError(getErr), &goRedisPipe{ctx: ctx, pipe: pipeliner})
+		})
+		return err
+	}, key)
+	if errors.Is(err, goredis.TxFailedErr) {
+		return errTxConflict
+	}
+
+	return err
+}
+
+func (c *goRedisClient) Pipelined(ctx context.Context, fn func(Pipe)) error {
+	return c.exec(ctx, c.client.Pipeline(), fn)
+}
+
+func (c *goRedisClient) exec(ctx context.Context, pipeliner goredis.Pipeliner, fn func(Pipe)) error {
+	fn(&goRedisPipe{ctx: ctx, pipe: pipeliner})
+	if pipeliner.Len() == 0 {
+		return nil
+	}
+	_, err := pipeliner.Exec(ctx)
+
+	return err
+}
+
+func (c *goRedisClient) Info(ctx context.Context, section string) (string, error) {
+	return c.client.Info(ctx, section).Result()
+}
+
+func (c *goRedisClient) Close() error {
+	return c.client.Close()
+}
+
+// translateError replaces client library specific nil reply error with errNil
+func translateError(err error) error {
+	if errors.Is(err, goredis.Nil) {
+		return errNil
+	}
+
+	return err
+}
+
+type goRedisPipe struct {
+	ctx  context.Context
+	pipe goredis.Pipeliner
+}
+
+func (p *goRedisPipe) Set(key, value string, ttl time.Duration) {
+	p.pipe.Set(p.ctx, key, value, ttl)
+}
+
+func (p *goRedisPipe) SetNX(key, value string) {
+	p.pipe.SetNX(p.ctx, key, value, 0)
+}
+
+func (p *goRedisPipe) Del(key string) {
+	p.pipe.Del(p.ctx, key)
+}
+
+func (p *goRedisPipe) HSet(key, field, value string) {
+	p.pipe.HSet(p.ctx, key, field, value)
+}
+
+func (p *goRedisPipe) HSetNX(key, field, value string) {
+	p.pipe.HSetNX(p.ctx, key, field, value)
+}
+
+func (p *goRedisPipe) HIncrBy(key, field string, increment int64) {
+	p.pipe.HIncrBy(p.ctx, key, field, increment)
+}
+
+func (p *goRedisPipe) HDel(key, field string) {
+	p.pipe.HDel(p.ctx, key, field)
+}
+
+func (p *goRedisPipe) LPush(key, value string) {
+	p.pipe.LPush(p.ctx, key, value)
+}
+
+func (p *goRedisPipe) LTrim(key string, start, stop int64) {
+	p.pipe.LTrim(p.ctx, key, start, stop)
+}
+
+func (p *goRedisPipe) SAdd(key, member string) {
+	p.pipe.SAdd(p.ctx, key, member)
+}
+
+func (p *goRedisPipe) SRem(key, member string) {
+	p.pipe.SRem(p.ctx, key, member)
+}
+
+func (p *goRedisPipe) ZAdd(key string, score float64, member string) {
+	p.pipe.ZAdd(p.ctx, key, goredis.Z{Score: score, Member: member})
+}
+
+func (p *goRedisPipe) ZRem(key, member string) {
+	p.pipe.ZRem(p.ctx, key, member)
+}
@@ -0,0 +1,22 @@
+package redis
+
+import "testing"
+
+func TestClient_HasHashTag(t *testing.T) {
+	type testCases struct {
+		keyPrefix string
+		expected  bool
+	}
+	for _, scenario := range []testCases{
+		{keyPrefix: "{pipeline-locker}/", expected: true},
+		{keyPrefix: "locker:{prod}:", expected: true},
+		{keyPrefix: "pipeline-locker/", expected: false},
+		{keyPrefix: "", expected: false},
+		{keyPrefix: "{}pipeline-locker/", expected: false},
+		{keyPrefix: "{pipeline-locker/", expected: false},
+	} {
+		if hasHashTag := HasHashTag(scenario.keyPrefix); hasHashTag != scenario.expected {
+			t.Errorf("Expected HasHashTag(%q) to be %t, got %t", scenario.keyPrefix, scenario.expected, hasHashTag)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+const (
+	// deploymentsKeyPrefix prefixes per pipeline hash of latest successful deployment of each version
+	deploymentsKeyPrefix = "deployments/"
+	// deploymentHistoryKeyPrefix prefixes per pipeline list of latest deployments, newest first
+	deploymentHistoryKeyPrefix = "deployment-history/"
+	// currentDeploymentsKey is hash of latest successful deployment of every pipeline
+	currentDeploymentsKey = "deployments-current"
+)
+
+type deploymentRepository struct {
+	redisClient      Client
+	caseSensitiveKey bool
+	keyPrefix        string
+}
+
+func NewDeploymentRepository(redisClient Client, caseSensitiveKey bool, keyPrefix string) *deploymentRepository {
+	return &deploymentRepository{
+		redisClient:      redisClient,
+		caseSensitiveKey: caseSensitiveKey,
+		keyPrefix:        keyPrefix,
+	}
+}
+
+func (r *deploymentRepository) Add(ctx context.Context, deployment domain.Deployment) error {
+	marshaledDeployment, err := json.Marshal(deployment)
+	if err != nil {
+		return err
+	}
+	pipelineKey := deployment.PipelineIdentifier.GetKey(r.caseSensitiveKey)
+
+	return r.redisClient.Tx(ctx, func(pipe Pipe) {
+		pipe.LPush(r.keyPrefix+deploymentHistoryKeyPrefix+pipelineKey, string(marshaledDeployment))
+		pipe.LTrim(r.keyPrefix+deploymentHistoryKeyPrefix+pipelineKey, 0, domain.DeploymentHistorySize-1)
+		if deployment.Status == domain.DeploymentSucceeded {
+			pipe.HSet(r.keyPrefix+deploymentsKeyPrefix+pipelineKey, deployment.Version, string(marshaledDeployment))
+			pipe.HSet(r.keyPrefix+currentDeploymentsKey, pipelineKey, string(marshaledDeployment))
+		}
+	})
+}
+
+func (r *deploymentRepository) FindByVersion(ctx context.Context, pipeline domain.PipelineIdentifier, version string) (*domain.Deployment, error) {
+	key := r.keyPrefix + deploymentsKeyPrefix + pipeline.GetKey(r.caseSensitiveKey)
+	value, err := r.redisClient.HGet(ctx, key, version)
+	if err != nil {
+		if errors.Is(err, errNil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var deployment domain.Deployment
+	if err = json.Unmarshal([]byte(value), &deployment); err != nil {
+		return nil, err
+	}
+
+	return &deployment, nil
+}
+
+func (r *deploymentRepository) FindHistory(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.Deployment, error) {
+	key := r.keyPrefix + deploymentHistoryKeyPrefix + pipeline.GetKey(r.caseSensitiveKey)
+	values, err := r.redisClient.LRange(ctx, key, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalDeployments(values)
+}
+
+func (r *deploymentRepository) FindCurrent(ctx context.Context) ([]domain.Deployment, error) {
+	values, err := r.redisClient.HVals(ctx, r.keyPrefix+currentDeploymentsKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalDeployments(values)
+}
+
+func unmarshalDeployments(values []string) ([]domain.Deployment, error) {
+	deployments := make([]domain.Deployment, 0, len(values))
+	for _, value := range values {
+		var deployment domain.Deployment
+		if err := json.Unmarshal([]byte(value), &deployment); err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, deployment)
+	}
+
+	return deployments, nil
+}
@@ -0,0 +1,103 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+const (
+	// knownPipelineKeyPrefix prefixes per pipeline hash of pipeline discovered from deploy status checks
+	knownPipelineKeyPrefix = "known-pipelines/"
+	// knownPipelinesKey is set of pipeline keys of every known pipeline
+	knownPipelinesKey = "known-pipelines"
+)
+
+// fields of known pipeline hash, checks are counted by the server so concurrent checks are not lost
+const (
+	knownPipelineIdentifierField  = "pipeline"
+	knownPipelineFirstSeenField   = "first_seen"
+	knownPipelineLastSeenField    = "last_seen"
+	knownPipelineChecksField      = "checks"
+	knownPipelineLastOutcomeField = "last_outcome"
+)
+
+type pipelineDiscoveryRepository struct {
+	redisClient      Client
+	caseSensitiveKey bool
+	keyPrefix        string
+}
+
+func NewPipelineDiscoveryRepository(redisClient Client, caseSensitiveKey bool, keyPrefix string) *pipelineDiscoveryRepository {
+	return &pipelineDiscoveryRepository{
+		redisClient:      redisClient,
+		caseSensitiveKey: caseSensitiveKey,
+		keyPrefix:        keyPrefix,
+	}
+}
+
+func (r *pipelineDiscoveryRepository) Record(ctx context.Context, check domain.PipelineCheck) error {
+	marshaledIdentifier, err := json.Marshal(check.PipelineIdentifier)
+	if err != nil {
+		return err
+	}
+	pipelineKey := check.PipelineIdentifier.GetKey(r.caseSensitiveKey)
+	key := r.keyPrefix + knownPipelineKeyPrefix + pipelineKey
+	checkedAt := check.CheckedAt.UTC().Format(time.RFC3339Nano)
+
+	return r.redisClient.Tx(ctx, func(pipe Pipe) {
+		pipe.HSet(key, knownPipelineIdentifierField, string(marshaledIdentifier))
+		pipe.HSetNX(key, knownPipelineFirstSeenField, checkedAt)
+		pipe.HSet(key, knownPipelineLastSeenField, checkedAt)
+		pipe.HSet(key, knownPipelineLastOutcomeField, string(check.Outcome))
+		pipe.HIncrBy(key, knownPipelineChecksField, 1)
+		pipe.SAdd(r.keyPrefix+knownPipelinesKey, pipelineKey)
+	})
+}
+
+func (r *pipelineDiscoveryRepository) FindAll(ctx context.Context) ([]domain.KnownPipeline, error) {
+	pipelineKeys, err := r.redisClient.SMembers(ctx, r.keyPrefix+knownPipelinesKey)
+	if err != nil {
+		return nil, err
+	}
+	pipelines := make([]domain.KnownPipeline, 0, len(pipelineKeys))
+	for _, pipelineKey := range pipelineKeys {
+		fields, err := r.redisClient.HGetAll(ctx, r.keyPrefix+knownPipelineKeyPrefix+pipelineKey)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		pipeline, err := parseKnownPipeline(fields)
+		if err != nil {
+			return nil, err
+		}
+		pipelines = append(pipelines, *pipeline)
+	}
+
+	return pipelines, nil
+}
+
+func parseKnownPipeline(fields map[string]string) (*domain.KnownPipeline, error) {
+	var pipeline domain.KnownPipeline
+	var err error
+	if err = json.Unmarshal([]byte(fields[knownPipelineIdentifierField]), &pipeline.PipelineIdentifier); err != nil {
+		return nil, err
+	}
+	if pipeline.FirstSeen, err = time.Parse(time.RFC3339Nano, fields[knownPipelineFirstSeenField]); err != nil {
+		return nil, err
+	}
+	if pipeline.LastSeen, err = time.Parse(time.RFC3339Nano, fields[knownPipelineLastSeenField]); err != nil {
+		return nil, err
+	}
+	if pipeline.Checks, err = strconv.Atoi(fields[knownPipelineChecksField]); err != nil {
+		return nil, err
+	}
+	pipeline.LastOutcome = domain.CheckOutcome(fields[knownPipelineLastOutcomeField])
+
+	return &pipeline, nil
+}
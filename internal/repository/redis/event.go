@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+// eventsKeyPrefix prefixes per pipeline list of latest events, newest first
+const eventsKeyPrefix = "events/"
+
+type pipelineEventRepository struct {
+	redisClient      Client
+	caseSensitiveKey bool
+	keyPrefix        string
+}
+
+func NewPipelineEventRepository(redisClient Client, caseSensitiveKey bool, keyPrefix string) *pipelineEventRepository {
+	return &pipelineEventRepository{
+		redisClient:      redisClient,
+		caseSensitiveKey: caseSensitiveKey,
+		keyPrefix:        keyPrefix,
+	}
+}
+
+func (r *pipelineEventRepository) Add(ctx context.Context, event domain.PipelineEvent) error {
+	marshaledEvent, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	key := r.keyPrefix + eventsKeyPrefix + event.PipelineIdentifier.GetKey(r.caseSensitiveKey)
+
+	return r.redisClient.Tx(ctx, func(pipe Pipe) {
+		pipe.LPush(key, string(marshaledEvent))
+		pipe.LTrim(key, 0, domain.PipelineHistorySize-1)
+	})
+}
+
+func (r *pipelineEventRepository) FindByPipeline(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.PipelineEvent, error) {
+	key := r.keyPrefix + eventsKeyPrefix + pipeline.GetKey(r.caseSensitiveKey)
+	values, err := r.redisClient.LRange(ctx, key, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]domain.PipelineEvent, 0, len(values))
+	for _, value := range values {
+		var event domain.PipelineEvent
+		if err = json.Unmarshal([]byte(value), &event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,212 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+// MigrateKeys moves values stored under keys of other encoding or case sensitivity to current pipeline keys
+// of key prefix namespace, values of pipelines which shared a key are split and values of pipelines in keep
+// are left under stored key. When current key already holds value or history, it is kept and moved value is
+// dropped. Returns number of moved keys.
+func (r *pipelineRepository) MigrateKeys(keep map[domain.PipelineIdentifier]bool) (int, error) {
+	ctx := context.Background()
+	migrated, err := r.migratePipelineKeys(ctx, keep)
+	if err != nil {
+		return migrated, err
+	}
+	for _, history := range []struct {
+		keyPrefix string
+		size      int
+	}{
+		{deploymentHistoryKeyPrefix, domain.DeploymentHistorySize},
+		{eventsKeyPrefix, domain.PipelineHistorySize},
+	} {
+		moved, err := r.migrateHistoryKeys(ctx, history.keyPrefix, history.size, keep)
+		migrated += moved
+		if err != nil {
+			return migrated, err
+		}
+	}
+	moved, err := r.migrateDeploymentKeys(ctx, keep)
+	migrated += moved
+	if err != nil {
+		return migrated, err
+	}
+	moved, err = r.migrateCurrentDeploymentKeys(ctx, keep)
+
+	return migrated + moved, err
+}
+
+// migratePipelineKeys removes moved pipelines from locked pipelines index, IndexLockedPipelines indexes them
+// under current key
+func (r *pipelineRepository) migratePipelineKeys(ctx context.Context, keep map[domain.PipelineIdentifier]bool) (int, error) {
+	prefix := r.keyPrefix + pipelineKeyPrefix
+	keys, err := r.redisClient.Scan(ctx, escapePattern(prefix)+"*")
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for i, result := range r.redisClient.GetMany(ctx, keys) {
+		pipeline, err := decodePipeline(result.Value, result.Err)
+		if errors.Is(err, errUndecodableKey) {
+			continue
+		}
+		if err != nil {
+			return migrated, err
+		}
+		if pipeline == nil || keep[pipeline.PipelineIdentifier] || r.pipelineKey(pipeline.PipelineIdentifier) == keys[i] {
+			continue
+		}
+		err = r.redisClient.Tx(ctx, func(pipe Pipe) {
+			pipe.SetNX(r.pipelineKey(pipeline.PipelineIdentifier), result.Value)
+			pipe.Del(keys[i])
+			pipe.ZRem(r.keyPrefix+lockedPipelinesKey, strings.TrimPrefix(keys[i], prefix))
+		})
+		if err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	return migrated, nil
+}
+
+// migrateHistoryKeys moves values of lists holding value of other pipeline key, oldest value first
+func (r *pipelineRepository) migrateHistoryKeys(ctx context.Context, keyPrefix string, size int, keep map[domain.PipelineIdentifier]bool) (int, error) {
+	prefix := r.keyPrefix + keyPrefix
+	return r.migrateKeys(ctx, prefix, func(key string) (map[string][]string, error) {
+		values, err := r.redisClient.LRange(ctx, key, 0, -1)
+		if err != nil {
+			return nil, err
+		}
+		return r.groupByKey(prefix, key, values, keep)
+	}, func(pipe Pipe, key string, values []string) {
+		for i := len(values) - 1; i >= 0; i-- {
+			pipe.LPush(key, values[i])
+		}
+		pipe.LTrim(key, 0, int64(size-1))
+	})
+}
+
+func (r *pipelineRepository) migrateDeploymentKeys(ctx context.Context, keep map[domain.PipelineIdentifier]bool) (int, error) {
+	prefix := r.keyPrefix + deploymentsKeyPrefix
+	return r.migrateKeys(ctx, prefix, func(key string) (map[string][]string, error) {
+		values, err := r.redisClient.HVals(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return r.groupByKey(prefix, key, values, keep)
+	}, func(pipe Pipe, key string, values []string) {
+		for _, value := range values {
+			var deployment domain.Deployment
+			if json.Unmarshal([]byte(value), &deployment) == nil {
+				pipe.HSet(key, deployment.Version, value)
+			}
+		}
+	})
+}
+
+// migrateKeys scans keys of prefix, key holding value of other pipeline key is removed and values grouped by
+// current key are stored under it by store unless current key existed before migration
+func (r *pipelineRepository) migrateKeys(ctx context.Context, prefix string, read func(key string) (map[string][]string, error), store func(pipe Pipe, key string, values []string)) (int, error) {
+	keys, err := r.redisClient.Scan(ctx, escapePattern(prefix)+"*")
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	kept := make(map[string]bool)
+	for _, key := range keys {
+		values, err := read(key)
+		if err != nil {
+			return migrated, err
+		}
+		if _, current := values[key]; current && len(values) == 1 {
+			continue
+		}
+		for currentKey := range values {
+			if _, checked := kept[currentKey]; !checked {
+				exists, err := r.redisClient.Exists(ctx, currentKey)
+				if err != nil {
+					return migrated, err
+				}
+				kept[currentKey] = exists && currentKey != key
+			}
+		}
+		err = r.redisClient.Tx(ctx, func(pipe Pipe) {
+			pipe.Del(key)
+			for currentKey, currentValues := range values {
+				if !kept[currentKey] {
+					store(pipe, currentKey, currentValues)
+				}
+			}
+		})
+		if err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	return migrated, nil
+}
+
+// groupByKey groups values by key of prefix computed from pipeline of value, order of values is kept. Values
+// of pipelines in keep are grouped under stored key.
+func (r *pipelineRepository) groupByKey(prefix, storedKey string, values []string, keep map[domain.PipelineIdentifier]bool) (map[string][]string, error) {
+	grouped := make(map[string][]string)
+	for _, value := range values {
+		var identifier domain.PipelineIdentifier
+		if err := json.Unmarshal([]byte(value), &identifier); err != nil {
+			return nil, err
+		}
+		key := prefix + identifier.GetKey(r.caseSensitiveKey)
+		if keep[identifier] {
+			key = storedKey
+		}
+		grouped[key] = append(grouped[key], value)
+	}
+
+	return grouped, nil
+}
+
+// migrateCurrentDeploymentKeys moves fields of current deployments hash, field is pipeline key
+func (r *pipelineRepository) migrateCurrentDeploymentKeys(ctx context.Context, keep map[domain.PipelineIdentifier]bool) (int, error) {
+	hashKey := r.keyPrefix + currentDeploymentsKey
+	values, err := r.redisClient.HVals(ctx, hashKey)
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for _, value := range values {
+		var identifier domain.PipelineIdentifier
+		if err = json.Unmarshal([]byte(value), &identifier); err != nil {
+			return migrated, err
+		}
+		// pipelines with scope dimensions were never stored under legacy key, which is key of their parent
+		legacyKey, currentKey := identifier.LegacyKey(r.caseSensitiveKey), identifier.GetKey(r.caseSensitiveKey)
+		if legacyKey == currentKey || keep[identifier] || len(identifier.Dimensions()) > 0 {
+			continue
+		}
+		_, err = r.redisClient.HGet(ctx, hashKey, currentKey)
+		if err != nil && !errors.Is(err, errNil) {
+			return migrated, err
+		}
+		exists := err == nil
+		err = r.redisClient.Tx(ctx, func(pipe Pipe) {
+			pipe.HDel(hashKey, legacyKey)
+			if !exists {
+				pipe.HSet(hashKey, currentKey, value)
+			}
+		})
+		if err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	return migrated, nil
+}
@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+// MigrateUnprefixedKeys moves pipelines stored under bare "project:environment" keys by earlier versions into
+// key prefix namespace. Only keys holding pipeline stored under its own key are moved, so keys of other
+// applications are left untouched. Pipeline already stored in namespace is kept. Returns number of moved keys.
+func (r *pipelineRepository) MigrateUnprefixedKeys() (int, error) {
+	ctx := context.Background()
+	unprefixed, err := r.findUnprefixedPipelines(ctx)
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for _, stored := range unprefixed {
+		err = r.redisClient.Tx(ctx, func(pipe Pipe) {
+			pipe.SetNX(r.pipelineKey(stored.pipeline.PipelineIdentifier), stored.value)
+			pipe.Del(stored.key)
+		})
+		if err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	return migrated, nil
+}
+
+// CountUnprefixedKeys counts pipelines stored under bare keys by earlier versions, which MigrateUnprefixedKeys
+// would move
+func (r *pipelineRepository) CountUnprefixedKeys() (int, error) {
+	unprefixed, err := r.findUnprefixedPipelines(context.Background())
+
+	return len(unprefixed), err
+}
+
+type unprefixedPipeline struct {
+	key      string
+	value    string
+	pipeline *domain.Pipeline
+}
+
+func (r *pipelineRepository) findUnprefixedPipelines(ctx context.Context) ([]unprefixedPipeline, error) {
+	keys, err := r.redisClient.Scan(ctx, "*")
+	if err != nil {
+		return nil, err
+	}
+	unprefixed := make([]unprefixedPipeline, 0)
+	for _, key := range keys {
+		if r.keyPrefix != "" && strings.HasPrefix(key, r.keyPrefix) {
+			continue
+		}
+		value, err := r.redisClient.Get(ctx, key)
+		if err != nil {
+			// key of other type, expired or removed after scan
+			continue
+		}
+		if pipeline, ok := r.decodeUnprefixedPipeline(key, value); ok {
+			unprefixed = append(unprefixed, unprefixedPipeline{key: key, value: value, pipeline: pipeline})
+		}
+	}
+
+	return unprefixed, nil
+}
+
+// decodeUnprefixedPipeline decodes value of key written by earlier versions, returns false if key is not such pipeline
+func (r *pipelineRepository) decodeUnprefixedPipeline(key, value string) (*domain.Pipeline, bool) {
+	var pipeline domain.Pipeline
+	if err := json.Unmarshal([]byte(value), &pipeline); err != nil {
+		return nil, false
+	}
+	if pipeline.Validate() != nil || pipeline.LegacyKey(r.caseSensitiveKey) != key {
+		return nil, false
+	}
+
+	return &pipeline, true
+}
@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+const overrideTokenKeyPrefix = "override/"
+
+type overrideTokenRepository struct {
+	redisClient      Client
+	caseSensitiveKey bool
+	keyPrefix        string
+}
+
+func NewOverrideTokenRepository(redisClient Client, caseSensitiveKey bool, keyPrefix string) *overrideTokenRepository {
+	return &overrideTokenRepository{
+		redisClient:      redisClient,
+		caseSensitiveKey: caseSensitiveKey,
+		keyPrefix:        keyPrefix,
+	}
+}
+
+func (r *overrideTokenRepository) Add(ctx context.Context, token domain.OverrideToken) error {
+	ttl := time.Until(token.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	marshaledToken, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	return r.redisClient.Set(ctx, r.keyPrefix+overrideTokenKeyPrefix+token.Token, string(marshaledToken), ttl)
+}
+
+func (r *overrideTokenRepository) Consume(ctx context.Context, pipeline domain.PipelineIdentifier, token string) (*domain.OverrideToken, error) {
+	key := r.keyPrefix + overrideTokenKeyPrefix + token
+	value, err := r.redisClient.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, errNil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var storedToken domain.OverrideToken
+	if err = json.Unmarshal([]byte(value), &storedToken); err != nil {
+		return nil, err
+	}
+	if storedToken.GetKey(r.caseSensitiveKey) != pipeline.GetKey(r.caseSensitiveKey) {
+		return nil, nil
+	}
+	// only the caller that actually deletes the key may use the token
+	deleted, err := r.redisClient.Del(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 || storedToken.IsExpired(time.Now()) {
+		return nil, nil
+	}
+
+	return &storedToken, nil
+}
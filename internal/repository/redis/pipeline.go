@@ -0,0 +1,247 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+const (
+	// pipelineKeyPrefix prefixes pipelines inside key prefix namespace
+	pipelineKeyPrefix = "pipeline/"
+	// lockedPipelinesKey is sorted set of locked pipeline keys scored by lock expiry in unix milliseconds
+	lockedPipelinesKey = "pipelines/locked"
+)
+
+// errUndecodableKey is returned when key holds something else than pipeline, e.g. key written by another application
+var errUndecodableKey = errors.New("key does not hold pipeline")
+
+type pipelineRepository struct {
+	redisClient      Client
+	caseSensitiveKey bool
+	keyPrefix        string
+	log              *logger.Logger
+}
+
+func NewPipelineRepository(redisClient Client, caseSensitiveKey bool, keyPrefix string, log *logger.Logger) *pipelineRepository {
+	return &pipelineRepository{
+		redisClient:      redisClient,
+		caseSensitiveKey: caseSensitiveKey,
+		keyPrefix:        keyPrefix,
+		log:              log,
+	}
+}
+
+func (r *pipelineRepository) Find(ctx context.Context, identifier domain.PipelineIdentifier) (*domain.Pipeline, error) {
+	return decodePipeline(r.redisClient.Get(ctx, r.pipelineKey(identifier)))
+}
+
+// findByKeys fetches pipelines of keys in one round trip, missing keys are omitted and keys not holding
+// pipeline are logged and skipped
+func (r *pipelineRepository) findByKeys(ctx context.Context, keys []string) ([]domain.Pipeline, error) {
+	pipelines := make([]domain.Pipeline, 0, len(keys))
+	for i, result := range r.redisClient.GetMany(ctx, keys) {
+		p, err := decodePipeline(result.Value, result.Err)
+		if errors.Is(err, errUndecodableKey) {
+			r.log.Error.Printf("Skipping redis key %s: %v", keys[i], err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// key may have been removed meanwhile
+		if p != nil {
+			pipelines = append(pipelines, *p)
+		}
+	}
+
+	return pipelines, nil
+}
+
+func decodePipeline(value string, err error) (*domain.Pipeline, error) {
+	if err != nil {
+		if errors.Is(err, errNil) {
+			return nil, nil
+		}
+		if strings.HasPrefix(err.Error(), "WRONGTYPE") {
+			return nil, fmt.Errorf("%w: %v", errUndecodableKey, err)
+		}
+		return nil, err
+	}
+	var pipeline domain.Pipeline
+	if err = json.Unmarshal([]byte(value), &pipeline); err != nil {
+		return nil, fmt.Errorf("%w: %v", errUndecodableKey, err)
+	}
+
+	return &pipeline, nil
+}
+
+// Add stores pipeline and updates locked pipelines index in the same transaction
+func (r *pipelineRepository) Add(ctx context.Context, pipeline domain.Pipeline) error {
+	marshaledPipeline, err := json.Marshal(pipeline)
+	if err != nil {
+		return err
+	}
+
+	return r.redisClient.Tx(ctx, func(pipe Pipe) {
+		pipe.Set(r.pipelineKey(pipeline.PipelineIdentifier), string(marshaledPipeline), 0)
+		r.updateLockIndex(pipe, pipeline)
+	})
+}
+
+// UnlockIfLocked watches pipeline key and unlocks pipeline only if it still has given lock
+func (r *pipelineRepository) UnlockIfLocked(ctx context.Context, lock domain.Pipeline) (bool, error) {
+	unlockedPipeline := domain.Pipeline{PipelineIdentifier: lock.PipelineIdentifier}
+	marshaledPipeline, err := json.Marshal(unlockedPipeline)
+	if err != nil {
+		return false, err
+	}
+	for {
+		unlocked := false
+		err = r.redisClient.WatchTx(ctx, r.pipelineKey(lock.PipelineIdentifier), func(value string, err error, pipe Pipe) error {
+			existingPipeline, err := decodePipeline(value, err)
+			if err != nil || existingPipeline == nil || !existingPipeline.HasLock(lock) {
+				return err
+			}
+			unlocked = true
+			pipe.Set(r.pipelineKey(lock.PipelineIdentifier), string(marshaledPipeline), 0)
+			r.updateLockIndex(pipe, unlockedPipeline)
+			return nil
+		})
+		// pipeline changed meanwhile is compared again
+		if !errors.Is(err, errTxConflict) {
+			return unlocked, err
+		}
+	}
+}
+
+func (r *pipelineRepository) updateLockIndex(pipe Pipe, pipeline domain.Pipeline) {
+	member := pipeline.GetKey(r.caseSensitiveKey)
+	if !pipeline.IsLocked(time.Now()) {
+		pipe.ZRem(r.keyPrefix+lockedPipelinesKey, member)
+		return
+	}
+	score := math.Inf(1)
+	if pipeline.LockedUntil != nil {
+		score = float64(pipeline.LockedUntil.UnixMilli())
+	}
+	pipe.ZAdd(r.keyPrefix+lockedPipelinesKey, score, member)
+}
+
+// FindLockedPipelines reads locked pipelines from index, expired locks are removed from index on the way
+func (r *pipelineRepository) FindLockedPipelines(ctx context.Context) ([]domain.Pipeline, error) {
+	now := time.Now()
+	nowScore := strconv.FormatInt(now.UnixMilli(), 10)
+	indexKey := r.keyPrefix + lockedPipelinesKey
+	if err := r.redisClient.ZRemRangeByScore(ctx, indexKey, "-inf", nowScore); err != nil {
+		return nil, err
+	}
+	members, err := r.redisClient.ZRangeByScore(ctx, indexKey, "("+nowScore, "+inf")
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(members))
+	for i, member := range members {
+		keys[i] = r.keyPrefix + pipelineKeyPrefix + member
+	}
+	pipelines, err := r.findByKeys(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	lockedPipelines := make([]domain.Pipeline, 0, len(pipelines))
+	for _, p := range pipelines {
+		// lock may be released between reading index and pipelines
+		if p.IsLocked(now) {
+			lockedPipelines = append(lockedPipelines, p)
+		}
+	}
+
+	return lockedPipelines, nil
+}
+
+// IndexLockedPipelines adds locked pipelines of key prefix namespace to locked pipelines index, so locks
+// stored by earlier versions or migrated are listed. Returns number of locked pipelines.
+func (r *pipelineRepository) IndexLockedPipelines() (int, error) {
+	ctx := context.Background()
+	pipelines, err := r.FindAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	indexed := 0
+	err = r.redisClient.Pipelined(ctx, func(pipe Pipe) {
+		for _, p := range pipelines {
+			if p.IsLocked(now) {
+				r.updateLockIndex(pipe, p)
+				indexed++
+			}
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return indexed, nil
+}
+
+// CountUnindexedLocks counts locked pipelines of key prefix namespace missing from locked pipelines index, e.g.
+// stored by earlier versions. Locks taken after index is read are not counted.
+func (r *pipelineRepository) CountUnindexedLocks() (int, error) {
+	ctx := context.Background()
+	indexedAt := time.Now()
+	members, err := r.redisClient.ZRangeByScore(ctx, r.keyPrefix+lockedPipelinesKey, "-inf", "+inf")
+	if err != nil {
+		return 0, err
+	}
+	indexed := make(map[string]bool, len(members))
+	for _, member := range members {
+		indexed[member] = true
+	}
+	pipelines, err := r.FindAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	unindexed := 0
+	for _, p := range pipelines {
+		if p.IsLocked(indexedAt) && p.LockedAt.Before(indexedAt) && !indexed[p.GetKey(r.caseSensitiveKey)] {
+			unindexed++
+		}
+	}
+
+	return unindexed, nil
+}
+
+// FindAll returns pipelines of key prefix namespace, keys not holding pipeline are logged and skipped
+func (r *pipelineRepository) FindAll(ctx context.Context) ([]domain.Pipeline, error) {
+	keys, err := r.redisClient.Scan(ctx, escapePattern(r.keyPrefix+pipelineKeyPrefix)+"*")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.findByKeys(ctx, keys)
+}
+
+func (r *pipelineRepository) pipelineKey(identifier domain.PipelineIdentifier) string {
+	return r.keyPrefix + pipelineKeyPrefix + identifier.GetKey(r.caseSensitiveKey)
+}
+
+// escapePattern escapes glob special characters, so value is matched literally by SCAN
+func escapePattern(value string) string {
+	var escaped strings.Builder
+	for _, c := range value {
+		if strings.ContainsRune(`*?[]^\`, c) {
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(c)
+	}
+
+	return escaped.String()
+}
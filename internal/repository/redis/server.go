@@ -0,0 +1,104 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	FlavorRedis     = "Redis"
+	FlavorValkey    = "Valkey"
+	FlavorKeyDB     = "KeyDB"
+	FlavorDragonfly = "Dragonfly"
+)
+
+// ServerInfo describes Redis compatible server detected from INFO
+type ServerInfo struct {
+	// Flavor is Redis, Valkey, KeyDB or Dragonfly
+	Flavor string
+	// Version is version of the flavor
+	Version string
+	// RedisVersion is Redis version the server is compatible with
+	RedisVersion string
+	// Cluster is true when server runs in cluster mode
+	Cluster bool
+}
+
+func (s ServerInfo) String() string {
+	if s.Flavor == FlavorRedis {
+		return fmt.Sprintf("%s %s", s.Flavor, s.Version)
+	}
+
+	return fmt.Sprintf("%s %s (Redis %s compatible)", s.Flavor, s.Version, s.RedisVersion)
+}
+
+// SupportsACL reports whether server accepts username, ACL users were introduced in Redis 6
+func (s ServerInfo) SupportsACL() bool {
+	return s.Flavor != FlavorRedis || majorVersion(s.RedisVersion) >= 6
+}
+
+// DetectServer reads server flavor, version and cluster mode from INFO, fails when server is unreachable
+func DetectServer(ctx context.Context, client Client) (*ServerInfo, error) {
+	server, err := client.Info(ctx, "server")
+	if err != nil {
+		return nil, err
+	}
+	info := parseServerInfo(parseInfo(server))
+	// compatible servers may not implement cluster section, they are treated as standalone
+	if cluster, err := client.Info(ctx, "cluster"); err == nil {
+		info.Cluster = parseInfo(cluster)["cluster_enabled"] == "1"
+	}
+
+	return info, nil
+}
+
+func parseServerInfo(fields map[string]string) *ServerInfo {
+	info := &ServerInfo{
+		Flavor:       FlavorRedis,
+		Version:      fields["redis_version"],
+		RedisVersion: fields["redis_version"],
+	}
+	switch {
+	case fields["valkey_version"] != "" || strings.EqualFold(fields["server_name"], "valkey"):
+		info.Flavor = FlavorValkey
+		if version := fields["valkey_version"]; version != "" {
+			info.Version = version
+		}
+	case fields["dragonfly_version"] != "":
+		info.Flavor = FlavorDragonfly
+		info.Version = strings.TrimPrefix(fields["dragonfly_version"], "df-v")
+	// KeyDB reports only redis_version, it is recognized by its executable and config file names
+	case strings.Contains(strings.ToLower(fields["executable"]), "keydb") || strings.Contains(strings.ToLower(fields["config_file"]), "keydb"):
+		info.Flavor = FlavorKeyDB
+	}
+
+	return info
+}
+
+// parseInfo parses "field:value" lines of INFO reply, section headers and empty lines are skipped
+func parseInfo(info string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if field, value, ok := strings.Cut(line, ":"); ok {
+			fields[field] = value
+		}
+	}
+
+	return fields
+}
+
+func majorVersion(version string) int {
+	major, _, _ := strings.Cut(version, ".")
+	value, err := strconv.Atoi(major)
+	if err != nil {
+		return 0
+	}
+
+	return value
+}
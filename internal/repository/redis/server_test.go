@@ -0,0 +1,123 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type clientMock struct {
+	Client
+	fakeInfo func(section string) (string, error)
+}
+
+func (m *clientMock) Info(ctx context.Context, section string) (string, error) {
+	return m.fakeInfo(section)
+}
+
+func TestServer_DetectServer(t *testing.T) {
+	type testCases struct {
+		description      string
+		server           string
+		cluster          string
+		clusterErr       error
+		expectedFlavor   string
+		expectedVersion  string
+		expectedRedis    string
+		expectedCluster  bool
+		expectedACL      bool
+		expectedToString string
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:      "redis7Standalone_redis",
+			server:           "# Server\r\nredis_version:7.0.4\r\nredis_mode:standalone\r\nexecutable:/usr/local/bin/redis-server\r\n",
+			cluster:          "# Cluster\r\ncluster_enabled:0\r\n",
+			expectedFlavor:   FlavorRedis,
+			expectedVersion:  "7.0.4",
+			expectedRedis:    "7.0.4",
+			expectedACL:      true,
+			expectedToString: "Redis 7.0.4",
+		},
+		{
+			description:     "redis5Cluster_clusterWithoutACL",
+			server:          "# Server\r\nredis_version:5.0.14\r\n",
+			cluster:         "# Cluster\r\ncluster_enabled:1\r\n",
+			expectedFlavor:  FlavorRedis,
+			expectedVersion: "5.0.14",
+			expectedRedis:   "5.0.14",
+			expectedCluster: true,
+		},
+		{
+			description:      "valkey_valkeyWithCompatibleRedisVersion",
+			server:           "# Server\r\nredis_version:7.2.4\r\nserver_name:valkey\r\nvalkey_version:8.0.1\r\n",
+			cluster:          "# Cluster\r\ncluster_enabled:0\r\n",
+			expectedFlavor:   FlavorValkey,
+			expectedVersion:  "8.0.1",
+			expectedRedis:    "7.2.4",
+			expectedACL:      true,
+			expectedToString: "Valkey 8.0.1 (Redis 7.2.4 compatible)",
+		},
+		{
+			description:     "keydb_keydbFromExecutable",
+			server:          "# Server\r\nredis_version:6.3.4\r\nexecutable:/usr/local/bin/keydb-server\r\nconfig_file:/etc/keydb/keydb.conf\r\n",
+			expectedFlavor:  FlavorKeyDB,
+			expectedVersion: "6.3.4",
+			expectedRedis:   "6.3.4",
+			expectedACL:     true,
+		},
+		{
+			description:     "dragonflyWithoutClusterSection_standaloneDragonfly",
+			server:          "# Server\r\nredis_version:6.2.11\r\ndragonfly_version:df-v1.14.1\r\n",
+			clusterErr:      errors.New("ERR unknown section"),
+			expectedFlavor:  FlavorDragonfly,
+			expectedVersion: "1.14.1",
+			expectedRedis:   "6.2.11",
+			expectedACL:     true,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			client := &clientMock{
+				fakeInfo: func(section string) (string, error) {
+					if section == "cluster" {
+						return scenario.cluster, scenario.clusterErr
+					}
+					return scenario.server, nil
+				},
+			}
+
+			info, err := DetectServer(context.Background(), client)
+
+			if err != nil {
+				t.Fatalf("Expected nil error, got %v", err)
+			}
+			if info.Flavor != scenario.expectedFlavor || info.Version != scenario.expectedVersion || info.RedisVersion != scenario.expectedRedis {
+				t.Errorf("Expected %s %s (Redis %s), got %+v", scenario.expectedFlavor, scenario.expectedVersion, scenario.expectedRedis, info)
+			}
+			if info.Cluster != scenario.expectedCluster {
+				t.Errorf("Expected cluster %t, got %t", scenario.expectedCluster, info.Cluster)
+			}
+			if info.SupportsACL() != scenario.expectedACL {
+				t.Errorf("Expected ACL support %t, got %t", scenario.expectedACL, info.SupportsACL())
+			}
+			if scenario.expectedToString != "" && info.String() != scenario.expectedToString {
+				t.Errorf("Expected %q, got %q", scenario.expectedToString, info.String())
+			}
+		})
+	}
+
+	t.Run("DetectServer_serverUnreachable_returnsError", func(t *testing.T) {
+		client := &clientMock{
+			fakeInfo: func(section string) (string, error) {
+				return "", errors.New("dial tcp: connection refused")
+			},
+		}
+
+		info, err := DetectServer(context.Background(), client)
+
+		if err == nil || info != nil {
+			t.Errorf("Expected error, got %v and %v", info, err)
+		}
+	})
+}
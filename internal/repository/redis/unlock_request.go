@@ -0,0 +1,116 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+const (
+	unlockRequestKeyPrefix = "unlock-request/"
+	pendingUnlockRequests  = "unlock-requests/pending"
+)
+
+type unlockRequestRepository struct {
+	redisClient Client
+	keyPrefix   string
+}
+
+func NewUnlockRequestRepository(redisClient Client, keyPrefix string) *unlockRequestRepository {
+	return &unlockRequestRepository{
+		redisClient: redisClient,
+		keyPrefix:   keyPrefix,
+	}
+}
+
+func (r *unlockRequestRepository) Add(ctx context.Context, request domain.UnlockRequest) error {
+	return r.save(ctx, request)
+}
+
+func (r *unlockRequestRepository) Find(ctx context.Context, id string) (*domain.UnlockRequest, error) {
+	value, err := r.redisClient.Get(ctx, r.keyPrefix+unlockRequestKeyPrefix+id)
+	if err != nil {
+		if errors.Is(err, errNil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var request domain.UnlockRequest
+	if err = json.Unmarshal([]byte(value), &request); err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+// Update watches request key, so that request changed meanwhile is not overwritten
+func (r *unlockRequestRepository) Update(ctx context.Context, request domain.UnlockRequest) error {
+	marshaledRequest, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	err = r.redisClient.WatchTx(ctx, r.keyPrefix+unlockRequestKeyPrefix+request.ID, func(value string, err error, pipe Pipe) error {
+		if errors.Is(err, errNil) {
+			return domain.ErrUnlockRequestNotFound
+		}
+		if err != nil {
+			return err
+		}
+		var storedRequest domain.UnlockRequest
+		if err = json.Unmarshal([]byte(value), &storedRequest); err != nil {
+			return err
+		}
+		if storedRequest.Status != domain.UnlockRequestPending {
+			return domain.ErrUnlockRequestNotPending
+		}
+		r.queueSave(pipe, request, string(marshaledRequest))
+		return nil
+	})
+	// pending request is changed only by its review or expiry
+	if errors.Is(err, errTxConflict) {
+		return domain.ErrUnlockRequestNotPending
+	}
+
+	return err
+}
+
+func (r *unlockRequestRepository) save(ctx context.Context, request domain.UnlockRequest) error {
+	marshaledRequest, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	return r.redisClient.Tx(ctx, func(pipe Pipe) {
+		r.queueSave(pipe, request, string(marshaledRequest))
+	})
+}
+
+func (r *unlockRequestRepository) queueSave(pipe Pipe, request domain.UnlockRequest, marshaledRequest string) {
+	pipe.Set(r.keyPrefix+unlockRequestKeyPrefix+request.ID, marshaledRequest, 0)
+	if request.Status == domain.UnlockRequestPending {
+		pipe.SAdd(r.keyPrefix+pendingUnlockRequests, request.ID)
+	} else {
+		pipe.SRem(r.keyPrefix+pendingUnlockRequests, request.ID)
+	}
+}
+
+func (r *unlockRequestRepository) FindPending(ctx context.Context) ([]domain.UnlockRequest, error) {
+	ids, err := r.redisClient.SMembers(ctx, r.keyPrefix+pendingUnlockRequests)
+	if err != nil {
+		return nil, err
+	}
+	pendingRequests := make([]domain.UnlockRequest, 0, len(ids))
+	for _, id := range ids {
+		request, err := r.Find(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if request != nil && request.Status == domain.UnlockRequestPending {
+			pendingRequests = append(pendingRequests, *request)
+		}
+	}
+
+	return pendingRequests, nil
+}
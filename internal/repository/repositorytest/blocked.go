@@ -0,0 +1,70 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+// TestBlockedAttemptRepository tests repositories returned by newRepository, every call must return repository of empty storage
+func TestBlockedAttemptRepository(t *testing.T, newRepository func(caseSensitiveKey bool) domain.BlockedAttemptRepository) {
+	pipeline := domain.PipelineIdentifier{
+		Project:     "Project",
+		Environment: "Staging",
+	}
+	newAttempt := func(clientIP string, attemptedAt time.Time) domain.BlockedAttempt {
+		return domain.BlockedAttempt{
+			PipelineIdentifier: pipeline,
+			Reason:             domain.CheckLocked,
+			ClientIP:           clientIP,
+			JobURL:             "https://ci.example/job/1",
+			User:               "alice",
+			AttemptedAt:        attemptedAt,
+		}
+	}
+	repository := newRepository(false)
+	lockedAt := time.Now().Add(-time.Hour)
+
+	t.Run("FindSince_nothingBlocked_returnsEmpty", func(t *testing.T) {
+		attempts, err := repository.FindSince(context.Background(), pipeline, lockedAt)
+
+		if err != nil || len(attempts) != 0 {
+			t.Errorf("Expected no attempts and nil error, got %v and %v", attempts, err)
+		}
+	})
+
+	// times are stored with microsecond precision
+	repository.Add(context.Background(), newAttempt("10.0.0.1", lockedAt.Add(-time.Minute).Truncate(time.Microsecond)))
+	repository.Add(context.Background(), newAttempt("10.0.0.2", lockedAt.Add(time.Minute).Truncate(time.Microsecond)))
+	lastAttemptedAt := time.Now().Truncate(time.Microsecond)
+	repository.Add(context.Background(), newAttempt("10.0.0.3", lastAttemptedAt))
+
+	t.Run("FindSince_attemptsBeforeAndAfterLock_returnsAttemptsAfterLockNewestFirst", func(t *testing.T) {
+		attempts, err := repository.FindSince(context.Background(), pipeline, lockedAt)
+
+		if err != nil || len(attempts) != 2 {
+			t.Fatalf("Expected 2 attempts and nil error, got %v and %v", attempts, err)
+		}
+		if attempts[0].ClientIP != "10.0.0.3" || attempts[1].ClientIP != "10.0.0.2" {
+			t.Errorf("Expected attempts newest first, got %v", attempts)
+		}
+		stored := attempts[0]
+		if !stored.AttemptedAt.Equal(lastAttemptedAt) {
+			t.Errorf("Expected attempted at %v, got %v", lastAttemptedAt, stored.AttemptedAt)
+		}
+		stored.AttemptedAt = lastAttemptedAt
+		if stored != newAttempt("10.0.0.3", lastAttemptedAt) {
+			t.Errorf("Expected stored attempt %v, got %v", newAttempt("10.0.0.3", lastAttemptedAt), stored)
+		}
+	})
+
+	t.Run("FindSince_otherPipeline_returnsEmpty", func(t *testing.T) {
+		attempts, _ := repository.FindSince(context.Background(), domain.PipelineIdentifier{Project: "Project", Environment: "Production"}, lockedAt)
+
+		if len(attempts) != 0 {
+			t.Errorf("Expected no attempts, got %v", attempts)
+		}
+	})
+}
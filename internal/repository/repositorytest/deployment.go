@@ -0,0 +1,120 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+// TestDeploymentRepository tests repositories returned by newRepository, every call must return repository of empty storage
+func TestDeploymentRepository(t *testing.T, newRepository func(caseSensitiveKey bool) domain.DeploymentRepository) {
+	pipeline := domain.PipelineIdentifier{
+		Project:     "Project",
+		Environment: "Staging",
+	}
+	newDeployment := func(version string, status domain.DeploymentStatus, deployedAt time.Time) domain.Deployment {
+		return domain.Deployment{
+			PipelineIdentifier: pipeline,
+			DeploymentDetails: domain.DeploymentDetails{
+				Version: version,
+			},
+			Status:     status,
+			DeployedAt: deployedAt,
+		}
+	}
+	repository := newRepository(true)
+
+	t.Run("FindByVersion_versionNotDeployed_returnsNil", func(t *testing.T) {
+		deployment, err := repository.FindByVersion(context.Background(), pipeline, "1.0.0")
+
+		if err != nil || deployment != nil {
+			t.Errorf("Expected nil deployment and error, got %v and %v", deployment, err)
+		}
+	})
+
+	// times are stored with microsecond precision
+	firstDeployedAt := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	lastDeployedAt := time.Now().Truncate(time.Microsecond)
+	repository.Add(context.Background(), newDeployment("1.0.0", domain.DeploymentSucceeded, firstDeployedAt))
+	repository.Add(context.Background(), newDeployment("1.1.0", domain.DeploymentFailed, firstDeployedAt))
+	repository.Add(context.Background(), newDeployment("1.0.0", domain.DeploymentSucceeded, lastDeployedAt))
+	repository.Add(context.Background(), newDeployment("1.2.0", domain.DeploymentStarted, lastDeployedAt))
+
+	t.Run("FindByVersion_versionDeployedTwice_returnsLatestDeployment", func(t *testing.T) {
+		deployment, _ := repository.FindByVersion(context.Background(), pipeline, "1.0.0")
+
+		if deployment == nil || !deployment.DeployedAt.Equal(lastDeployedAt) {
+			t.Errorf("Expected latest deployment, got %v", deployment)
+		}
+	})
+
+	t.Run("FindByVersion_versionDeploymentFailed_returnsNil", func(t *testing.T) {
+		deployment, _ := repository.FindByVersion(context.Background(), pipeline, "1.1.0")
+
+		if deployment != nil {
+			t.Errorf("Expected failed deployment not to be returned, got %v", deployment)
+		}
+	})
+
+	t.Run("FindByVersion_anotherPipeline_returnsNil", func(t *testing.T) {
+		deployment, _ := repository.FindByVersion(context.Background(), domain.PipelineIdentifier{
+			Project:     "Project",
+			Environment: "Production",
+		}, "1.0.0")
+
+		if deployment != nil {
+			t.Errorf("Expected nil deployment, got %v", deployment)
+		}
+	})
+
+	t.Run("FindHistory_pipelineHasDeployments_returnsNewestFirst", func(t *testing.T) {
+		history, _ := repository.FindHistory(context.Background(), pipeline)
+
+		if len(history) != 4 {
+			t.Fatalf("Expected 4 deployments, got %d", len(history))
+		}
+		if history[0].Version != "1.2.0" || history[3].Version != "1.0.0" {
+			t.Errorf("Expected newest deployment first, got %v", history)
+		}
+	})
+
+	t.Run("FindCurrent_pipelineHasDeployments_returnsLatestSuccessfulDeployment", func(t *testing.T) {
+		current, _ := repository.FindCurrent(context.Background())
+
+		if len(current) != 1 {
+			t.Fatalf("Expected one current deployment, got %d", len(current))
+		}
+		if current[0].Version != "1.0.0" || !current[0].DeployedAt.Equal(lastDeployedAt) {
+			t.Errorf("Expected latest successful deployment, got %v", current[0])
+		}
+	})
+
+	t.Run("Add_historySizeExceeded_keepsLatestDeployments", func(t *testing.T) {
+		repository := newRepository(true)
+		for i := 0; i < domain.DeploymentHistorySize+1; i++ {
+			repository.Add(context.Background(), newDeployment("1.0.0", domain.DeploymentSucceeded, time.Now()))
+		}
+
+		history, _ := repository.FindHistory(context.Background(), pipeline)
+
+		if len(history) != domain.DeploymentHistorySize {
+			t.Errorf("Expected history size %d, got %d", domain.DeploymentHistorySize, len(history))
+		}
+	})
+
+	t.Run("FindByVersion_pipelineKeyCaseInsensitive_returnsDeployment", func(t *testing.T) {
+		repository := newRepository(false)
+		repository.Add(context.Background(), newDeployment("1.0.0", domain.DeploymentSucceeded, time.Now()))
+
+		deployment, _ := repository.FindByVersion(context.Background(), domain.PipelineIdentifier{
+			Project:     "project",
+			Environment: "staging",
+		}, "1.0.0")
+
+		if deployment == nil {
+			t.Errorf("Expected repository to have caseInsensitive keys!")
+		}
+	})
+}
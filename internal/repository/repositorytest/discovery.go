@@ -0,0 +1,54 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+// TestPipelineDiscoveryRepository tests repositories returned by newRepository, every call must return repository of empty storage
+func TestPipelineDiscoveryRepository(t *testing.T, newRepository func(caseSensitiveKey bool) domain.PipelineDiscoveryRepository) {
+	pipeline := domain.PipelineIdentifier{
+		Project:     "Project",
+		Environment: "Staging",
+	}
+	repository := newRepository(false)
+
+	t.Run("FindAll_nothingChecked_returnsEmpty", func(t *testing.T) {
+		pipelines, err := repository.FindAll(context.Background())
+
+		if err != nil || len(pipelines) != 0 {
+			t.Errorf("Expected no pipelines and nil error, got %v and %v", pipelines, err)
+		}
+	})
+
+	// times are stored with microsecond precision
+	firstSeen := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	lastSeen := time.Now().Truncate(time.Microsecond)
+	repository.Record(context.Background(), domain.PipelineCheck{PipelineIdentifier: pipeline, Outcome: domain.CheckAllowed, CheckedAt: firstSeen})
+	repository.Record(context.Background(), domain.PipelineCheck{
+		PipelineIdentifier: domain.PipelineIdentifier{Project: "project", Environment: "staging"},
+		Outcome:            domain.CheckLocked,
+		CheckedAt:          lastSeen,
+	})
+
+	t.Run("FindAll_pipelineCheckedTwice_returnsCountedPipeline", func(t *testing.T) {
+		pipelines, err := repository.FindAll(context.Background())
+
+		if err != nil || len(pipelines) != 1 {
+			t.Fatalf("Expected single pipeline and nil error, got %v and %v", pipelines, err)
+		}
+		known := pipelines[0]
+		if known.Checks != 2 || known.LastOutcome != domain.CheckLocked {
+			t.Errorf("Expected 2 checks with last outcome locked, got %d and %s", known.Checks, known.LastOutcome)
+		}
+		if !known.FirstSeen.Equal(firstSeen) || !known.LastSeen.Equal(lastSeen) {
+			t.Errorf("Expected seen between %v and %v, got %v and %v", firstSeen, lastSeen, known.FirstSeen, known.LastSeen)
+		}
+		if known.Project != "project" || known.Environment != "staging" {
+			t.Errorf("Expected identifier of last check, got %s", known.Name())
+		}
+	})
+}
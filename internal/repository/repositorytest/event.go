@@ -0,0 +1,80 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+// TestPipelineEventRepository tests repositories returned by newRepository, every call must return repository of empty storage
+func TestPipelineEventRepository(t *testing.T, newRepository func(caseSensitiveKey bool) domain.PipelineEventRepository) {
+	pipeline := domain.PipelineIdentifier{
+		Project:     "Project",
+		Environment: "Staging",
+	}
+	newEvent := func(eventType domain.PipelineEventType, occurredAt time.Time) domain.PipelineEvent {
+		return domain.PipelineEvent{
+			PipelineIdentifier: pipeline,
+			Type:               eventType,
+			Actor:              "user",
+			OccurredAt:         occurredAt,
+		}
+	}
+
+	t.Run("FindByPipeline_noEvents_returnsEmptySlice", func(t *testing.T) {
+		repository := newRepository(true)
+
+		events, err := repository.FindByPipeline(context.Background(), pipeline)
+
+		if err != nil || events == nil || len(events) != 0 {
+			t.Errorf("Expected empty slice and nil error, got %v and %v", events, err)
+		}
+	})
+
+	t.Run("FindByPipeline_eventsAdded_returnsNewestFirst", func(t *testing.T) {
+		repository := newRepository(true)
+		repository.Add(context.Background(), newEvent(domain.PipelineLockedEvent, time.Now().Add(-time.Hour)))
+		repository.Add(context.Background(), newEvent(domain.PipelineUnlockedEvent, time.Now()))
+
+		events, _ := repository.FindByPipeline(context.Background(), pipeline)
+
+		if len(events) != 2 || events[0].Type != domain.PipelineUnlockedEvent {
+			t.Errorf("Expected two events newest first, got %v", events)
+		}
+	})
+
+	t.Run("Add_historyFull_dropsOldestEvents", func(t *testing.T) {
+		repository := newRepository(true)
+		repository.Add(context.Background(), newEvent(domain.PipelineLockedEvent, time.Now()))
+		for i := 0; i < domain.PipelineHistorySize; i++ {
+			repository.Add(context.Background(), newEvent(domain.PipelineUnlockedEvent, time.Now()))
+		}
+
+		events, _ := repository.FindByPipeline(context.Background(), pipeline)
+
+		if len(events) != domain.PipelineHistorySize {
+			t.Errorf("Expected %d events, got %d", domain.PipelineHistorySize, len(events))
+		}
+		for _, event := range events {
+			if event.Type == domain.PipelineLockedEvent {
+				t.Errorf("Expected oldest event to be dropped")
+			}
+		}
+	})
+
+	t.Run("FindByPipeline_pipelineKeyCaseInsensitive_returnsEvents", func(t *testing.T) {
+		repository := newRepository(false)
+		repository.Add(context.Background(), newEvent(domain.PipelineLockedEvent, time.Now()))
+
+		events, _ := repository.FindByPipeline(context.Background(), domain.PipelineIdentifier{
+			Project:     "project",
+			Environment: "staging",
+		})
+
+		if len(events) != 1 {
+			t.Errorf("Expected repository to match pipeline case insensitively!")
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+// UnlockPipelineRepository is pipeline repository able to unlock pipeline atomically
+type UnlockPipelineRepository interface {
+	domain.PipelineRepository
+	domain.PipelineUnlockRepository
+}
+
+// TestPipelineRepositoryUnlockIfLocked tests repository returned by newRepository, the repository must be empty
+func TestPipelineRepositoryUnlockIfLocked(t *testing.T, newRepository func(caseSensitiveKey bool) UnlockPipelineRepository) {
+	repository := newRepository(true)
+	lock := domain.Pipeline{
+		PipelineIdentifier: domain.PipelineIdentifier{
+			Project:     "project",
+			Environment: "environment",
+		},
+		PipelineLockedBy: domain.PipelineLockedBy{
+			LockedBy: "user",
+		},
+		// storages keep lock time in microseconds
+		PipelineLockedAt: domain.PipelineLockedAt{LockedAt: time.Now().Truncate(time.Microsecond)},
+	}
+
+	t.Run("UnlockIfLocked_pipelineNotExists_returnsFalse", func(t *testing.T) {
+		unlocked, err := repository.UnlockIfLocked(context.Background(), lock)
+		if unlocked || err != nil {
+			t.Errorf("Expected pipeline not to be unlocked, got %t and %v", unlocked, err)
+		}
+	})
+
+	t.Run("UnlockIfLocked_pipelineLockedAgain_returnsFalse", func(t *testing.T) {
+		anotherLock := lock
+		anotherLock.LockedAt = lock.LockedAt.Add(time.Minute)
+		repository.Add(context.Background(), anotherLock)
+
+		unlocked, err := repository.UnlockIfLocked(context.Background(), lock)
+		if unlocked || err != nil {
+			t.Errorf("Expected pipeline not to be unlocked, got %t and %v", unlocked, err)
+		}
+		stored, _ := repository.Find(context.Background(), lock.PipelineIdentifier)
+		if stored == nil || !stored.HasLock(anotherLock) {
+			t.Errorf("Expected lock taken later to be kept, got %v", stored)
+		}
+	})
+
+	t.Run("UnlockIfLocked_pipelineHasLock_unlocksPipeline", func(t *testing.T) {
+		repository.Add(context.Background(), lock)
+
+		unlocked, err := repository.UnlockIfLocked(context.Background(), lock)
+		if !unlocked || err != nil {
+			t.Errorf("Expected pipeline to be unlocked, got %t and %v", unlocked, err)
+		}
+		stored, _ := repository.Find(context.Background(), lock.PipelineIdentifier)
+		if stored == nil || stored.LockedBy != "" {
+			t.Errorf("Expected pipeline to be unlocked, got %v", stored)
+		}
+	})
+}
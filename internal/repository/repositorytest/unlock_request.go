@@ -0,0 +1,93 @@
+// Package repositorytest holds tests every storage backend runs against its repositories
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+// TestUnlockRequestRepository tests repository returned by newRepository, the repository must be empty
+func TestUnlockRequestRepository(t *testing.T, newRepository func() domain.UnlockRequestRepository) {
+	repository := newRepository()
+	request := domain.UnlockRequest{
+		ID: "id",
+		PipelineIdentifier: domain.PipelineIdentifier{
+			Project:     "project",
+			Environment: "production",
+		},
+		LockedBy:    "locker",
+		LockedAt:    time.Now().Truncate(time.Microsecond),
+		RequestedBy: "user",
+		Status:      domain.UnlockRequestPending,
+	}
+
+	t.Run("Find_requestNotExists_returnsNil", func(t *testing.T) {
+		found, err := repository.Find(context.Background(), request.ID)
+
+		if err != nil || found != nil {
+			t.Errorf("Expected nil request and error, got %v and %v", found, err)
+		}
+	})
+
+	t.Run("Update_requestNotExists_returnsNotFoundError", func(t *testing.T) {
+		err := repository.Update(context.Background(), request)
+
+		if !errors.Is(err, domain.ErrUnlockRequestNotFound) {
+			t.Errorf("Expected %v, got %v", domain.ErrUnlockRequestNotFound, err)
+		}
+	})
+
+	t.Run("Add_requestAdded_findReturnsRequest", func(t *testing.T) {
+		repository.Add(context.Background(), request)
+
+		found, _ := repository.Find(context.Background(), request.ID)
+
+		if found == nil || found.RequestedBy != request.RequestedBy || found.LockedBy != request.LockedBy || !found.LockedAt.Equal(request.LockedAt) {
+			t.Errorf("Expected request %v, got %v", request, found)
+		}
+	})
+
+	t.Run("FindPending_pendingRequestExists_returnsSliceOfOneRequest", func(t *testing.T) {
+		pending, _ := repository.FindPending(context.Background())
+
+		if len(pending) != 1 {
+			t.Errorf("Expected one pending request, got %d", len(pending))
+		}
+	})
+
+	t.Run("Update_requestApproved_findPendingReturnsEmptySlice", func(t *testing.T) {
+		approved := request
+		approved.Status = domain.UnlockRequestApproved
+		if err := repository.Update(context.Background(), approved); err != nil {
+			t.Errorf("Expected nil error, got %v", err)
+		}
+
+		pending, _ := repository.FindPending(context.Background())
+
+		if pending == nil || len(pending) != 0 {
+			t.Errorf("Expected empty slice, got %v", pending)
+		}
+		found, _ := repository.Find(context.Background(), request.ID)
+		if found == nil || found.Status != domain.UnlockRequestApproved {
+			t.Errorf("Expected request to be approved, got %v", found)
+		}
+	})
+	t.Run("Update_requestNotPending_returnsNotPendingError", func(t *testing.T) {
+		rejected := request
+		rejected.Status = domain.UnlockRequestRejected
+
+		err := repository.Update(context.Background(), rejected)
+
+		if !errors.Is(err, domain.ErrUnlockRequestNotPending) {
+			t.Errorf("Expected %v, got %v", domain.ErrUnlockRequestNotPending, err)
+		}
+		found, _ := repository.Find(context.Background(), request.ID)
+		if found == nil || found.Status != domain.UnlockRequestApproved {
+			t.Errorf("Expected request to stay approved, got %v", found)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type blockedAttemptRepository struct {
+	db               *sql.DB
+	caseSensitiveKey bool
+}
+
+func NewBlockedAttemptRepository(db *sql.DB, caseSensitiveKey bool) *blockedAttemptRepository {
+	return &blockedAttemptRepository{
+		db:               db,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *blockedAttemptRepository) Add(ctx context.Context, attempt domain.BlockedAttempt) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO blocked_attempts (pipeline_key, project, environment, region, cluster, component, reason, client_ip, job_url, user_name, attempted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		attempt.PipelineIdentifier.GetKey(r.caseSensitiveKey), attempt.Project, attempt.Environment, attempt.Region, attempt.Cluster, attempt.Component, string(attempt.Reason), attempt.ClientIP, attempt.JobURL, attempt.User, toUnix(attempt.AttemptedAt))
+
+	return err
+}
+
+func (r *blockedAttemptRepository) FindSince(ctx context.Context, pipeline domain.PipelineIdentifier, since time.Time) ([]domain.BlockedAttempt, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT project, environment, region, cluster, component, reason, client_ip, job_url, user_name, attempted_at
+		FROM blocked_attempts WHERE pipeline_key = ? AND attempted_at >= ? ORDER BY id DESC LIMIT ?`,
+		pipeline.GetKey(r.caseSensitiveKey), toUnix(since), domain.BlockedAttemptHistorySize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	attempts := make([]domain.BlockedAttempt, 0)
+	for rows.Next() {
+		var attempt domain.BlockedAttempt
+		var attemptedAt int64
+		if err = rows.Scan(&attempt.Project, &attempt.Environment, &attempt.Region, &attempt.Cluster, &attempt.Component, &attempt.Reason, &attempt.ClientIP, &attempt.JobURL, &attempt.User, &attemptedAt); err != nil {
+			return nil, err
+		}
+		attempt.AttemptedAt = fromUnix(attemptedAt)
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, rows.Err()
+}
@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+const deploymentColumns = "project, environment, region, cluster, component, version, commit_sha, actor, job_url, status, deployed_at"
+
+// deploymentRepository keeps every reported deployment, history queries return latest domain.DeploymentHistorySize of them
+type deploymentRepository struct {
+	db               *sql.DB
+	caseSensitiveKey bool
+}
+
+func NewDeploymentRepository(db *sql.DB, caseSensitiveKey bool) *deploymentRepository {
+	return &deploymentRepository{
+		db:               db,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *deploymentRepository) Add(ctx context.Context, deployment domain.Deployment) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO deployments (pipeline_key, "+deploymentColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		deployment.PipelineIdentifier.GetKey(r.caseSensitiveKey), deployment.Project, deployment.Environment, deployment.Region, deployment.Cluster, deployment.Component, deployment.Version,
+		deployment.Commit, deployment.Actor, deployment.JobURL, string(deployment.Status), toUnix(deployment.DeployedAt))
+
+	return err
+}
+
+func (r *deploymentRepository) FindByVersion(ctx context.Context, pipeline domain.PipelineIdentifier, version string) (*domain.Deployment, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+deploymentColumns+" FROM deployments WHERE pipeline_key = ? AND version = ? AND status = ? ORDER BY id DESC LIMIT 1",
+		pipeline.GetKey(r.caseSensitiveKey), version, string(domain.DeploymentSucceeded))
+	deployment, err := scanDeployment(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return deployment, err
+}
+
+func (r *deploymentRepository) FindHistory(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.Deployment, error) {
+	return r.query(ctx, "SELECT "+deploymentColumns+" FROM deployments WHERE pipeline_key = ? ORDER BY id DESC LIMIT ?",
+		pipeline.GetKey(r.caseSensitiveKey), domain.DeploymentHistorySize)
+}
+
+func (r *deploymentRepository) FindCurrent(ctx context.Context) ([]domain.Deployment, error) {
+	return r.query(ctx, "SELECT "+deploymentColumns+" FROM deployments WHERE id IN (SELECT MAX(id) FROM deployments WHERE status = ? GROUP BY pipeline_key)",
+		string(domain.DeploymentSucceeded))
+}
+
+func (r *deploymentRepository) query(ctx context.Context, query string, args ...interface{}) ([]domain.Deployment, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	deployments := make([]domain.Deployment, 0)
+	for rows.Next() {
+		deployment, err := scanDeployment(rows)
+		if err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, *deployment)
+	}
+
+	return deployments, rows.Err()
+}
+
+func scanDeployment(row scanner) (*domain.Deployment, error) {
+	var deployment domain.Deployment
+	var deployedAt int64
+	if err := row.Scan(&deployment.Project, &deployment.Environment, &deployment.Region, &deployment.Cluster, &deployment.Component, &deployment.Version, &deployment.Commit, &deployment.Actor, &deployment.JobURL, &deployment.Status, &deployedAt); err != nil {
+		return nil, err
+	}
+	deployment.DeployedAt = fromUnix(deployedAt)
+
+	return &deployment, nil
+}
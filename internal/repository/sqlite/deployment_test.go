@@ -0,0 +1,14 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/repository/repositorytest"
+)
+
+func TestDeploymentRepository(t *testing.T) {
+	repositorytest.TestDeploymentRepository(t, func(caseSensitiveKey bool) domain.DeploymentRepository {
+		return NewDeploymentRepository(openTestDatabase(t), caseSensitiveKey)
+	})
+}
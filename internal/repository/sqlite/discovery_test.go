@@ -0,0 +1,14 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/repository/repositorytest"
+)
+
+func TestPipelineDiscoveryRepository(t *testing.T) {
+	repositorytest.TestPipelineDiscoveryRepository(t, func(caseSensitiveKey bool) domain.PipelineDiscoveryRepository {
+		return NewPipelineDiscoveryRepository(openTestDatabase(t), caseSensitiveKey)
+	})
+}
@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type pipelineEventRepository struct {
+	db               *sql.DB
+	caseSensitiveKey bool
+}
+
+func NewPipelineEventRepository(db *sql.DB, caseSensitiveKey bool) *pipelineEventRepository {
+	return &pipelineEventRepository{
+		db:               db,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *pipelineEventRepository) Add(ctx context.Context, event domain.PipelineEvent) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO pipeline_events (pipeline_key, project, environment, region, cluster, component, type, actor, details, url, occurred_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		event.PipelineIdentifier.GetKey(r.caseSensitiveKey), event.Project, event.Environment, event.Region, event.Cluster, event.Component, string(event.Type), event.Actor, event.Details, event.URL, toUnix(event.OccurredAt))
+
+	return err
+}
+
+func (r *pipelineEventRepository) FindByPipeline(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.PipelineEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT project, environment, region, cluster, component, type, actor, details, url, occurred_at
+		FROM pipeline_events WHERE pipeline_key = ? ORDER BY id DESC LIMIT ?`,
+		pipeline.GetKey(r.caseSensitiveKey), domain.PipelineHistorySize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	events := make([]domain.PipelineEvent, 0)
+	for rows.Next() {
+		var event domain.PipelineEvent
+		var occurredAt int64
+		if err = rows.Scan(&event.Project, &event.Environment, &event.Region, &event.Cluster, &event.Component, &event.Type, &event.Actor, &event.Details, &event.URL, &occurredAt); err != nil {
+			return nil, err
+		}
+		event.OccurredAt = fromUnix(occurredAt)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,14 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/repository/repositorytest"
+)
+
+func TestPipelineEventRepository(t *testing.T) {
+	repositorytest.TestPipelineEventRepository(t, func(caseSensitiveKey bool) domain.PipelineEventRepository {
+		return NewPipelineEventRepository(openTestDatabase(t), caseSensitiveKey)
+	})
+}
@@ -0,0 +1,70 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+// keyedTables hold pipeline_key column next to project and environment it was computed from
+var keyedTables = []string{"pipelines", "pipeline_events", "deployments", "override_tokens"}
+
+type staleKey struct {
+	stored string
+	domain.PipelineIdentifier
+}
+
+// MigrateKeys rewrites pipeline keys of every table stored under other key encoding or case sensitivity to
+// current keys, keys of pipelines in keep are left as they are. When several stored pipelines get the same
+// key, pipeline already stored under it is kept and history of both is kept. Returns number of rewritten keys.
+func (r *pipelineRepository) MigrateKeys(keep map[domain.PipelineIdentifier]bool) (int, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	migrated := 0
+	for _, table := range keyedTables {
+		keys, err := findStaleKeys(ctx, tx, table, keep, r.caseSensitiveKey)
+		if err != nil {
+			return 0, err
+		}
+		for _, key := range keys {
+			current := key.GetKey(r.caseSensitiveKey)
+			if table == "pipelines" {
+				if _, err = tx.ExecContext(ctx, "DELETE FROM pipelines WHERE pipeline_key = ? AND EXISTS (SELECT 1 FROM pipelines WHERE pipeline_key = ?)", key.stored, current); err != nil {
+					return 0, err
+				}
+			}
+			if _, err = tx.ExecContext(ctx, "UPDATE "+table+" SET pipeline_key = ? WHERE pipeline_key = ? AND project = ? AND environment = ? AND region = ? AND cluster = ? AND component = ?",
+				current, key.stored, key.Project, key.Environment, key.Region, key.Cluster, key.Component); err != nil {
+				return 0, err
+			}
+			migrated++
+		}
+	}
+
+	return migrated, tx.Commit()
+}
+
+func findStaleKeys(ctx context.Context, tx *sql.Tx, table string, keep map[domain.PipelineIdentifier]bool, caseSensitiveKey bool) ([]staleKey, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT DISTINCT pipeline_key, project, environment, region, cluster, component FROM "+table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	keys := make([]staleKey, 0)
+	for rows.Next() {
+		var key staleKey
+		if err = rows.Scan(&key.stored, &key.Project, &key.Environment, &key.Region, &key.Cluster, &key.Component); err != nil {
+			return nil, err
+		}
+		if key.stored != key.GetKey(caseSensitiveKey) && !keep[key.PipelineIdentifier] {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, rows.Err()
+}
@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+func TestPipelineRepository_MigrateKeys(t *testing.T) {
+	db := openTestDatabase(t)
+	repository := NewPipelineRepository(db, false)
+	colliding := []domain.PipelineIdentifier{{Project: "a:b", Environment: "c"}, {Project: "a", Environment: "b:c"}}
+	folded := domain.PipelineIdentifier{Project: "Straße", Environment: "prod"}
+	for _, pipeline := range []domain.PipelineIdentifier{colliding[0], folded} {
+		db.Exec("INSERT INTO pipelines (pipeline_key, project, environment, locked_by, locked_at) VALUES (?, ?, ?, 'user', ?)",
+			pipeline.LegacyKey(false), pipeline.Project, pipeline.Environment, toUnix(time.Now()))
+	}
+	for _, pipeline := range colliding {
+		db.Exec("INSERT INTO pipeline_events (pipeline_key, project, environment, type, occurred_at) VALUES (?, ?, ?, ?, ?)",
+			pipeline.LegacyKey(false), pipeline.Project, pipeline.Environment, string(domain.PipelineLockedEvent), toUnix(time.Now()))
+	}
+
+	migrated, err := repository.MigrateKeys(nil)
+
+	if err != nil || migrated != 4 {
+		t.Fatalf("Expected 4 migrated keys and nil error, got %d and %v", migrated, err)
+	}
+	if pipeline, _ := repository.Find(context.Background(), domain.PipelineIdentifier{Project: "STRASSE", Environment: "PROD"}); pipeline == nil || pipeline.Project != folded.Project {
+		t.Errorf("Expected pipeline %v to be found by case folded key, got %v", folded, pipeline)
+	}
+	events := NewPipelineEventRepository(db, false)
+	for _, pipeline := range colliding {
+		if history, _ := events.FindByPipeline(context.Background(), pipeline); len(history) != 1 || history[0].PipelineIdentifier != pipeline {
+			t.Errorf("Expected only own event of pipeline %v, got %v", pipeline, history)
+		}
+	}
+	if migrated, _ = repository.MigrateKeys(nil); migrated != 0 {
+		t.Errorf("Expected nothing to migrate again, got %d", migrated)
+	}
+}
+
+func TestPipelineRepository_MigrateKeysOfChangedCaseSensitivity(t *testing.T) {
+	db := openTestDatabase(t)
+	caseSensitiveRepository := NewPipelineRepository(db, true)
+	stale := domain.Pipeline{PipelineIdentifier: domain.PipelineIdentifier{Project: "Api", Environment: "prod"}, PipelineLockedBy: domain.PipelineLockedBy{LockedBy: "alice"}}
+	current := domain.Pipeline{PipelineIdentifier: domain.PipelineIdentifier{Project: "api", Environment: "prod"}, PipelineLockedBy: domain.PipelineLockedBy{LockedBy: "bob"}}
+	for _, pipeline := range []domain.Pipeline{stale, current} {
+		caseSensitiveRepository.Add(context.Background(), pipeline)
+	}
+	repository := NewPipelineRepository(db, false)
+	countPipelines := func() (count int) {
+		db.QueryRow("SELECT COUNT(*) FROM pipelines").Scan(&count)
+		return count
+	}
+
+	t.Run("keptPipeline_leftUnderStoredKey", func(t *testing.T) {
+		migrated, err := repository.MigrateKeys(map[domain.PipelineIdentifier]bool{stale.PipelineIdentifier: true, current.PipelineIdentifier: true})
+
+		if err != nil || migrated != 0 {
+			t.Fatalf("Expected 0 migrated keys and nil error, got %d and %v", migrated, err)
+		}
+		if count := countPipelines(); count != 2 {
+			t.Errorf("Expected both pipelines to be kept, got %d", count)
+		}
+	})
+
+	t.Run("notKeptPipeline_droppedForPipelineStoredUnderKey", func(t *testing.T) {
+		migrated, err := repository.MigrateKeys(nil)
+
+		if err != nil || migrated != 1 {
+			t.Fatalf("Expected 1 migrated key and nil error, got %d and %v", migrated, err)
+		}
+		if count := countPipelines(); count != 1 {
+			t.Errorf("Expected one pipeline, got %d", count)
+		}
+		if pipeline, _ := repository.Find(context.Background(), domain.PipelineIdentifier{Project: "API", Environment: "PROD"}); pipeline == nil || pipeline.LockedBy != current.LockedBy {
+			t.Errorf("Expected pipeline %v stored under key to be kept, got %v", current, pipeline)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+-- times are stored as unix microseconds
+CREATE TABLE pipelines (
+    pipeline_key TEXT PRIMARY KEY,
+    project      TEXT NOT NULL,
+    environment  TEXT NOT NULL,
+    locked_by    TEXT NOT NULL DEFAULT '',
+    locked_at    INTEGER NOT NULL,
+    locked_until INTEGER
+);
+
+CREATE TABLE override_tokens (
+    token        TEXT PRIMARY KEY,
+    pipeline_key TEXT NOT NULL,
+    project      TEXT NOT NULL,
+    environment  TEXT NOT NULL,
+    issued_by    TEXT NOT NULL,
+    issued_at    INTEGER NOT NULL,
+    expires_at   INTEGER NOT NULL
+);
+
+CREATE INDEX override_tokens_expires_at_idx ON override_tokens (expires_at);
+
+CREATE TABLE unlock_requests (
+    id           TEXT PRIMARY KEY,
+    project      TEXT NOT NULL,
+    environment  TEXT NOT NULL,
+    requested_by TEXT NOT NULL,
+    requested_at INTEGER NOT NULL,
+    expires_at   INTEGER NOT NULL,
+    status       TEXT NOT NULL,
+    reviewed_by  TEXT NOT NULL DEFAULT '',
+    reviewed_at  INTEGER NOT NULL
+);
+
+CREATE INDEX unlock_requests_status_idx ON unlock_requests (status);
+
+CREATE TABLE deployments (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    pipeline_key TEXT NOT NULL,
+    project      TEXT NOT NULL,
+    environment  TEXT NOT NULL,
+    version      TEXT NOT NULL,
+    commit_sha   TEXT NOT NULL DEFAULT '',
+    actor        TEXT NOT NULL DEFAULT '',
+    job_url      TEXT NOT NULL DEFAULT '',
+    status       TEXT NOT NULL,
+    deployed_at  INTEGER NOT NULL
+);
+
+CREATE INDEX deployments_pipeline_key_idx ON deployments (pipeline_key, id);
+
+CREATE TABLE pipeline_events (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    pipeline_key TEXT NOT NULL,
+    project      TEXT NOT NULL,
+    environment  TEXT NOT NULL,
+    type         TEXT NOT NULL,
+    actor        TEXT NOT NULL DEFAULT '',
+    details      TEXT NOT NULL DEFAULT '',
+    url          TEXT NOT NULL DEFAULT '',
+    occurred_at  INTEGER NOT NULL
+);
+
+CREATE INDEX pipeline_events_pipeline_key_idx ON pipeline_events (pipeline_key, id);
@@ -0,0 +1,20 @@
+-- scope dimensions of pipelines created before them are empty
+ALTER TABLE pipelines ADD COLUMN region TEXT NOT NULL DEFAULT '';
+ALTER TABLE pipelines ADD COLUMN cluster TEXT NOT NULL DEFAULT '';
+ALTER TABLE pipelines ADD COLUMN component TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE override_tokens ADD COLUMN region TEXT NOT NULL DEFAULT '';
+ALTER TABLE override_tokens ADD COLUMN cluster TEXT NOT NULL DEFAULT '';
+ALTER TABLE override_tokens ADD COLUMN component TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE unlock_requests ADD COLUMN region TEXT NOT NULL DEFAULT '';
+ALTER TABLE unlock_requests ADD COLUMN cluster TEXT NOT NULL DEFAULT '';
+ALTER TABLE unlock_requests ADD COLUMN component TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE deployments ADD COLUMN region TEXT NOT NULL DEFAULT '';
+ALTER TABLE deployments ADD COLUMN cluster TEXT NOT NULL DEFAULT '';
+ALTER TABLE deployments ADD COLUMN component TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE pipeline_events ADD COLUMN region TEXT NOT NULL DEFAULT '';
+ALTER TABLE pipeline_events ADD COLUMN cluster TEXT NOT NULL DEFAULT '';
+ALTER TABLE pipeline_events ADD COLUMN component TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,17 @@
+-- deploy status checks answered with 423 Locked
+CREATE TABLE blocked_attempts (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    pipeline_key TEXT NOT NULL,
+    project      TEXT NOT NULL,
+    environment  TEXT NOT NULL,
+    region       TEXT NOT NULL DEFAULT '',
+    cluster      TEXT NOT NULL DEFAULT '',
+    component    TEXT NOT NULL DEFAULT '',
+    reason       TEXT NOT NULL,
+    client_ip    TEXT NOT NULL DEFAULT '',
+    job_url      TEXT NOT NULL DEFAULT '',
+    user_name    TEXT NOT NULL DEFAULT '',
+    attempted_at INTEGER NOT NULL
+);
+
+CREATE INDEX blocked_attempts_pipeline_key_idx ON blocked_attempts (pipeline_key, id);
@@ -0,0 +1,3 @@
+-- lock unlock is requested for, requests created before it can't be approved
+ALTER TABLE unlock_requests ADD COLUMN locked_by TEXT NOT NULL DEFAULT '';
+ALTER TABLE unlock_requests ADD COLUMN locked_at INTEGER NOT NULL DEFAULT 0;
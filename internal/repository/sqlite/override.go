@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type overrideTokenRepository struct {
+	db               *sql.DB
+	caseSensitiveKey bool
+}
+
+func NewOverrideTokenRepository(db *sql.DB, caseSensitiveKey bool) *overrideTokenRepository {
+	return &overrideTokenRepository{
+		db:               db,
+		caseSensitiveKey: caseSensitiveKey,
+	}
+}
+
+func (r *overrideTokenRepository) Add(ctx context.Context, token domain.OverrideToken) error {
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM override_tokens WHERE expires_at <= ?", toUnix(time.Now())); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `INSERT INTO override_tokens (token, pipeline_key, project, environment, region, cluster, component, issued_by, issued_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		token.Token, token.GetKey(r.caseSensitiveKey), token.Project, token.Environment, token.Region, token.Cluster, token.Component, token.IssuedBy, toUnix(token.IssuedAt), toUnix(token.ExpiresAt))
+
+	return err
+}
+
+// Consume deletes token bound to given pipeline, only the caller that actually deletes the row may use the token
+func (r *overrideTokenRepository) Consume(ctx context.Context, pipeline domain.PipelineIdentifier, token string) (*domain.OverrideToken, error) {
+	consumed := domain.OverrideToken{
+		Token: token,
+	}
+	var issuedAt, expiresAt int64
+	err := r.db.QueryRowContext(ctx, `DELETE FROM override_tokens WHERE token = ? AND pipeline_key = ?
+		RETURNING project, environment, region, cluster, component, issued_by, issued_at, expires_at`,
+		token, pipeline.GetKey(r.caseSensitiveKey),
+	).Scan(&consumed.Project, &consumed.Environment, &consumed.Region, &consumed.Cluster, &consumed.Component, &consumed.IssuedBy, &issuedAt, &expiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	consumed.IssuedAt = fromUnix(issuedAt)
+	consumed.ExpiresAt = fromUnix(expiresAt)
+	if consumed.IsExpired(time.Now()) {
+		return nil, nil
+	}
+
+	return &consumed, nil
+}
@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+func TestOverrideTokenRepository(t *testing.T) {
+	const token = "token"
+	pipeline := domain.PipelineIdentifier{
+		Project:     "Project",
+		Environment: "Environment",
+	}
+	otherPipeline := domain.PipelineIdentifier{
+		Project:     "Project",
+		Environment: "dev",
+	}
+	newToken := func(value string, expiresAt time.Time) domain.OverrideToken {
+		return domain.OverrideToken{
+			PipelineIdentifier: pipeline,
+			Token:              value,
+			IssuedBy:           "user",
+			IssuedAt:           time.Now(),
+			ExpiresAt:          expiresAt,
+		}
+	}
+
+	t.Run("Consume_tokenNotExists_returnsNil", func(t *testing.T) {
+		repository := NewOverrideTokenRepository(openTestDatabase(t), true)
+
+		consumed, err := repository.Consume(context.Background(), pipeline, token)
+
+		if err != nil || consumed != nil {
+			t.Errorf("Expected nil token and error, got %v and %v", consumed, err)
+		}
+	})
+
+	t.Run("Consume_tokenBoundToAnotherPipeline_returnsNilAndKeepsToken", func(t *testing.T) {
+		repository := NewOverrideTokenRepository(openTestDatabase(t), true)
+		repository.Add(context.Background(), newToken(token, time.Now().Add(time.Minute)))
+
+		consumed, _ := repository.Consume(context.Background(), otherPipeline, token)
+
+		if consumed != nil {
+			t.Errorf("Expected nil token, got %v", consumed)
+		}
+		if consumed, _ = repository.Consume(context.Background(), pipeline, token); consumed == nil {
+			t.Errorf("Expected token to remain in store")
+		}
+	})
+
+	t.Run("Consume_tokenExists_returnsTokenOnlyOnce", func(t *testing.T) {
+		repository := NewOverrideTokenRepository(openTestDatabase(t), true)
+		if err := repository.Add(context.Background(), newToken(token, time.Now().Add(time.Minute))); err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+
+		consumed, _ := repository.Consume(context.Background(), pipeline, token)
+		if consumed == nil || consumed.Token != token || consumed.IssuedBy != "user" {
+			t.Errorf("Expected token %s to be consumed, got %v", token, consumed)
+		}
+		consumed, _ = repository.Consume(context.Background(), pipeline, token)
+		if consumed != nil {
+			t.Errorf("Expected token to be consumed only once, got %v", consumed)
+		}
+	})
+
+	t.Run("Consume_tokenExpired_returnsNil", func(t *testing.T) {
+		db := openTestDatabase(t)
+		repository := NewOverrideTokenRepository(db, true)
+		expired := newToken(token, time.Now().Add(-time.Minute))
+		db.Exec("INSERT INTO override_tokens (token, pipeline_key, project, environment, issued_by, issued_at, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			expired.Token, expired.GetKey(true), expired.Project, expired.Environment, expired.IssuedBy, toUnix(expired.IssuedAt), toUnix(expired.ExpiresAt))
+
+		consumed, _ := repository.Consume(context.Background(), pipeline, token)
+
+		if consumed != nil {
+			t.Errorf("Expected expired token not to be returned, got %v", consumed)
+		}
+	})
+
+	t.Run("Consume_pipelineKeyCaseInsensitive_returnsToken", func(t *testing.T) {
+		repository := NewOverrideTokenRepository(openTestDatabase(t), false)
+		repository.Add(context.Background(), newToken(token, time.Now().Add(time.Minute)))
+
+		consumed, _ := repository.Consume(context.Background(), domain.PipelineIdentifier{
+			Project:     "project",
+			Environment: "environment",
+		}, token)
+
+		if consumed == nil {
+			t.Errorf("Expected repository to match pipeline case insensitively!")
+		}
+	})
+
+	t.Run("Add_expiredTokensInStore_removesExpiredTokens", func(t *testing.T) {
+		db := openTestDatabase(t)
+		repository := NewOverrideTokenRepository(db, true)
+		db.Exec("INSERT INTO override_tokens (token, pipeline_key, project, environment, issued_by, issued_at, expires_at) VALUES ('expired', '', '', '', '', 0, ?)",
+			toUnix(time.Now().Add(-time.Minute)))
+
+		repository.Add(context.Background(), newToken(token, time.Now().Add(time.Minute)))
+
+		var count int
+		db.QueryRow("SELECT COUNT(*) FROM override_tokens").Scan(&count)
+		if count != 1 {
+			t.Errorf("Expected store size 1, got %d", count)
+		}
+	})
+}
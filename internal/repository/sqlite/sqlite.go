@@ -0,0 +1,139 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	_ "modernc.org/sqlite"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Open opens database file at given path, creating it if needed, and applies schema migrations
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// sqlite allows single writer, sharing one connection avoids SQLITE_BUSY errors
+	db.SetMaxOpenConns(1)
+	if err = Migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// OpenMigrated opens existing database file at given path read-only, returns domain.ErrStorageNotInitialized
+// when the file is missing or schema migrations are pending
+func OpenMigrated(path string) (*sql.DB, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w, file %s does not exist", domain.ErrStorageNotInitialized, path)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	pending, err := PendingMigrations(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if len(pending) > 0 {
+		db.Close()
+		return nil, fmt.Errorf("%w, pending migrations: %s", domain.ErrStorageNotInitialized, strings.Join(pending, ", "))
+	}
+
+	return db, nil
+}
+
+// PendingMigrations returns embedded migrations not applied yet in file name order
+func PendingMigrations(db *sql.DB) ([]string, error) {
+	ctx := context.Background()
+	var initialized bool
+	if err := db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations')").Scan(&initialized); err != nil {
+		return nil, err
+	}
+	entries, err := fs.ReadDir(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]string, 0)
+	for _, entry := range entries {
+		applied := false
+		if initialized {
+			if err = db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = ?)", entry.Name()).Scan(&applied); err != nil {
+				return nil, err
+			}
+		}
+		if !applied {
+			pending = append(pending, entry.Name())
+		}
+	}
+
+	return pending, nil
+}
+
+// Migrate applies embedded migrations that are not applied yet in file name order
+func Migrate(db *sql.DB) error {
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err = tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	)`); err != nil {
+		return err
+	}
+	entries, err := fs.ReadDir(migrations, "migrations")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		var applied bool
+		if err = tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = ?)", entry.Name()).Scan(&applied); err != nil {
+			return err
+		}
+		if applied {
+			continue
+		}
+		migration, err := migrations.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, string(migration)); err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)", entry.Name(), toUnix(time.Now())); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func toUnix(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+func fromUnix(value int64) time.Time {
+	return time.UnixMicro(value)
+}
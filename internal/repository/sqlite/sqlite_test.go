@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+func openTestDatabase(t *testing.T) *sql.DB {
+	db, err := Open(filepath.Join(t.TempDir(), "pipeline-locker.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return db
+}
+
+func TestMigrate(t *testing.T) {
+	db := openTestDatabase(t)
+
+	t.Run("Migrate_databaseAlreadyMigrated_appliesNothing", func(t *testing.T) {
+		if err := Migrate(db); err != nil {
+			t.Errorf("Expected nil error, got %v", err)
+		}
+		var count int
+		db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count)
+		entries, _ := migrations.ReadDir("migrations")
+		if count != len(entries) {
+			t.Errorf("Expected %d applied migrations, got %d", len(entries), count)
+		}
+	})
+}
+
+func TestOpenMigrated(t *testing.T) {
+	t.Run("OpenMigrated_migratedDatabase_opensReadOnly", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pipeline-locker.db")
+		migrated, _ := Open(path)
+		migrated.Close()
+
+		db, err := OpenMigrated(path)
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		defer db.Close()
+		if _, err = db.Exec("DELETE FROM schema_migrations"); err == nil {
+			t.Errorf("Expected write to fail")
+		}
+	})
+
+	t.Run("OpenMigrated_missingFile_returnsErrStorageNotInitializedWithoutCreatingFile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pipeline-locker.db")
+
+		db, err := OpenMigrated(path)
+		if !errors.Is(err, domain.ErrStorageNotInitialized) || db != nil {
+			t.Errorf("Expected %v, got %v and %v", domain.ErrStorageNotInitialized, db, err)
+		}
+		if _, err = os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected file not to be created, got %v", err)
+		}
+	})
+
+	t.Run("OpenMigrated_pendingMigrations_returnsErrStorageNotInitialized", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pipeline-locker.db")
+		migrated, _ := Open(path)
+		migrated.Exec("DELETE FROM schema_migrations")
+		migrated.Close()
+
+		db, err := OpenMigrated(path)
+		if !errors.Is(err, domain.ErrStorageNotInitialized) || db != nil {
+			t.Errorf("Expected %v, got %v and %v", domain.ErrStorageNotInitialized, db, err)
+		}
+	})
+}
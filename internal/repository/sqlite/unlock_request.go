@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+const unlockRequestColumns = "id, project, environment, region, cluster, component, locked_by, locked_at, requested_by, requested_at, expires_at, status, reviewed_by, reviewed_at"
+
+type unlockRequestRepository struct {
+	db *sql.DB
+}
+
+func NewUnlockRequestRepository(db *sql.DB) *unlockRequestRepository {
+	return &unlockRequestRepository{
+		db: db,
+	}
+}
+
+func (r *unlockRequestRepository) Add(ctx context.Context, request domain.UnlockRequest) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO unlock_requests ("+unlockRequestColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		request.ID, request.Project, request.Environment, request.Region, request.Cluster, request.Component, request.LockedBy, toUnix(request.LockedAt), request.RequestedBy, toUnix(request.RequestedAt), toUnix(request.ExpiresAt),
+		string(request.Status), request.ReviewedBy, toUnix(request.ReviewedAt))
+
+	return err
+}
+
+func (r *unlockRequestRepository) Find(ctx context.Context, id string) (*domain.UnlockRequest, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+unlockRequestColumns+" FROM unlock_requests WHERE id = ?", id)
+	request, err := scanUnlockRequest(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return request, err
+}
+
+// Update changes only pending request, so that request is reviewed once
+func (r *unlockRequestRepository) Update(ctx context.Context, request domain.UnlockRequest) error {
+	result, err := r.db.ExecContext(ctx, "UPDATE unlock_requests SET status = ?, reviewed_by = ?, reviewed_at = ? WHERE id = ? AND status = ?",
+		string(request.Status), request.ReviewedBy, toUnix(request.ReviewedAt), request.ID, string(domain.UnlockRequestPending))
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 1 {
+		return nil
+	}
+	var exists bool
+	if err = r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM unlock_requests WHERE id = ?)", request.ID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrUnlockRequestNotFound
+	}
+
+	return domain.ErrUnlockRequestNotPending
+}
+
+func (r *unlockRequestRepository) FindPending(ctx context.Context) ([]domain.UnlockRequest, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+unlockRequestColumns+" FROM unlock_requests WHERE status = ? ORDER BY requested_at", string(domain.UnlockRequestPending))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	requests := make([]domain.UnlockRequest, 0)
+	for rows.Next() {
+		request, err := scanUnlockRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+
+	return requests, rows.Err()
+}
+
+func scanUnlockRequest(row scanner) (*domain.UnlockRequest, error) {
+	var request domain.UnlockRequest
+	var lockedAt, requestedAt, expiresAt, reviewedAt int64
+	if err := row.Scan(&request.ID, &request.Project, &request.Environment, &request.Region, &request.Cluster, &request.Component, &request.LockedBy, &lockedAt, &request.RequestedBy, &requestedAt, &expiresAt, &request.Status, &request.ReviewedBy, &reviewedAt); err != nil {
+		return nil, err
+	}
+	request.LockedAt = fromUnix(lockedAt)
+	request.RequestedAt = fromUnix(requestedAt)
+	request.ExpiresAt = fromUnix(expiresAt)
+	request.ReviewedAt = fromUnix(reviewedAt)
+
+	return &request, nil
+}
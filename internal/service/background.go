@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+// backgroundQueueSize limits writes waiting in background queue, writes beyond it are dropped
+const backgroundQueueSize = 1024
+
+type backgroundWrite struct {
+	description string
+	write       func(ctx context.Context) error
+}
+
+// backgroundWriter stores records which must not slow down or fail the request they were made in. Writes are done
+// one by one, each bounded by timeout, and failures are logged.
+type backgroundWriter struct {
+	timeout   time.Duration
+	log       *logger.Logger
+	writes    chan backgroundWrite
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newBackgroundWriter(timeout time.Duration, log *logger.Logger) *backgroundWriter {
+	w := &backgroundWriter{
+		timeout: timeout,
+		log:     log,
+		writes:  make(chan backgroundWrite, backgroundQueueSize),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+
+	return w
+}
+
+// enqueue queues write without waiting for it, write is dropped when queue is full
+func (w *backgroundWriter) enqueue(description string, write func(ctx context.Context) error) {
+	select {
+	case w.writes <- backgroundWrite{description: description, write: write}:
+	default:
+		w.log.Error.Printf("Failed to %s, %d writes are waiting in queue", description, backgroundQueueSize)
+	}
+}
+
+// close stops accepting writes and waits until queued writes are done
+func (w *backgroundWriter) close() {
+	w.closeOnce.Do(func() {
+		close(w.writes)
+	})
+	<-w.done
+}
+
+func (w *backgroundWriter) run() {
+	defer close(w.done)
+	for write := range w.writes {
+		w.do(write)
+	}
+}
+
+func (w *backgroundWriter) do(write backgroundWrite) {
+	ctx, cancel := withTimeout(context.Background(), w.timeout)
+	defer cancel()
+	if err := contextError(ctx, write.write(ctx)); err != nil {
+		w.log.Error.Printf("Failed to %s: %v", write.description, err)
+	}
+}
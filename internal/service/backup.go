@@ -0,0 +1,38 @@
+package service
+
+import (
+	"io"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+type backupService struct {
+	repository domain.BackupRepository
+	log        *logger.Logger
+}
+
+// NewBackupService creates backup service, repository is nil when configured storage can't be backed up
+func NewBackupService(repository domain.BackupRepository, log *logger.Logger) *backupService {
+	return &backupService{
+		repository: repository,
+		log:        log,
+	}
+}
+
+func (s *backupService) IsSupported() bool {
+	return s.repository != nil
+}
+
+func (s *backupService) Backup(w io.Writer) error {
+	if !s.IsSupported() {
+		return domain.ErrBackupNotSupported
+	}
+	if err := s.repository.Backup(w); err != nil {
+		s.log.Error.Printf("Failed to write backup: %v", err)
+		return err
+	}
+	s.log.Info.Printf("Backup written")
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+type backupRepositoryMock struct {
+	fakeBackup func(w io.Writer) error
+}
+
+func (r *backupRepositoryMock) Backup(w io.Writer) error {
+	if r.fakeBackup != nil {
+		return r.fakeBackup(w)
+	}
+
+	return nil
+}
+
+func TestBackupService_Backup(t *testing.T) {
+	errWrite := errors.New("WRITE_FAILED")
+	type testCases struct {
+		description       string
+		repository        domain.BackupRepository
+		expectedSupported bool
+		expectedError     error
+		expectedBackup    string
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:       "repositoryNotConfigured_returnNotSupportedError",
+			expectedSupported: false,
+			expectedError:     domain.ErrBackupNotSupported,
+		},
+		{
+			description: "repositoryFails_returnError",
+			repository: &backupRepositoryMock{
+				fakeBackup: func(w io.Writer) error {
+					return errWrite
+				},
+			},
+			expectedSupported: true,
+			expectedError:     errWrite,
+		},
+		{
+			description: "repositoryWritesSnapshot_snapshotIsWritten",
+			repository: &backupRepositoryMock{
+				fakeBackup: func(w io.Writer) error {
+					_, err := w.Write([]byte("snapshot"))
+					return err
+				},
+			},
+			expectedSupported: true,
+			expectedBackup:    "snapshot",
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			service := NewBackupService(scenario.repository, logger.New())
+			var backup bytes.Buffer
+
+			if service.IsSupported() != scenario.expectedSupported {
+				t.Errorf("Expected supported %t, got %t", scenario.expectedSupported, service.IsSupported())
+			}
+			err := service.Backup(&backup)
+			if err != scenario.expectedError {
+				t.Errorf("Expected error %v, got %v", scenario.expectedError, err)
+			}
+			if backup.String() != scenario.expectedBackup {
+				t.Errorf("Expected backup %q, got %q", scenario.expectedBackup, backup.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+type blockedAttemptService struct {
+	repository         domain.BlockedAttemptRepository
+	pipelineRepository domain.PipelineRepository
+	aliases            domain.EnvironmentAliases
+	timeouts           PipelineTimeouts
+	log                *logger.Logger
+	writer             *backgroundWriter
+}
+
+func NewBlockedAttemptService(repository domain.BlockedAttemptRepository, pipelineRepository domain.PipelineRepository, aliases domain.EnvironmentAliases, timeouts PipelineTimeouts, log *logger.Logger) *blockedAttemptService {
+	return &blockedAttemptService{
+		repository:         repository,
+		pipelineRepository: pipelineRepository,
+		aliases:            aliases,
+		timeouts:           timeouts,
+		log:                log,
+		writer:             newBackgroundWriter(timeouts.Status, log),
+	}
+}
+
+// Record queues attempt to be added in background, like discovery checks. Attempt stopped by a lock is added under
+// identifier of the lock, which may cover the pipeline from a shorter scope path.
+func (s *blockedAttemptService) Record(ctx context.Context, attempt domain.BlockedAttempt) {
+	attempt.PipelineIdentifier = s.aliases.Resolve(attempt.PipelineIdentifier)
+	if err := attempt.PipelineIdentifier.Validate(); err != nil {
+		s.log.Error.Printf("Blocked attempt of pipeline %s not recorded: %v", attempt.Name(), err)
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	attempt.AttemptedAt = time.Now()
+	s.writer.enqueue("record blocked attempt of pipeline "+attempt.Name(), func(ctx context.Context) error {
+		if attempt.Reason == domain.CheckLocked {
+			lock, err := s.findBlockingLock(ctx, attempt.PipelineIdentifier, attempt.AttemptedAt)
+			if err != nil {
+				return err
+			}
+			if lock != nil {
+				attempt.PipelineIdentifier = lock.PipelineIdentifier
+			}
+		}
+
+		return s.repository.Add(ctx, attempt)
+	})
+}
+
+// Close stops accepting attempts and waits until queued attempts are added
+func (s *blockedAttemptService) Close() {
+	s.writer.close()
+}
+
+// findBlockingLock returns lock of the pipeline or lock of shorter scope path covering it, nil if none is locked
+func (s *blockedAttemptService) findBlockingLock(ctx context.Context, identifier domain.PipelineIdentifier, now time.Time) (*domain.Pipeline, error) {
+	for _, candidate := range append([]domain.PipelineIdentifier{identifier}, identifier.Parents()...) {
+		pipeline, err := s.pipelineRepository.Find(ctx, candidate)
+		if err != nil {
+			return nil, err
+		}
+		if pipeline != nil && pipeline.IsLocked(now) {
+			return pipeline, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *blockedAttemptService) GetForLock(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.BlockedAttempt, error) {
+	pipeline = s.aliases.Resolve(pipeline)
+	if err := pipeline.Validate(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := withTimeout(ctx, s.timeouts.List)
+	defer cancel()
+	locked, err := s.pipelineRepository.Find(ctx, pipeline)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	if locked == nil || !locked.IsLocked(time.Now()) {
+		return make([]domain.BlockedAttempt, 0), nil
+	}
+
+	attempts, err := s.repository.FindSince(ctx, pipeline, locked.LockedAt)
+
+	return attempts, contextError(ctx, err)
+}
+
+func (s *blockedAttemptService) CountForLocks(ctx context.Context, pipelines []domain.Pipeline) (map[domain.PipelineIdentifier]int, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.List)
+	defer cancel()
+	counts := make(map[domain.PipelineIdentifier]int, len(pipelines))
+	for _, pipeline := range pipelines {
+		attempts, err := s.repository.FindSince(ctx, pipeline.PipelineIdentifier, pipeline.LockedAt)
+		if err != nil {
+			return nil, contextError(ctx, err)
+		}
+		counts[pipeline.PipelineIdentifier] = len(attempts)
+	}
+
+	return counts, nil
+}
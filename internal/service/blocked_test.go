@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+type blockedAttemptRepositoryMock struct {
+	domain.BlockedAttemptRepository
+	fakeAdd       func(attempt domain.BlockedAttempt)
+	fakeFindSince func(pipeline domain.PipelineIdentifier, since time.Time) []domain.BlockedAttempt
+}
+
+func (r *blockedAttemptRepositoryMock) Add(ctx context.Context, attempt domain.BlockedAttempt) error {
+	if r.fakeAdd != nil {
+		r.fakeAdd(attempt)
+	}
+
+	return nil
+}
+
+func (r *blockedAttemptRepositoryMock) FindSince(ctx context.Context, pipeline domain.PipelineIdentifier, since time.Time) ([]domain.BlockedAttempt, error) {
+	if r.fakeFindSince != nil {
+		return r.fakeFindSince(pipeline, since), nil
+	}
+
+	return make([]domain.BlockedAttempt, 0), nil
+}
+
+func TestBlockedAttemptService_Record(t *testing.T) {
+	t.Run("projectIsEmpty_notAdded", func(t *testing.T) {
+		added := false
+		service := NewBlockedAttemptService(&blockedAttemptRepositoryMock{
+			fakeAdd: func(attempt domain.BlockedAttempt) {
+				added = true
+			},
+		}, &pipelineRepositoryMock{}, domain.EnvironmentAliases{}, PipelineTimeouts{}, logger.New())
+
+		service.Record(context.Background(), domain.BlockedAttempt{Reason: domain.CheckLocked})
+		service.Close()
+
+		if added {
+			t.Error("Expected attempt of invalid pipeline not to be added")
+		}
+	})
+
+	t.Run("aliasedEnvironment_addsAttemptOfCanonicalPipeline", func(t *testing.T) {
+		var aliases domain.EnvironmentAliases
+		aliases.Add("", environment, "prod")
+		var added domain.BlockedAttempt
+		service := NewBlockedAttemptService(&blockedAttemptRepositoryMock{
+			fakeAdd: func(attempt domain.BlockedAttempt) {
+				added = attempt
+			},
+		}, &pipelineRepositoryMock{}, aliases, PipelineTimeouts{}, logger.New())
+
+		service.Record(context.Background(), domain.BlockedAttempt{
+			PipelineIdentifier: domain.PipelineIdentifier{Project: project, Environment: "prod"},
+			Reason:             domain.CheckLocked,
+			ClientIP:           "10.0.0.1",
+		})
+		service.Close()
+
+		if added.PipelineIdentifier != getPipelineIdentifierMock() {
+			t.Errorf("Expected attempt of %v to be added, got %v", getPipelineIdentifierMock(), added.PipelineIdentifier)
+		}
+		if added.ClientIP != "10.0.0.1" || added.AttemptedAt.IsZero() {
+			t.Errorf("Expected attempt from client with attempt time, got %v", added)
+		}
+	})
+
+	scoped := domain.PipelineIdentifier{Project: project, Environment: environment, PipelineScope: domain.NewPipelineScope("eu", "blue")}
+	type testCases struct {
+		description        string
+		reason             domain.CheckOutcome
+		locked             []domain.PipelineIdentifier
+		expectedIdentifier domain.PipelineIdentifier
+	}
+	for _, scenario := range []testCases{
+		{
+			description:        "pipelineLocked_addsAttemptOfPipeline",
+			reason:             domain.CheckLocked,
+			locked:             []domain.PipelineIdentifier{scoped},
+			expectedIdentifier: scoped,
+		},
+		{
+			description:        "parentScopeLocked_addsAttemptOfParentLock",
+			reason:             domain.CheckLocked,
+			locked:             []domain.PipelineIdentifier{scoped.Parents()[1]},
+			expectedIdentifier: scoped.Parents()[1],
+		},
+		{
+			description:        "lockReleasedBeforeAdding_addsAttemptOfPipeline",
+			reason:             domain.CheckLocked,
+			expectedIdentifier: scoped,
+		},
+		{
+			description:        "versionNotPromoted_addsAttemptOfPipeline",
+			reason:             domain.CheckNotPromoted,
+			locked:             []domain.PipelineIdentifier{scoped.Parents()[0]},
+			expectedIdentifier: scoped,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			var added domain.BlockedAttempt
+			service := NewBlockedAttemptService(&blockedAttemptRepositoryMock{
+				fakeAdd: func(attempt domain.BlockedAttempt) {
+					added = attempt
+				},
+			}, &pipelineRepositoryMock{
+				fakeFind: func(pipeline domain.PipelineIdentifier) *domain.Pipeline {
+					for _, locked := range scenario.locked {
+						if pipeline == locked {
+							return &domain.Pipeline{PipelineIdentifier: locked, PipelineLockedBy: domain.PipelineLockedBy{LockedBy: "alice"}, PipelineLockedAt: domain.PipelineLockedAt{LockedAt: time.Now().Add(-time.Minute)}}
+						}
+					}
+					return nil
+				},
+			}, domain.EnvironmentAliases{}, PipelineTimeouts{}, logger.New())
+
+			service.Record(context.Background(), domain.BlockedAttempt{PipelineIdentifier: scoped, Reason: scenario.reason})
+			service.Close()
+
+			if added.PipelineIdentifier != scenario.expectedIdentifier {
+				t.Errorf("Expected attempt of %s to be added, got %s", scenario.expectedIdentifier.Name(), added.Name())
+			}
+		})
+	}
+}
+
+func TestBlockedAttemptService_GetForLock(t *testing.T) {
+	lockedAt := time.Now().Add(-time.Hour)
+
+	t.Run("pipelineNotLocked_returnsEmpty", func(t *testing.T) {
+		var findSinceCalls int
+		service := NewBlockedAttemptService(&blockedAttemptRepositoryMock{
+			fakeFindSince: func(pipeline domain.PipelineIdentifier, since time.Time) []domain.BlockedAttempt {
+				findSinceCalls++
+				return nil
+			},
+		}, &pipelineRepositoryMock{}, domain.EnvironmentAliases{}, PipelineTimeouts{}, logger.New())
+
+		attempts, err := service.GetForLock(context.Background(), getPipelineIdentifierMock())
+
+		if err != nil || len(attempts) != 0 || findSinceCalls != 0 {
+			t.Errorf("Expected no attempts without lookup, got %v, %v and %d lookups", attempts, err, findSinceCalls)
+		}
+	})
+
+	t.Run("pipelineLocked_returnsAttemptsSinceLock", func(t *testing.T) {
+		var requestedSince time.Time
+		service := NewBlockedAttemptService(&blockedAttemptRepositoryMock{
+			fakeFindSince: func(pipeline domain.PipelineIdentifier, since time.Time) []domain.BlockedAttempt {
+				requestedSince = since
+				return []domain.BlockedAttempt{{PipelineIdentifier: pipeline}}
+			},
+		}, &pipelineRepositoryMock{
+			fakeFind: func(pipeline domain.PipelineIdentifier) *domain.Pipeline {
+				return &domain.Pipeline{
+					PipelineIdentifier: pipeline,
+					PipelineLockedBy:   domain.PipelineLockedBy{LockedBy: user},
+					PipelineLockedAt:   domain.PipelineLockedAt{LockedAt: lockedAt},
+				}
+			},
+		}, domain.EnvironmentAliases{}, PipelineTimeouts{}, logger.New())
+
+		attempts, _ := service.GetForLock(context.Background(), getPipelineIdentifierMock())
+
+		if !requestedSince.Equal(lockedAt) {
+			t.Errorf("Expected attempts since %v, got since %v", lockedAt, requestedSince)
+		}
+		if len(attempts) != 1 {
+			t.Errorf("Expected single attempt, got %v", attempts)
+		}
+	})
+}
+
+func TestBlockedAttemptService_CountForLocks(t *testing.T) {
+	t.Run("lockedPipelines_countsAttemptsByPipelineIdentifier", func(t *testing.T) {
+		sameName := domain.PipelineIdentifier{Project: project + "/" + environment, Environment: "staging"}
+		otherName := domain.PipelineIdentifier{Project: project, Environment: environment + "/staging"}
+		service := NewBlockedAttemptService(&blockedAttemptRepositoryMock{
+			fakeFindSince: func(pipeline domain.PipelineIdentifier, since time.Time) []domain.BlockedAttempt {
+				switch pipeline {
+				case getPipelineIdentifierMock():
+					return make([]domain.BlockedAttempt, 3)
+				case otherName:
+					return make([]domain.BlockedAttempt, 2)
+				}
+				return nil
+			},
+		}, &pipelineRepositoryMock{}, domain.EnvironmentAliases{}, PipelineTimeouts{}, logger.New())
+
+		counts, err := service.CountForLocks(context.Background(), []domain.Pipeline{
+			{PipelineIdentifier: getPipelineIdentifierMock()},
+			{PipelineIdentifier: sameName},
+			{PipelineIdentifier: otherName},
+		})
+
+		if err != nil {
+			t.Fatalf("Expected error nil, got %v", err)
+		}
+		if counts[getPipelineIdentifierMock()] != 3 || counts[sameName] != 0 || counts[otherName] != 2 || len(counts) != 3 {
+			t.Errorf("Expected 3 attempts and 0 and 2 attempts of pipelines sharing name, got %v", counts)
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+// withTimeout limits ctx by operation timeout, zero timeout leaves ctx as is
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// contextError replaces error caused by expired or canceled ctx with domain error. Storage clients report
+// expired deadline in their own way, e.g. as network timeout, so ctx is checked as well.
+func contextError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return domain.ErrStorageTimeout
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled) {
+		return domain.ErrRequestCanceled
+	}
+
+	return err
+}
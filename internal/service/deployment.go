@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type deploymentService struct {
+	repository domain.DeploymentRepository
+	aliases    domain.EnvironmentAliases
+	timeouts   PipelineTimeouts
+}
+
+func NewDeploymentService(repository domain.DeploymentRepository, aliases domain.EnvironmentAliases, timeouts PipelineTimeouts) *deploymentService {
+	return &deploymentService{
+		repository: repository,
+		aliases:    aliases,
+		timeouts:   timeouts,
+	}
+}
+
+func (s *deploymentService) Report(ctx context.Context, status domain.DeploymentStatus, report domain.DeploymentReport) (*domain.Deployment, error) {
+	if err := status.Validate(); err != nil {
+		return nil, err
+	}
+	report.PipelineIdentifier = s.aliases.Resolve(report.PipelineIdentifier)
+	if err := report.Validate(); err != nil {
+		return nil, err
+	}
+	deployment := domain.Deployment{
+		PipelineIdentifier: report.PipelineIdentifier,
+		DeploymentDetails:  report.DeploymentDetails,
+		Status:             status,
+		DeployedAt:         time.Now(),
+	}
+	ctx, cancel := withTimeout(ctx, s.timeouts.Lock)
+	defer cancel()
+	if err := s.repository.Add(ctx, deployment); err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	return &deployment, nil
+}
+
+func (s *deploymentService) GetHistory(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.Deployment, error) {
+	pipeline = s.aliases.Resolve(pipeline)
+	if err := pipeline.Validate(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := withTimeout(ctx, s.timeouts.List)
+	defer cancel()
+	deployments, err := s.repository.FindHistory(ctx, pipeline)
+
+	return deployments, contextError(ctx, err)
+}
+
+func (s *deploymentService) GetCurrent(ctx context.Context) ([]domain.Deployment, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.List)
+	defer cancel()
+	deployments, err := s.repository.FindCurrent(ctx)
+
+	return deployments, contextError(ctx, err)
+}
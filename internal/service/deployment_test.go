@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type deploymentRepositoryMock struct {
+	domain.DeploymentRepository
+	fakeAdd           func(deployment domain.Deployment)
+	fakeFindByVersion func(pipeline domain.PipelineIdentifier, version string) *domain.Deployment
+	fakeFindHistory   func(pipeline domain.PipelineIdentifier) []domain.Deployment
+	fakeFindCurrent   func() []domain.Deployment
+}
+
+func (r *deploymentRepositoryMock) Add(ctx context.Context, deployment domain.Deployment) error {
+	if r.fakeAdd != nil {
+		r.fakeAdd(deployment)
+	}
+
+	return nil
+}
+
+func (r *deploymentRepositoryMock) FindByVersion(ctx context.Context, pipeline domain.PipelineIdentifier, version string) (*domain.Deployment, error) {
+	if r.fakeFindByVersion != nil {
+		return r.fakeFindByVersion(pipeline, version), nil
+	}
+
+	return nil, nil
+}
+
+func (r *deploymentRepositoryMock) FindHistory(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.Deployment, error) {
+	if r.fakeFindHistory != nil {
+		return r.fakeFindHistory(pipeline), nil
+	}
+
+	return make([]domain.Deployment, 0), nil
+}
+
+func (r *deploymentRepositoryMock) FindCurrent(ctx context.Context) ([]domain.Deployment, error) {
+	if r.fakeFindCurrent != nil {
+		return r.fakeFindCurrent(), nil
+	}
+
+	return make([]domain.Deployment, 0), nil
+}
+
+func getDeploymentReportMock() domain.DeploymentReport {
+	return domain.DeploymentReport{
+		PipelineIdentifier: getPipelineIdentifierMock(),
+		DeploymentDetails: domain.DeploymentDetails{
+			Version: "1.0.0",
+			Commit:  "abc123",
+			Actor:   user,
+			JobURL:  "https://ci.example/job/1",
+		},
+	}
+}
+
+func TestDeploymentService_Report(t *testing.T) {
+	type testCases struct {
+		description      string
+		status           domain.DeploymentStatus
+		input            domain.DeploymentReport
+		expectedError    error
+		expectedAddCalls int
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:   "statusIsInvalid_returnError",
+			status:        "finished",
+			input:         getDeploymentReportMock(),
+			expectedError: domain.ErrDeploymentStatusInvalid,
+		},
+		{
+			description:   "projectIsEmpty_returnError",
+			status:        domain.DeploymentSucceeded,
+			input:         domain.DeploymentReport{},
+			expectedError: domain.ErrProjectEmpty,
+		},
+		{
+			description: "versionIsEmpty_returnError",
+			status:      domain.DeploymentStarted,
+			input: domain.DeploymentReport{
+				PipelineIdentifier: getPipelineIdentifierMock(),
+			},
+			expectedError: domain.ErrVersionEmpty,
+		},
+		{
+			description:      "deploymentStarted_callsAdd",
+			status:           domain.DeploymentStarted,
+			input:            getDeploymentReportMock(),
+			expectedAddCalls: 1,
+		},
+		{
+			description:      "deploymentFailed_callsAdd",
+			status:           domain.DeploymentFailed,
+			input:            getDeploymentReportMock(),
+			expectedAddCalls: 1,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			var addCallsCount int
+			var addedDeployment domain.Deployment
+			repository := &deploymentRepositoryMock{
+				fakeAdd: func(deployment domain.Deployment) {
+					addCallsCount++
+					addedDeployment = deployment
+				},
+			}
+			service := NewDeploymentService(repository, domain.EnvironmentAliases{}, PipelineTimeouts{})
+
+			deployment, err := service.Report(context.Background(), scenario.status, scenario.input)
+
+			if !errors.Is(err, scenario.expectedError) {
+				t.Errorf("Expected error %s, but received %s", scenario.expectedError, err)
+			}
+			if addCallsCount != scenario.expectedAddCalls {
+				t.Errorf("Expected repository Add method calls %d, but Add was called %d times", scenario.expectedAddCalls, addCallsCount)
+			}
+			if scenario.expectedAddCalls == 0 {
+				return
+			}
+			if deployment == nil || *deployment != addedDeployment || deployment.DeployedAt.IsZero() {
+				t.Errorf("Expected added deployment to be returned, got %v", deployment)
+			} else if deployment.Status != scenario.status || deployment.DeploymentDetails != scenario.input.DeploymentDetails {
+				t.Errorf("Expected deployment with status %s and details %v, got %v", scenario.status, scenario.input.DeploymentDetails, deployment)
+			}
+		})
+	}
+}
+
+func TestDeploymentService_GetHistory(t *testing.T) {
+	t.Run("projectIsEmpty_returnError", func(t *testing.T) {
+		service := NewDeploymentService(&deploymentRepositoryMock{}, domain.EnvironmentAliases{}, PipelineTimeouts{})
+
+		_, err := service.GetHistory(context.Background(), domain.PipelineIdentifier{})
+
+		if !errors.Is(err, domain.ErrProjectEmpty) {
+			t.Errorf("Expected error %s, but received %s", domain.ErrProjectEmpty, err)
+		}
+	})
+
+	t.Run("repositoryFindHistoryIsCalled_proxiesValue", func(t *testing.T) {
+		var requestedPipeline domain.PipelineIdentifier
+		service := NewDeploymentService(&deploymentRepositoryMock{
+			fakeFindHistory: func(pipeline domain.PipelineIdentifier) []domain.Deployment {
+				requestedPipeline = pipeline
+				return []domain.Deployment{{PipelineIdentifier: pipeline}}
+			},
+		}, domain.EnvironmentAliases{}, PipelineTimeouts{})
+
+		history, _ := service.GetHistory(context.Background(), getPipelineIdentifierMock())
+
+		if requestedPipeline != getPipelineIdentifierMock() {
+			t.Errorf("Expected history of %v to be requested, got %v", getPipelineIdentifierMock(), requestedPipeline)
+		}
+		if len(history) != 1 {
+			t.Errorf("Expected history of one deployment, got %v", history)
+		}
+	})
+}
+
+func TestDeploymentService_GetCurrent(t *testing.T) {
+	t.Run("repositoryFindCurrentIsCalled_proxiesValue", func(t *testing.T) {
+		var findCurrentCalls int
+		service := NewDeploymentService(&deploymentRepositoryMock{
+			fakeFindCurrent: func() []domain.Deployment {
+				findCurrentCalls++
+				return make([]domain.Deployment, 0)
+			},
+		}, domain.EnvironmentAliases{}, PipelineTimeouts{})
+
+		deployments, _ := service.GetCurrent(context.Background())
+
+		if findCurrentCalls != 1 {
+			t.Errorf("Expected repository FindCurrent() to be called once, got %d", findCurrentCalls)
+		}
+		if deployments == nil || len(deployments) != 0 {
+			t.Errorf("Expected empty slice to be returned, but got %v", deployments)
+		}
+	})
+}
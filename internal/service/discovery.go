@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+type pipelineDiscoveryService struct {
+	repository domain.PipelineDiscoveryRepository
+	aliases    domain.EnvironmentAliases
+	timeouts   PipelineTimeouts
+	log        *logger.Logger
+	writer     *backgroundWriter
+}
+
+func NewPipelineDiscoveryService(repository domain.PipelineDiscoveryRepository, aliases domain.EnvironmentAliases, timeouts PipelineTimeouts, log *logger.Logger) *pipelineDiscoveryService {
+	return &pipelineDiscoveryService{
+		repository: repository,
+		aliases:    aliases,
+		timeouts:   timeouts,
+		log:        log,
+		writer:     newBackgroundWriter(timeouts.Status, log),
+	}
+}
+
+// Record queues check to be recorded in background. Request context isn't used for storing, as request ends before
+// check is stored, storing is bounded by status timeout instead.
+func (s *pipelineDiscoveryService) Record(ctx context.Context, pipeline domain.PipelineIdentifier, outcome domain.CheckOutcome) {
+	pipeline = s.aliases.Resolve(pipeline)
+	if err := pipeline.Validate(); err != nil {
+		s.log.Error.Printf("Check of pipeline %s not recorded: %v", pipeline.Name(), err)
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	check := domain.PipelineCheck{
+		PipelineIdentifier: pipeline,
+		Outcome:            outcome,
+		CheckedAt:          time.Now(),
+	}
+	s.writer.enqueue("record check of pipeline "+pipeline.Name(), func(ctx context.Context) error {
+		return s.repository.Record(ctx, check)
+	})
+}
+
+// Close stops accepting checks and waits until queued checks are recorded
+func (s *pipelineDiscoveryService) Close() {
+	s.writer.close()
+}
+
+func (s *pipelineDiscoveryService) GetKnown(ctx context.Context) ([]domain.KnownPipeline, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.List)
+	defer cancel()
+	pipelines, err := s.repository.FindAll(ctx)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	sort.Slice(pipelines, func(i, j int) bool {
+		return pipelines[i].Less(pipelines[j].PipelineIdentifier)
+	})
+
+	return pipelines, nil
+}
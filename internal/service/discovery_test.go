@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+type pipelineDiscoveryRepositoryMock struct {
+	domain.PipelineDiscoveryRepository
+	fakeRecord  func(check domain.PipelineCheck)
+	fakeFindAll func() []domain.KnownPipeline
+}
+
+func (r *pipelineDiscoveryRepositoryMock) Record(ctx context.Context, check domain.PipelineCheck) error {
+	if r.fakeRecord != nil {
+		r.fakeRecord(check)
+	}
+
+	return nil
+}
+
+func (r *pipelineDiscoveryRepositoryMock) FindAll(ctx context.Context) ([]domain.KnownPipeline, error) {
+	if r.fakeFindAll != nil {
+		return r.fakeFindAll(), nil
+	}
+
+	return make([]domain.KnownPipeline, 0), nil
+}
+
+func TestPipelineDiscoveryService_Record(t *testing.T) {
+	t.Run("projectIsEmpty_notRecorded", func(t *testing.T) {
+		recorded := false
+		service := NewPipelineDiscoveryService(&pipelineDiscoveryRepositoryMock{
+			fakeRecord: func(check domain.PipelineCheck) {
+				recorded = true
+			},
+		}, domain.EnvironmentAliases{}, PipelineTimeouts{}, logger.New())
+
+		service.Record(context.Background(), domain.PipelineIdentifier{}, domain.CheckAllowed)
+		service.Close()
+
+		if recorded {
+			t.Error("Expected check of invalid pipeline not to be recorded")
+		}
+	})
+
+	t.Run("aliasedEnvironment_recordsCanonicalPipeline", func(t *testing.T) {
+		var aliases domain.EnvironmentAliases
+		aliases.Add("", environment, "prod")
+		var recorded domain.PipelineCheck
+		service := NewPipelineDiscoveryService(&pipelineDiscoveryRepositoryMock{
+			fakeRecord: func(check domain.PipelineCheck) {
+				recorded = check
+			},
+		}, aliases, PipelineTimeouts{}, logger.New())
+
+		service.Record(context.Background(), domain.PipelineIdentifier{Project: project, Environment: "prod"}, domain.CheckLocked)
+		service.Close()
+
+		if recorded.PipelineIdentifier != getPipelineIdentifierMock() {
+			t.Errorf("Expected %v to be recorded, got %v", getPipelineIdentifierMock(), recorded.PipelineIdentifier)
+		}
+		if recorded.Outcome != domain.CheckLocked || recorded.CheckedAt.IsZero() {
+			t.Errorf("Expected locked check with check time, got %v", recorded)
+		}
+	})
+
+	t.Run("repositoryIsSlow_returnsWithoutWaitingForRepository", func(t *testing.T) {
+		release := make(chan struct{})
+		service := NewPipelineDiscoveryService(&pipelineDiscoveryRepositoryMock{
+			fakeRecord: func(check domain.PipelineCheck) {
+				<-release
+			},
+		}, domain.EnvironmentAliases{}, PipelineTimeouts{}, logger.New())
+		recorded := make(chan struct{})
+
+		go func() {
+			for i := 0; i < backgroundQueueSize+2; i++ {
+				service.Record(context.Background(), getPipelineIdentifierMock(), domain.CheckAllowed)
+			}
+			close(recorded)
+		}()
+
+		select {
+		case <-recorded:
+		case <-time.After(time.Second):
+			t.Error("Expected Record not to wait for repository")
+		}
+		close(release)
+		service.Close()
+	})
+
+	t.Run("requestIsCanceled_notRecorded", func(t *testing.T) {
+		recorded := false
+		service := NewPipelineDiscoveryService(&pipelineDiscoveryRepositoryMock{
+			fakeRecord: func(check domain.PipelineCheck) {
+				recorded = true
+			},
+		}, domain.EnvironmentAliases{}, PipelineTimeouts{}, logger.New())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		service.Record(ctx, getPipelineIdentifierMock(), domain.CheckAllowed)
+		service.Close()
+
+		if recorded {
+			t.Error("Expected check of canceled request not to be recorded")
+		}
+	})
+}
+
+func TestPipelineDiscoveryService_GetKnown(t *testing.T) {
+	t.Run("repositoryReturnsPipelines_returnsOrderedByScopePath", func(t *testing.T) {
+		service := NewPipelineDiscoveryService(&pipelineDiscoveryRepositoryMock{
+			fakeFindAll: func() []domain.KnownPipeline {
+				return []domain.KnownPipeline{
+					{PipelineIdentifier: domain.PipelineIdentifier{Project: "web", Environment: "staging"}},
+					{PipelineIdentifier: domain.PipelineIdentifier{Project: "api", Environment: "staging", PipelineScope: domain.NewPipelineScope("eu")}},
+					{PipelineIdentifier: domain.PipelineIdentifier{Project: "api", Environment: "staging"}},
+				}
+			},
+		}, domain.EnvironmentAliases{}, PipelineTimeouts{}, logger.New())
+
+		pipelines, err := service.GetKnown(context.Background())
+
+		if err != nil {
+			t.Fatalf("Expected error nil, got %v", err)
+		}
+		expected := []string{"api/staging", "api/staging/eu", "web/staging"}
+		for i, pipeline := range pipelines {
+			if pipeline.Name() != expected[i] {
+				t.Errorf("Expected pipeline %s at %d, got %s", expected[i], i, pipeline.Name())
+			}
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+type exportService struct {
+	pipelineRepository   domain.PipelineRepository
+	eventRepository      domain.PipelineEventRepository
+	deploymentRepository domain.DeploymentRepository
+	identifierRules      domain.IdentifierRules
+	aliases              domain.EnvironmentAliases
+	log                  *logger.Logger
+}
+
+func NewExportService(pipelineRepository domain.PipelineRepository, eventRepository domain.PipelineEventRepository, deploymentRepository domain.DeploymentRepository, identifierRules domain.IdentifierRules, aliases domain.EnvironmentAliases, log *logger.Logger) *exportService {
+	return &exportService{
+		pipelineRepository:   pipelineRepository,
+		eventRepository:      eventRepository,
+		deploymentRepository: deploymentRepository,
+		identifierRules:      identifierRules,
+		aliases:              aliases,
+		log:                  log,
+	}
+}
+
+// Export returns every stored pipeline and pipeline having only deployments, ordered by project and environment
+func (s *exportService) Export(ctx context.Context) (*domain.Export, error) {
+	pipelines, err := s.pipelineRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deployments, err := s.deploymentRepository.FindCurrent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	storedPipelines := make(map[domain.PipelineIdentifier]domain.Pipeline, len(pipelines))
+	for _, pipeline := range pipelines {
+		storedPipelines[pipeline.PipelineIdentifier] = pipeline
+	}
+	export := &domain.Export{
+		Version:    domain.ExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Pipelines:  make([]domain.PipelineExport, 0, len(storedPipelines)),
+	}
+	for _, identifier := range uniqueIdentifiers(append(pipelineIdentifiers(pipelines), deploymentIdentifiers(deployments)...)) {
+		pipelineExport, err := s.exportPipeline(ctx, identifier, storedPipelines)
+		if err != nil {
+			return nil, err
+		}
+		export.Pipelines = append(export.Pipelines, *pipelineExport)
+	}
+	sort.Slice(export.Pipelines, func(i, j int) bool {
+		return export.Pipelines[i].Less(export.Pipelines[j].PipelineIdentifier)
+	})
+
+	return export, nil
+}
+
+// exportPipeline reads history of pipeline, repositories return it newest first and export holds it oldest first
+func (s *exportService) exportPipeline(ctx context.Context, identifier domain.PipelineIdentifier, storedPipelines map[domain.PipelineIdentifier]domain.Pipeline) (*domain.PipelineExport, error) {
+	pipeline, exists := storedPipelines[identifier]
+	if !exists {
+		pipeline = domain.Pipeline{PipelineIdentifier: identifier}
+	}
+	events, err := s.eventRepository.FindByPipeline(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+	deployments, err := s.deploymentRepository.FindHistory(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+	pipelineExport := &domain.PipelineExport{
+		Pipeline:    pipeline,
+		Events:      make([]domain.PipelineEvent, 0, len(events)),
+		Deployments: make([]domain.Deployment, 0, len(deployments)),
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		pipelineExport.Events = append(pipelineExport.Events, events[i])
+	}
+	for i := len(deployments) - 1; i >= 0; i-- {
+		pipelineExport.Deployments = append(pipelineExport.Deployments, deployments[i])
+	}
+
+	return pipelineExport, nil
+}
+
+// Import applies identifier rules to export in place and stores pipelines under canonical environments, so
+// imported pipelines are found by the same requests as pipelines locked through the API
+func (s *exportService) Import(ctx context.Context, export *domain.Export, strategy domain.ImportStrategy) (*domain.ImportReport, error) {
+	if err := strategy.Validate(); err != nil {
+		return nil, err
+	}
+	if err := export.ApplyIdentifierRules(s.identifierRules); err != nil {
+		return nil, err
+	}
+	if err := export.Validate(); err != nil {
+		return nil, err
+	}
+	report := &domain.ImportReport{}
+	now := time.Now()
+	for _, pipelineExport := range export.Pipelines {
+		pipelineExport = pipelineExport.ResolveAliases(s.aliases)
+		existingPipeline, err := s.pipelineRepository.Find(ctx, pipelineExport.PipelineIdentifier)
+		if err != nil {
+			return report, err
+		}
+		if existingPipeline != nil && strategy == domain.ImportSkip {
+			report.Skipped++
+			continue
+		}
+		if existingPipeline == nil || strategy == domain.ImportOverwrite || replacesLock(pipelineExport.Pipeline, *existingPipeline, now) {
+			if err = s.pipelineRepository.Add(ctx, pipelineExport.Pipeline); err != nil {
+				return report, err
+			}
+			report.Pipelines++
+		}
+		if err = s.importHistory(ctx, pipelineExport, report); err != nil {
+			return report, err
+		}
+	}
+	s.log.Info.Printf("Imported %d pipelines, %d events and %d deployments with %s strategy, skipped %d pipelines", report.Pipelines, report.Events, report.Deployments, strategy, report.Skipped)
+
+	return report, nil
+}
+
+// replacesLock reports whether imported pipeline is merged over stored one, lock wins over no lock and later
+// lock wins over earlier one
+func replacesLock(imported, stored domain.Pipeline, now time.Time) bool {
+	if !imported.IsLocked(now) {
+		return false
+	}
+
+	return !stored.IsLocked(now) || imported.LockedAt.After(stored.LockedAt)
+}
+
+// importHistory adds events and deployments missing from storage in order they happened
+func (s *exportService) importHistory(ctx context.Context, pipelineExport domain.PipelineExport, report *domain.ImportReport) error {
+	existingEvents, err := s.eventRepository.FindByPipeline(ctx, pipelineExport.PipelineIdentifier)
+	if err != nil {
+		return err
+	}
+	for _, event := range pipelineExport.Events {
+		if containsEvent(existingEvents, event) {
+			continue
+		}
+		if err = s.eventRepository.Add(ctx, event); err != nil {
+			return err
+		}
+		report.Events++
+	}
+	existingDeployments, err := s.deploymentRepository.FindHistory(ctx, pipelineExport.PipelineIdentifier)
+	if err != nil {
+		return err
+	}
+	for _, deployment := range pipelineExport.Deployments {
+		if containsDeployment(existingDeployments, deployment) {
+			continue
+		}
+		if err = s.deploymentRepository.Add(ctx, deployment); err != nil {
+			return err
+		}
+		report.Deployments++
+	}
+
+	return nil
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+func TestExportService_Export(t *testing.T) {
+	now := time.Now()
+	deployment := domain.Deployment{
+		PipelineIdentifier: domain.PipelineIdentifier{Project: "api", Environment: "dev"},
+		DeploymentDetails:  domain.DeploymentDetails{Version: "1.0.0"},
+		Status:             domain.DeploymentSucceeded,
+	}
+	service := NewExportService(
+		&pipelineRepositoryMock{
+			fakeFindAll: func() []domain.Pipeline {
+				return []domain.Pipeline{*getPipelineMock(user)}
+			},
+		},
+		&pipelineEventRepositoryMock{
+			fakeFindByPipeline: func(pipeline domain.PipelineIdentifier) []domain.PipelineEvent {
+				return []domain.PipelineEvent{
+					{PipelineIdentifier: pipeline, Type: domain.PipelineUnlockedEvent, OccurredAt: now},
+					{PipelineIdentifier: pipeline, Type: domain.PipelineLockedEvent, OccurredAt: now.Add(-time.Minute)},
+				}
+			},
+		},
+		&deploymentRepositoryMock{
+			fakeFindCurrent: func() []domain.Deployment {
+				return []domain.Deployment{deployment}
+			},
+			fakeFindHistory: func(pipeline domain.PipelineIdentifier) []domain.Deployment {
+				if pipeline != deployment.PipelineIdentifier {
+					return nil
+				}
+				return []domain.Deployment{deployment}
+			},
+		},
+		domain.IdentifierRules{},
+		domain.EnvironmentAliases{},
+		logger.New(),
+	)
+
+	export, err := service.Export(context.Background())
+
+	if err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+	if export.Version != domain.ExportVersion || len(export.Pipelines) != 2 {
+		t.Fatalf("Expected 2 pipelines of version %d, got %+v", domain.ExportVersion, export)
+	}
+	deployed, locked := export.Pipelines[0], export.Pipelines[1]
+	if deployed.PipelineIdentifier != deployment.PipelineIdentifier || len(deployed.Deployments) != 1 || deployed.LockedBy != "" {
+		t.Errorf("Expected pipeline having only deployment to be exported first, got %+v", deployed)
+	}
+	if locked.LockedBy != user || len(locked.Events) != 2 || locked.Events[0].Type != domain.PipelineLockedEvent {
+		t.Errorf("Expected locked pipeline with events oldest first, got %+v", locked)
+	}
+	if err = export.Validate(); err != nil {
+		t.Errorf("Expected export to be valid, got %v", err)
+	}
+}
+
+func TestExportService_Import(t *testing.T) {
+	now := time.Now()
+	stored := getExpiringPipelineMock("alice", now.Add(time.Hour))
+	stored.LockedAt = now.Add(-time.Hour)
+	imported := getPipelineMock(user)
+	imported.LockedAt = now
+	importedEvent := domain.PipelineEvent{PipelineIdentifier: getPipelineIdentifierMock(), Type: domain.PipelineLockedEvent, Actor: user, OccurredAt: now}
+
+	type testCases struct {
+		description      string
+		strategy         domain.ImportStrategy
+		stored           *domain.Pipeline
+		imported         domain.Pipeline
+		expectedLockedBy string
+		expectedReport   domain.ImportReport
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:      "pipelineNotStored_pipelineAndHistoryImported",
+			strategy:         domain.ImportSkip,
+			imported:         *imported,
+			expectedLockedBy: user,
+			expectedReport:   domain.ImportReport{Pipelines: 1, Events: 1},
+		},
+		{
+			description:      "skipStoredPipeline_storedPipelineAndHistoryKept",
+			strategy:         domain.ImportSkip,
+			stored:           stored,
+			imported:         *imported,
+			expectedLockedBy: "alice",
+			expectedReport:   domain.ImportReport{Skipped: 1},
+		},
+		{
+			description:      "overwriteStoredPipeline_importedLockStored",
+			strategy:         domain.ImportOverwrite,
+			stored:           stored,
+			imported:         domain.Pipeline{PipelineIdentifier: getPipelineIdentifierMock()},
+			expectedLockedBy: "",
+			expectedReport:   domain.ImportReport{Pipelines: 1, Events: 1},
+		},
+		{
+			description:      "mergeLaterLock_importedLockStored",
+			strategy:         domain.ImportMerge,
+			stored:           stored,
+			imported:         *imported,
+			expectedLockedBy: user,
+			expectedReport:   domain.ImportReport{Pipelines: 1, Events: 1},
+		},
+		{
+			description:      "mergeUnlockedPipeline_storedLockKeptAndHistoryImported",
+			strategy:         domain.ImportMerge,
+			stored:           stored,
+			imported:         domain.Pipeline{PipelineIdentifier: getPipelineIdentifierMock()},
+			expectedLockedBy: "alice",
+			expectedReport:   domain.ImportReport{Events: 1},
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			storedPipeline := scenario.stored
+			var events []domain.PipelineEvent
+			service := NewExportService(
+				&pipelineRepositoryMock{
+					fakeFind: func(pipeline domain.PipelineIdentifier) *domain.Pipeline {
+						return storedPipeline
+					},
+					fakeAdd: func(pipeline domain.Pipeline) {
+						storedPipeline = &pipeline
+					},
+				},
+				&pipelineEventRepositoryMock{
+					fakeAdd: func(event domain.PipelineEvent) {
+						events = append(events, event)
+					},
+					fakeFindByPipeline: func(pipeline domain.PipelineIdentifier) []domain.PipelineEvent {
+						return events
+					},
+				},
+				&deploymentRepositoryMock{},
+				domain.IdentifierRules{},
+				domain.EnvironmentAliases{},
+				logger.New(),
+			)
+			export := &domain.Export{
+				Version:   domain.ExportVersion,
+				Pipelines: []domain.PipelineExport{{Pipeline: scenario.imported, Events: []domain.PipelineEvent{importedEvent}}},
+			}
+
+			report, err := service.Import(context.Background(), export, scenario.strategy)
+
+			if err != nil {
+				t.Fatalf("Expected nil error, got %v", err)
+			}
+			if *report != scenario.expectedReport {
+				t.Errorf("Expected report %+v, got %+v", scenario.expectedReport, *report)
+			}
+			if storedPipeline.LockedBy != scenario.expectedLockedBy {
+				t.Errorf("Expected pipeline locked by %q, got %q", scenario.expectedLockedBy, storedPipeline.LockedBy)
+			}
+			// importing again adds no history
+			report, _ = service.Import(context.Background(), export, scenario.strategy)
+			if report.Events != 0 {
+				t.Errorf("Expected events not to be imported again, got %d", report.Events)
+			}
+		})
+	}
+
+	t.Run("aliasedEnvironmentWithSpaces_storedUnderCanonicalIdentifier", func(t *testing.T) {
+		var storedPipeline *domain.Pipeline
+		var events []domain.PipelineEvent
+		aliases := domain.EnvironmentAliases{}
+		aliases.Add("", "production", "prod")
+		service := NewExportService(
+			&pipelineRepositoryMock{fakeAdd: func(pipeline domain.Pipeline) { storedPipeline = &pipeline }},
+			&pipelineEventRepositoryMock{fakeAdd: func(event domain.PipelineEvent) { events = append(events, event) }},
+			&deploymentRepositoryMock{},
+			domain.IdentifierRules{Trim: true},
+			aliases,
+			logger.New(),
+		)
+		identifier := domain.PipelineIdentifier{Project: " api ", Environment: "PROD "}
+		export := &domain.Export{
+			Version: domain.ExportVersion,
+			Pipelines: []domain.PipelineExport{{
+				Pipeline: domain.Pipeline{PipelineIdentifier: identifier, PipelineLockedBy: domain.PipelineLockedBy{LockedBy: user}},
+				Events:   []domain.PipelineEvent{{PipelineIdentifier: identifier, Type: domain.PipelineLockedEvent, Actor: user}},
+			}},
+		}
+
+		_, err := service.Import(context.Background(), export, domain.ImportSkip)
+
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		expected := domain.PipelineIdentifier{Project: "api", Environment: "production"}
+		if storedPipeline == nil || storedPipeline.PipelineIdentifier != expected {
+			t.Errorf("Expected pipeline stored as %v, got %v", expected, storedPipeline)
+		}
+		if len(events) != 1 || events[0].PipelineIdentifier != expected {
+			t.Errorf("Expected event stored under %v, got %v", expected, events)
+		}
+	})
+
+	t.Run("identifierBreakingRules_nothingStored", func(t *testing.T) {
+		added := false
+		service := NewExportService(&pipelineRepositoryMock{fakeAdd: func(pipeline domain.Pipeline) { added = true }}, &pipelineEventRepositoryMock{}, &deploymentRepositoryMock{}, domain.IdentifierRules{MaxLength: 12}, domain.EnvironmentAliases{}, logger.New())
+		export := &domain.Export{
+			Version:   domain.ExportVersion,
+			Pipelines: []domain.PipelineExport{{Pipeline: *imported}, {Pipeline: domain.Pipeline{PipelineIdentifier: domain.PipelineIdentifier{Project: "too-long-project", Environment: "dev"}}}},
+		}
+
+		_, err := service.Import(context.Background(), export, domain.ImportOverwrite)
+
+		if !errors.Is(err, domain.ErrExportInvalid) || !strings.Contains(err.Error(), domain.ErrProjectTooLong.Error()) || added {
+			t.Errorf("Expected %v of too long project without storing pipelines, got %v and stored %t", domain.ErrExportInvalid, err, added)
+		}
+	})
+
+	t.Run("invalidExport_nothingStored", func(t *testing.T) {
+		added := false
+		service := NewExportService(&pipelineRepositoryMock{fakeAdd: func(pipeline domain.Pipeline) { added = true }}, &pipelineEventRepositoryMock{}, &deploymentRepositoryMock{}, domain.IdentifierRules{}, domain.EnvironmentAliases{}, logger.New())
+		export := &domain.Export{
+			Version:   domain.ExportVersion,
+			Pipelines: []domain.PipelineExport{{Pipeline: *imported}, {Pipeline: domain.Pipeline{}}},
+		}
+
+		_, err := service.Import(context.Background(), export, domain.ImportOverwrite)
+
+		if !errors.Is(err, domain.ErrExportInvalid) || added {
+			t.Errorf("Expected %v without storing pipelines, got %v and stored %t", domain.ErrExportInvalid, err, added)
+		}
+	})
+
+	t.Run("unknownStrategy_returnStrategyInvalidError", func(t *testing.T) {
+		service := NewExportService(&pipelineRepositoryMock{}, &pipelineEventRepositoryMock{}, &deploymentRepositoryMock{}, domain.IdentifierRules{}, domain.EnvironmentAliases{}, logger.New())
+
+		_, err := service.Import(context.Background(), &domain.Export{Version: domain.ExportVersion}, "replace")
+
+		if err != domain.ErrImportStrategyInvalid {
+			t.Errorf("Expected %v, got %v", domain.ErrImportStrategyInvalid, err)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type pipelineKeyService struct {
+	pipelineRepository domain.PipelineRepository
+	keyRepository      domain.PipelineKeyRepository
+	caseSensitiveKey   bool
+}
+
+func NewPipelineKeyService(pipelineRepository domain.PipelineRepository, keyRepository domain.PipelineKeyRepository, caseSensitiveKey bool) *pipelineKeyService {
+	return &pipelineKeyService{
+		pipelineRepository: pipelineRepository,
+		keyRepository:      keyRepository,
+		caseSensitiveKey:   caseSensitiveKey,
+	}
+}
+
+// Check looks every stored pipeline up by its current key, pipeline found under the key is stored there and
+// every other pipeline of the key is orphaned
+func (s *pipelineKeyService) Check(ctx context.Context) (*domain.KeyCheckReport, error) {
+	pipelines, err := s.pipelineRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.KeyCheckReport{
+		Orphaned:   make([]domain.PipelineIdentifier, 0),
+		Collisions: make([][]domain.Pipeline, 0),
+	}
+	groups := make(map[string][]domain.Pipeline)
+	keys := make([]string, 0)
+	for _, pipeline := range pipelines {
+		stored, err := s.pipelineRepository.Find(ctx, pipeline.PipelineIdentifier)
+		if err != nil {
+			return nil, err
+		}
+		key := pipeline.PipelineIdentifier.GetKey(s.caseSensitiveKey)
+		if _, exists := groups[key]; !exists {
+			keys = append(keys, key)
+		}
+		if stored != nil && stored.PipelineIdentifier == pipeline.PipelineIdentifier {
+			groups[key] = append([]domain.Pipeline{pipeline}, groups[key]...)
+			continue
+		}
+		report.Orphaned = append(report.Orphaned, pipeline.PipelineIdentifier)
+		groups[key] = append(groups[key], pipeline)
+	}
+	for _, key := range keys {
+		if len(groups[key]) > 1 {
+			report.Collisions = append(report.Collisions, groups[key])
+		}
+	}
+
+	return report, nil
+}
+
+// Migrate stores newest lock of colliding pipelines under their key before keys are migrated, so the others
+// are dropped by migration. With keep all strategy colliding pipelines are not migrated.
+func (s *pipelineKeyService) Migrate(ctx context.Context, strategy domain.KeyConflictStrategy, dryRun bool) (*domain.KeyMigrationReport, error) {
+	if err := strategy.Validate(); err != nil {
+		return nil, err
+	}
+	check, err := s.Check(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.KeyMigrationReport{KeyCheckReport: *check, DryRun: dryRun}
+	orphaned := make(map[domain.PipelineIdentifier]bool)
+	for _, identifier := range check.Orphaned {
+		orphaned[identifier] = true
+	}
+	keep := make(map[domain.PipelineIdentifier]bool)
+	now := time.Now()
+	for _, collision := range check.Collisions {
+		if strategy == domain.KeyConflictKeepAll {
+			for _, pipeline := range collision {
+				keep[pipeline.PipelineIdentifier] = true
+				if orphaned[pipeline.PipelineIdentifier] {
+					report.Kept++
+				}
+			}
+			continue
+		}
+		winner := newestLock(collision, now)
+		report.Dropped += len(collision) - 1
+		if dryRun || !orphaned[winner.PipelineIdentifier] {
+			continue
+		}
+		if err = s.pipelineRepository.Add(ctx, winner); err != nil {
+			return report, err
+		}
+	}
+	if dryRun {
+		return report, nil
+	}
+	report.Migrated, err = s.keyRepository.MigrateKeys(keep)
+
+	return report, err
+}
+
+// newestLock returns pipeline whose lock wins over locks of other pipelines, pipeline stored under the key
+// wins when none of the others holds newer lock
+func newestLock(pipelines []domain.Pipeline, now time.Time) domain.Pipeline {
+	winner := pipelines[0]
+	for _, pipeline := range pipelines[1:] {
+		if replacesLock(pipeline, winner, now) {
+			winner = pipeline
+		}
+	}
+
+	return winner
+}
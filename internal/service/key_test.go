@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+)
+
+type pipelineKeyRepositoryMock struct {
+	fakeMigrateKeys func(keep map[domain.PipelineIdentifier]bool) int
+}
+
+func (r *pipelineKeyRepositoryMock) MigrateKeys(keep map[domain.PipelineIdentifier]bool) (int, error) {
+	if r.fakeMigrateKeys != nil {
+		return r.fakeMigrateKeys(keep), nil
+	}
+
+	return 0, nil
+}
+
+// keyedPipelineRepositoryMock finds pipeline stored under case insensitive key, while pipelines stored earlier
+// under case sensitive keys are listed too
+func keyedPipelineRepositoryMock(storedUnderKey []domain.Pipeline, storedUnderStaleKey []domain.Pipeline, added *[]domain.Pipeline) *pipelineRepositoryMock {
+	return &pipelineRepositoryMock{
+		fakeFind: func(identifier domain.PipelineIdentifier) *domain.Pipeline {
+			for _, pipeline := range storedUnderKey {
+				if pipeline.GetKey(false) == identifier.GetKey(false) {
+					return &pipeline
+				}
+			}
+			return nil
+		},
+		fakeFindAll: func() []domain.Pipeline {
+			return append(append([]domain.Pipeline{}, storedUnderStaleKey...), storedUnderKey...)
+		},
+		fakeAdd: func(pipeline domain.Pipeline) {
+			*added = append(*added, pipeline)
+		},
+	}
+}
+
+func TestPipelineKeyService_Check(t *testing.T) {
+	current := domain.Pipeline{PipelineIdentifier: domain.PipelineIdentifier{Project: "api", Environment: environment}}
+	colliding := domain.Pipeline{PipelineIdentifier: domain.PipelineIdentifier{Project: "Api", Environment: environment}}
+	orphaned := domain.Pipeline{PipelineIdentifier: domain.PipelineIdentifier{Project: "Web", Environment: environment}}
+	var added []domain.Pipeline
+	service := NewPipelineKeyService(keyedPipelineRepositoryMock([]domain.Pipeline{current}, []domain.Pipeline{colliding, orphaned}, &added), &pipelineKeyRepositoryMock{}, false)
+
+	report, err := service.Check(context.Background())
+
+	if err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+	if expected := []domain.PipelineIdentifier{colliding.PipelineIdentifier, orphaned.PipelineIdentifier}; !reflect.DeepEqual(report.Orphaned, expected) {
+		t.Errorf("Expected orphaned pipelines %v, got %v", expected, report.Orphaned)
+	}
+	if expected := [][]domain.Pipeline{{current, colliding}}; !reflect.DeepEqual(report.Collisions, expected) {
+		t.Errorf("Expected pipeline stored under key first in collisions %v, got %v", expected, report.Collisions)
+	}
+}
+
+func TestPipelineKeyService_Migrate(t *testing.T) {
+	now := time.Now()
+	older := domain.Pipeline{
+		PipelineIdentifier: domain.PipelineIdentifier{Project: "api", Environment: environment},
+		PipelineLockedBy:   domain.PipelineLockedBy{LockedBy: "alice"},
+		PipelineLockedAt:   domain.PipelineLockedAt{LockedAt: now.Add(-time.Hour)},
+	}
+	newer := domain.Pipeline{
+		PipelineIdentifier: domain.PipelineIdentifier{Project: "Api", Environment: environment},
+		PipelineLockedBy:   domain.PipelineLockedBy{LockedBy: user},
+		PipelineLockedAt:   domain.PipelineLockedAt{LockedAt: now},
+	}
+	unlocked := domain.Pipeline{PipelineIdentifier: domain.PipelineIdentifier{Project: "API", Environment: environment}}
+
+	type testCases struct {
+		description    string
+		strategy       domain.KeyConflictStrategy
+		dryRun         bool
+		storedUnderKey domain.Pipeline
+		storedStale    domain.Pipeline
+		expectedAdded  []domain.Pipeline
+		expectedKeep   map[domain.PipelineIdentifier]bool
+		expectedReport domain.KeyMigrationReport
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:    "keepAll_collidingPipelinesKept",
+			strategy:       domain.KeyConflictKeepAll,
+			storedUnderKey: older,
+			storedStale:    newer,
+			expectedKeep:   map[domain.PipelineIdentifier]bool{older.PipelineIdentifier: true, newer.PipelineIdentifier: true},
+			expectedReport: domain.KeyMigrationReport{Migrated: 1, Kept: 1},
+		},
+		{
+			description:    "newestLockStoredUnderStaleKey_newestLockAdded",
+			strategy:       domain.KeyConflictNewestLock,
+			storedUnderKey: older,
+			storedStale:    newer,
+			expectedAdded:  []domain.Pipeline{newer},
+			expectedKeep:   map[domain.PipelineIdentifier]bool{},
+			expectedReport: domain.KeyMigrationReport{Migrated: 1, Dropped: 1},
+		},
+		{
+			description:    "newestLockStoredUnderKey_nothingAdded",
+			strategy:       domain.KeyConflictNewestLock,
+			storedUnderKey: older,
+			storedStale:    unlocked,
+			expectedKeep:   map[domain.PipelineIdentifier]bool{},
+			expectedReport: domain.KeyMigrationReport{Migrated: 1, Dropped: 1},
+		},
+		{
+			description:    "dryRun_nothingAddedNorMigrated",
+			strategy:       domain.KeyConflictNewestLock,
+			dryRun:         true,
+			storedUnderKey: older,
+			storedStale:    newer,
+			expectedReport: domain.KeyMigrationReport{DryRun: true, Dropped: 1},
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			var added []domain.Pipeline
+			var keep map[domain.PipelineIdentifier]bool
+			service := NewPipelineKeyService(keyedPipelineRepositoryMock([]domain.Pipeline{scenario.storedUnderKey}, []domain.Pipeline{scenario.storedStale}, &added), &pipelineKeyRepositoryMock{
+				fakeMigrateKeys: func(pipelines map[domain.PipelineIdentifier]bool) int {
+					keep = pipelines
+					return 1
+				},
+			}, false)
+
+			report, err := service.Migrate(context.Background(), scenario.strategy, scenario.dryRun)
+
+			if err != nil {
+				t.Fatalf("Expected nil error, got %v", err)
+			}
+			if !reflect.DeepEqual(added, scenario.expectedAdded) {
+				t.Errorf("Expected added pipelines %v, got %v", scenario.expectedAdded, added)
+			}
+			if !reflect.DeepEqual(keep, scenario.expectedKeep) {
+				t.Errorf("Expected kept pipelines %v, got %v", scenario.expectedKeep, keep)
+			}
+			report.KeyCheckReport = domain.KeyCheckReport{}
+			if !reflect.DeepEqual(*report, scenario.expectedReport) {
+				t.Errorf("Expected report %+v, got %+v", scenario.expectedReport, *report)
+			}
+		})
+	}
+
+	t.Run("invalidStrategy_returnsErrKeyConflictStrategyInvalid", func(t *testing.T) {
+		var added []domain.Pipeline
+		service := NewPipelineKeyService(keyedPipelineRepositoryMock(nil, nil, &added), &pipelineKeyRepositoryMock{}, false)
+
+		_, err := service.Migrate(context.Background(), "oldest", false)
+
+		if err != domain.ErrKeyConflictStrategyInvalid {
+			t.Errorf("Expected error %v, got %v", domain.ErrKeyConflictStrategyInvalid, err)
+		}
+	})
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+// timePrecision is the coarsest precision storages keep timestamps with, postgres rounds to microseconds
+const timePrecision = time.Millisecond
+
+// MigrationRepositories are repositories of single storage taking part in migration
+type MigrationRepositories struct {
+	Pipelines   domain.PipelineRepository
+	Events      domain.PipelineEventRepository
+	Deployments domain.DeploymentRepository
+	// UnlockRequests holds pending unlock requests, reviewed ones are kept as pipeline events
+	UnlockRequests domain.UnlockRequestRepository
+}
+
+type migrationService struct {
+	source MigrationRepositories
+	target MigrationRepositories
+	log    *logger.Logger
+}
+
+func NewMigrationService(source, target MigrationRepositories, log *logger.Logger) *migrationService {
+	return &migrationService{
+		source: source,
+		target: target,
+		log:    log,
+	}
+}
+
+// Migrate overwrites pipelines in target storage and adds events, deployments and pending unlock requests
+// missing from it, so interrupted migration can be run again
+func (s *migrationService) Migrate(ctx context.Context, dryRun bool) (*domain.MigrationReport, error) {
+	report := &domain.MigrationReport{DryRun: dryRun}
+	pipelines, err := s.source.Pipelines.FindAll(ctx)
+	if err != nil {
+		return report, err
+	}
+	identifiers, err := s.identifiers(ctx, pipelines)
+	if err != nil {
+		return report, err
+	}
+	for _, pipeline := range pipelines {
+		if !dryRun {
+			if err = s.target.Pipelines.Add(ctx, pipeline); err != nil {
+				return report, err
+			}
+		}
+		report.Pipelines++
+	}
+	for _, identifier := range identifiers {
+		if err = s.migrateEvents(ctx, identifier, dryRun, report); err != nil {
+			return report, err
+		}
+		if err = s.migrateDeployments(ctx, identifier, dryRun, report); err != nil {
+			return report, err
+		}
+	}
+	if err = s.migrateUnlockRequests(ctx, dryRun, report); err != nil {
+		return report, err
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	return report, s.verify(ctx, pipelines)
+}
+
+// identifiers returns stored pipelines and pipelines having only deployments
+func (s *migrationService) identifiers(ctx context.Context, pipelines []domain.Pipeline) ([]domain.PipelineIdentifier, error) {
+	deployments, err := s.source.Deployments.FindCurrent(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return uniqueIdentifiers(append(pipelineIdentifiers(pipelines), deploymentIdentifiers(deployments)...)), nil
+}
+
+func uniqueIdentifiers(identifiers []domain.PipelineIdentifier) []domain.PipelineIdentifier {
+	seen := make(map[domain.PipelineIdentifier]bool)
+	unique := make([]domain.PipelineIdentifier, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		if !seen[identifier] {
+			seen[identifier] = true
+			unique = append(unique, identifier)
+		}
+	}
+
+	return unique
+}
+
+func pipelineIdentifiers(pipelines []domain.Pipeline) []domain.PipelineIdentifier {
+	identifiers := make([]domain.PipelineIdentifier, 0, len(pipelines))
+	for _, pipeline := range pipelines {
+		identifiers = append(identifiers, pipeline.PipelineIdentifier)
+	}
+
+	return identifiers
+}
+
+func deploymentIdentifiers(deployments []domain.Deployment) []domain.PipelineIdentifier {
+	identifiers := make([]domain.PipelineIdentifier, 0, len(deployments))
+	for _, deployment := range deployments {
+		identifiers = append(identifiers, deployment.PipelineIdentifier)
+	}
+
+	return identifiers
+}
+
+// migrateEvents adds events oldest first, events already in target are skipped
+func (s *migrationService) migrateEvents(ctx context.Context, identifier domain.PipelineIdentifier, dryRun bool, report *domain.MigrationReport) error {
+	events, err := s.source.Events.FindByPipeline(ctx, identifier)
+	if err != nil {
+		return err
+	}
+	existingEvents, err := s.target.Events.FindByPipeline(ctx, identifier)
+	if err != nil {
+		return err
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		if containsEvent(existingEvents, events[i]) {
+			report.Skipped++
+			continue
+		}
+		if !dryRun {
+			if err = s.target.Events.Add(ctx, events[i]); err != nil {
+				return err
+			}
+		}
+		report.Events++
+	}
+
+	return nil
+}
+
+// migrateDeployments adds deployments oldest first, so the latest successful one becomes current in target
+func (s *migrationService) migrateDeployments(ctx context.Context, identifier domain.PipelineIdentifier, dryRun bool, report *domain.MigrationReport) error {
+	deployments, err := s.source.Deployments.FindHistory(ctx, identifier)
+	if err != nil {
+		return err
+	}
+	existingDeployments, err := s.target.Deployments.FindHistory(ctx, identifier)
+	if err != nil {
+		return err
+	}
+	for i := len(deployments) - 1; i >= 0; i-- {
+		if containsDeployment(existingDeployments, deployments[i]) {
+			report.Skipped++
+			continue
+		}
+		if !dryRun {
+			if err = s.target.Deployments.Add(ctx, deployments[i]); err != nil {
+				return err
+			}
+		}
+		report.Deployments++
+	}
+
+	return nil
+}
+
+// migrateUnlockRequests adds pending unlock requests under their ids, requests already in target are skipped
+func (s *migrationService) migrateUnlockRequests(ctx context.Context, dryRun bool, report *domain.MigrationReport) error {
+	requests, err := s.source.UnlockRequests.FindPending(ctx)
+	if err != nil {
+		return err
+	}
+	for _, request := range requests {
+		existingRequest, err := s.target.UnlockRequests.Find(ctx, request.ID)
+		if err != nil {
+			return err
+		}
+		if existingRequest != nil {
+			report.Skipped++
+			continue
+		}
+		if !dryRun {
+			if err = s.target.UnlockRequests.Add(ctx, request); err != nil {
+				return err
+			}
+		}
+		report.UnlockRequests++
+	}
+
+	return nil
+}
+
+// verify reads every pipeline back from target storage and compares its lock
+func (s *migrationService) verify(ctx context.Context, pipelines []domain.Pipeline) error {
+	now := time.Now()
+	for _, pipeline := range pipelines {
+		stored, err := s.target.Pipelines.Find(ctx, pipeline.PipelineIdentifier)
+		if err != nil {
+			return err
+		}
+		if !sameLock(pipeline, stored, now) {
+			s.log.Error.Printf("Pipeline %s differs in target storage: expected %+v, got %+v", pipeline.Name(), pipeline, stored)
+			return domain.ErrMigrationNotVerified
+		}
+	}
+
+	return nil
+}
+
+// sameLock compares lock state, unlocked pipelines may be left out of target storage
+func sameLock(pipeline domain.Pipeline, stored *domain.Pipeline, now time.Time) bool {
+	if !pipeline.IsLocked(now) {
+		return stored == nil || !stored.IsLocked(now)
+	}
+	if stored == nil || !stored.IsLocked(now) || stored.LockedBy != pipeline.LockedBy {
+		return false
+	}
+	if pipeline.LockedUntil == nil || stored.LockedUntil == nil {
+		return pipeline.LockedUntil == stored.LockedUntil
+	}
+
+	return sameTime(*pipeline.LockedUntil, *stored.LockedUntil)
+}
+
+func containsEvent(events []domain.PipelineEvent, event domain.PipelineEvent) bool {
+	for _, existing := range events {
+		if existing.Type == event.Type && existing.Actor == event.Actor && existing.Details == event.Details && existing.URL == event.URL && sameTime(existing.OccurredAt, event.OccurredAt) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsDeployment(deployments []domain.Deployment, deployment domain.Deployment) bool {
+	for _, existing := range deployments {
+		if existing.Status == deployment.Status && existing.DeploymentDetails == deployment.DeploymentDetails && sameTime(existing.DeployedAt, deployment.DeployedAt) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameTime compares times stored with different precision
+func sameTime(a, b time.Time) bool {
+	difference := a.Sub(b)
+
+	return difference < timePrecision && difference > -timePrecision
+}
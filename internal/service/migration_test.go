@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+// migrationTargetMock stores added pipelines, events and deployments
+type migrationTargetMock struct {
+	pipelines   map[domain.PipelineIdentifier]domain.Pipeline
+	events      []domain.PipelineEvent
+	deployments []domain.Deployment
+	requests    map[string]domain.UnlockRequest
+}
+
+func newMigrationTargetMock() *migrationTargetMock {
+	return &migrationTargetMock{
+		pipelines: make(map[domain.PipelineIdentifier]domain.Pipeline),
+		requests:  make(map[string]domain.UnlockRequest),
+	}
+}
+
+func (m *migrationTargetMock) repositories() MigrationRepositories {
+	return MigrationRepositories{
+		Pipelines: &pipelineRepositoryMock{
+			fakeAdd: func(pipeline domain.Pipeline) {
+				m.pipelines[pipeline.PipelineIdentifier] = pipeline
+			},
+			fakeFind: func(identifier domain.PipelineIdentifier) *domain.Pipeline {
+				if pipeline, ok := m.pipelines[identifier]; ok {
+					return &pipeline
+				}
+				return nil
+			},
+		},
+		Events: &pipelineEventRepositoryMock{
+			fakeAdd: func(event domain.PipelineEvent) {
+				m.events = append(m.events, event)
+			},
+			fakeFindByPipeline: func(pipeline domain.PipelineIdentifier) []domain.PipelineEvent {
+				return m.events
+			},
+		},
+		Deployments: &deploymentRepositoryMock{
+			fakeAdd: func(deployment domain.Deployment) {
+				m.deployments = append(m.deployments, deployment)
+			},
+			fakeFindHistory: func(pipeline domain.PipelineIdentifier) []domain.Deployment {
+				return m.deployments
+			},
+		},
+		UnlockRequests: &unlockRequestRepositoryMock{
+			fakeAdd: func(request domain.UnlockRequest) {
+				m.requests[request.ID] = request
+			},
+			fakeFind: func(id string) *domain.UnlockRequest {
+				if request, ok := m.requests[id]; ok {
+					return &request
+				}
+				return nil
+			},
+		},
+	}
+}
+
+func getMigrationSourceMock() MigrationRepositories {
+	now := time.Now()
+	lockedUntil := now.Add(time.Hour)
+	deployment := domain.Deployment{
+		PipelineIdentifier: domain.PipelineIdentifier{Project: project, Environment: "dev"},
+		DeploymentDetails:  domain.DeploymentDetails{Version: "1.0.0"},
+		Status:             domain.DeploymentSucceeded,
+		DeployedAt:         now,
+	}
+
+	return MigrationRepositories{
+		Pipelines: &pipelineRepositoryMock{
+			fakeFindAll: func() []domain.Pipeline {
+				return []domain.Pipeline{*getExpiringPipelineMock(user, lockedUntil), {PipelineIdentifier: domain.PipelineIdentifier{Project: project, Environment: "test"}}}
+			},
+		},
+		Events: &pipelineEventRepositoryMock{
+			fakeFindByPipeline: func(pipeline domain.PipelineIdentifier) []domain.PipelineEvent {
+				if pipeline != getPipelineIdentifierMock() {
+					return nil
+				}
+				// newest first
+				return []domain.PipelineEvent{
+					{PipelineIdentifier: pipeline, Type: domain.PipelineLockedEvent, Actor: user, OccurredAt: now},
+					{PipelineIdentifier: pipeline, Type: domain.PipelineUnlockedEvent, Actor: user, OccurredAt: now.Add(-time.Minute)},
+				}
+			},
+		},
+		Deployments: &deploymentRepositoryMock{
+			fakeFindCurrent: func() []domain.Deployment {
+				return []domain.Deployment{deployment}
+			},
+			fakeFindHistory: func(pipeline domain.PipelineIdentifier) []domain.Deployment {
+				if pipeline != deployment.PipelineIdentifier {
+					return nil
+				}
+				return []domain.Deployment{deployment}
+			},
+		},
+		UnlockRequests: &unlockRequestRepositoryMock{
+			fakeFindPending: func() []domain.UnlockRequest {
+				return []domain.UnlockRequest{*getUnlockRequestMock(domain.UnlockRequestPending, lockedUntil)}
+			},
+		},
+	}
+}
+
+func TestMigrationService_Migrate(t *testing.T) {
+	t.Run("Migrate_emptyTarget_copiesPipelinesEventsAndDeployments", func(t *testing.T) {
+		target := newMigrationTargetMock()
+		service := NewMigrationService(getMigrationSourceMock(), target.repositories(), logger.New())
+
+		report, err := service.Migrate(context.Background(), false)
+
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		expected := domain.MigrationReport{Pipelines: 2, Events: 2, Deployments: 1, UnlockRequests: 1}
+		if *report != expected {
+			t.Errorf("Expected report %+v, got %+v", expected, *report)
+		}
+		if len(target.pipelines) != 2 || target.pipelines[getPipelineIdentifierMock()].LockedBy != user {
+			t.Errorf("Expected 2 pipelines with locked one, got %v", target.pipelines)
+		}
+		if len(target.events) != 2 || target.events[0].Type != domain.PipelineUnlockedEvent {
+			t.Errorf("Expected events to be added oldest first, got %v", target.events)
+		}
+		if len(target.requests) != 1 {
+			t.Errorf("Expected pending unlock request, got %v", target.requests)
+		}
+	})
+
+	t.Run("Migrate_runAgain_eventsDeploymentsAndUnlockRequestsSkipped", func(t *testing.T) {
+		target := newMigrationTargetMock()
+		service := NewMigrationService(getMigrationSourceMock(), target.repositories(), logger.New())
+		service.Migrate(context.Background(), false)
+
+		report, err := service.Migrate(context.Background(), false)
+
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		expected := domain.MigrationReport{Pipelines: 2, Skipped: 4}
+		if *report != expected {
+			t.Errorf("Expected report %+v, got %+v", expected, *report)
+		}
+		if len(target.events) != 2 || len(target.deployments) != 1 {
+			t.Errorf("Expected nothing to be added again, got %d events and %d deployments", len(target.events), len(target.deployments))
+		}
+	})
+
+	t.Run("Migrate_dryRun_nothingWritten", func(t *testing.T) {
+		target := newMigrationTargetMock()
+		service := NewMigrationService(getMigrationSourceMock(), target.repositories(), logger.New())
+
+		report, err := service.Migrate(context.Background(), true)
+
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		expected := domain.MigrationReport{DryRun: true, Pipelines: 2, Events: 2, Deployments: 1, UnlockRequests: 1}
+		if *report != expected {
+			t.Errorf("Expected report %+v, got %+v", expected, *report)
+		}
+		if len(target.pipelines) != 0 || len(target.events) != 0 || len(target.deployments) != 0 || len(target.requests) != 0 {
+			t.Errorf("Expected nothing to be written, got %v, %v, %v and %v", target.pipelines, target.events, target.deployments, target.requests)
+		}
+	})
+
+	t.Run("Migrate_targetLosesLock_returnsErrMigrationNotVerified", func(t *testing.T) {
+		target := newMigrationTargetMock()
+		repositories := target.repositories()
+		repositories.Pipelines = &pipelineRepositoryMock{}
+		service := NewMigrationService(getMigrationSourceMock(), repositories, logger.New())
+
+		_, err := service.Migrate(context.Background(), false)
+
+		if err != domain.ErrMigrationNotVerified {
+			t.Errorf("Expected %v, got %v", domain.ErrMigrationNotVerified, err)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+const overrideTokenBytes = 16
+
+type overrideService struct {
+	repository      domain.OverrideTokenRepository
+	eventRepository domain.PipelineEventRepository
+	ttl             time.Duration
+	aliases         domain.EnvironmentAliases
+	timeouts        PipelineTimeouts
+	log             *logger.Logger
+}
+
+func NewOverrideService(repository domain.OverrideTokenRepository, eventRepository domain.PipelineEventRepository, ttl time.Duration, aliases domain.EnvironmentAliases, timeouts PipelineTimeouts, log *logger.Logger) *overrideService {
+	return &overrideService{
+		repository:      repository,
+		eventRepository: eventRepository,
+		ttl:             ttl,
+		aliases:         aliases,
+		timeouts:        timeouts,
+		log:             log,
+	}
+}
+
+func (s *overrideService) Issue(ctx context.Context, request domain.OverrideTokenRequest) (*domain.OverrideToken, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Lock)
+	defer cancel()
+	token, err := s.issue(ctx, request)
+
+	return token, contextError(ctx, err)
+}
+
+func (s *overrideService) issue(ctx context.Context, request domain.OverrideTokenRequest) (*domain.OverrideToken, error) {
+	request.PipelineIdentifier = s.aliases.Resolve(request.PipelineIdentifier)
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	value, err := generateToken(overrideTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	token := domain.OverrideToken{
+		PipelineIdentifier: request.PipelineIdentifier,
+		Token:              value,
+		IssuedBy:           request.IssuedBy,
+		IssuedAt:           now,
+		ExpiresAt:          now.Add(s.ttl),
+	}
+	if err = s.repository.Add(ctx, token); err != nil {
+		return nil, err
+	}
+	s.log.Info.Printf("Override token for pipeline %s issued by %s, valid until %s", token.Name(), token.IssuedBy, token.ExpiresAt.Format(time.RFC3339))
+	if err = s.eventRepository.Add(ctx, domain.PipelineEvent{
+		PipelineIdentifier: token.PipelineIdentifier,
+		Type:               domain.OverrideTokenIssuedEvent,
+		Actor:              token.IssuedBy,
+		Details:            "valid until " + token.ExpiresAt.Format(time.RFC3339),
+		OccurredAt:         now,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// Use consumes token of locked pipeline, deploy status check is limited by status timeout
+func (s *overrideService) Use(ctx context.Context, pipeline domain.PipelineIdentifier, token string) (bool, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Status)
+	defer cancel()
+	used, err := s.use(ctx, pipeline, token)
+
+	return used, contextError(ctx, err)
+}
+
+func (s *overrideService) use(ctx context.Context, pipeline domain.PipelineIdentifier, token string) (bool, error) {
+	pipeline = s.aliases.Resolve(pipeline)
+	if err := pipeline.Validate(); err != nil {
+		return false, err
+	}
+	if token == "" {
+		return false, nil
+	}
+	consumed, err := s.repository.Consume(ctx, pipeline, token)
+	if err != nil {
+		return false, err
+	}
+	if consumed == nil {
+		s.log.Info.Printf("Invalid or already used override token presented for pipeline %s", pipeline.Name())
+		return false, nil
+	}
+	s.log.Info.Printf("Override token issued by %s used for locked pipeline %s", consumed.IssuedBy, pipeline.Name())
+	if err = s.eventRepository.Add(ctx, domain.PipelineEvent{
+		PipelineIdentifier: pipeline,
+		Type:               domain.OverrideTokenUsedEvent,
+		Actor:              consumed.IssuedBy,
+		Details:            "token issued by " + consumed.IssuedBy,
+		OccurredAt:         time.Now(),
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
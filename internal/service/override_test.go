@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+const overrideTTL = time.Minute
+
+type overrideTokenRepositoryMock struct {
+	domain.OverrideTokenRepository
+	fakeAdd     func(token domain.OverrideToken)
+	fakeConsume func(pipeline domain.PipelineIdentifier, token string) *domain.OverrideToken
+}
+
+func (r *overrideTokenRepositoryMock) Add(ctx context.Context, token domain.OverrideToken) error {
+	if r.fakeAdd != nil {
+		r.fakeAdd(token)
+	}
+
+	return nil
+}
+
+func (r *overrideTokenRepositoryMock) Consume(ctx context.Context, pipeline domain.PipelineIdentifier, token string) (*domain.OverrideToken, error) {
+	if r.fakeConsume != nil {
+		return r.fakeConsume(pipeline, token), nil
+	}
+
+	return nil, nil
+}
+
+func TestOverrideService_Issue(t *testing.T) {
+	type testCases struct {
+		description      string
+		input            domain.OverrideTokenRequest
+		expectedError    error
+		expectedAddCalls int
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:   "projectIsEmpty_returnError",
+			input:         domain.OverrideTokenRequest{},
+			expectedError: domain.ErrProjectEmpty,
+		},
+		{
+			description: "issuedByIsEmpty_returnError",
+			input: domain.OverrideTokenRequest{
+				PipelineIdentifier: getPipelineIdentifierMock(),
+			},
+			expectedError: domain.ErrIssuedByEmpty,
+		},
+		{
+			description: "inputIsOK_callsAddWithTokenBoundToPipeline",
+			input: domain.OverrideTokenRequest{
+				PipelineIdentifier: getPipelineIdentifierMock(),
+				IssuedBy:           user,
+			},
+			expectedAddCalls: 1,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			var addCallsCount int
+			var addedToken domain.OverrideToken
+			repository := &overrideTokenRepositoryMock{
+				fakeAdd: func(token domain.OverrideToken) {
+					addCallsCount++
+					addedToken = token
+				},
+			}
+			service := NewOverrideService(repository, &pipelineEventRepositoryMock{}, overrideTTL, domain.EnvironmentAliases{}, PipelineTimeouts{}, logger.New())
+
+			token, err := service.Issue(context.Background(), scenario.input)
+
+			if !errors.Is(err, scenario.expectedError) {
+				t.Errorf("Expected error %s, but received %s", scenario.expectedError, err)
+			}
+			if addCallsCount != scenario.expectedAddCalls {
+				t.Errorf("Expected repository Add method calls %d, but Add was called %d times", scenario.expectedAddCalls, addCallsCount)
+			}
+			if scenario.expectedAddCalls == 0 {
+				return
+			}
+			if token == nil || token.Token == "" || token.Token != addedToken.Token {
+				t.Errorf("Expected issued token to be returned, got %v", token)
+			} else if token.PipelineIdentifier != scenario.input.PipelineIdentifier {
+				t.Errorf("Expected token to be bound to %v, got %v", scenario.input.PipelineIdentifier, token.PipelineIdentifier)
+			} else if token.ExpiresAt.Sub(token.IssuedAt) != overrideTTL {
+				t.Errorf("Expected token to expire after %s, got %s", overrideTTL, token.ExpiresAt.Sub(token.IssuedAt))
+			}
+		})
+	}
+}
+
+func TestOverrideService_Use(t *testing.T) {
+	type testCases struct {
+		description            string
+		input                  domain.PipelineIdentifier
+		token                  string
+		fakeConsumeReturnValue *domain.OverrideToken
+		expectedError          error
+		expectedValue          bool
+		expectedConsumeCalls   int
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:   "projectIsEmpty_returnError",
+			input:         domain.PipelineIdentifier{},
+			token:         "token",
+			expectedError: domain.ErrProjectEmpty,
+		},
+		{
+			description: "tokenIsEmpty_returnFalse",
+			input:       getPipelineIdentifierMock(),
+		},
+		{
+			description:          "tokenNotConsumed_returnFalse",
+			input:                getPipelineIdentifierMock(),
+			token:                "token",
+			expectedConsumeCalls: 1,
+		},
+		{
+			description: "tokenConsumed_returnTrue",
+			input:       getPipelineIdentifierMock(),
+			token:       "token",
+			fakeConsumeReturnValue: &domain.OverrideToken{
+				PipelineIdentifier: getPipelineIdentifierMock(),
+				Token:              "token",
+				IssuedBy:           user,
+			},
+			expectedValue:        true,
+			expectedConsumeCalls: 1,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			var consumeCallsCount int
+			var addedEvents []domain.PipelineEvent
+			repository := &overrideTokenRepositoryMock{
+				fakeConsume: func(pipeline domain.PipelineIdentifier, token string) *domain.OverrideToken {
+					consumeCallsCount++
+					return scenario.fakeConsumeReturnValue
+				},
+			}
+			eventRepository := &pipelineEventRepositoryMock{
+				fakeAdd: func(event domain.PipelineEvent) {
+					addedEvents = append(addedEvents, event)
+				},
+			}
+			service := NewOverrideService(repository, eventRepository, overrideTTL, domain.EnvironmentAliases{}, PipelineTimeouts{}, logger.New())
+
+			allowed, err := service.Use(context.Background(), scenario.input, scenario.token)
+
+			if !errors.Is(err, scenario.expectedError) {
+				t.Errorf("Expected error %s, but received %s", scenario.expectedError, err)
+			}
+			if allowed != scenario.expectedValue {
+				t.Errorf("Expected return value %t, got %t", scenario.expectedValue, allowed)
+			}
+			if consumeCallsCount != scenario.expectedConsumeCalls {
+				t.Errorf("Expected repository Consume method calls %d, but Consume was called %d times", scenario.expectedConsumeCalls, consumeCallsCount)
+			}
+			if scenario.expectedValue && (len(addedEvents) != 1 || addedEvents[0].Type != domain.OverrideTokenUsedEvent) {
+				t.Errorf("Expected %s event to be recorded, got %v", domain.OverrideTokenUsedEvent, addedEvents)
+			}
+		})
+	}
+}
@@ -1,70 +1,356 @@
 package service
 
 import (
+	"context"
 	"time"
 
 	"github.com/msoovali/pipeline-locker/internal/domain"
 )
 
+type PipelineServiceConfig struct {
+	AllowOverlocking      bool
+	ProtectedEnvironments []string
+	PromotionOrder        domain.PromotionOrder
+	// EnvironmentAliases resolve environment of every request to its canonical name before storage is accessed
+	EnvironmentAliases domain.EnvironmentAliases
+	Timeouts           PipelineTimeouts
+}
+
+// PipelineTimeouts limit storage operations of services, zero timeout leaves operation unlimited
+type PipelineTimeouts struct {
+	// Status limits deploy status check and everything recorded while checking it
+	Status time.Duration
+	// Lock limits locking, unlocking and extending lock, issuing override tokens, reviewing unlock requests and
+	// reporting deployments
+	Lock time.Duration
+	// List limits listing locked pipelines, pipeline matrix, pipeline details and histories
+	List time.Duration
+}
+
 type pipelineService struct {
-	repository       domain.PipelineRepository
-	allowOverLocking bool
+	repository           domain.PipelineRepository
+	deploymentRepository domain.DeploymentRepository
+	eventRepository      domain.PipelineEventRepository
+	config               PipelineServiceConfig
 }
 
-func NewPipelineService(repository domain.PipelineRepository, allowOverlocking bool) *pipelineService {
+func NewPipelineService(repository domain.PipelineRepository, deploymentRepository domain.DeploymentRepository, eventRepository domain.PipelineEventRepository, config PipelineServiceConfig) *pipelineService {
 	return &pipelineService{
-		repository:       repository,
-		allowOverLocking: allowOverlocking,
+		repository:           repository,
+		deploymentRepository: deploymentRepository,
+		eventRepository:      eventRepository,
+		config:               config,
 	}
 }
 
-func (s *pipelineService) IsDeployAllowed(request domain.PipelineIdentifier) (bool, error) {
+func (s *pipelineService) IsDeployAllowed(ctx context.Context, request domain.PipelineStatusRequest) (bool, error) {
+	ctx, cancel := withTimeout(ctx, s.config.Timeouts.Status)
+	defer cancel()
+	request.PipelineIdentifier = s.config.EnvironmentAliases.Resolve(request.PipelineIdentifier)
+	allowed, err := s.isDeployAllowed(ctx, request)
+
+	return allowed, contextError(ctx, err)
+}
+
+// isDeployAllowed returns false without error only when pipeline or its parent is locked, so that override token used
+// on false lifts only the lock. Version not promoted is returned as error also for locked pipeline.
+func (s *pipelineService) isDeployAllowed(ctx context.Context, request domain.PipelineStatusRequest) (bool, error) {
 	if err := request.Validate(); err != nil {
 		return false, err
 	}
-	pipeline, err := s.repository.Find(request)
+	locked, err := s.isLocked(ctx, request.PipelineIdentifier, time.Now())
 	if err != nil {
 		return false, err
 	}
+	if promoted, err := s.isPromoted(ctx, request); !promoted {
+		return false, err
+	}
 
-	return pipeline == nil || pipeline.LockedBy == "", nil
+	return !locked, nil
 }
 
-func (s *pipelineService) Lock(pipeline domain.PipelineLockRequest) error {
-	if err := pipeline.Validate(); err != nil {
-		return err
+// isLocked reports whether pipeline or pipeline of shorter scope path covering it is locked
+func (s *pipelineService) isLocked(ctx context.Context, identifier domain.PipelineIdentifier, now time.Time) (bool, error) {
+	pipeline, err := s.repository.Find(ctx, identifier)
+	if err != nil {
+		return false, err
+	}
+	if pipeline != nil && pipeline.IsLocked(now) {
+		return true, nil
 	}
-	if !s.allowOverLocking {
-		existingPipeline, err := s.repository.Find(pipeline.PipelineIdentifier)
+	parent, err := s.findLockedParent(ctx, identifier, now)
+
+	return parent != nil, err
+}
+
+// findLockedParent returns locked pipeline of shorter scope path covering given pipeline, nil if none is locked
+func (s *pipelineService) findLockedParent(ctx context.Context, identifier domain.PipelineIdentifier, now time.Time) (*domain.Pipeline, error) {
+	for _, parent := range identifier.Parents() {
+		pipeline, err := s.repository.Find(ctx, parent)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if existingPipeline != nil && existingPipeline.LockedBy != "" {
-			return domain.ErrPipelineAlreadyLocked
+		if pipeline != nil && pipeline.IsLocked(now) {
+			return pipeline, nil
 		}
 	}
-	s.repository.Add(domain.Pipeline{
+
+	return nil, nil
+}
+
+// isPromoted reports whether requested version has been deployed to upstream environment of the same scope. Scope
+// never deployed upstream falls back to deployments of its parent scopes, down to the unscoped upstream environment.
+func (s *pipelineService) isPromoted(ctx context.Context, request domain.PipelineStatusRequest) (bool, error) {
+	upstreamEnvironment := s.config.PromotionOrder.UpstreamEnvironment(request.PipelineIdentifier)
+	if upstreamEnvironment == "" {
+		return true, nil
+	}
+	if request.Version == "" {
+		return false, domain.ErrPromotionVersionRequired
+	}
+	upstream := request.PipelineIdentifier
+	upstream.Environment = upstreamEnvironment
+	candidates := []domain.PipelineIdentifier{upstream}
+	parents := upstream.Parents()
+	for i := len(parents) - 1; i >= 0; i-- {
+		candidates = append(candidates, parents[i])
+	}
+	for _, candidate := range candidates {
+		deployment, err := s.deploymentRepository.FindByVersion(ctx, candidate, request.Version)
+		if err != nil {
+			return false, err
+		}
+		if deployment != nil {
+			return true, nil
+		}
+	}
+
+	return false, domain.ErrVersionNotPromoted
+}
+
+func (s *pipelineService) Lock(ctx context.Context, pipeline domain.PipelineLockRequest) error {
+	ctx, cancel := withTimeout(ctx, s.config.Timeouts.Lock)
+	defer cancel()
+	pipeline.PipelineIdentifier = s.config.EnvironmentAliases.Resolve(pipeline.PipelineIdentifier)
+
+	return contextError(ctx, s.lock(ctx, pipeline))
+}
+
+func (s *pipelineService) lock(ctx context.Context, pipeline domain.PipelineLockRequest) error {
+	if err := pipeline.Validate(); err != nil {
+		return err
+	}
+	duration, err := pipeline.Parse()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	lockedPipeline := domain.Pipeline{
 		PipelineIdentifier: pipeline.PipelineIdentifier,
 		PipelineLockedBy:   pipeline.PipelineLockedBy,
 		PipelineLockedAt: domain.PipelineLockedAt{
-			LockedAt: time.Now(),
+			LockedAt: now,
 		},
+	}
+	if duration > 0 {
+		lockedUntil := now.Add(duration)
+		lockedPipeline.LockedUntil = &lockedUntil
+	}
+	if err = s.add(ctx, lockedPipeline); err != nil {
+		return err
+	}
+
+	return s.eventRepository.Add(ctx, domain.PipelineEvent{
+		PipelineIdentifier: pipeline.PipelineIdentifier,
+		Type:               domain.PipelineLockedEvent,
+		Actor:              pipeline.LockedBy,
+		Details:            lockedUntilDetails(lockedPipeline.LockedUntil),
+		OccurredAt:         now,
 	})
+}
+
+// add stores locked pipeline, already locked pipeline or pipeline covered by locked parent is not overwritten
+// unless overlocking is allowed
+func (s *pipelineService) add(ctx context.Context, pipeline domain.Pipeline) error {
+	if s.config.AllowOverlocking {
+		return s.repository.Add(ctx, pipeline)
+	}
+	parent, err := s.findLockedParent(ctx, pipeline.PipelineIdentifier, pipeline.LockedAt)
+	if err != nil {
+		return err
+	}
+	if parent != nil {
+		return domain.ErrParentPipelineLocked
+	}
+	if lockRepository, ok := s.repository.(domain.PipelineLockRepository); ok {
+		locked, err := lockRepository.LockIfUnlocked(ctx, pipeline)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			return domain.ErrPipelineAlreadyLocked
+		}
+		return nil
+	}
+	existingPipeline, err := s.repository.Find(ctx, pipeline.PipelineIdentifier)
+	if err != nil {
+		return err
+	}
+	if existingPipeline != nil && existingPipeline.IsLocked(pipeline.LockedAt) {
+		return domain.ErrPipelineAlreadyLocked
+	}
+
+	return s.repository.Add(ctx, pipeline)
+}
+
+func (s *pipelineService) Unlock(ctx context.Context, pipeline domain.PipelineIdentifier) error {
+	ctx, cancel := withTimeout(ctx, s.config.Timeouts.Lock)
+	defer cancel()
 
-	return nil
+	return contextError(ctx, s.unlock(ctx, s.config.EnvironmentAliases.Resolve(pipeline)))
 }
 
-func (s *pipelineService) Unlock(pipeline domain.PipelineIdentifier) error {
+func (s *pipelineService) unlock(ctx context.Context, pipeline domain.PipelineIdentifier) error {
 	if err := pipeline.Validate(); err != nil {
 		return err
 	}
-	s.repository.Add(domain.Pipeline{
+	if containsFold(s.config.ProtectedEnvironments, pipeline.Environment) {
+		return domain.ErrUnlockApprovalRequired
+	}
+	if err := s.repository.Add(ctx, domain.Pipeline{
+		PipelineIdentifier: pipeline,
+	}); err != nil {
+		return err
+	}
+
+	return s.eventRepository.Add(ctx, domain.PipelineEvent{
 		PipelineIdentifier: pipeline,
+		Type:               domain.PipelineUnlockedEvent,
+		OccurredAt:         time.Now(),
 	})
+}
 
-	return nil
+// Extend prolongs active lock with expiry time by given duration
+func (s *pipelineService) Extend(ctx context.Context, request domain.PipelineExtendRequest) error {
+	ctx, cancel := withTimeout(ctx, s.config.Timeouts.Lock)
+	defer cancel()
+	request.PipelineIdentifier = s.config.EnvironmentAliases.Resolve(request.PipelineIdentifier)
+
+	return contextError(ctx, s.extend(ctx, request))
 }
 
-func (s *pipelineService) GetLockedPipelines() ([]domain.Pipeline, error) {
-	return s.repository.FindLockedPipelines()
+func (s *pipelineService) extend(ctx context.Context, request domain.PipelineExtendRequest) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	duration, err := request.Parse()
+	if err != nil {
+		return err
+	}
+	pipeline, err := s.repository.Find(ctx, request.PipelineIdentifier)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if pipeline == nil || !pipeline.IsLocked(now) {
+		return domain.ErrPipelineNotLocked
+	}
+	if pipeline.LockedUntil == nil {
+		return domain.ErrPipelineLockNoExpiry
+	}
+	lockedUntil := pipeline.LockedUntil.Add(duration)
+	pipeline.LockedUntil = &lockedUntil
+	if err = s.repository.Add(ctx, *pipeline); err != nil {
+		return err
+	}
+
+	return s.eventRepository.Add(ctx, domain.PipelineEvent{
+		PipelineIdentifier: request.PipelineIdentifier,
+		Type:               domain.PipelineLockExtendedEvent,
+		Actor:              pipeline.LockedBy,
+		Details:            lockedUntilDetails(pipeline.LockedUntil),
+		OccurredAt:         now,
+	})
+}
+
+func (s *pipelineService) GetLockedPipelines(ctx context.Context) ([]domain.Pipeline, error) {
+	ctx, cancel := withTimeout(ctx, s.config.Timeouts.List)
+	defer cancel()
+	pipelines, err := s.repository.FindLockedPipelines(ctx)
+
+	return pipelines, contextError(ctx, err)
+}
+
+func (s *pipelineService) GetPipelineMatrix(ctx context.Context) (*domain.PipelineMatrix, error) {
+	ctx, cancel := withTimeout(ctx, s.config.Timeouts.List)
+	defer cancel()
+	pipelines, err := s.repository.FindAll(ctx)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	deployments, err := s.deploymentRepository.FindCurrent(ctx)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	return domain.NewPipelineMatrix(pipelines, deployments, s.config.PromotionOrder.Default), nil
+}
+
+// GetPipelineDetails returns pipeline state with timeline of lock and deployment events, newest first
+func (s *pipelineService) GetPipelineDetails(ctx context.Context, identifier domain.PipelineIdentifier) (*domain.PipelineDetails, error) {
+	identifier = s.config.EnvironmentAliases.Resolve(identifier)
+	if err := identifier.Validate(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := withTimeout(ctx, s.config.Timeouts.List)
+	defer cancel()
+	pipeline, err := s.repository.Find(ctx, identifier)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	events, err := s.eventRepository.FindByPipeline(ctx, identifier)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	deployments, err := s.deploymentRepository.FindHistory(ctx, identifier)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	parent, err := s.findLockedParent(ctx, identifier, time.Now())
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	details := domain.PipelineDetails{
+		PipelineIdentifier: identifier,
+		Pipeline:           pipeline,
+		LockedParent:       parent,
+		Timeline:           events,
+	}
+	for i, deployment := range deployments {
+		if details.Deployment == nil && deployment.Status == domain.DeploymentSucceeded {
+			details.Deployment = &deployments[i]
+		}
+		details.Timeline = append(details.Timeline, domain.NewDeploymentEvent(deployment))
+	}
+	domain.SortTimeline(details.Timeline)
+
+	return &details, nil
+}
+
+func lockedUntilDetails(lockedUntil *time.Time) string {
+	if lockedUntil == nil {
+		return ""
+	}
+
+	return "until " + lockedUntil.Format(time.RFC3339)
+}
+
+// WatchPipelines returns channel of pipeline changes, if repository supports watching
+func (s *pipelineService) WatchPipelines(ctx context.Context) (<-chan domain.Pipeline, error) {
+	watchRepository, ok := s.repository.(domain.PipelineWatchRepository)
+	if !ok {
+		return nil, domain.ErrWatchNotSupported
+	}
+
+	return watchRepository.Watch(ctx)
 }
@@ -1,8 +1,11 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/msoovali/pipeline-locker/internal/domain"
 )
@@ -18,9 +21,10 @@ type pipelineRepositoryMock struct {
 	fakeAdd                 func(pipeline domain.Pipeline)
 	fakeFind                func(pipeline domain.PipelineIdentifier) *domain.Pipeline
 	fakeFindLockedPipelines func() []domain.Pipeline
+	fakeFindAll             func() []domain.Pipeline
 }
 
-func (r *pipelineRepositoryMock) Find(pipeline domain.PipelineIdentifier) (*domain.Pipeline, error) {
+func (r *pipelineRepositoryMock) Find(ctx context.Context, pipeline domain.PipelineIdentifier) (*domain.Pipeline, error) {
 	if r.fakeFind != nil {
 		return r.fakeFind(pipeline), nil
 	}
@@ -28,7 +32,7 @@ func (r *pipelineRepositoryMock) Find(pipeline domain.PipelineIdentifier) (*doma
 	return nil, nil
 }
 
-func (r *pipelineRepositoryMock) Add(pipeline domain.Pipeline) error {
+func (r *pipelineRepositoryMock) Add(ctx context.Context, pipeline domain.Pipeline) error {
 	if r.fakeAdd != nil {
 		r.fakeAdd(pipeline)
 	}
@@ -36,7 +40,7 @@ func (r *pipelineRepositoryMock) Add(pipeline domain.Pipeline) error {
 	return nil
 }
 
-func (r *pipelineRepositoryMock) FindLockedPipelines() ([]domain.Pipeline, error) {
+func (r *pipelineRepositoryMock) FindLockedPipelines(ctx context.Context) ([]domain.Pipeline, error) {
 	if r.fakeFindLockedPipelines != nil {
 		return r.fakeFindLockedPipelines(), nil
 	}
@@ -44,6 +48,64 @@ func (r *pipelineRepositoryMock) FindLockedPipelines() ([]domain.Pipeline, error
 	return make([]domain.Pipeline, 0), nil
 }
 
+func (r *pipelineRepositoryMock) FindAll(ctx context.Context) ([]domain.Pipeline, error) {
+	if r.fakeFindAll != nil {
+		return r.fakeFindAll(), nil
+	}
+
+	return make([]domain.Pipeline, 0), nil
+}
+
+type watchingPipelineRepositoryMock struct {
+	pipelineRepositoryMock
+	changes chan domain.Pipeline
+}
+
+func (r *watchingPipelineRepositoryMock) Watch(ctx context.Context) (<-chan domain.Pipeline, error) {
+	return r.changes, nil
+}
+
+type lockingPipelineRepositoryMock struct {
+	pipelineRepositoryMock
+	fakeLockIfUnlocked func(pipeline domain.Pipeline) bool
+}
+
+func (r *lockingPipelineRepositoryMock) LockIfUnlocked(ctx context.Context, pipeline domain.Pipeline) (bool, error) {
+	return r.fakeLockIfUnlocked(pipeline), nil
+}
+
+// slowPipelineRepositoryMock doesn't respond until ctx is done
+type slowPipelineRepositoryMock struct {
+	pipelineRepositoryMock
+}
+
+func (r *slowPipelineRepositoryMock) Find(ctx context.Context, pipeline domain.PipelineIdentifier) (*domain.Pipeline, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+type pipelineEventRepositoryMock struct {
+	domain.PipelineEventRepository
+	fakeAdd            func(event domain.PipelineEvent)
+	fakeFindByPipeline func(pipeline domain.PipelineIdentifier) []domain.PipelineEvent
+}
+
+func (r *pipelineEventRepositoryMock) Add(ctx context.Context, event domain.PipelineEvent) error {
+	if r.fakeAdd != nil {
+		r.fakeAdd(event)
+	}
+
+	return nil
+}
+
+func (r *pipelineEventRepositoryMock) FindByPipeline(ctx context.Context, pipeline domain.PipelineIdentifier) ([]domain.PipelineEvent, error) {
+	if r.fakeFindByPipeline != nil {
+		return r.fakeFindByPipeline(pipeline), nil
+	}
+
+	return make([]domain.PipelineEvent, 0), nil
+}
+
 func getPipelineMock(lockedBy string) *domain.Pipeline {
 	return &domain.Pipeline{
 		PipelineIdentifier: getPipelineIdentifierMock(),
@@ -53,6 +115,13 @@ func getPipelineMock(lockedBy string) *domain.Pipeline {
 	}
 }
 
+func getExpiringPipelineMock(lockedBy string, lockedUntil time.Time) *domain.Pipeline {
+	pipeline := getPipelineMock(lockedBy)
+	pipeline.LockedUntil = &lockedUntil
+
+	return pipeline
+}
+
 func getPipelineIdentifierMock() domain.PipelineIdentifier {
 	return domain.PipelineIdentifier{
 		Project:     project,
@@ -99,6 +168,17 @@ func TestPipelineService_Lock(t *testing.T) {
 			input:         getPipelineLockRequestMock(""),
 			expectedError: domain.ErrLockedByEmpty,
 		},
+		{
+			description: "durationIsInvalid_returnError",
+			input: domain.PipelineLockRequest{
+				PipelineIdentifier: getPipelineIdentifierMock(),
+				PipelineLockedBy:   domain.PipelineLockedBy{LockedBy: user},
+				PipelineLockDuration: domain.PipelineLockDuration{
+					Duration: "forever",
+				},
+			},
+			expectedError: domain.ErrDurationInvalid,
+		},
 		{
 			description:         "lockAlreadyExistsAndOverLockingNotAllowed_returnError",
 			input:               getPipelineLockRequestMock(user),
@@ -116,6 +196,12 @@ func TestPipelineService_Lock(t *testing.T) {
 			expectedAddCalls:    1,
 			fakeFindReturnValue: getPipelineMock(""),
 		},
+		{
+			description:         "lockExpiredAndOverLockingNotAllowed_callsAdd",
+			input:               getPipelineLockRequestMock(user),
+			expectedAddCalls:    1,
+			fakeFindReturnValue: getExpiringPipelineMock(user, time.Now().Add(-time.Minute)),
+		},
 		{
 			description:             "lockAlreadyExistsButOverLockingIsAllowed_callsAdd",
 			input:                   getPipelineLockRequestMock(user),
@@ -126,6 +212,7 @@ func TestPipelineService_Lock(t *testing.T) {
 	} {
 		t.Run(scenario.description, func(t *testing.T) {
 			var addCallsCount int
+			var addedEvents []domain.PipelineEvent
 			repository := &pipelineRepositoryMock{
 				fakeFind: func(pipeline domain.PipelineIdentifier) *domain.Pipeline {
 					return scenario.fakeFindReturnValue
@@ -134,9 +221,14 @@ func TestPipelineService_Lock(t *testing.T) {
 					addCallsCount++
 				},
 			}
-			service := NewPipelineService(repository, scenario.serviceAllowOverLocking)
+			eventRepository := &pipelineEventRepositoryMock{
+				fakeAdd: func(event domain.PipelineEvent) {
+					addedEvents = append(addedEvents, event)
+				},
+			}
+			service := NewPipelineService(repository, &deploymentRepositoryMock{}, eventRepository, PipelineServiceConfig{AllowOverlocking: scenario.serviceAllowOverLocking})
 
-			err := service.Lock(scenario.input)
+			err := service.Lock(context.Background(), scenario.input)
 
 			if !errors.Is(err, scenario.expectedError) {
 				t.Errorf("Expected error %s, but received %s", scenario.expectedError, err)
@@ -144,16 +236,79 @@ func TestPipelineService_Lock(t *testing.T) {
 			if addCallsCount != scenario.expectedAddCalls {
 				t.Errorf("Expected repository Add method calls %d, but Add was called %d times", scenario.expectedAddCalls, addCallsCount)
 			}
+			if len(addedEvents) != scenario.expectedAddCalls {
+				t.Errorf("Expected %d events to be recorded, but got %d", scenario.expectedAddCalls, len(addedEvents))
+			} else if len(addedEvents) == 1 && addedEvents[0].Type != domain.PipelineLockedEvent {
+				t.Errorf("Expected %s event, but got %s", domain.PipelineLockedEvent, addedEvents[0].Type)
+			}
+		})
+	}
+}
+
+func TestPipelineService_Lock_repositoryLocksAtomically(t *testing.T) {
+	type testCases struct {
+		description                   string
+		serviceAllowOverLocking       bool
+		fakeLockIfUnlockedReturnValue bool
+		expectedError                 error
+		expectedLockIfUnlockedCalls   int
+		expectedAddCalls              int
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:                   "pipelineUnlocked_callsLockIfUnlocked",
+			fakeLockIfUnlockedReturnValue: true,
+			expectedLockIfUnlockedCalls:   1,
+		},
+		{
+			description:                 "pipelineLocked_returnError",
+			expectedError:               domain.ErrPipelineAlreadyLocked,
+			expectedLockIfUnlockedCalls: 1,
+		},
+		{
+			description:             "overLockingIsAllowed_callsAdd",
+			serviceAllowOverLocking: true,
+			expectedAddCalls:        1,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			var lockIfUnlockedCallsCount, addCallsCount int
+			repository := &lockingPipelineRepositoryMock{
+				pipelineRepositoryMock: pipelineRepositoryMock{
+					fakeAdd: func(pipeline domain.Pipeline) {
+						addCallsCount++
+					},
+				},
+				fakeLockIfUnlocked: func(pipeline domain.Pipeline) bool {
+					lockIfUnlockedCallsCount++
+					return scenario.fakeLockIfUnlockedReturnValue
+				},
+			}
+			service := NewPipelineService(repository, &deploymentRepositoryMock{}, &pipelineEventRepositoryMock{}, PipelineServiceConfig{AllowOverlocking: scenario.serviceAllowOverLocking})
+
+			err := service.Lock(context.Background(), getPipelineLockRequestMock(user))
+
+			if !errors.Is(err, scenario.expectedError) {
+				t.Errorf("Expected error %s, but received %s", scenario.expectedError, err)
+			}
+			if lockIfUnlockedCallsCount != scenario.expectedLockIfUnlockedCalls {
+				t.Errorf("Expected repository LockIfUnlocked method calls %d, but LockIfUnlocked was called %d times", scenario.expectedLockIfUnlockedCalls, lockIfUnlockedCallsCount)
+			}
+			if addCallsCount != scenario.expectedAddCalls {
+				t.Errorf("Expected repository Add method calls %d, but Add was called %d times", scenario.expectedAddCalls, addCallsCount)
+			}
 		})
 	}
 }
 
 func TestPipelineService_Unlock(t *testing.T) {
 	type testCases struct {
-		description      string
-		input            domain.PipelineIdentifier
-		expectedError    error
-		expectedAddCalls int
+		description           string
+		input                 domain.PipelineIdentifier
+		protectedEnvironments []string
+		expectedError         error
+		expectedAddCalls      int
 	}
 
 	for _, scenario := range []testCases{
@@ -169,11 +324,23 @@ func TestPipelineService_Unlock(t *testing.T) {
 			},
 			expectedError: domain.ErrEnvironmentEmpty,
 		},
+		{
+			description:           "environmentIsProtected_returnError",
+			input:                 getPipelineIdentifierMock(),
+			protectedEnvironments: []string{"ENVIRONMENT"},
+			expectedError:         domain.ErrUnlockApprovalRequired,
+		},
 		{
 			description:      "inputIsOK_callsAdd",
 			input:            getPipelineIdentifierMock(),
 			expectedAddCalls: 1,
 		},
+		{
+			description:           "anotherEnvironmentIsProtected_callsAdd",
+			input:                 getPipelineIdentifierMock(),
+			protectedEnvironments: []string{"production"},
+			expectedAddCalls:      1,
+		},
 	} {
 		t.Run(scenario.description, func(t *testing.T) {
 			var addCallsCount int
@@ -184,9 +351,9 @@ func TestPipelineService_Unlock(t *testing.T) {
 					lockedByValue = pipeline.LockedBy
 				},
 			}
-			service := NewPipelineService(repository, false)
+			service := NewPipelineService(repository, &deploymentRepositoryMock{}, &pipelineEventRepositoryMock{}, PipelineServiceConfig{ProtectedEnvironments: scenario.protectedEnvironments})
 
-			err := service.Unlock(scenario.input)
+			err := service.Unlock(context.Background(), scenario.input)
 
 			if !errors.Is(err, scenario.expectedError) {
 				t.Errorf("Expected error %s, but received %s", scenario.expectedError, err)
@@ -202,53 +369,115 @@ func TestPipelineService_Unlock(t *testing.T) {
 }
 
 func TestPipelineService_IsDeployAllowed(t *testing.T) {
+	const version = "1.0.0"
+	promotionOrder := domain.PromotionOrder{
+		Default: []string{"staging", environment},
+	}
 	type testCases struct {
-		description         string
-		input               domain.PipelineIdentifier
-		expectedError       error
-		expectedValue       bool
-		fakeFindReturnValue *domain.Pipeline
+		description                  string
+		input                        domain.PipelineStatusRequest
+		promotionOrder               domain.PromotionOrder
+		expectedError                error
+		expectedValue                bool
+		fakeFindReturnValue          *domain.Pipeline
+		fakeFindByVersionReturnValue *domain.Deployment
+		expectedUpstreamPipeline     domain.PipelineIdentifier
 	}
 
 	for _, scenario := range []testCases{
 		{
 			description:   "projectIsEmpty_returnError",
-			input:         domain.PipelineIdentifier{},
+			input:         domain.PipelineStatusRequest{},
 			expectedError: domain.ErrProjectEmpty,
 		},
 		{
 			description: "environmentIsEmpty_returnError",
-			input: domain.PipelineIdentifier{
-				Project: project,
+			input: domain.PipelineStatusRequest{
+				PipelineIdentifier: domain.PipelineIdentifier{
+					Project: project,
+				},
 			},
 			expectedError: domain.ErrEnvironmentEmpty,
 		},
 		{
 			description:   "pipelineIsNotFoundFromStore_returnTrue",
-			input:         getPipelineIdentifierMock(),
+			input:         domain.PipelineStatusRequest{PipelineIdentifier: getPipelineIdentifierMock()},
 			expectedValue: true,
 		},
 		{
 			description:         "pipelineIsLocked_returnFalse",
-			input:               getPipelineIdentifierMock(),
+			input:               domain.PipelineStatusRequest{PipelineIdentifier: getPipelineIdentifierMock()},
 			fakeFindReturnValue: getPipelineMock(user),
 		},
 		{
 			description:         "pipelineIsNotLocked_returnTrue",
-			input:               getPipelineIdentifierMock(),
+			input:               domain.PipelineStatusRequest{PipelineIdentifier: getPipelineIdentifierMock()},
 			fakeFindReturnValue: getPipelineMock(""),
 			expectedValue:       true,
 		},
+		{
+			description:    "downstreamEnvironmentAndVersionMissing_returnError",
+			input:          domain.PipelineStatusRequest{PipelineIdentifier: getPipelineIdentifierMock()},
+			promotionOrder: promotionOrder,
+			expectedError:  domain.ErrPromotionVersionRequired,
+		},
+		{
+			description:              "versionNotDeployedToUpstream_returnError",
+			input:                    domain.PipelineStatusRequest{PipelineIdentifier: getPipelineIdentifierMock(), Version: version},
+			promotionOrder:           promotionOrder,
+			expectedError:            domain.ErrVersionNotPromoted,
+			expectedUpstreamPipeline: domain.PipelineIdentifier{Project: project, Environment: "staging"},
+		},
+		{
+			description:              "pipelineIsLockedAndVersionNotDeployedToUpstream_returnError",
+			input:                    domain.PipelineStatusRequest{PipelineIdentifier: getPipelineIdentifierMock(), Version: version},
+			promotionOrder:           promotionOrder,
+			fakeFindReturnValue:      getPipelineMock(user),
+			expectedError:            domain.ErrVersionNotPromoted,
+			expectedUpstreamPipeline: domain.PipelineIdentifier{Project: project, Environment: "staging"},
+		},
+		{
+			description:                  "pipelineIsLockedAndVersionDeployedToUpstream_returnFalse",
+			input:                        domain.PipelineStatusRequest{PipelineIdentifier: getPipelineIdentifierMock(), Version: version},
+			promotionOrder:               promotionOrder,
+			fakeFindReturnValue:          getPipelineMock(user),
+			fakeFindByVersionReturnValue: &domain.Deployment{DeploymentDetails: domain.DeploymentDetails{Version: version}},
+			expectedUpstreamPipeline:     domain.PipelineIdentifier{Project: project, Environment: "staging"},
+		},
+		{
+			description:                  "versionDeployedToUpstream_returnTrue",
+			input:                        domain.PipelineStatusRequest{PipelineIdentifier: getPipelineIdentifierMock(), Version: version},
+			promotionOrder:               promotionOrder,
+			fakeFindByVersionReturnValue: &domain.Deployment{DeploymentDetails: domain.DeploymentDetails{Version: version}},
+			expectedValue:                true,
+			expectedUpstreamPipeline:     domain.PipelineIdentifier{Project: project, Environment: "staging"},
+		},
+		{
+			description: "firstEnvironmentInOrder_returnTrue",
+			input: domain.PipelineStatusRequest{PipelineIdentifier: domain.PipelineIdentifier{
+				Project:     project,
+				Environment: "staging",
+			}},
+			promotionOrder: promotionOrder,
+			expectedValue:  true,
+		},
 	} {
 		t.Run(scenario.description, func(t *testing.T) {
+			var upstreamPipeline domain.PipelineIdentifier
 			repository := &pipelineRepositoryMock{
 				fakeFind: func(pipeline domain.PipelineIdentifier) *domain.Pipeline {
 					return scenario.fakeFindReturnValue
 				},
 			}
-			service := NewPipelineService(repository, false)
+			deploymentRepository := &deploymentRepositoryMock{
+				fakeFindByVersion: func(pipeline domain.PipelineIdentifier, version string) *domain.Deployment {
+					upstreamPipeline = pipeline
+					return scenario.fakeFindByVersionReturnValue
+				},
+			}
+			service := NewPipelineService(repository, deploymentRepository, &pipelineEventRepositoryMock{}, PipelineServiceConfig{PromotionOrder: scenario.promotionOrder})
 
-			isAllowed, err := service.IsDeployAllowed(scenario.input)
+			isAllowed, err := service.IsDeployAllowed(context.Background(), scenario.input)
 
 			if !errors.Is(err, scenario.expectedError) {
 				t.Errorf("Expected error %s, but received %s", scenario.expectedError, err)
@@ -256,10 +485,219 @@ func TestPipelineService_IsDeployAllowed(t *testing.T) {
 			if isAllowed != scenario.expectedValue {
 				t.Errorf("Expected return value %t, got %t", scenario.expectedValue, isAllowed)
 			}
+			if upstreamPipeline != scenario.expectedUpstreamPipeline {
+				t.Errorf("Expected upstream pipeline %v to be checked, got %v", scenario.expectedUpstreamPipeline, upstreamPipeline)
+			}
 		})
 	}
 }
 
+func TestPipelineService_IsDeployAllowed_upstreamParentScopes(t *testing.T) {
+	const version = "1.0.0"
+	request := domain.PipelineStatusRequest{
+		PipelineIdentifier: domain.PipelineIdentifier{Project: project, Environment: environment, PipelineScope: domain.NewPipelineScope("eu-west", "k8s-1")},
+		Version:            version,
+	}
+	upstream := domain.PipelineIdentifier{Project: project, Environment: "staging", PipelineScope: domain.NewPipelineScope("eu-west", "k8s-1")}
+	type testCases struct {
+		description     string
+		deployedTo      *domain.PipelineIdentifier
+		expectedError   error
+		expectedValue   bool
+		expectedChecked []domain.PipelineIdentifier
+	}
+	for _, scenario := range []testCases{
+		{
+			description:     "deployedToSameScope_returnTrue",
+			deployedTo:      &upstream,
+			expectedValue:   true,
+			expectedChecked: []domain.PipelineIdentifier{upstream},
+		},
+		{
+			description:     "deployedToParentScope_returnTrue",
+			deployedTo:      &upstream.Parents()[1],
+			expectedValue:   true,
+			expectedChecked: []domain.PipelineIdentifier{upstream, upstream.Parents()[1]},
+		},
+		{
+			description:     "deployedToUnscopedEnvironment_returnTrue",
+			deployedTo:      &upstream.Parents()[0],
+			expectedValue:   true,
+			expectedChecked: []domain.PipelineIdentifier{upstream, upstream.Parents()[1], upstream.Parents()[0]},
+		},
+		{
+			description:     "notDeployedToAnyScope_returnError",
+			expectedError:   domain.ErrVersionNotPromoted,
+			expectedChecked: []domain.PipelineIdentifier{upstream, upstream.Parents()[1], upstream.Parents()[0]},
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			var checked []domain.PipelineIdentifier
+			service := NewPipelineService(&pipelineRepositoryMock{}, &deploymentRepositoryMock{
+				fakeFindByVersion: func(pipeline domain.PipelineIdentifier, version string) *domain.Deployment {
+					checked = append(checked, pipeline)
+					if scenario.deployedTo != nil && pipeline == *scenario.deployedTo {
+						return &domain.Deployment{DeploymentDetails: domain.DeploymentDetails{Version: version}}
+					}
+					return nil
+				},
+			}, &pipelineEventRepositoryMock{}, PipelineServiceConfig{PromotionOrder: domain.PromotionOrder{Default: []string{"staging", environment}}})
+
+			isAllowed, err := service.IsDeployAllowed(context.Background(), request)
+
+			if !errors.Is(err, scenario.expectedError) {
+				t.Errorf("Expected error %s, but received %s", scenario.expectedError, err)
+			}
+			if isAllowed != scenario.expectedValue {
+				t.Errorf("Expected return value %t, got %t", scenario.expectedValue, isAllowed)
+			}
+			if !reflect.DeepEqual(checked, scenario.expectedChecked) {
+				t.Errorf("Expected upstream pipelines %v to be checked, got %v", scenario.expectedChecked, checked)
+			}
+		})
+	}
+}
+
+func TestPipelineService_parentLock(t *testing.T) {
+	child := getPipelineIdentifierMock()
+	child.PipelineScope = domain.NewPipelineScope("eu-west", "k8s-1")
+	var addCalls int
+	repository := &pipelineRepositoryMock{
+		fakeFind: func(pipeline domain.PipelineIdentifier) *domain.Pipeline {
+			// only region is locked
+			if pipeline.Region == "eu-west" && pipeline.Cluster == "" {
+				return &domain.Pipeline{PipelineIdentifier: pipeline, PipelineLockedBy: domain.PipelineLockedBy{LockedBy: user}}
+			}
+			return nil
+		},
+		fakeAdd: func(pipeline domain.Pipeline) {
+			addCalls++
+		},
+	}
+	service := NewPipelineService(repository, &deploymentRepositoryMock{}, &pipelineEventRepositoryMock{}, PipelineServiceConfig{})
+
+	isAllowed, err := service.IsDeployAllowed(context.Background(), domain.PipelineStatusRequest{PipelineIdentifier: child})
+	if err != nil || isAllowed {
+		t.Errorf("Expected deploy of pipeline covered by locked parent not allowed, got %t and %v", isAllowed, err)
+	}
+	err = service.Lock(context.Background(), domain.PipelineLockRequest{PipelineIdentifier: child, PipelineLockedBy: domain.PipelineLockedBy{LockedBy: user}})
+	if err != domain.ErrParentPipelineLocked || addCalls != 0 {
+		t.Errorf("Expected error %v and no added pipelines, got %v and %d", domain.ErrParentPipelineLocked, err, addCalls)
+	}
+	isAllowed, err = service.IsDeployAllowed(context.Background(), domain.PipelineStatusRequest{PipelineIdentifier: getPipelineIdentifierMock()})
+	if err != nil || !isAllowed {
+		t.Errorf("Expected deploy of parent pipeline allowed, got %t and %v", isAllowed, err)
+	}
+}
+
+func TestPipelineService_environmentAliases(t *testing.T) {
+	var aliases domain.EnvironmentAliases
+	aliases.Add("", environment, "prod", "prd")
+	var stored *domain.Pipeline
+	repository := &pipelineRepositoryMock{
+		fakeFind: func(pipeline domain.PipelineIdentifier) *domain.Pipeline {
+			if stored != nil && stored.PipelineIdentifier == pipeline {
+				return stored
+			}
+			return nil
+		},
+		fakeAdd: func(pipeline domain.Pipeline) {
+			stored = &pipeline
+		},
+	}
+	service := NewPipelineService(repository, &deploymentRepositoryMock{}, &pipelineEventRepositoryMock{}, PipelineServiceConfig{EnvironmentAliases: aliases})
+	request := getPipelineLockRequestMock(user)
+	request.Environment = "prod"
+
+	if err := service.Lock(context.Background(), request); err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+	if stored == nil || stored.PipelineIdentifier != getPipelineIdentifierMock() {
+		t.Fatalf("Expected pipeline to be stored under canonical environment %s, got %v", environment, stored)
+	}
+	isAllowed, err := service.IsDeployAllowed(context.Background(), domain.PipelineStatusRequest{PipelineIdentifier: domain.PipelineIdentifier{Project: project, Environment: "PRD"}})
+	if err != nil || isAllowed {
+		t.Errorf("Expected deploy using other alias not allowed, got %t and %v", isAllowed, err)
+	}
+}
+
+func TestPipelineService_aliasedProtectedEnvironmentsAndPromotionOrder(t *testing.T) {
+	const version = "1.0.0"
+	var aliases domain.EnvironmentAliases
+	aliases.Add("", environment, "prod")
+	aliases.Add("", "staging", "stage")
+	protectedEnvironments, err := aliases.ResolveEnvironments([]string{"prod"})
+	if err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+	promotionOrder, err := domain.PromotionOrder{Default: []string{"stage", "prod"}}.ResolveAliases(aliases)
+	if err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+	var upstreamPipeline domain.PipelineIdentifier
+	deploymentRepository := &deploymentRepositoryMock{
+		fakeFindByVersion: func(pipeline domain.PipelineIdentifier, version string) *domain.Deployment {
+			upstreamPipeline = pipeline
+			return nil
+		},
+	}
+	service := NewPipelineService(&pipelineRepositoryMock{}, deploymentRepository, &pipelineEventRepositoryMock{}, PipelineServiceConfig{
+		ProtectedEnvironments: protectedEnvironments,
+		PromotionOrder:        promotionOrder,
+		EnvironmentAliases:    aliases,
+	})
+
+	for _, alias := range []string{"prod", environment} {
+		identifier := domain.PipelineIdentifier{Project: project, Environment: alias}
+		if err = service.Unlock(context.Background(), identifier); err != domain.ErrUnlockApprovalRequired {
+			t.Errorf("Expected unlock of %s to require approval, got %v", alias, err)
+		}
+		_, err = service.IsDeployAllowed(context.Background(), domain.PipelineStatusRequest{PipelineIdentifier: identifier, Version: version})
+		if err != domain.ErrVersionNotPromoted {
+			t.Errorf("Expected deploy to %s to require promotion, got %v", alias, err)
+		}
+		if upstreamPipeline.Environment != "staging" {
+			t.Errorf("Expected canonical upstream environment staging to be checked, got %v", upstreamPipeline)
+		}
+	}
+}
+
+func TestPipelineService_Timeouts(t *testing.T) {
+	t.Run("IsDeployAllowed_repositoryDoesNotRespondInTime_returnsStorageTimeout", func(t *testing.T) {
+		service := NewPipelineService(&slowPipelineRepositoryMock{}, &deploymentRepositoryMock{}, &pipelineEventRepositoryMock{}, PipelineServiceConfig{
+			Timeouts: PipelineTimeouts{Status: 10 * time.Millisecond},
+		})
+
+		_, err := service.IsDeployAllowed(context.Background(), domain.PipelineStatusRequest{PipelineIdentifier: getPipelineIdentifierMock()})
+
+		if !errors.Is(err, domain.ErrStorageTimeout) {
+			t.Errorf("Expected error %s, but received %v", domain.ErrStorageTimeout, err)
+		}
+	})
+
+	t.Run("Lock_requestCanceled_returnsRequestCanceled", func(t *testing.T) {
+		service := NewPipelineService(&slowPipelineRepositoryMock{}, &deploymentRepositoryMock{}, &pipelineEventRepositoryMock{}, PipelineServiceConfig{})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := service.Lock(ctx, getPipelineLockRequestMock(user))
+
+		if !errors.Is(err, domain.ErrRequestCanceled) {
+			t.Errorf("Expected error %s, but received %v", domain.ErrRequestCanceled, err)
+		}
+	})
+
+	t.Run("Lock_invalidRequestAndNoTimeout_returnsValidationError", func(t *testing.T) {
+		service := NewPipelineService(&slowPipelineRepositoryMock{}, &deploymentRepositoryMock{}, &pipelineEventRepositoryMock{}, PipelineServiceConfig{})
+
+		err := service.Lock(context.Background(), domain.PipelineLockRequest{})
+
+		if !errors.Is(err, domain.ErrProjectEmpty) {
+			t.Errorf("Expected error %s, but received %v", domain.ErrProjectEmpty, err)
+		}
+	})
+}
+
 func TestPipelineService_GetLockedPipelines(t *testing.T) {
 	t.Run("repositoryFindLockedPipelinesIsCalled_proxiesValue", func(t *testing.T) {
 		var findLockedPipelinesCalls int
@@ -269,9 +707,9 @@ func TestPipelineService_GetLockedPipelines(t *testing.T) {
 				return make([]domain.Pipeline, 0)
 			},
 		}
-		service := NewPipelineService(repository, false)
+		service := NewPipelineService(repository, &deploymentRepositoryMock{}, &pipelineEventRepositoryMock{}, PipelineServiceConfig{})
 
-		lockedPipelines, _ := service.GetLockedPipelines()
+		lockedPipelines, _ := service.GetLockedPipelines(context.Background())
 
 		if findLockedPipelinesCalls != 1 {
 			t.Errorf("Expected repository findLockedPipelines() to be called once, got %d", findLockedPipelinesCalls)
@@ -281,3 +719,219 @@ func TestPipelineService_GetLockedPipelines(t *testing.T) {
 		}
 	})
 }
+
+func TestPipelineService_GetPipelineMatrix(t *testing.T) {
+	t.Run("repositoriesReturnPipelinesAndDeployments_returnsMatrix", func(t *testing.T) {
+		repository := &pipelineRepositoryMock{
+			fakeFindAll: func() []domain.Pipeline {
+				return []domain.Pipeline{*getPipelineMock(user)}
+			},
+		}
+		deploymentRepository := &deploymentRepositoryMock{
+			fakeFindCurrent: func() []domain.Deployment {
+				return []domain.Deployment{{
+					PipelineIdentifier: domain.PipelineIdentifier{Project: project, Environment: "staging"},
+				}}
+			},
+		}
+		service := NewPipelineService(repository, deploymentRepository, &pipelineEventRepositoryMock{}, PipelineServiceConfig{
+			PromotionOrder: domain.PromotionOrder{
+				Default: []string{"staging", environment},
+			},
+		})
+
+		matrix, err := service.GetPipelineMatrix(context.Background())
+
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		if len(matrix.Environments) != 2 || matrix.Environments[0] != "staging" || matrix.Environments[1] != environment {
+			t.Errorf("Expected environments in promotion order, got %v", matrix.Environments)
+		}
+		if len(matrix.Rows) != 1 || !matrix.Rows[0].Cells[1].IsLocked() || matrix.Rows[0].Cells[0].Deployment == nil {
+			t.Errorf("Expected one project with deployed staging and locked %s, got %v", environment, matrix.Rows)
+		}
+	})
+}
+
+func TestPipelineService_Extend(t *testing.T) {
+	lockedUntil := time.Now().Add(time.Hour)
+	type testCases struct {
+		description         string
+		input               domain.PipelineExtendRequest
+		fakeFindReturnValue *domain.Pipeline
+		expectedError       error
+		expectedLockedUntil time.Time
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description: "durationIsEmpty_returnError",
+			input: domain.PipelineExtendRequest{
+				PipelineIdentifier: getPipelineIdentifierMock(),
+			},
+			expectedError: domain.ErrDurationEmpty,
+		},
+		{
+			description: "pipelineNotLocked_returnError",
+			input: domain.PipelineExtendRequest{
+				PipelineIdentifier:   getPipelineIdentifierMock(),
+				PipelineLockDuration: domain.PipelineLockDuration{Duration: "1h"},
+			},
+			fakeFindReturnValue: getPipelineMock(""),
+			expectedError:       domain.ErrPipelineNotLocked,
+		},
+		{
+			description: "lockExpired_returnError",
+			input: domain.PipelineExtendRequest{
+				PipelineIdentifier:   getPipelineIdentifierMock(),
+				PipelineLockDuration: domain.PipelineLockDuration{Duration: "1h"},
+			},
+			fakeFindReturnValue: getExpiringPipelineMock(user, time.Now().Add(-time.Minute)),
+			expectedError:       domain.ErrPipelineNotLocked,
+		},
+		{
+			description: "lockHasNoExpiry_returnError",
+			input: domain.PipelineExtendRequest{
+				PipelineIdentifier:   getPipelineIdentifierMock(),
+				PipelineLockDuration: domain.PipelineLockDuration{Duration: "1h"},
+			},
+			fakeFindReturnValue: getPipelineMock(user),
+			expectedError:       domain.ErrPipelineLockNoExpiry,
+		},
+		{
+			description: "lockIsExpiring_extendsLock",
+			input: domain.PipelineExtendRequest{
+				PipelineIdentifier:   getPipelineIdentifierMock(),
+				PipelineLockDuration: domain.PipelineLockDuration{Duration: "30m"},
+			},
+			fakeFindReturnValue: getExpiringPipelineMock(user, lockedUntil),
+			expectedLockedUntil: lockedUntil.Add(30 * time.Minute),
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			var addedPipeline *domain.Pipeline
+			var addedEvents []domain.PipelineEvent
+			repository := &pipelineRepositoryMock{
+				fakeFind: func(pipeline domain.PipelineIdentifier) *domain.Pipeline {
+					return scenario.fakeFindReturnValue
+				},
+				fakeAdd: func(pipeline domain.Pipeline) {
+					addedPipeline = &pipeline
+				},
+			}
+			eventRepository := &pipelineEventRepositoryMock{
+				fakeAdd: func(event domain.PipelineEvent) {
+					addedEvents = append(addedEvents, event)
+				},
+			}
+			service := NewPipelineService(repository, &deploymentRepositoryMock{}, eventRepository, PipelineServiceConfig{})
+
+			err := service.Extend(context.Background(), scenario.input)
+
+			if !errors.Is(err, scenario.expectedError) {
+				t.Errorf("Expected error %s, but received %s", scenario.expectedError, err)
+			}
+			if scenario.expectedError != nil {
+				if addedPipeline != nil || len(addedEvents) != 0 {
+					t.Errorf("Expected nothing to be stored, got %v and %v", addedPipeline, addedEvents)
+				}
+				return
+			}
+			if addedPipeline == nil || addedPipeline.LockedUntil == nil || !addedPipeline.LockedUntil.Equal(scenario.expectedLockedUntil) {
+				t.Errorf("Expected lock to be extended until %s, got %v", scenario.expectedLockedUntil, addedPipeline)
+			} else if addedPipeline.LockedBy != user {
+				t.Errorf("Expected lock owner %s to be kept, got %s", user, addedPipeline.LockedBy)
+			}
+			if len(addedEvents) != 1 || addedEvents[0].Type != domain.PipelineLockExtendedEvent {
+				t.Errorf("Expected %s event to be recorded, got %v", domain.PipelineLockExtendedEvent, addedEvents)
+			}
+		})
+	}
+}
+
+func TestPipelineService_GetPipelineDetails(t *testing.T) {
+	now := time.Now()
+	repository := &pipelineRepositoryMock{
+		fakeFind: func(pipeline domain.PipelineIdentifier) *domain.Pipeline {
+			return getPipelineMock(user)
+		},
+	}
+	eventRepository := &pipelineEventRepositoryMock{
+		fakeFindByPipeline: func(pipeline domain.PipelineIdentifier) []domain.PipelineEvent {
+			return []domain.PipelineEvent{
+				{PipelineIdentifier: pipeline, Type: domain.PipelineLockedEvent, OccurredAt: now.Add(-time.Minute)},
+				{PipelineIdentifier: pipeline, Type: domain.PipelineUnlockedEvent, OccurredAt: now.Add(-time.Hour)},
+			}
+		},
+	}
+	deploymentRepository := &deploymentRepositoryMock{
+		fakeFindHistory: func(pipeline domain.PipelineIdentifier) []domain.Deployment {
+			return []domain.Deployment{
+				{PipelineIdentifier: pipeline, DeploymentDetails: domain.DeploymentDetails{Version: "1.1.0"}, Status: domain.DeploymentFailed, DeployedAt: now},
+				{PipelineIdentifier: pipeline, DeploymentDetails: domain.DeploymentDetails{Version: "1.0.0"}, Status: domain.DeploymentSucceeded, DeployedAt: now.Add(-time.Hour / 2)},
+			}
+		},
+	}
+	service := NewPipelineService(repository, deploymentRepository, eventRepository, PipelineServiceConfig{})
+
+	t.Run("projectIsEmpty_returnError", func(t *testing.T) {
+		_, err := service.GetPipelineDetails(context.Background(), domain.PipelineIdentifier{})
+
+		if !errors.Is(err, domain.ErrProjectEmpty) {
+			t.Errorf("Expected error %s, but received %s", domain.ErrProjectEmpty, err)
+		}
+	})
+
+	t.Run("inputIsOK_returnsStateWithMergedTimeline", func(t *testing.T) {
+		details, err := service.GetPipelineDetails(context.Background(), getPipelineIdentifierMock())
+
+		if err != nil {
+			t.Fatalf("Expected no error, but received %s", err)
+		}
+		if details.Pipeline == nil || details.Pipeline.LockedBy != user {
+			t.Errorf("Expected pipeline locked by %s, got %v", user, details.Pipeline)
+		}
+		if details.Deployment == nil || details.Deployment.Version != "1.0.0" {
+			t.Errorf("Expected latest successful deployment 1.0.0, got %v", details.Deployment)
+		}
+		expectedTypes := []domain.PipelineEventType{"deployment_failed", domain.PipelineLockedEvent, "deployment_succeeded", domain.PipelineUnlockedEvent}
+		if len(details.Timeline) != len(expectedTypes) {
+			t.Fatalf("Expected %d timeline events, got %d", len(expectedTypes), len(details.Timeline))
+		}
+		for i, expectedType := range expectedTypes {
+			if details.Timeline[i].Type != expectedType {
+				t.Errorf("Expected timeline event %d to be %s, got %s", i, expectedType, details.Timeline[i].Type)
+			}
+		}
+	})
+}
+
+func TestPipelineService_WatchPipelines(t *testing.T) {
+	t.Run("repositoryDoesNotSupportWatch_returnError", func(t *testing.T) {
+		service := NewPipelineService(&pipelineRepositoryMock{}, &deploymentRepositoryMock{}, &pipelineEventRepositoryMock{}, PipelineServiceConfig{})
+
+		changes, err := service.WatchPipelines(context.Background())
+
+		if changes != nil || err != domain.ErrWatchNotSupported {
+			t.Errorf("Expected nil changes and error %s, got %v and %v", domain.ErrWatchNotSupported, changes, err)
+		}
+	})
+
+	t.Run("repositorySupportsWatch_returnsRepositoryChanges", func(t *testing.T) {
+		repository := &watchingPipelineRepositoryMock{
+			changes: make(chan domain.Pipeline, 1),
+		}
+		repository.changes <- *getPipelineMock(user)
+		service := NewPipelineService(repository, &deploymentRepositoryMock{}, &pipelineEventRepositoryMock{}, PipelineServiceConfig{})
+
+		changes, err := service.WatchPipelines(context.Background())
+
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+		if change := <-changes; change.LockedBy != user {
+			t.Errorf("Expected change of pipeline locked by %s, got %v", user, change)
+		}
+	})
+}
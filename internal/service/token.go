@@ -0,0 +1,16 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// generateToken returns hex encoded random value of given size in bytes
+func generateToken(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+const unlockRequestIDBytes = 8
+
+type unlockRequestService struct {
+	repository         domain.UnlockRequestRepository
+	pipelineRepository domain.PipelineRepository
+	eventRepository    domain.PipelineEventRepository
+	reviewers          []string
+	ttl                time.Duration
+	aliases            domain.EnvironmentAliases
+	timeouts           PipelineTimeouts
+	log                *logger.Logger
+}
+
+func NewUnlockRequestService(repository domain.UnlockRequestRepository, pipelineRepository domain.PipelineRepository, eventRepository domain.PipelineEventRepository, reviewers []string, ttl time.Duration, aliases domain.EnvironmentAliases, timeouts PipelineTimeouts, log *logger.Logger) *unlockRequestService {
+	return &unlockRequestService{
+		repository:         repository,
+		pipelineRepository: pipelineRepository,
+		eventRepository:    eventRepository,
+		reviewers:          reviewers,
+		ttl:                ttl,
+		aliases:            aliases,
+		timeouts:           timeouts,
+		log:                log,
+	}
+}
+
+func (s *unlockRequestService) Create(ctx context.Context, request domain.UnlockRequestCreateRequest) (*domain.UnlockRequest, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Lock)
+	defer cancel()
+	unlockRequest, err := s.create(ctx, request)
+
+	return unlockRequest, contextError(ctx, err)
+}
+
+func (s *unlockRequestService) create(ctx context.Context, request domain.UnlockRequestCreateRequest) (*domain.UnlockRequest, error) {
+	request.PipelineIdentifier = s.aliases.Resolve(request.PipelineIdentifier)
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	pipeline, err := s.pipelineRepository.Find(ctx, request.PipelineIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if pipeline == nil || !pipeline.IsLocked(time.Now()) {
+		return nil, domain.ErrPipelineNotLocked
+	}
+	pendingRequests, err := s.getPending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pendingRequest := range pendingRequests {
+		if pendingRequest.PipelineIdentifier == request.PipelineIdentifier {
+			return nil, domain.ErrUnlockRequestAlreadyPending
+		}
+	}
+	id, err := generateToken(unlockRequestIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	unlockRequest := domain.UnlockRequest{
+		ID:                 id,
+		PipelineIdentifier: request.PipelineIdentifier,
+		LockedBy:           pipeline.LockedBy,
+		LockedAt:           pipeline.LockedAt,
+		RequestedBy:        request.RequestedBy,
+		RequestedAt:        now,
+		ExpiresAt:          now.Add(s.ttl),
+		Status:             domain.UnlockRequestPending,
+	}
+	if err = s.repository.Add(ctx, unlockRequest); err != nil {
+		return nil, err
+	}
+	s.log.Info.Printf("Unlock of pipeline %s requested by %s", unlockRequest.Name(), unlockRequest.RequestedBy)
+	if err = s.addEvent(ctx, &unlockRequest, domain.UnlockRequestedEvent, unlockRequest.RequestedBy, now); err != nil {
+		return nil, err
+	}
+
+	return &unlockRequest, nil
+}
+
+func (s *unlockRequestService) Approve(ctx context.Context, id string, review domain.UnlockRequestReview) (*domain.UnlockRequest, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Lock)
+	defer cancel()
+	request, err := s.approve(ctx, id, review)
+
+	return request, contextError(ctx, err)
+}
+
+func (s *unlockRequestService) approve(ctx context.Context, id string, review domain.UnlockRequestReview) (*domain.UnlockRequest, error) {
+	if err := review.Validate(); err != nil {
+		return nil, err
+	}
+	if len(s.reviewers) == 0 {
+		return nil, domain.ErrUnlockReviewersNotConfigured
+	}
+	request, err := s.findPending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(review.ReviewedBy, request.RequestedBy) {
+		return nil, domain.ErrUnlockSelfApprovalNotAllowed
+	}
+	if !s.isReviewer(review.ReviewedBy) {
+		return nil, domain.ErrUnlockReviewerNotAuthorized
+	}
+	// request is approved before unlocking, so that concurrent reviews don't both take effect
+	if err = s.review(ctx, request, domain.UnlockRequestApproved, review); err != nil {
+		return nil, err
+	}
+	unlocked, err := s.unlockIfLocked(ctx, request.Lock())
+	if err != nil {
+		return nil, err
+	}
+	if !unlocked {
+		s.log.Info.Printf("Unlock of pipeline %s approved by %s was not applied, lock of %s has changed", request.Name(), request.ReviewedBy, request.LockedBy)
+		return nil, domain.ErrUnlockRequestLockChanged
+	}
+	s.log.Info.Printf("Unlock of pipeline %s requested by %s approved by %s", request.Name(), request.RequestedBy, request.ReviewedBy)
+	if err = s.addEvent(ctx, request, domain.UnlockRequestApprovedEvent, request.ReviewedBy, request.ReviewedAt); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+func (s *unlockRequestService) Reject(ctx context.Context, id string, review domain.UnlockRequestReview) (*domain.UnlockRequest, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Lock)
+	defer cancel()
+	request, err := s.reject(ctx, id, review)
+
+	return request, contextError(ctx, err)
+}
+
+func (s *unlockRequestService) reject(ctx context.Context, id string, review domain.UnlockRequestReview) (*domain.UnlockRequest, error) {
+	if err := review.Validate(); err != nil {
+		return nil, err
+	}
+	request, err := s.findPending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// requester is allowed to withdraw own request
+	if !strings.EqualFold(review.ReviewedBy, request.RequestedBy) && !s.isReviewer(review.ReviewedBy) {
+		return nil, domain.ErrUnlockReviewerNotAuthorized
+	}
+	if err = s.review(ctx, request, domain.UnlockRequestRejected, review); err != nil {
+		return nil, err
+	}
+	s.log.Info.Printf("Unlock of pipeline %s requested by %s rejected by %s", request.Name(), request.RequestedBy, request.ReviewedBy)
+	if err = s.addEvent(ctx, request, domain.UnlockRequestRejectedEvent, request.ReviewedBy, request.ReviewedAt); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+func (s *unlockRequestService) GetPending(ctx context.Context) ([]domain.UnlockRequest, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.List)
+	defer cancel()
+	requests, err := s.getPending(ctx)
+
+	return requests, contextError(ctx, err)
+}
+
+func (s *unlockRequestService) getPending(ctx context.Context) ([]domain.UnlockRequest, error) {
+	requests, err := s.repository.FindPending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	pendingRequests := make([]domain.UnlockRequest, 0, len(requests))
+	for _, request := range requests {
+		if request.StatusAt(now) == domain.UnlockRequestPending {
+			pendingRequests = append(pendingRequests, request)
+		}
+	}
+
+	return pendingRequests, nil
+}
+
+func (s *unlockRequestService) findPending(ctx context.Context, id string) (*domain.UnlockRequest, error) {
+	request, err := s.repository.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if request == nil {
+		return nil, domain.ErrUnlockRequestNotFound
+	}
+	status := request.StatusAt(time.Now())
+	if status == domain.UnlockRequestExpired && request.Status == domain.UnlockRequestPending {
+		request.Status = domain.UnlockRequestExpired
+		if err = s.repository.Update(ctx, *request); err != nil {
+			return nil, err
+		}
+	}
+	if status != domain.UnlockRequestPending {
+		return nil, domain.ErrUnlockRequestNotPending
+	}
+
+	return request, nil
+}
+
+func (s *unlockRequestService) review(ctx context.Context, request *domain.UnlockRequest, status domain.UnlockRequestStatus, review domain.UnlockRequestReview) error {
+	request.Status = status
+	request.ReviewedBy = review.ReviewedBy
+	request.ReviewedAt = time.Now()
+
+	return s.repository.Update(ctx, *request)
+}
+
+// unlockIfLocked unlocks pipeline only if it still has the lock unlock was requested for
+func (s *unlockRequestService) unlockIfLocked(ctx context.Context, lock domain.Pipeline) (bool, error) {
+	if unlockRepository, ok := s.pipelineRepository.(domain.PipelineUnlockRepository); ok {
+		return unlockRepository.UnlockIfLocked(ctx, lock)
+	}
+	pipeline, err := s.pipelineRepository.Find(ctx, lock.PipelineIdentifier)
+	if err != nil {
+		return false, err
+	}
+	if pipeline == nil || !pipeline.HasLock(lock) {
+		return false, nil
+	}
+
+	return true, s.pipelineRepository.Add(ctx, domain.Pipeline{PipelineIdentifier: lock.PipelineIdentifier})
+}
+
+func (s *unlockRequestService) addEvent(ctx context.Context, request *domain.UnlockRequest, eventType domain.PipelineEventType, actor string, occurredAt time.Time) error {
+	return s.eventRepository.Add(ctx, domain.PipelineEvent{
+		PipelineIdentifier: request.PipelineIdentifier,
+		Type:               eventType,
+		Actor:              actor,
+		Details:            "requested by " + request.RequestedBy,
+		OccurredAt:         occurredAt,
+	})
+}
+
+// isReviewer reports whether given person is configured to review unlock requests
+func (s *unlockRequestService) isReviewer(name string) bool {
+	return containsFold(s.reviewers, name)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
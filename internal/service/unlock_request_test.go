@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+)
+
+const (
+	unlockRequestID  = "id"
+	unlockRequestTTL = time.Hour
+	reviewer         = "reviewer"
+)
+
+type unlockRequestRepositoryMock struct {
+	domain.UnlockRequestRepository
+	fakeAdd         func(request domain.UnlockRequest)
+	fakeFind        func(id string) *domain.UnlockRequest
+	fakeUpdate      func(request domain.UnlockRequest) error
+	fakeFindPending func() []domain.UnlockRequest
+}
+
+func (r *unlockRequestRepositoryMock) Add(ctx context.Context, request domain.UnlockRequest) error {
+	if r.fakeAdd != nil {
+		r.fakeAdd(request)
+	}
+
+	return nil
+}
+
+func (r *unlockRequestRepositoryMock) Find(ctx context.Context, id string) (*domain.UnlockRequest, error) {
+	if r.fakeFind != nil {
+		return r.fakeFind(id), nil
+	}
+
+	return nil, nil
+}
+
+func (r *unlockRequestRepositoryMock) Update(ctx context.Context, request domain.UnlockRequest) error {
+	if r.fakeUpdate != nil {
+		return r.fakeUpdate(request)
+	}
+
+	return nil
+}
+
+func (r *unlockRequestRepositoryMock) FindPending(ctx context.Context) ([]domain.UnlockRequest, error) {
+	if r.fakeFindPending != nil {
+		return r.fakeFindPending(), nil
+	}
+
+	return make([]domain.UnlockRequest, 0), nil
+}
+
+func getUnlockRequestMock(status domain.UnlockRequestStatus, expiresAt time.Time) *domain.UnlockRequest {
+	return &domain.UnlockRequest{
+		ID:                 unlockRequestID,
+		PipelineIdentifier: getPipelineIdentifierMock(),
+		LockedBy:           user,
+		RequestedBy:        user,
+		ExpiresAt:          expiresAt,
+		Status:             status,
+	}
+}
+
+func TestUnlockRequestService_Create(t *testing.T) {
+	type testCases struct {
+		description                string
+		input                      domain.UnlockRequestCreateRequest
+		fakeFindReturnValue        *domain.Pipeline
+		fakeFindPendingReturnValue []domain.UnlockRequest
+		expectedError              error
+		expectedAddCalls           int
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:   "projectIsEmpty_returnError",
+			input:         domain.UnlockRequestCreateRequest{},
+			expectedError: domain.ErrProjectEmpty,
+		},
+		{
+			description: "pipelineNotFound_returnError",
+			input: domain.UnlockRequestCreateRequest{
+				PipelineIdentifier: getPipelineIdentifierMock(),
+				RequestedBy:        user,
+			},
+			expectedError: domain.ErrPipelineNotLocked,
+		},
+		{
+			description: "pipelineNotLocked_returnError",
+			input: domain.UnlockRequestCreateRequest{
+				PipelineIdentifier: getPipelineIdentifierMock(),
+				RequestedBy:        user,
+			},
+			fakeFindReturnValue: getPipelineMock(""),
+			expectedError:       domain.ErrPipelineNotLocked,
+		},
+		{
+			description: "requestAlreadyPending_returnError",
+			input: domain.UnlockRequestCreateRequest{
+				PipelineIdentifier: getPipelineIdentifierMock(),
+				RequestedBy:        user,
+			},
+			fakeFindReturnValue:        getPipelineMock(user),
+			fakeFindPendingReturnValue: []domain.UnlockRequest{*getUnlockRequestMock(domain.UnlockRequestPending, time.Now().Add(time.Hour))},
+			expectedError:              domain.ErrUnlockRequestAlreadyPending,
+		},
+		{
+			description: "previousRequestExpired_callsAdd",
+			input: domain.UnlockRequestCreateRequest{
+				PipelineIdentifier: getPipelineIdentifierMock(),
+				RequestedBy:        user,
+			},
+			fakeFindReturnValue:        getPipelineMock(user),
+			fakeFindPendingReturnValue: []domain.UnlockRequest{*getUnlockRequestMock(domain.UnlockRequestPending, time.Now().Add(-time.Hour))},
+			expectedAddCalls:           1,
+		},
+		{
+			description: "pipelineLocked_callsAdd",
+			input: domain.UnlockRequestCreateRequest{
+				PipelineIdentifier: getPipelineIdentifierMock(),
+				RequestedBy:        user,
+			},
+			fakeFindReturnValue: getPipelineMock(user),
+			expectedAddCalls:    1,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			var addCallsCount int
+			repository := &unlockRequestRepositoryMock{
+				fakeAdd: func(request domain.UnlockRequest) {
+					addCallsCount++
+				},
+				fakeFindPending: func() []domain.UnlockRequest {
+					return scenario.fakeFindPendingReturnValue
+				},
+			}
+			pipelineRepository := &pipelineRepositoryMock{
+				fakeFind: func(pipeline domain.PipelineIdentifier) *domain.Pipeline {
+					return scenario.fakeFindReturnValue
+				},
+			}
+			service := NewUnlockRequestService(repository, pipelineRepository, &pipelineEventRepositoryMock{}, nil, unlockRequestTTL, domain.EnvironmentAliases{}, PipelineTimeouts{}, logger.New())
+
+			request, err := service.Create(context.Background(), scenario.input)
+
+			if !errors.Is(err, scenario.expectedError) {
+				t.Errorf("Expected error %s, but received %s", scenario.expectedError, err)
+			}
+			if addCallsCount != scenario.expectedAddCalls {
+				t.Errorf("Expected repository Add method calls %d, but Add was called %d times", scenario.expectedAddCalls, addCallsCount)
+			}
+			if scenario.expectedAddCalls == 1 && (request == nil || request.Status != domain.UnlockRequestPending || request.ID == "" || request.LockedBy != user) {
+				t.Errorf("Expected pending request with ID and lock to be returned, got %v", request)
+			}
+		})
+	}
+}
+
+func TestUnlockRequestService_Approve(t *testing.T) {
+	type testCases struct {
+		description                 string
+		review                      domain.UnlockRequestReview
+		reviewers                   []string
+		fakeFindReturnValue         *domain.UnlockRequest
+		fakeUpdateReturnError       error
+		fakeFindPipelineReturnValue *domain.Pipeline
+		expectedError               error
+		expectedUnlocks             int
+		expectedStatus              domain.UnlockRequestStatus
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:   "reviewedByIsEmpty_returnError",
+			expectedError: domain.ErrReviewedByEmpty,
+		},
+		{
+			description:         "reviewersNotConfigured_returnError",
+			review:              domain.UnlockRequestReview{ReviewedBy: reviewer},
+			fakeFindReturnValue: getUnlockRequestMock(domain.UnlockRequestPending, time.Now().Add(time.Hour)),
+			expectedError:       domain.ErrUnlockReviewersNotConfigured,
+		},
+		{
+			description:   "requestNotFound_returnError",
+			review:        domain.UnlockRequestReview{ReviewedBy: reviewer},
+			reviewers:     []string{reviewer},
+			expectedError: domain.ErrUnlockRequestNotFound,
+		},
+		{
+			description:         "requestAlreadyRejected_returnError",
+			review:              domain.UnlockRequestReview{ReviewedBy: reviewer},
+			reviewers:           []string{reviewer},
+			fakeFindReturnValue: getUnlockRequestMock(domain.UnlockRequestRejected, time.Now().Add(time.Hour)),
+			expectedError:       domain.ErrUnlockRequestNotPending,
+		},
+		{
+			description:         "requestExpired_marksExpiredAndReturnsError",
+			review:              domain.UnlockRequestReview{ReviewedBy: reviewer},
+			reviewers:           []string{reviewer},
+			fakeFindReturnValue: getUnlockRequestMock(domain.UnlockRequestPending, time.Now().Add(-time.Hour)),
+			expectedError:       domain.ErrUnlockRequestNotPending,
+			expectedStatus:      domain.UnlockRequestExpired,
+		},
+		{
+			description:         "reviewerIsRequester_returnError",
+			review:              domain.UnlockRequestReview{ReviewedBy: "USER"},
+			reviewers:           []string{user},
+			fakeFindReturnValue: getUnlockRequestMock(domain.UnlockRequestPending, time.Now().Add(time.Hour)),
+			expectedError:       domain.ErrUnlockSelfApprovalNotAllowed,
+		},
+		{
+			description:         "reviewerNotAuthorized_returnError",
+			review:              domain.UnlockRequestReview{ReviewedBy: reviewer},
+			reviewers:           []string{"admin"},
+			fakeFindReturnValue: getUnlockRequestMock(domain.UnlockRequestPending, time.Now().Add(time.Hour)),
+			expectedError:       domain.ErrUnlockReviewerNotAuthorized,
+		},
+		{
+			description:           "requestReviewedConcurrently_returnErrorWithoutUnlocking",
+			review:                domain.UnlockRequestReview{ReviewedBy: reviewer},
+			reviewers:             []string{reviewer},
+			fakeFindReturnValue:   getUnlockRequestMock(domain.UnlockRequestPending, time.Now().Add(time.Hour)),
+			fakeUpdateReturnError: domain.ErrUnlockRequestNotPending,
+			expectedError:         domain.ErrUnlockRequestNotPending,
+			expectedStatus:        domain.UnlockRequestApproved,
+		},
+		{
+			description:                 "pipelineLockedAgain_returnErrorWithoutUnlocking",
+			review:                      domain.UnlockRequestReview{ReviewedBy: reviewer},
+			reviewers:                   []string{reviewer},
+			fakeFindReturnValue:         getUnlockRequestMock(domain.UnlockRequestPending, time.Now().Add(time.Hour)),
+			fakeFindPipelineReturnValue: getPipelineMock("someone else"),
+			expectedError:               domain.ErrUnlockRequestLockChanged,
+			expectedStatus:              domain.UnlockRequestApproved,
+		},
+		{
+			description:                 "reviewerAuthorized_unlocksPipeline",
+			review:                      domain.UnlockRequestReview{ReviewedBy: reviewer},
+			reviewers:                   []string{"admin", reviewer},
+			fakeFindReturnValue:         getUnlockRequestMock(domain.UnlockRequestPending, time.Now().Add(time.Hour)),
+			fakeFindPipelineReturnValue: getPipelineMock(user),
+			expectedUnlocks:             1,
+			expectedStatus:              domain.UnlockRequestApproved,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			var unlocks int
+			var updatedStatus domain.UnlockRequestStatus
+			repository := &unlockRequestRepositoryMock{
+				fakeFind: func(id string) *domain.UnlockRequest {
+					return scenario.fakeFindReturnValue
+				},
+				fakeUpdate: func(request domain.UnlockRequest) error {
+					updatedStatus = request.Status
+					if request.Status == domain.UnlockRequestApproved {
+						return scenario.fakeUpdateReturnError
+					}
+					return nil
+				},
+			}
+			pipelineRepository := &pipelineRepositoryMock{
+				fakeFind: func(pipeline domain.PipelineIdentifier) *domain.Pipeline {
+					return scenario.fakeFindPipelineReturnValue
+				},
+				fakeAdd: func(pipeline domain.Pipeline) {
+					if pipeline.LockedBy == "" {
+						unlocks++
+					}
+				},
+			}
+			service := NewUnlockRequestService(repository, pipelineRepository, &pipelineEventRepositoryMock{}, scenario.reviewers, unlockRequestTTL, domain.EnvironmentAliases{}, PipelineTimeouts{}, logger.New())
+
+			_, err := service.Approve(context.Background(), unlockRequestID, scenario.review)
+
+			if !errors.Is(err, scenario.expectedError) {
+				t.Errorf("Expected error %s, but received %s", scenario.expectedError, err)
+			}
+			if unlocks != scenario.expectedUnlocks {
+				t.Errorf("Expected pipeline to be unlocked %d times, got %d", scenario.expectedUnlocks, unlocks)
+			}
+			if updatedStatus != scenario.expectedStatus {
+				t.Errorf("Expected request to be updated with status '%s', got '%s'", scenario.expectedStatus, updatedStatus)
+			}
+		})
+	}
+}
+
+func TestUnlockRequestService_Reject(t *testing.T) {
+	type testCases struct {
+		description    string
+		review         domain.UnlockRequestReview
+		reviewers      []string
+		expectedError  error
+		expectedStatus domain.UnlockRequestStatus
+	}
+
+	for _, scenario := range []testCases{
+		{
+			description:   "reviewedByIsEmpty_returnError",
+			expectedError: domain.ErrReviewedByEmpty,
+		},
+		{
+			description:   "reviewerNotAuthorized_returnError",
+			review:        domain.UnlockRequestReview{ReviewedBy: reviewer},
+			reviewers:     []string{"admin"},
+			expectedError: domain.ErrUnlockReviewerNotAuthorized,
+		},
+		{
+			description:    "requesterWithdrawsRequest_rejectsRequest",
+			review:         domain.UnlockRequestReview{ReviewedBy: user},
+			reviewers:      []string{"admin"},
+			expectedStatus: domain.UnlockRequestRejected,
+		},
+		{
+			description:    "reviewerAuthorized_rejectsRequest",
+			review:         domain.UnlockRequestReview{ReviewedBy: "admin"},
+			reviewers:      []string{"admin"},
+			expectedStatus: domain.UnlockRequestRejected,
+		},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			var updatedStatus domain.UnlockRequestStatus
+			repository := &unlockRequestRepositoryMock{
+				fakeFind: func(id string) *domain.UnlockRequest {
+					return getUnlockRequestMock(domain.UnlockRequestPending, time.Now().Add(time.Hour))
+				},
+				fakeUpdate: func(request domain.UnlockRequest) error {
+					updatedStatus = request.Status
+					return nil
+				},
+			}
+			pipelineRepository := &pipelineRepositoryMock{
+				fakeAdd: func(pipeline domain.Pipeline) {
+					t.Errorf("Expected pipeline not to be changed on reject")
+				},
+			}
+			service := NewUnlockRequestService(repository, pipelineRepository, &pipelineEventRepositoryMock{}, scenario.reviewers, unlockRequestTTL, domain.EnvironmentAliases{}, PipelineTimeouts{}, logger.New())
+
+			_, err := service.Reject(context.Background(), unlockRequestID, scenario.review)
+
+			if !errors.Is(err, scenario.expectedError) {
+				t.Errorf("Expected error %s, but received %s", scenario.expectedError, err)
+			}
+			if updatedStatus != scenario.expectedStatus {
+				t.Errorf("Expected request to be updated with status '%s', got '%s'", scenario.expectedStatus, updatedStatus)
+			}
+		})
+	}
+}
+
+func TestUnlockRequestService_GetPending(t *testing.T) {
+	t.Run("repositoryReturnsExpiredRequest_filtersExpiredRequests", func(t *testing.T) {
+		repository := &unlockRequestRepositoryMock{
+			fakeFindPending: func() []domain.UnlockRequest {
+				return []domain.UnlockRequest{
+					*getUnlockRequestMock(domain.UnlockRequestPending, time.Now().Add(time.Hour)),
+					*getUnlockRequestMock(domain.UnlockRequestPending, time.Now().Add(-time.Hour)),
+				}
+			},
+		}
+		service := NewUnlockRequestService(repository, &pipelineRepositoryMock{}, &pipelineEventRepositoryMock{}, nil, unlockRequestTTL, domain.EnvironmentAliases{}, PipelineTimeouts{}, logger.New())
+
+		pending, _ := service.GetPending(context.Background())
+
+		if len(pending) != 1 {
+			t.Errorf("Expected one pending request, got %d", len(pending))
+		}
+	})
+}
@@ -3,11 +3,14 @@ package integration_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
-	"github.com/go-redis/redis/v8"
+	"github.com/go-redis/redis/v9"
+	"github.com/gofiber/fiber/v2"
+	"github.com/msoovali/pipeline-locker/internal/app"
 	"github.com/msoovali/pipeline-locker/internal/domain"
-	v6 "github.com/msoovali/pipeline-locker/internal/repository/redis/v6"
+	redis_repository "github.com/msoovali/pipeline-locker/internal/repository/redis"
 	"github.com/msoovali/pipeline-locker/internal/service"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -17,6 +20,7 @@ const (
 	project     = "area51"
 	environment = "production"
 	user        = "bob"
+	keyPrefix   = "{pipeline-locker}/"
 )
 
 type redisContainer struct {
@@ -76,20 +80,21 @@ func TestIntegrationLockUnlock(t *testing.T) {
 	}
 	client := redis.NewClient(options)
 	defer flushRedis(ctx, *client)
+	repositoryClient := redis_repository.NewClient(client)
 
-	repository := v6.NewPipelineRepository(client, true)
-	service := service.NewPipelineService(repository, false)
+	repository := redis_repository.NewPipelineRepository(repositoryClient, true, keyPrefix, testLogger)
+	service := service.NewPipelineService(repository, redis_repository.NewDeploymentRepository(repositoryClient, true, keyPrefix), redis_repository.NewPipelineEventRepository(repositoryClient, true, keyPrefix), service.PipelineServiceConfig{})
 
 	pipeline := getPipelineIdentifierMock()
 	pipelineLockRequest := getPipelineLockRequestMock()
 	// lock pipeline
-	err = service.Lock(pipelineLockRequest)
+	err = service.Lock(context.Background(), pipelineLockRequest)
 	if err != nil {
 		t.Errorf("Failed to lock pipeline: %v", err)
 		return
 	}
 	// check pipeline is locked
-	isAllowed, err := service.IsDeployAllowed(pipeline)
+	isAllowed, err := service.IsDeployAllowed(context.Background(), domain.PipelineStatusRequest{PipelineIdentifier: pipeline})
 	if err != nil {
 		t.Errorf("Failed to get deploy allow status: %v", err)
 		return
@@ -100,13 +105,13 @@ func TestIntegrationLockUnlock(t *testing.T) {
 	}
 	// lock another pipeline
 	pipelineLockRequest.Environment = "dev"
-	err = service.Lock(pipelineLockRequest)
+	err = service.Lock(context.Background(), pipelineLockRequest)
 	if err != nil {
 		t.Errorf("Failed to lock pipeline: %v", err)
 		return
 	}
 	// get locked pipelines
-	pipelines, err := service.GetLockedPipelines()
+	pipelines, err := service.GetLockedPipelines(context.Background())
 	if err != nil {
 		t.Errorf("Failed to get locked pipelines: %v", err)
 		return
@@ -116,13 +121,13 @@ func TestIntegrationLockUnlock(t *testing.T) {
 		return
 	}
 	// unlock pipeline
-	err = service.Unlock(pipeline)
+	err = service.Unlock(context.Background(), pipeline)
 	if err != nil {
 		t.Errorf("Failed to unlock pipeline: %v", err)
 		return
 	}
 	// deploy status is allowed
-	isAllowed, err = service.IsDeployAllowed(pipeline)
+	isAllowed, err = service.IsDeployAllowed(context.Background(), domain.PipelineStatusRequest{PipelineIdentifier: pipeline})
 	if err != nil {
 		t.Errorf("Failed to get deploy allow status: %v", err)
 		return
@@ -133,6 +138,207 @@ func TestIntegrationLockUnlock(t *testing.T) {
 	}
 }
 
+func TestIntegrationPromotion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	ctx := context.Background()
+
+	redisContainer, err := setupRedis(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redisContainer.Terminate(ctx)
+
+	options, err := redis.ParseURL(redisContainer.URI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := redis.NewClient(options)
+	defer flushRedis(ctx, *client)
+	repositoryClient := redis_repository.NewClient(client)
+
+	deploymentRepository := redis_repository.NewDeploymentRepository(repositoryClient, true, keyPrefix)
+	pipelineService := service.NewPipelineService(redis_repository.NewPipelineRepository(repositoryClient, true, keyPrefix, testLogger), deploymentRepository, redis_repository.NewPipelineEventRepository(repositoryClient, true, keyPrefix), service.PipelineServiceConfig{
+		PromotionOrder: domain.PromotionOrder{
+			Default: []string{"staging", environment},
+		},
+	})
+	deploymentService := service.NewDeploymentService(deploymentRepository, domain.EnvironmentAliases{}, service.PipelineTimeouts{})
+
+	request := domain.PipelineStatusRequest{
+		PipelineIdentifier: getPipelineIdentifierMock(),
+		Version:            "1.0.0",
+	}
+	// version is not deployed to staging
+	isAllowed, err := pipelineService.IsDeployAllowed(context.Background(), request)
+	if isAllowed || err != domain.ErrVersionNotPromoted {
+		t.Errorf("Expected version not to be promoted, got %t and %v", isAllowed, err)
+		return
+	}
+	// report successful staging deployment
+	_, err = deploymentService.Report(context.Background(), domain.DeploymentSucceeded, domain.DeploymentReport{
+		PipelineIdentifier: domain.PipelineIdentifier{
+			Project:     project,
+			Environment: "staging",
+		},
+		DeploymentDetails: domain.DeploymentDetails{
+			Version: request.Version,
+		},
+	})
+	if err != nil {
+		t.Errorf("Failed to report deployment: %v", err)
+		return
+	}
+	// version is promoted to production
+	isAllowed, err = pipelineService.IsDeployAllowed(context.Background(), request)
+	if err != nil {
+		t.Errorf("Failed to get deploy allow status: %v", err)
+		return
+	}
+	if !isAllowed {
+		t.Errorf("Expected version to be promoted, but it is not")
+	}
+}
+
+func TestIntegrationRedisKeyNamespace(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	ctx := context.Background()
+
+	redisContainer, err := setupRedis(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redisContainer.Terminate(ctx)
+
+	options, err := redis.ParseURL(redisContainer.URI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := redis.NewClient(options)
+	defer flushRedis(ctx, *client)
+	repositoryClient := redis_repository.NewClient(client)
+
+	// pipeline written by earlier version without key prefix and keys of another application
+	client.Set(ctx, project+":"+environment, `{"project":"`+project+`","environment":"`+environment+`","locked_by":"`+user+`"}`, 0)
+	client.Set(ctx, "session:123", "not json", 0)
+	client.HSet(ctx, "cache:"+project, "field", "value")
+	client.Set(ctx, keyPrefix+"pipeline/broken", "not json", 0)
+	repository := redis_repository.NewPipelineRepository(repositoryClient, true, keyPrefix, testLogger)
+
+	if unprefixed, err := repository.CountUnprefixedKeys(); err != nil || unprefixed != 1 {
+		t.Fatalf("Expected 1 unprefixed key, got %d and error %v", unprefixed, err)
+	}
+	if unprefixed, err := repository.CountUnprefixedKeys(); err != nil || unprefixed != 1 {
+		t.Fatalf("Expected 1 unprefixed key, got %d and error %v", unprefixed, err)
+	}
+	migrated, err := repository.MigrateUnprefixedKeys()
+	if err != nil || migrated != 1 {
+		t.Fatalf("Expected 1 migrated key, got %d and error %v", migrated, err)
+	}
+	if unprefixed, err := repository.CountUnprefixedKeys(); err != nil || unprefixed != 0 {
+		t.Fatalf("Expected no unprefixed keys after migration, got %d and error %v", unprefixed, err)
+	}
+	if unprefixed, err := repository.CountUnprefixedKeys(); err != nil || unprefixed != 0 {
+		t.Fatalf("Expected no unprefixed keys after migration, got %d and error %v", unprefixed, err)
+	}
+	if unindexed, err := repository.CountUnindexedLocks(); err != nil || unindexed != 1 {
+		t.Fatalf("Expected 1 unindexed lock, got %d and error %v", unindexed, err)
+	}
+	indexed, err := repository.IndexLockedPipelines()
+	if err != nil || indexed != 1 {
+		t.Fatalf("Expected 1 indexed pipeline, got %d and error %v", indexed, err)
+	}
+	if unindexed, err := repository.CountUnindexedLocks(); err != nil || unindexed != 0 {
+		t.Fatalf("Expected no unindexed locks after indexing, got %d and error %v", unindexed, err)
+	}
+	pipelines, err := repository.FindLockedPipelines(context.Background())
+	if err != nil {
+		t.Fatalf("Expected undecodable keys to be skipped, got error %v", err)
+	}
+	if len(pipelines) != 1 || pipelines[0].LockedBy != user {
+		t.Errorf("Expected migrated pipeline locked by %s, got %v", user, pipelines)
+	}
+	if exists, _ := client.Exists(ctx, "session:123", "cache:"+project).Result(); exists != 2 {
+		t.Errorf("Expected keys of another application to be left untouched, %d of 2 exist", exists)
+	}
+}
+
+func TestIntegrationRedisMigrateKeysOnStartup(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	ctx := context.Background()
+
+	redisContainer, err := setupRedis(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redisContainer.Terminate(ctx)
+
+	options, err := redis.ParseURL(redisContainer.URI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := redis.NewClient(options)
+	defer flushRedis(ctx, *client)
+
+	// locked pipeline written by earlier version without key prefix nor locked pipelines index
+	client.Set(ctx, project+":"+environment, `{"project":"`+project+`","environment":"`+environment+`","locked_by":"`+user+`"}`, 0)
+	t.Setenv("STORAGE", "redis")
+	t.Setenv("REDIS_URL", redisContainer.URI)
+	t.Setenv("REDIS_MIGRATE_KEYS", "true")
+
+	application := app.New(fiber.New())
+
+	pipelines, err := application.Repositories.PipelineRepository.FindLockedPipelines(ctx)
+	if err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+	if len(pipelines) != 1 || pipelines[0].LockedBy != user {
+		t.Errorf("Expected migrated pipeline locked by %s to be listed, got %v", user, pipelines)
+	}
+}
+
+func TestIntegrationRedisDetectServer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	ctx := context.Background()
+
+	redisContainer, err := setupRedis(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redisContainer.Terminate(ctx)
+
+	options, err := redis.ParseURL(redisContainer.URI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := redis.NewClient(options)
+
+	server, err := redis_repository.DetectServer(ctx, redis_repository.NewClient(client))
+	if err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+	if server.Flavor != redis_repository.FlavorRedis || !strings.HasPrefix(server.Version, "6.") || server.Cluster {
+		t.Errorf("Expected standalone Redis 6, got %s", server)
+	}
+
+	// server is unreachable after container is stopped
+	redisContainer.Terminate(ctx)
+	if _, err = redis_repository.DetectServer(ctx, redis_repository.NewClient(client)); err == nil {
+		t.Errorf("Expected error of unreachable server")
+	}
+}
+
 func getPipelineIdentifierMock() domain.PipelineIdentifier {
 	return domain.PipelineIdentifier{
 		Project:     project,
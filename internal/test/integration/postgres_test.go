@@ -0,0 +1,283 @@
+package integration_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	"github.com/msoovali/pipeline-locker/internal/logger"
+	"github.com/msoovali/pipeline-locker/internal/repository/postgres"
+	"github.com/msoovali/pipeline-locker/internal/service"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const overrideTTL = time.Minute
+
+var testLogger = logger.New()
+
+type postgresContainer struct {
+	testcontainers.Container
+	URI string
+}
+
+func setupPostgres(ctx context.Context) (*postgresContainer, error) {
+	req := testcontainers.ContainerRequest{
+		Image:        "postgres:14-alpine",
+		ExposedPorts: []string{"5432/tcp"},
+		Env: map[string]string{
+			"POSTGRES_USER":     "locker",
+			"POSTGRES_PASSWORD": "locker",
+			"POSTGRES_DB":       "locker",
+		},
+		// server is restarted once after init scripts
+		WaitingFor: wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+	}
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mappedPort, err := container.MappedPort(ctx, "5432")
+	if err != nil {
+		return nil, err
+	}
+
+	hostIP, err := container.Host(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := fmt.Sprintf("postgres://locker:locker@%s:%s/locker?sslmode=disable", hostIP, mappedPort.Port())
+
+	return &postgresContainer{Container: container, URI: uri}, nil
+}
+
+func openPostgres(t *testing.T) *sql.DB {
+	ctx := context.Background()
+	postgresContainer, err := setupPostgres(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		postgresContainer.Terminate(ctx)
+	})
+	db, err := postgres.Open(postgresContainer.URI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return db
+}
+
+func newPostgresPipelineService(db *sql.DB, config service.PipelineServiceConfig) domain.PipelineService {
+	return service.NewPipelineService(
+		postgres.NewPipelineRepository(db, true),
+		postgres.NewDeploymentRepository(db, true),
+		postgres.NewPipelineEventRepository(db, true),
+		config,
+	)
+}
+
+func TestIntegrationPostgresLockUnlock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	db := openPostgres(t)
+	// migrations are applied only once
+	if err := postgres.Migrate(db); err != nil {
+		t.Fatalf("Failed to migrate already migrated database: %v", err)
+	}
+	service := newPostgresPipelineService(db, service.PipelineServiceConfig{})
+
+	pipeline := getPipelineIdentifierMock()
+	pipelineLockRequest := getPipelineLockRequestMock()
+	// lock pipeline
+	if err := service.Lock(context.Background(), pipelineLockRequest); err != nil {
+		t.Fatalf("Failed to lock pipeline: %v", err)
+	}
+	// check pipeline is locked
+	isAllowed, err := service.IsDeployAllowed(context.Background(), domain.PipelineStatusRequest{PipelineIdentifier: pipeline})
+	if err != nil {
+		t.Fatalf("Failed to get deploy allow status: %v", err)
+	}
+	if isAllowed {
+		t.Fatalf("Expected pipeline to be locked, but it is not")
+	}
+	// locked pipeline can't be locked again
+	if err = service.Lock(context.Background(), pipelineLockRequest); err != domain.ErrPipelineAlreadyLocked {
+		t.Fatalf("Expected %v, got %v", domain.ErrPipelineAlreadyLocked, err)
+	}
+	// lock another pipeline
+	pipelineLockRequest.Environment = "dev"
+	if err = service.Lock(context.Background(), pipelineLockRequest); err != nil {
+		t.Fatalf("Failed to lock pipeline: %v", err)
+	}
+	// get locked pipelines
+	pipelines, err := service.GetLockedPipelines(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to get locked pipelines: %v", err)
+	}
+	if len(pipelines) != 2 {
+		t.Fatalf("Expected 2 locked pipelines, but got %d", len(pipelines))
+	}
+	// unlock pipeline
+	if err = service.Unlock(context.Background(), pipeline); err != nil {
+		t.Fatalf("Failed to unlock pipeline: %v", err)
+	}
+	// deploy status is allowed
+	isAllowed, err = service.IsDeployAllowed(context.Background(), domain.PipelineStatusRequest{PipelineIdentifier: pipeline})
+	if err != nil {
+		t.Fatalf("Failed to get deploy allow status: %v", err)
+	}
+	if !isAllowed {
+		t.Fatalf("Expected pipeline to be unlocked, but it is not")
+	}
+	// lock and unlock are in pipeline history
+	details, err := service.GetPipelineDetails(context.Background(), pipeline)
+	if err != nil {
+		t.Fatalf("Failed to get pipeline details: %v", err)
+	}
+	if len(details.Timeline) != 2 || details.Timeline[0].Type != domain.PipelineUnlockedEvent {
+		t.Errorf("Expected unlock and lock events in history, got %v", details.Timeline)
+	}
+}
+
+func TestIntegrationPostgresConcurrentLock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	const lockers = 10
+	service := newPostgresPipelineService(openPostgres(t), service.PipelineServiceConfig{})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var succeeded int
+	for i := 0; i < lockers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			request := getPipelineLockRequestMock()
+			request.LockedBy = fmt.Sprintf("user%d", i)
+			if err := service.Lock(context.Background(), request); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Errorf("Expected exactly one of concurrent locks to succeed, but %d succeeded", succeeded)
+	}
+}
+
+func TestIntegrationPostgresDeploymentsAndOverrides(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	db := openPostgres(t)
+	deploymentService := service.NewDeploymentService(postgres.NewDeploymentRepository(db, true), domain.EnvironmentAliases{}, service.PipelineTimeouts{})
+	overrideRepository := postgres.NewOverrideTokenRepository(db, true)
+	pipeline := getPipelineIdentifierMock()
+
+	for _, version := range []string{"1.0.0", "1.1.0"} {
+		_, err := deploymentService.Report(context.Background(), domain.DeploymentSucceeded, domain.DeploymentReport{
+			PipelineIdentifier: pipeline,
+			DeploymentDetails: domain.DeploymentDetails{
+				Version: version,
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to report deployment: %v", err)
+		}
+	}
+	current, err := deploymentService.GetCurrent(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to get current deployments: %v", err)
+	}
+	if len(current) != 1 || current[0].Version != "1.1.0" {
+		t.Errorf("Expected current deployment 1.1.0, got %v", current)
+	}
+	history, err := deploymentService.GetHistory(context.Background(), pipeline)
+	if err != nil {
+		t.Fatalf("Failed to get deployment history: %v", err)
+	}
+	if len(history) != 2 || history[0].Version != "1.1.0" {
+		t.Errorf("Expected deployment history newest first, got %v", history)
+	}
+
+	overrideService := service.NewOverrideService(overrideRepository, postgres.NewPipelineEventRepository(db, true), overrideTTL, domain.EnvironmentAliases{}, service.PipelineTimeouts{}, testLogger)
+	token, err := overrideService.Issue(context.Background(), domain.OverrideTokenRequest{
+		PipelineIdentifier: pipeline,
+		IssuedBy:           user,
+	})
+	if err != nil {
+		t.Fatalf("Failed to issue override token: %v", err)
+	}
+	for i, expected := range []bool{true, false} {
+		allowed, err := overrideService.Use(context.Background(), pipeline, token.Token)
+		if err != nil {
+			t.Fatalf("Failed to use override token: %v", err)
+		}
+		if allowed != expected {
+			t.Errorf("Expected override token usage %d to return %t, got %t", i+1, expected, allowed)
+		}
+	}
+}
+
+func TestIntegrationPostgresUnlockRequestUpdate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	repository := postgres.NewUnlockRequestRepository(openPostgres(t))
+	now := time.Now()
+	request := domain.UnlockRequest{
+		ID:                 "request",
+		PipelineIdentifier: getPipelineIdentifierMock(),
+		RequestedBy:        user,
+		RequestedAt:        now,
+		ExpiresAt:          now.Add(time.Hour),
+		Status:             domain.UnlockRequestPending,
+	}
+	// missing request is not created by update
+	if err := repository.Update(context.Background(), request); err != domain.ErrUnlockRequestNotFound {
+		t.Fatalf("Expected %v, got %v", domain.ErrUnlockRequestNotFound, err)
+	}
+	if err := repository.Add(context.Background(), request); err != nil {
+		t.Fatalf("Failed to add unlock request: %v", err)
+	}
+	request.Status = domain.UnlockRequestRejected
+	request.ReviewedBy = "reviewer"
+	request.ReviewedAt = now
+	if err := repository.Update(context.Background(), request); err != nil {
+		t.Fatalf("Failed to update unlock request: %v", err)
+	}
+	updated, err := repository.Find(context.Background(), request.ID)
+	if err != nil {
+		t.Fatalf("Failed to find unlock request: %v", err)
+	}
+	if updated == nil || updated.Status != domain.UnlockRequestRejected || updated.ReviewedBy != "reviewer" {
+		t.Errorf("Expected unlock request to be rejected by reviewer, got %v", updated)
+	}
+	// reviewed request is not reviewed again
+	request.Status = domain.UnlockRequestApproved
+	if err = repository.Update(context.Background(), request); err != domain.ErrUnlockRequestNotPending {
+		t.Errorf("Expected %v, got %v", domain.ErrUnlockRequestNotPending, err)
+	}
+}
@@ -0,0 +1,136 @@
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+	"github.com/msoovali/pipeline-locker/internal/domain"
+	redis_repository "github.com/msoovali/pipeline-locker/internal/repository/redis"
+)
+
+const (
+	benchmarkPipelines       = 5000
+	benchmarkLockedPipelines = 50
+)
+
+func TestIntegrationRedisLockedPipelinesIndex(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	ctx := context.Background()
+
+	redisContainer, err := setupRedis(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redisContainer.Terminate(ctx)
+
+	options, err := redis.ParseURL(redisContainer.URI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := redis.NewClient(options)
+	defer flushRedis(ctx, *client)
+	repositoryClient := redis_repository.NewClient(client)
+
+	repository := redis_repository.NewPipelineRepository(repositoryClient, true, keyPrefix, testLogger)
+	expired := time.Now().Add(-time.Minute)
+	expiring := time.Now().Add(time.Minute)
+	for _, pipeline := range []domain.Pipeline{
+		newBenchmarkPipeline("locked", user, nil),
+		newBenchmarkPipeline("expiring", user, &expiring),
+		newBenchmarkPipeline("expired", user, &expired),
+		newBenchmarkPipeline("unlocked", "", nil),
+	} {
+		if err = repository.Add(context.Background(), pipeline); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// unlock removes pipeline from index
+	repository.Add(context.Background(), newBenchmarkPipeline("locked", "", nil))
+
+	pipelines, err := repository.FindLockedPipelines(context.Background())
+	if err != nil || len(pipelines) != 1 || pipelines[0].Environment != "expiring" {
+		t.Errorf("Expected only expiring pipeline to be locked, got %v and error %v", pipelines, err)
+	}
+	if size, _ := client.ZCard(ctx, keyPrefix+"pipelines/locked").Result(); size != 1 {
+		t.Errorf("Expected unlocked and expired pipelines to be removed from index, index has %d members", size)
+	}
+}
+
+// BenchmarkIntegrationRedisFindLockedPipelines compares locked pipelines index to scanning every pipeline
+func BenchmarkIntegrationRedisFindLockedPipelines(b *testing.B) {
+	if testing.Short() {
+		b.Skip("Skipping integration benchmark")
+	}
+
+	ctx := context.Background()
+
+	redisContainer, err := setupRedis(ctx)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer redisContainer.Terminate(ctx)
+
+	options, err := redis.ParseURL(redisContainer.URI)
+	if err != nil {
+		b.Fatal(err)
+	}
+	client := redis.NewClient(options)
+	defer flushRedis(ctx, *client)
+	repositoryClient := redis_repository.NewClient(client)
+
+	repository := redis_repository.NewPipelineRepository(repositoryClient, true, keyPrefix, testLogger)
+	for i := 0; i < benchmarkPipelines; i++ {
+		lockedBy := ""
+		if i < benchmarkLockedPipelines {
+			lockedBy = user
+		}
+		if err = repository.Add(context.Background(), newBenchmarkPipeline(fmt.Sprintf("env-%d", i), lockedBy, nil)); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("Index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if pipelines, _ := repository.FindLockedPipelines(context.Background()); len(pipelines) != benchmarkLockedPipelines {
+				b.Fatalf("Expected %d locked pipelines, got %d", benchmarkLockedPipelines, len(pipelines))
+			}
+		}
+	})
+
+	b.Run("Scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pipelines, _ := repository.FindAll(context.Background())
+			locked := 0
+			now := time.Now()
+			for _, p := range pipelines {
+				if p.IsLocked(now) {
+					locked++
+				}
+			}
+			if locked != benchmarkLockedPipelines {
+				b.Fatalf("Expected %d locked pipelines, got %d", benchmarkLockedPipelines, locked)
+			}
+		}
+	})
+}
+
+func newBenchmarkPipeline(environment, lockedBy string, lockedUntil *time.Time) domain.Pipeline {
+	return domain.Pipeline{
+		PipelineIdentifier: domain.PipelineIdentifier{
+			Project:     project,
+			Environment: environment,
+		},
+		PipelineLockedBy: domain.PipelineLockedBy{
+			LockedBy: lockedBy,
+		},
+		PipelineLockedUntil: domain.PipelineLockedUntil{
+			LockedUntil: lockedUntil,
+		},
+	}
+}
@@ -1,10 +1,10 @@
 #!/bin/bash
 
-status_code=$(curl --write-out %{http_code} --silent --output /dev/null https://pipeline-checker.example/v1/pipeline/status/project/proj/environment/test)
+status_code=$(curl --write-out %{http_code} --silent --output /dev/null "https://pipeline-checker.example/v1/pipeline/status/project/proj/environment/test?version=${VERSION}&override_token=${OVERRIDE_TOKEN}")
 
 if [[ "$status_code" -ne 423 ]] ; then
   exit 0
 else
-  echo "Pipeline is locked!"
+  echo "Pipeline is locked or version is not promoted!"
   exit 1
 fi
\ No newline at end of file